@@ -0,0 +1,124 @@
+package gotogen
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// capability is an optional feature of the driver (or its displays), and whether this build has it.
+type capability struct {
+	name string
+	has  bool
+}
+
+// checkCapabilities works out which optional features the driver supports, so that users can see why a feature
+// doesn't do anything on their build.
+func (g *Gotogen) checkCapabilities() {
+	_, boop := g.driver.BoopDistance()
+	_, _, _, accel := g.driver.Accelerometer()
+	_, battery := g.driver.(BatteryDriver)
+	_, audio := g.driver.(AudioOutput)
+	_, buttons := g.driver.(ButtonStateDriver)
+	_, settings := g.driver.(SettingsStorage)
+	_, media := g.driver.(MediaStorage)
+	_, provider := g.driver.(MediaProviderDriver)
+	_, chars := g.driver.(CharacterProvider)
+	_, qrs := g.driver.(QRCodeProvider)
+	_, exprs := g.driver.(ExpressionSource)
+	_, shots := g.driver.(ScreenshotStorage)
+	_, commands := g.driver.(CommandSource)
+	_, peers := g.driver.(PeerLink)
+	_, clock := g.driver.(WallClock)
+	_, vibrate := g.driver.(Vibrator)
+	_, icons := g.driver.(StatusIconProvider)
+	_, pages := g.driver.(StatusPageProvider)
+	_, logs := g.driver.(LogStorage)
+	_, temp := g.driver.(TemperatureSensor)
+	_, talkLevel := g.driver.(TalkingLevelSensor)
+	_, heartbeat := g.driver.(HeartbeatSink)
+	_, layout := g.driver.(FaceLayoutProvider)
+	_, font := g.driver.(StatusFontProvider)
+	_, rgbLED := g.blinker.(StatusLED)
+	_, hwBright := g.faceDisplay.(BrightnessDisplay)
+	_, partial := g.faceDisplay.(PartialDisplay)
+	_, limited := g.faceDisplay.(LimitedColorDisplay)
+	g.capabilities = []capability{
+		{"boop", boop != SensorStatusUnavailable},
+		{"accel", accel != SensorStatusUnavailable},
+		{"battery", battery},
+		{"audio", audio},
+		{"buttons", buttons},
+		{"settings", settings},
+		{"media", media},
+		{"provider", provider},
+		{"characters", chars},
+		{"qr", qrs},
+		{"expressions", exprs},
+		{"shots", shots},
+		{"commands", commands},
+		{"peers", peers},
+		{"clock", clock},
+		{"vibrate", vibrate},
+		{"icons", icons},
+		{"pages", pages},
+		{"logs", logs},
+		{"temp", temp},
+		{"talk level", talkLevel},
+		{"heartbeat", heartbeat},
+		{"layout", layout},
+		{"font", font},
+		{"RGB LED", rgbLED},
+		{"HW bright", hwBright},
+		{"partial", partial},
+		{"few colors", limited},
+	}
+}
+
+// reportCapabilities logs the capabilities, and lists the ones this build has in the boot log.
+func (g *Gotogen) reportCapabilities() {
+	var names []string
+	for _, c := range g.capabilities {
+		if c.has {
+			g.log(LogInfo, "capability "+c.name+": yes")
+			names = append(names, c.name)
+		} else {
+			g.log(LogInfo, "capability "+c.name+": no")
+		}
+	}
+	w, _ := g.statusText.Size()
+	for _, line := range wordWrap("Has: "+strings.Join(names, ", "), int(w)) {
+		_ = g.statusText.Println(line)
+	}
+}
+
+// aboutLines are the lines of the About page: the display sizes, then every capability and whether this build has it.
+func (g *Gotogen) aboutLines() []string {
+	w, _ := g.statusText.Size()
+	fw, fh := g.faceDisplay.Size()
+	lines := []string{
+		"Face " + strconv.Itoa(int(fw)) + "x" + strconv.Itoa(int(fh)),
+		"RAM " + g.totalRAM + "k",
+	}
+	for _, c := range g.capabilities {
+		v := "no"
+		if c.has {
+			v = "yes"
+		}
+		lines = append(lines, padLine(c.name, v, int(w)))
+	}
+	return lines
+}
+
+// padLine puts the name at the start of the line and the value at the end.
+func padLine(name, value string, width int) string {
+	pad := width - utf8.RuneCountInString(name) - utf8.RuneCountInString(value)
+	if pad < 1 {
+		pad = 1
+	}
+	return name + strings.Repeat(" ", pad) + value
+}
+
+func (g *Gotogen) showAbout() {
+	g.showPage("ABOUT", g.aboutLines())
+}
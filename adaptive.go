@@ -0,0 +1,83 @@
+package gotogen
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	// maxAutoFrameSkip is the largest frame skip the adaptive status redraw will pick.
+	maxAutoFrameSkip = 16
+	// autoSkipInterval is how often the adaptive status redraw re-evaluates the frame skip.
+	autoSkipInterval = time.Second
+)
+
+// statusTiming keeps running averages of how long the main loop spends on the face and on the status display, to
+// automatically choose a status frame skip that keeps the face running at the target framerate.
+type statusTiming struct {
+	// face is the average time taken by a tick, excluding refreshing the status display.
+	face time.Duration
+	// status is the average time taken to refresh the status display, when it was refreshed.
+	status time.Duration
+	// next is when to next re-evaluate the frame skip.
+	next time.Time
+}
+
+// average folds the sample into the running average, weighted 1/8 for the new sample.
+func average(avg, sample time.Duration) time.Duration {
+	if avg == 0 {
+		return sample
+	}
+	return avg + (sample-avg)/8
+}
+
+// recordTickTiming is called at the end of every tick with how long the tick took in total, and how much of that was
+// spent refreshing the status display (zero if it was not refreshed this tick).
+func (g *Gotogen) recordTickTiming(total, status time.Duration) {
+	t := &g.statusTiming
+	t.face = average(t.face, total-status)
+	if status > 0 {
+		t.status = average(t.status, status)
+	}
+
+	if !g.statusAutoSkip {
+		return
+	}
+	now := time.Now()
+	if now.Before(t.next) {
+		return
+	}
+	t.next = now.Add(autoSkipInterval)
+
+	skip := g.autoFrameSkip()
+	if skip != g.statusFrameSkip {
+		g.log(LogDebug, "adjusting status frame skip to "+strconv.Itoa(int(skip)))
+		g.statusFrameSkip = skip
+	}
+}
+
+// autoFrameSkip determines the smallest frame skip that leaves enough time in each frame to refresh the status
+// display. Skips are always a power of two, like the ones available in the menu.
+func (g *Gotogen) autoFrameSkip() uint8 {
+	t := &g.statusTiming
+	spare := g.frameTime - t.face
+	if spare <= 0 {
+		// the face alone is already over budget; get the status display out of the way as much as possible
+		return maxAutoFrameSkip
+	}
+
+	// when reducing the skip, require some headroom so that it doesn't flap between two values
+	status := t.status
+	if g.statusFrameSkip > 1 {
+		status += status / 4
+	}
+
+	skip := uint8(1)
+	for time.Duration(skip)*spare < status && skip < maxAutoFrameSkip {
+		skip <<= 1
+	}
+	if skip == 1 {
+		return 0
+	}
+	return skip
+}
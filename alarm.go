@@ -0,0 +1,209 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/fmtlite"
+)
+
+const (
+	alarmsSetting = "alarms"
+	// maxAlarms is how many alarms and timers can be set at once.
+	maxAlarms = 8
+	// alarmBannerTime is how much longer than a normal caption an alarm stays on the status display.
+	alarmBannerTime = 10 * time.Second
+	// alarmVibrateTime is how long to vibrate for an alarm.
+	alarmVibrateTime = 500 * time.Millisecond
+	// alarmSound is the sound played via AudioOutput for an alarm.
+	alarmSound = "alarm"
+)
+
+// Vibrator is an optional interface that a Driver may implement if it has a vibration motor.
+type Vibrator interface {
+	// Vibrate starts vibrating for the duration. It should not wait for it to finish.
+	Vibrate(d time.Duration)
+}
+
+// alarm is a reminder shown on the status display at a time of day, or after a delay.
+type alarm struct {
+	// minute of the day on the wall clock, or -1 for a timer
+	minute int
+	// when a timer goes off
+	deadline time.Time
+	label    string
+}
+
+// parseAlarmTime parses HH:MM into the minute of the day.
+func parseAlarmTime(s string) (int, error) {
+	h, m, ok := strings.Cut(s, ":")
+	hour, err1 := strconv.Atoi(h)
+	minute, err2 := strconv.Atoi(m)
+	if !ok || err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, errors.New("bad time " + s + ", need HH:MM")
+	}
+	return hour*60 + minute, nil
+}
+
+// SetAlarm sets a reminder to go off once at the time of day (HH:MM), which requires the time of day to be known (see
+// WallClock). Alarms are saved across reboots.
+//
+// SetAlarm must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) SetAlarm(at string, label string) error {
+	g.owner.check()
+	if len(g.alarms) >= maxAlarms {
+		return errors.New("too many alarms")
+	}
+	if _, ok := g.wallClock(); !ok {
+		return errors.New("time not known")
+	}
+	m, err := parseAlarmTime(at)
+	if err != nil {
+		return err
+	}
+	if label == "" {
+		label = "Alarm"
+	}
+	g.alarms = append(g.alarms, alarm{minute: m, label: label})
+	g.saveAlarms()
+	return nil
+}
+
+// SetTimer sets a reminder to go off after the delay. Timers are not saved across reboots.
+//
+// SetTimer must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) SetTimer(d time.Duration, label string) error {
+	g.owner.check()
+	if len(g.alarms) >= maxAlarms {
+		return errors.New("too many alarms")
+	}
+	if label == "" {
+		label = "Timer"
+	}
+	g.alarms = append(g.alarms, alarm{minute: -1, deadline: time.Now().Add(d), label: label})
+	return nil
+}
+
+// ClearAlarms removes every alarm and timer.
+//
+// ClearAlarms must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) ClearAlarms() {
+	g.owner.check()
+	g.alarms = g.alarms[:0]
+	g.saveAlarms()
+}
+
+// updateAlarms sets off any alarms that are due.
+func (g *Gotogen) updateAlarms() {
+	if len(g.alarms) == 0 {
+		return
+	}
+	now := time.Now()
+	t, clockOK := g.wallClock()
+	minute := t.Hour()*60 + t.Minute()
+	fired := false
+	for i := 0; i < len(g.alarms); i++ {
+		a := g.alarms[i]
+		due := (a.minute < 0 && now.After(a.deadline)) || (a.minute >= 0 && clockOK && a.minute == minute)
+		if !due {
+			continue
+		}
+		g.alarms = append(g.alarms[:i], g.alarms[i+1:]...)
+		i--
+		if a.minute >= 0 {
+			fired = true
+		}
+		g.alarmAlert(a.label)
+	}
+	if fired {
+		g.saveAlarms()
+	}
+}
+
+// alarmAlert shows the alarm banner and vibrates and/or chirps, if enabled.
+func (g *Gotogen) alarmAlert(label string) {
+	g.log(LogInfo, "alarm: "+label)
+	g.queueCaption(caption{text: "** " + label + " **", extra: alarmBannerTime})
+	if g.alarmAlerts&1 != 0 {
+		if ao, ok := g.driver.(AudioOutput); ok {
+			err := ao.PlaySound(alarmSound)
+			if err != nil {
+				g.log(LogWarning, "alarm sound: "+err.Error())
+			}
+		}
+	}
+	if g.alarmAlerts&2 != 0 {
+		if v, ok := g.driver.(Vibrator); ok {
+			v.Vibrate(alarmVibrateTime)
+		}
+	}
+}
+
+func (g *Gotogen) loadAlarms() {
+	b, ok := g.loadSetting(alarmsSetting)
+	if !ok {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		at, label, _ := strings.Cut(line, " ")
+		m, err := parseAlarmTime(at)
+		if err != nil || len(g.alarms) >= maxAlarms {
+			continue
+		}
+		g.alarms = append(g.alarms, alarm{minute: m, label: label})
+	}
+}
+
+// saveAlarms saves the time-of-day alarms as "HH:MM LABEL" lines.
+func (g *Gotogen) saveAlarms() {
+	var b []byte
+	for _, a := range g.alarms {
+		if a.minute < 0 {
+			continue
+		}
+		b = appendAlarmTime(b, a.minute)
+		b = append(b, ' ')
+		b = append(b, a.label...)
+		b = append(b, '\n')
+	}
+	g.saveSetting(alarmsSetting, b)
+}
+
+// alarmTime formats the minute of the day as HH:MM.
+func alarmTime(m int) string {
+	return string(appendAlarmTime(nil, m))
+}
+
+// appendAlarmTime is alarmTime without the garbage, for the status screen.
+func appendAlarmTime(b []byte, m int) []byte {
+	return fmtlite.AppendClock(b, m/60, m%60)
+}
+
+func (g *Gotogen) alarmsMenu() *Menu {
+	timer := func(name string, d time.Duration) Item {
+		return &ActionItem{
+			Name:   name,
+			Invoke: func() { g.reportError(g.SetTimer(d, "")) },
+		}
+	}
+	return &Menu{
+		Name: "Alarms",
+		Items: []Item{
+			timer("Timer 15 min", 15*time.Minute),
+			timer("Timer 30 min", 30*time.Minute),
+			timer("Timer 1 hour", time.Hour),
+			&ActionItem{
+				Name:   "Clear alarms",
+				Invoke: g.ClearAlarms,
+			},
+			&SettingItem{
+				Name:    "Alert",
+				Options: []string{"banner", "+sound", "+vibrate", "+both"},
+				Active:  3,
+				Apply:   func(selected uint8) { g.alarmAlerts = selected },
+			},
+		},
+	}
+}
@@ -0,0 +1,178 @@
+package gotogen
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/animation/animated"
+	"github.com/ajanata/gotogen/internal/animation/peek"
+	"github.com/ajanata/gotogen/internal/animation/sequence"
+	"github.com/ajanata/gotogen/internal/animation/slide"
+	"github.com/ajanata/gotogen/internal/animation/static"
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+// Animation is a full-screen animation on the face. DrawFrame is called every frame with the face display until it
+// returns false. Animations may also implement the optional hints from the animation package that Gotogen checks
+// for: Regioned, NoStatusPreview, and BoopListener.
+type Animation = animation.Animation
+
+type (
+	// Regioned is an optional interface for animations that only draw parts of the display each frame.
+	Regioned = animation.Regioned
+	// NoStatusPreview is an optional hint for animations that are too expensive to also preview on the status screen.
+	NoStatusPreview = animation.NoStatusPreview
+	// BoopListener is an optional interface for animations that react to boops.
+	BoopListener = animation.BoopListener
+)
+
+// animationKind is a way of animating a full-face image.
+type animationKind struct {
+	name string
+	new  func(file string) (animation.Animation, error)
+	// sequence is set for kinds that play a numbered sequence of images (see media.LoadSequence), and are offered for
+	// sequences instead of single images
+	sequence bool
+}
+
+// animationKinds are all the ways a full-face image can be animated, in the order they appear in the menu.
+var animationKinds = []animationKind{
+	{"Static", static.New, false},
+	{"Slide", slide.New, false},
+	{"Peek", peek.New, false},
+	// plays animated GIFs frame by frame
+	{"Animated", animated.New, false},
+	// plays numbered images like wave_000 to wave_029 frame by frame
+	{"Sequence", sequence.New, true},
+}
+
+// findAnimationKind finds the kind of animation by name, case-insensitively.
+func findAnimationKind(name string) (animationKind, bool) {
+	for _, k := range animationKinds {
+		if equalFold(k.name, name) {
+			return k, true
+		}
+	}
+	return animationKind{}, false
+}
+
+// RegisterAnimation adds a kind of full-screen animation from an application or driver. It is offered for every
+// full-screen image in the full-screen animations menu, after the built-in kinds, and can be started by name like
+// them, e.g. with StartAnimationByName or the "anim" command. factory makes the animation for an image file, which it
+// may load with any method it likes, or ignore.
+//
+// RegisterAnimation must be called before Init. It panics if the name is empty, has spaces, or is already taken, since
+// that is a mistake in the program rather than something that can be handled.
+func RegisterAnimation(name string, factory func(file string) (Animation, error)) {
+	if name == "" || strings.ContainsAny(name, " \t\r\n") {
+		panic("gotogen: invalid animation name \"" + name + "\"")
+	}
+	if _, ok := findAnimationKind(name); ok {
+		panic("gotogen: animation " + name + " registered twice")
+	}
+	animationKinds = append(animationKinds, animationKind{name, factory, false})
+}
+
+// AnimationOption changes how an animation started with StartAnimation plays.
+type AnimationOption func(*animationOptions)
+
+type animationOptions struct {
+	kind       string
+	onComplete func()
+	duration   time.Duration
+	noPreview  bool
+}
+
+// WithKind animates the image in one of the ways from the full-screen animations menu, e.g. "slide". The default is
+// "static".
+func WithKind(kind string) AnimationOption {
+	return func(o *animationOptions) { o.kind = kind }
+}
+
+// WithOnComplete calls fn when the animation finishes by itself, after the face has been put back. fn may start
+// another animation to chain them together. It is not called if the animation is interrupted, e.g. by another
+// animation starting or the Back button.
+func WithOnComplete(fn func()) AnimationOption {
+	return func(o *animationOptions) { o.onComplete = fn }
+}
+
+// WithDuration finishes the animation after d, for animations (like static images) that would otherwise never finish.
+func WithDuration(d time.Duration) AnimationOption {
+	return func(o *animationOptions) { o.duration = d }
+}
+
+// WithNoStatusPreview doesn't draw the preview of the face on the status screen while the animation is running, as if
+// the animation had the NoStatusPreview hint.
+func WithNoStatusPreview() AnimationOption {
+	return func(o *animationOptions) { o.noPreview = true }
+}
+
+// StartAnimation starts the named full-screen image as an animation on the face. Unlike StartAnimationByName, it starts
+// right away rather than going through Command, since the options can't be recorded or sent to peers; it is still
+// refused while do not disturb is on.
+//
+// StartAnimation must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) StartAnimation(name string, opts ...AnimationOption) error {
+	g.owner.check()
+	if g.dnd {
+		return errDND
+	}
+	o := animationOptions{kind: animationKinds[0].name}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	k, ok := findAnimationKind(o.kind)
+	if !ok {
+		return errors.New("unknown animation kind " + o.kind)
+	}
+	err := g.newAnimation(name, k)
+	if err != nil {
+		return err
+	}
+	g.onComplete = o.onComplete
+	if o.duration > 0 {
+		g.animUntil = time.Now().Add(o.duration)
+	}
+	if o.noPreview {
+		g.setNoPreview(true)
+	}
+	return nil
+}
+
+// withoutSequenceFrames removes the frames of the sequences from the image names.
+func withoutSequenceFrames(names, seqs []string) []string {
+	var out []string
+	for _, name := range names {
+		seq, _, ok := media.SequenceFrame(name)
+		framed := false
+		for _, s := range seqs {
+			framed = framed || ok && s == seq
+		}
+		if !framed {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// equalFold is strings.EqualFold for ASCII, without pulling in unicode tables.
+func equalFold(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		ca, cb := a[i], b[i]
+		if 'A' <= ca && ca <= 'Z' {
+			ca += 'a' - 'A'
+		}
+		if 'A' <= cb && cb <= 'Z' {
+			cb += 'a' - 'A'
+		}
+		if ca != cb {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+//go:build !nogames
+
+package gotogen
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation/canvas"
+)
+
+const (
+	// artSlots is how many pieces of pixel art can be saved.
+	artSlots = 3
+	// artExitTime is how quickly back has to be pressed twice to leave the editor.
+	artExitTime = 500 * time.Millisecond
+)
+
+// artEditor is the state of the on-device pixel art editor. It is left out of builds with the nogames build tag.
+type artEditor struct {
+	canvas *canvas.Anim
+	color  uint8
+	// whether up and down move vertically
+	vertical bool
+	lastBack time.Time
+}
+
+func artSetting(slot int) string {
+	return "art" + strconv.Itoa(slot+1)
+}
+
+// loadArt loads the pixel art from the slot, or returns nil if there is none.
+func (g *Gotogen) loadArt(slot int) *canvas.Anim {
+	b, ok := g.loadSetting(artSetting(slot))
+	if !ok {
+		return nil
+	}
+	w, h := g.Size()
+	c, err := canvas.Load(w, h, b)
+	if err != nil {
+		g.log(LogWarning, "loading art "+strconv.Itoa(slot+1)+": "+err.Error())
+		return nil
+	}
+	return c
+}
+
+// editArt starts the editor with the pixel art from the slot, or a blank canvas if the slot is empty or negative.
+func (g *Gotogen) editArt(slot int) {
+	var c *canvas.Anim
+	if slot >= 0 {
+		c = g.loadArt(slot)
+	}
+	if c == nil {
+		c = canvas.New(g.Size())
+	}
+	c.Editing = true
+	g.art = artEditor{canvas: c, color: 1}
+	g.startAnimation(c)
+	g.changeStatusState(statusStateEditor)
+	g.renderArtStatus()
+}
+
+// showArt puts the pixel art from the slot on the face.
+func (g *Gotogen) showArt(slot int) {
+	c := g.loadArt(slot)
+	if c == nil {
+		g.setWarning("Art slot " + strconv.Itoa(slot+1) + " empty")
+		return
+	}
+	g.startAnimation(c)
+}
+
+func (g *Gotogen) renderArtStatus() {
+	g.statusText.Clear()
+	_ = g.statusText.SetLineInverse(0, "PIXEL ART")
+	axis := "horiz."
+	if g.art.vertical {
+		axis = "vert."
+	}
+	_ = g.statusText.SetLine(1, strconv.Itoa(int(g.art.canvas.CursorX)), ",", strconv.Itoa(int(g.art.canvas.CursorY)),
+		" ", axis, " col ", strconv.Itoa(int(g.art.color)))
+	if g.compactStatus {
+		_ = g.statusText.SetLine(2, "Up/Dn move Menu paint")
+		_ = g.statusText.SetLine(3, "Dflt col Back axis/x2")
+		return
+	}
+	_ = g.statusText.SetLine(3, "Up/Dn: move")
+	_ = g.statusText.SetLine(4, "Menu: paint")
+	_ = g.statusText.SetLine(5, "Dflt: color")
+	_ = g.statusText.SetLine(6, "Back: axis")
+	_ = g.statusText.SetLine(7, "Back x2: done")
+}
+
+// updateArt is called every frame while the editor is running.
+func (g *Gotogen) updateArt() {
+	c := g.art.canvas
+	switch g.pressedButton() {
+	case MenuButtonNone:
+		return
+	case MenuButtonUp:
+		if g.art.vertical {
+			c.Move(0, -1)
+		} else {
+			c.Move(-1, 0)
+		}
+	case MenuButtonDown:
+		if g.art.vertical {
+			c.Move(0, 1)
+		} else {
+			c.Move(1, 0)
+		}
+	case MenuButtonMenu:
+		// painting the same color again erases
+		if c.At(c.CursorX, c.CursorY) == g.art.color {
+			c.Paint(0)
+		} else {
+			c.Paint(g.art.color)
+		}
+	case MenuButtonDefault:
+		g.art.color++
+		if int(g.art.color) >= len(canvas.Palette) {
+			g.art.color = 1
+		}
+	case MenuButtonBack:
+		if time.Since(g.art.lastBack) < artExitTime {
+			g.finishArt()
+			return
+		}
+		g.art.lastBack = time.Now()
+		g.art.vertical = !g.art.vertical
+	}
+	g.renderArtStatus()
+}
+
+// finishArt leaves the editor, offering to save the art.
+func (g *Gotogen) finishArt() {
+	g.art.canvas.Editing = false
+	g.changeStatusState(statusStateMenu)
+	save := &Menu{Name: "Save art?"}
+	for i := 0; i < artSlots; i++ {
+		slot := i
+		save.Items = append(save.Items, &ActionItem{
+			Name: "Save to slot " + strconv.Itoa(i+1),
+			Invoke: func() {
+				g.saveSetting(artSetting(slot), g.art.canvas.Bytes())
+				g.changeStatusState(statusStateIdle)
+			},
+		})
+	}
+	save.Items = append(save.Items, &ActionItem{
+		Name:   "Discard",
+		Invoke: func() { g.changeStatusState(statusStateIdle) },
+	})
+	g.activeMenu = save
+	save.Render(g.menuRenderer)
+}
+
+func (g *Gotogen) artMenu() *Menu {
+	m := &Menu{
+		Name: "Pixel art",
+		Items: []Item{
+			&ActionItem{
+				Name:   "New",
+				Invoke: func() { g.editArt(-1) },
+			},
+		},
+	}
+	for i := 0; i < artSlots; i++ {
+		slot := i
+		m.Items = append(m.Items,
+			&ActionItem{
+				Name:   "Show slot " + strconv.Itoa(i+1),
+				Invoke: func() { g.showArt(slot) },
+			},
+			&ActionItem{
+				Name:   "Edit slot " + strconv.Itoa(i+1),
+				Invoke: func() { g.editArt(slot) },
+			},
+		)
+	}
+	return m
+}
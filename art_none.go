@@ -0,0 +1,16 @@
+//go:build nogames
+
+package gotogen
+
+// The pixel art editor is left out of builds with the nogames build tag, to save space; see art.go.
+
+// artSlots is 0, so that there are no art settings.
+const artSlots = 0
+
+type artEditor struct{}
+
+func artSetting(slot int) string { return "" }
+
+func (g *Gotogen) updateArt() {}
+
+func (g *Gotogen) artMenu() *Menu { return nil }
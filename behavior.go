@@ -0,0 +1,232 @@
+package gotogen
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+	"github.com/ajanata/gotogen/internal/media"
+	"github.com/ajanata/gotogen/internal/script"
+)
+
+const behaviorSetting = "behavior"
+
+// behavior is the running behavior script, if there is one. Behavior scripts are like rules that can keep state, wait
+// and loop, and they are loaded from media storage, so how the head reacts can be changed in the field without
+// reflashing. See package script for the language.
+//
+// Scripts handle these events: start (when the script is loaded), boop, shake, talking, boop=KIND (tap, double, triple,
+// or long), and any others sent with "behavior fire EVENT", e.g. by a phone app. Boop and shake events are subject to
+// the reaction limits, like rules.
+type behavior struct {
+	name    string
+	machine *script.Machine
+	// the sensors last tick, so that events only fire when they start
+	booped, shaking, talking bool
+}
+
+// LoadBehavior loads a behavior script from media storage (media/behavior/NAME.txt), replacing the running one, and
+// remembers it for the next boot.
+//
+// LoadBehavior must be called from the same goroutine as RunTick; other goroutines can use Do.
+func (g *Gotogen) LoadBehavior(name string) error {
+	g.owner.check()
+	if err := g.startBehavior(name); err != nil {
+		return err
+	}
+	g.saveSetting(behaviorSetting, []byte(name))
+	return nil
+}
+
+// startBehavior compiles a behavior script and starts it.
+func (g *Gotogen) startBehavior(name string) error {
+	b, err := media.ReadFileMax(media.TypeBehavior, name+".txt", script.MaxSource)
+	if err != nil {
+		return errcode.Context("load behavior "+name, err)
+	}
+	p, err := script.Compile(string(b))
+	if err != nil {
+		return errcode.Context("load behavior "+name, err)
+	}
+	g.behavior = behavior{name: name, machine: script.New(p, behaviorHost{g})}
+	g.behavior.machine.Fire("start")
+	g.log(LogInfo, "behavior: "+name)
+	return nil
+}
+
+// StopBehavior stops the behavior script, and forgets it so that it isn't loaded at the next boot.
+func (g *Gotogen) StopBehavior() {
+	g.owner.check()
+	g.behavior = behavior{}
+	g.saveSetting(behaviorSetting, nil)
+}
+
+func (g *Gotogen) loadBehavior() {
+	b, ok := g.loadSetting(behaviorSetting)
+	if !ok || len(b) == 0 {
+		return
+	}
+	if err := g.startBehavior(string(b)); err != nil {
+		g.log(LogWarning, "loading behavior: "+err.Error())
+	}
+}
+
+// updateBehavior fires the events that started this tick, and runs the script for a bit. A script that goes wrong, or
+// runs over its budget, is stopped.
+func (g *Gotogen) updateBehavior() {
+	b := &g.behavior
+	if b.machine == nil || g.dnd {
+		return
+	}
+
+	booped, talking := g.booped(), g.Talking()
+	if booped && !b.booped {
+		g.react(func() { g.fireBehavior("boop") })
+	}
+	if g.shaking && !b.shaking {
+		g.react(func() { g.fireBehavior("shake") })
+	}
+	if talking && !b.talking {
+		g.fireBehavior("talking")
+	}
+	if e := g.boopClass.event; e != BoopEventNone {
+		g.react(func() { g.fireBehavior("boop=" + e.String()) })
+	}
+	b.booped, b.shaking, b.talking = booped, g.shaking, talking
+
+	// a command run by the script may have stopped it or loaded another one, which mustn't be stopped for this one's error
+	m, name := b.machine, b.name
+	if err := m.Step(time.Now()); err != nil {
+		g.reportError(errcode.Context("behavior "+name, err))
+		if g.behavior.machine == m {
+			g.behavior = behavior{}
+		}
+	}
+}
+
+// fireBehavior starts the script's handlers for an event. It is called later than the event if the reaction limits
+// held it back, by which time the script may have been stopped.
+func (g *Gotogen) fireBehavior(event string) {
+	if g.behavior.machine != nil {
+		g.behavior.machine.Fire(event)
+	}
+}
+
+// behaviorHost gives behavior scripts the sensors and commands.
+type behaviorHost struct {
+	g *Gotogen
+}
+
+func (h behaviorHost) Value(b script.Builtin) int32 {
+	g := h.g
+	switch b {
+	case script.Boop:
+		return int32(g.boopDist)
+	case script.Talk:
+		if g.talkLevel.active {
+			return int32(g.talkLevel.level)
+		}
+		if g.behavior.talking {
+			return 0xFF
+		}
+		return 0
+	case script.Battery:
+		if !g.hasBattery {
+			return -1
+		}
+		return int32(g.battery)
+	case script.FPS:
+		return int32(g.lastFPS)
+	case script.AX:
+		return g.aX
+	case script.AY:
+		return g.aY
+	case script.AZ:
+		return g.aZ
+	case script.Hour, script.Minute:
+		t, ok := g.wallClock()
+		if !ok {
+			return -1
+		}
+		if b == script.Hour {
+			return int32(t.Hour())
+		}
+		return int32(t.Minute())
+	default:
+		return 0
+	}
+}
+
+// Command runs a command for the script. Scripts can be uploaded by anyone who can write to media storage, so they can
+// only run the commands that change the expression or animation (see expressionCommand); anything else stops the
+// script. Like a rule's, a command doesn't change a locked expression. A command that fails, e.g. because the face is
+// busy, is reported but doesn't stop the script.
+func (h behaviorHost) Command(line string) error {
+	g := h.g
+	cmd := strings.ToLower(strings.Fields(line)[0])
+	if !expressionCommand(cmd) {
+		return errors.New(cmd + " is not allowed")
+	}
+	if g.exprLocked() {
+		g.log(LogInfo, "behavior: "+errExprLocked.Error())
+		return nil
+	}
+	if err := g.Command(line); err != nil {
+		g.reportError(errcode.Context("behavior "+g.behavior.name, err))
+	}
+	return nil
+}
+
+// behaviorCommand handles "behavior [load NAME|stop|fire EVENT]". Without a subcommand, it replies with the scripts in
+// media storage, marking the running one.
+func (g *Gotogen) behaviorCommand(args []string) error {
+	switch {
+	case len(args) == 0:
+		for _, n := range media.EnumerateFiles(media.TypeBehavior, ".txt") {
+			line := "behavior " + n
+			if n == g.behavior.name {
+				line += " *"
+			}
+			g.reply(line)
+		}
+		return nil
+	case args[0] == "load" && len(args) == 2:
+		return g.LoadBehavior(args[1])
+	case args[0] == "stop":
+		g.StopBehavior()
+		return nil
+	case args[0] == "fire" && len(args) == 2:
+		if g.behavior.machine == nil {
+			return errors.New("behavior: not running")
+		}
+		g.fireBehavior(args[1])
+		return nil
+	default:
+		return errors.New("behavior: need load, stop, or fire")
+	}
+}
+
+func (g *Gotogen) behaviorMenu() *Menu {
+	g.behaviorMenuItem = &Menu{Name: "Behavior"}
+	g.refreshBehaviorMenu()
+	return g.behaviorMenuItem
+}
+
+// refreshBehaviorMenu rebuilds the behavior menu, since scripts can be uploaded at any time.
+func (g *Gotogen) refreshBehaviorMenu() {
+	m := g.behaviorMenuItem
+	m.selected, m.top = 0, 0
+	m.Items = append(m.Items[:0], &ActionItem{
+		Name:   "Stop",
+		Help:   "Stops the behavior script, and doesn't load it again at the next boot.",
+		Invoke: g.StopBehavior,
+	})
+	for _, n := range media.EnumerateFiles(media.TypeBehavior, ".txt") {
+		name := n
+		m.Items = append(m.Items, &ActionItem{
+			Name:   "Load " + name,
+			Invoke: func() { g.reportError(g.LoadBehavior(name)) },
+		})
+	}
+}
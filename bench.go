@@ -0,0 +1,174 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// benchSettle is how long each benchmark step runs before it is measured, for the settings to take effect.
+	benchSettle = time.Second
+	// benchMeasure is how long each benchmark step is measured for.
+	benchMeasure = 4 * time.Second
+)
+
+// benchStep is one combination of settings that the benchmark measures.
+type benchStep struct {
+	// anim is the full-screen animation to play as "KIND FILE", or empty for the default face
+	anim string
+	// skip is the Frame skip setting, as the menu option
+	skip       uint8
+	downmix    colorChannel
+	brightness uint8
+}
+
+// benchSkipNames and benchDownmixNames are the names of the settings in the benchmark results, as in the menu.
+var (
+	benchSkipNames    = [...]string{"auto", "0", "1", "2", "4", "8", "16"}
+	benchDownmixNames = [...]string{"full", "red", "green", "blue", "luma", "dither"}
+)
+
+// benchSteps are the steps of the benchmark: a baseline for the default face and for a full-screen animation, then one
+// setting changed at a time from each, so the cost of each setting can be read off against the baseline.
+var benchSteps = func() []benchStep {
+	var steps []benchStep
+	for _, anim := range []string{"", "slide wait"} {
+		base := benchStep{anim: anim, skip: 1, downmix: colorChannelRed, brightness: 0xFF}
+		steps = append(steps, base)
+		for _, skip := range []uint8{2, 4} {
+			s := base
+			s.skip = skip
+			steps = append(steps, s)
+		}
+		for _, dm := range []colorChannel{colorChannelLuma, colorChannelDither} {
+			s := base
+			s.downmix = dm
+			steps = append(steps, s)
+		}
+		s := base
+		s.brightness = 0x40
+		steps = append(steps, s)
+	}
+	return steps
+}()
+
+// benchmark is the state of a running benchmark.
+type benchmark struct {
+	running bool
+	step    int
+	// start is when the current step started, and measuring is when measuring it started
+	start, measuring time.Time
+	frames           int
+	busy             time.Duration
+	// the settings from before the benchmark, to put back afterwards
+	autoSkip   bool
+	frameSkip  uint8
+	downmix    colorChannel
+	brightness uint8
+}
+
+// startBench starts the benchmark. Each step's results are replied as a "bench" line once it has been measured, and
+// "bench done" is replied at the end.
+func (g *Gotogen) startBench() error {
+	if g.bench.running {
+		return errors.New("bench: already running")
+	}
+	g.bench = benchmark{
+		running:    true,
+		autoSkip:   g.statusAutoSkip,
+		frameSkip:  g.statusFrameSkip,
+		downmix:    g.statusDownmixChannel,
+		brightness: g.brightness,
+	}
+	g.log(LogInfo, "benchmark started")
+	g.changeStatusState(statusStateIdle)
+	g.startBenchStep()
+	return nil
+}
+
+// startBenchStep puts the current step's settings in place.
+func (g *Gotogen) startBenchStep() {
+	b := &g.bench
+	s := benchSteps[b.step]
+	g.setStatusFrameSkip(s.skip)
+	g.setStatusDuplicateColor(uint8(s.downmix))
+	g.reportError(g.SetBrightness(s.brightness))
+	if s.anim == "" {
+		g.returnToFace()
+	} else {
+		kind, file, _ := strings.Cut(s.anim, " ")
+		g.reportError(g.StartAnimationByName(kind, file))
+	}
+	b.start = time.Now()
+	b.measuring = time.Time{}
+	b.frames, b.busy = 0, 0
+}
+
+// recordBench is called at the end of every tick with how long it took, to measure the current step and move on to
+// the next one when it is done.
+func (g *Gotogen) recordBench(tickTime time.Duration) {
+	b := &g.bench
+	if !b.running {
+		return
+	}
+	now := time.Now()
+	if b.measuring.IsZero() {
+		if now.Sub(b.start) >= benchSettle {
+			b.measuring = now
+		}
+		return
+	}
+	b.frames++
+	b.busy += tickTime
+	elapsed := now.Sub(b.measuring)
+	if elapsed < benchMeasure {
+		return
+	}
+
+	s := benchSteps[b.step]
+	anim := s.anim
+	if anim == "" {
+		anim = "face"
+	}
+	fps := float64(b.frames) / elapsed.Seconds()
+	headroom := 100 - int(b.busy*100/elapsed)
+	if headroom < 0 {
+		headroom = 0
+	}
+	g.reply("bench anim=" + anim + " skip=" + benchSkipNames[s.skip] + " downmix=" + benchDownmixNames[s.downmix] +
+		" brightness=" + strconv.Itoa(int(s.brightness)) + " fps=" + strconv.FormatFloat(fps, 'f', 1, 32) +
+		" headroom=" + strconv.Itoa(headroom) + "%")
+
+	b.step++
+	if b.step < len(benchSteps) {
+		g.startBenchStep()
+		return
+	}
+	g.stopBench()
+	g.reply("bench done")
+}
+
+// stopBench stops the benchmark, if it is running, and puts the settings back how they were.
+func (g *Gotogen) stopBench() {
+	b := &g.bench
+	if !b.running {
+		return
+	}
+	b.running = false
+	g.statusAutoSkip, g.statusFrameSkip = b.autoSkip, b.frameSkip
+	g.statusDownmixChannel = b.downmix
+	g.reportError(g.SetBrightness(b.brightness))
+	g.returnToFace()
+	g.log(LogInfo, "benchmark finished")
+}
+
+// benchCommand handles "bench [stop]".
+func (g *Gotogen) benchCommand(args []string) error {
+	if len(args) > 0 && args[0] == "stop" {
+		g.stopBench()
+		return nil
+	}
+	return g.startBench()
+}
@@ -0,0 +1,205 @@
+package gotogen
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	bindingsSetting = "bindings"
+	// longPressTime is how long a single button has to be held to trigger a binding.
+	longPressTime = time.Second
+	// chordTime is how long a chord of buttons has to be held to trigger a binding.
+	chordTime = 300 * time.Millisecond
+)
+
+// quickAction is something that can be bound to a button chord or long-press in the idle state.
+type quickAction uint8
+
+const (
+	quickActionNone quickAction = iota
+	quickActionNextFace
+	quickActionToggleBlank
+	quickActionPhotoMode
+	quickActionMuteMic
+	quickActionClock
+	quickActionScreenshot
+	quickActionMacro1
+	quickActionMacro2
+	quickActionMacro3
+	quickActionDND
+	quickActionLockExpr
+	quickActionCount
+)
+
+func (a quickAction) String() string {
+	switch a {
+	case quickActionNone:
+		return "none"
+	case quickActionNextFace:
+		return "next face"
+	case quickActionToggleBlank:
+		return "blank status"
+	case quickActionPhotoMode:
+		return "photo mode"
+	case quickActionMuteMic:
+		return "mute mic"
+	case quickActionClock:
+		return "show clock"
+	case quickActionScreenshot:
+		return "screenshot"
+	case quickActionMacro1, quickActionMacro2, quickActionMacro3:
+		return "macro " + strconv.Itoa(int(a-quickActionMacro1)+1)
+	case quickActionDND:
+		return "do not disturb"
+	case quickActionLockExpr:
+		return "lock expression"
+	default:
+		return "INVALID"
+	}
+}
+
+// bindingTrigger is a combination of held buttons that can trigger a quick action.
+type bindingTrigger struct {
+	name    string
+	buttons ButtonSet
+}
+
+// bindingTriggers are the available triggers. The menu button can't be long-pressed since it opens the menu right away.
+var bindingTriggers = []bindingTrigger{
+	{"Hold Up", ButtonSet(0).With(MenuButtonUp)},
+	{"Hold Down", ButtonSet(0).With(MenuButtonDown)},
+	{"Hold Back", ButtonSet(0).With(MenuButtonBack)},
+	{"Up+Down", ButtonSet(0).With(MenuButtonUp).With(MenuButtonDown)},
+	{"Back+Up", ButtonSet(0).With(MenuButtonBack).With(MenuButtonUp)},
+	{"Back+Down", ButtonSet(0).With(MenuButtonBack).With(MenuButtonDown)},
+}
+
+// defaultBindings are the actions bound to each trigger when nothing has been saved.
+var defaultBindings = []quickAction{
+	quickActionNone, quickActionNone, quickActionNone, quickActionNextFace, quickActionToggleBlank, quickActionMuteMic,
+}
+
+// chordState tracks the buttons currently held, for detecting bindings.
+type chordState struct {
+	held  ButtonSet
+	since time.Time
+	fired bool
+}
+
+// checkBindings detects chords and long-presses while idle and runs the bound actions. Only drivers that implement
+// ButtonStateDriver can trigger bindings.
+func (g *Gotogen) checkBindings() {
+	bs, ok := g.driver.(ButtonStateDriver)
+	if !ok {
+		return
+	}
+	held := g.buttonMap.applySet(bs.ButtonState())
+	now := time.Now()
+	if held != g.chord.held {
+		g.chord = chordState{held: held, since: now}
+		return
+	}
+	if held == 0 || g.chord.fired {
+		return
+	}
+
+	for i, t := range bindingTriggers {
+		if t.buttons != held {
+			continue
+		}
+		wait := chordTime
+		if isSingleButton(held) {
+			wait = longPressTime
+		}
+		if now.Sub(g.chord.since) < wait {
+			return
+		}
+		g.chord.fired = true
+		g.runQuickAction(g.bindings[i])
+		return
+	}
+}
+
+func isSingleButton(s ButtonSet) bool {
+	return s != 0 && s&(s-1) == 0
+}
+
+func (g *Gotogen) runQuickAction(a quickAction) {
+	g.log(LogInfo, "quick action "+a.String())
+	switch a {
+	case quickActionNextFace:
+		g.nextFace()
+	case quickActionToggleBlank:
+		if g.statusState == statusStateBlank {
+			g.changeStatusState(statusStateIdle)
+		} else {
+			g.changeStatusState(statusStateBlank)
+		}
+	case quickActionPhotoMode:
+		// no automatic effects, and no blinking LED showing up in photos
+		g.photoMode = !g.photoMode
+		if g.photoMode {
+			g.blinkerOff()
+		}
+	case quickActionMuteMic:
+		g.micMuted = !g.micMuted
+	case quickActionClock:
+		g.reportError(g.ShowClock())
+	case quickActionScreenshot:
+		g.reportError(g.Screenshot())
+	case quickActionMacro1, quickActionMacro2, quickActionMacro3:
+		g.reportError(g.PlayMacro(int(a-quickActionMacro1) + 1))
+	case quickActionDND:
+		g.SetDoNotDisturb(!g.dnd)
+	case quickActionLockExpr:
+		if g.exprLocked() {
+			g.LockExpression(0)
+		} else {
+			g.LockExpression(defaultExprLockTime)
+		}
+	}
+}
+
+func (g *Gotogen) loadBindings() {
+	g.bindings = make([]quickAction, len(bindingTriggers))
+	copy(g.bindings, defaultBindings)
+	b, ok := g.loadSetting(bindingsSetting)
+	if !ok || len(b) != len(g.bindings) {
+		return
+	}
+	for i, v := range b {
+		if v < uint8(quickActionCount) {
+			g.bindings[i] = quickAction(v)
+		}
+	}
+}
+
+func (g *Gotogen) saveBindings() {
+	b := make([]byte, len(g.bindings))
+	for i, a := range g.bindings {
+		b[i] = uint8(a)
+	}
+	g.saveSetting(bindingsSetting, b)
+}
+
+func (g *Gotogen) bindingsMenu() *Menu {
+	actions := make([]string, quickActionCount)
+	for i := range actions {
+		actions[i] = quickAction(i).String()
+	}
+	m := &Menu{Name: "Bindings"}
+	for i, t := range bindingTriggers {
+		idx := i
+		m.Items = append(m.Items, &SettingItem{
+			Name:    t.name,
+			Options: actions,
+			Active:  uint8(g.bindings[i]),
+			Apply: func(selected uint8) {
+				g.bindings[idx] = quickAction(selected)
+				g.saveBindings()
+			},
+		})
+	}
+	return m
+}
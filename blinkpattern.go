@@ -0,0 +1,134 @@
+package gotogen
+
+import (
+	"time"
+)
+
+// blinkUnit is the length of a Morse dot, and the unit of BlinkPattern durations.
+const blinkUnit = 150 * time.Millisecond
+
+// BlinkPattern is a sequence of alternating on and off durations for the Blinker, starting with on, in units of
+// 150ms (the length of a Morse dot).
+type BlinkPattern []uint8
+
+// morse is the Morse code for letters and digits, as dots and dashes.
+var morse = map[byte]string{
+	'A': ".-", 'B': "-...", 'C': "-.-.", 'D': "-..", 'E': ".", 'F': "..-.", 'G': "--.", 'H': "....", 'I': "..",
+	'J': ".---", 'K': "-.-", 'L': ".-..", 'M': "--", 'N': "-.", 'O': "---", 'P': ".--.", 'Q': "--.-", 'R': ".-.",
+	'S': "...", 'T': "-", 'U': "..-", 'V': "...-", 'W': ".--", 'X': "-..-", 'Y': "-.--", 'Z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-", '5': ".....", '6': "-....", '7': "--...",
+	'8': "---..", '9': "----.",
+}
+
+// MorsePattern converts the text to a Morse code BlinkPattern. Characters without a Morse representation are treated
+// as spaces.
+func MorsePattern(text string) BlinkPattern {
+	var p BlinkPattern
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		code, ok := morse[c]
+		if !ok {
+			// word gap is 7 units, and the previous letter already ended with a 3 unit gap
+			if len(p) > 0 {
+				p[len(p)-1] += 4
+			}
+			continue
+		}
+		for j := 0; j < len(code); j++ {
+			if code[j] == '-' {
+				p = append(p, 3, 1)
+			} else {
+				p = append(p, 1, 1)
+			}
+		}
+		// letter gap is 3 units
+		p[len(p)-1] = 3
+	}
+	if len(p) > 0 {
+		// pause before repeating
+		p[len(p)-1] = 7
+	}
+	return p
+}
+
+// CodePattern is n short blinks followed by a long pause, for blinking out status codes that are easy to count.
+func CodePattern(n uint8) BlinkPattern {
+	var p BlinkPattern
+	for i := uint8(0); i < n; i++ {
+		p = append(p, 2, 2)
+	}
+	if len(p) > 0 {
+		p[len(p)-1] = 10
+	}
+	return p
+}
+
+// blinkPlayer steps through a BlinkPattern as time passes.
+type blinkPlayer struct {
+	pattern BlinkPattern
+	repeat  bool
+	step    int
+	next    time.Time
+}
+
+func (p *blinkPlayer) start(pattern BlinkPattern, repeat bool) {
+	p.pattern = pattern
+	p.repeat = repeat
+	p.step = 0
+	p.next = time.Now()
+	if len(pattern) > 0 {
+		p.next = p.next.Add(time.Duration(pattern[0]) * blinkUnit)
+	}
+}
+
+// active returns whether a pattern is playing.
+func (p *blinkPlayer) active() bool {
+	return p.step < len(p.pattern)
+}
+
+// update advances the pattern and returns whether the blinker should be on now.
+func (p *blinkPlayer) update(now time.Time) bool {
+	for p.active() && !now.Before(p.next) {
+		p.step++
+		if p.step >= len(p.pattern) && p.repeat {
+			p.step = 0
+		}
+		if p.active() {
+			p.next = p.next.Add(time.Duration(p.pattern[p.step]) * blinkUnit)
+		}
+	}
+	return p.active() && p.step%2 == 0
+}
+
+// Blink plays the pattern on the Blinker instead of the usual once-per-frame heartbeat. If repeat is false, the
+// heartbeat resumes when the pattern is done. Passing an empty pattern stops any pattern that is playing.
+//
+// Blink must be called from the same goroutine as RunTick; other goroutines can use Do.
+func (g *Gotogen) Blink(p BlinkPattern, repeat bool) {
+	g.owner.check()
+	g.blinkPattern.start(p, repeat)
+}
+
+// BlinkMorse blinks the text in Morse code once.
+//
+// BlinkMorse must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) BlinkMorse(text string) {
+	g.owner.check()
+	g.Blink(MorsePattern(text), false)
+}
+
+// updateBlinker sets the Blinker for the pattern being played, and reports whether a pattern is playing.
+func (g *Gotogen) updateBlinker() bool {
+	if !g.blinkPattern.active() {
+		return false
+	}
+	if g.blinkPattern.update(time.Now()) {
+		g.blinkerOn()
+	} else {
+		g.blinkerOff()
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package gotogen
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	boopCalSetting = "boopcal"
+	// boopCalTime is how long the boop sensor is sampled for to calibrate it.
+	boopCalTime = 3 * time.Second
+	// boopMinGap is the least the threshold is above the baseline, so that a quiet sensor doesn't trigger on a twitch.
+	boopMinGap = 32
+	// boopMaxThreshold keeps the threshold reachable even with a high baseline.
+	boopMaxThreshold = 250
+	// boopCalTitle is the page that shows the progress and result of calibrating from the menu.
+	boopCalTitle = "CALIBRATE BOOP"
+)
+
+// boopCalibration is the boop sensor's resting level, and the threshold for a boop worked out from it.
+type boopCalibration struct {
+	baseline, noise, threshold uint8
+
+	// the calibration in progress, if running
+	running      bool
+	manual       bool
+	until        time.Time
+	sum, samples uint32
+	lo, hi       uint8
+}
+
+// booped returns whether something is close enough to the boop sensor to count as a boop.
+func (g *Gotogen) booped() bool {
+	return g.boopDist > g.boopCal.threshold
+}
+
+// boopThresholdFor works out the trigger threshold from the baseline and how much the readings wander around it: a
+// boop has to be well clear of the noise, and at least halfway to the top of the range from the baseline.
+func boopThresholdFor(baseline, noise uint8) uint8 {
+	gap := 3 * int(noise)
+	if half := (0xFF - int(baseline)) / 2; half > gap {
+		gap = half
+	}
+	if gap < boopMinGap {
+		gap = boopMinGap
+	}
+	t := int(baseline) + gap
+	if t > boopMaxThreshold {
+		t = boopMaxThreshold
+	}
+	return uint8(t)
+}
+
+func (g *Gotogen) loadBoopCal() {
+	c := &g.boopCal
+	c.threshold = boopThreshold
+	b, ok := g.loadSetting(boopCalSetting)
+	if ok && len(b) == 3 {
+		c.baseline, c.noise, c.threshold = b[0], b[1], b[2]
+	}
+}
+
+// startBoopCal starts sampling the boop sensor to find its baseline. Boops keep using the old threshold until it is
+// done.
+func (g *Gotogen) startBoopCal(manual bool) {
+	g.boopCal.running = true
+	g.boopCal.manual = manual
+	g.boopCal.sum, g.boopCal.samples = 0, 0
+	g.boopCal.lo, g.boopCal.hi = 0xFF, 0
+}
+
+// calibrateBoop is the menu action to calibrate the boop sensor.
+func (g *Gotogen) calibrateBoop() {
+	g.startBoopCal(true)
+	g.showPage(boopCalTitle, []string{"Keep everything", "away from the", "sensor..."})
+}
+
+// updateBoopCal is called with every reading of the boop sensor, and finishes the calibration when it has run long
+// enough.
+func (g *Gotogen) updateBoopCal(d uint8) {
+	c := &g.boopCal
+	if !c.running {
+		return
+	}
+	if c.samples == 0 {
+		// the time starts from the first reading, since calibrating at boot starts before the sensor is being read
+		c.until = time.Now().Add(boopCalTime)
+	}
+	c.sum += uint32(d)
+	c.samples++
+	if d < c.lo {
+		c.lo = d
+	}
+	if d > c.hi {
+		c.hi = d
+	}
+	if time.Now().Before(c.until) {
+		return
+	}
+	c.running = false
+
+	baseline := uint8(c.sum / c.samples)
+	noise := c.hi - c.lo
+	if !c.manual && baseline >= c.threshold {
+		// something was in front of the sensor while booting; the last calibration is better than this one
+		g.log(LogInfo, "boop calibration skipped, baseline "+strconv.Itoa(int(baseline)))
+		return
+	}
+	c.baseline, c.noise = baseline, noise
+	c.threshold = boopThresholdFor(baseline, noise)
+	g.saveSetting(boopCalSetting, []byte{c.baseline, c.noise, c.threshold})
+	g.log(LogInfo, "boop calibrated, baseline "+strconv.Itoa(int(c.baseline))+" threshold "+strconv.Itoa(int(c.threshold)))
+
+	if c.manual && g.statusState == statusStateHelp && g.help.title == boopCalTitle {
+		w, _ := g.statusText.Size()
+		g.showPage(boopCalTitle, []string{
+			padLine("baseline", strconv.Itoa(int(c.baseline)), int(w)),
+			padLine("noise", strconv.Itoa(int(c.noise)), int(w)),
+			padLine("threshold", strconv.Itoa(int(c.threshold)), int(w)),
+		})
+	}
+}
+
+// resetBoopCal goes back to the fixed threshold.
+func (g *Gotogen) resetBoopCal() {
+	g.boopCal = boopCalibration{threshold: boopThreshold}
+	g.saveSetting(boopCalSetting, []byte{0, 0, boopThreshold})
+}
+
+// boopMenu has the boop sensor's calibration, if there is a boop sensor.
+func (g *Gotogen) boopMenu() *Menu {
+	if _, st := g.driver.BoopDistance(); st == SensorStatusUnavailable {
+		return nil
+	}
+	return &Menu{
+		Name: "Boop sensor",
+		Items: append([]Item{
+			&ActionItem{
+				Name:   "Calibrate",
+				Help:   "Samples the sensor for a few seconds with nothing in front of it, and sets how close a boop has to be from that. This also happens every boot.",
+				Invoke: g.calibrateBoop,
+			},
+			&ActionItem{
+				Name:   "Reset calibration",
+				Invoke: g.resetBoopCal,
+			},
+		}, g.boopReactionItems()...),
+	}
+}
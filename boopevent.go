@@ -0,0 +1,102 @@
+package gotogen
+
+import (
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation"
+)
+
+const (
+	// boopDebounce is how long the boop sensor has to stay on or off for it to count, so that a noisy reading at the
+	// edge of the threshold doesn't turn into a string of taps.
+	boopDebounce = 50 * time.Millisecond
+	// boopLongTime is how long a boop has to be held to be a long boop.
+	boopLongTime = 800 * time.Millisecond
+	// boopTapGap is how long to wait after a tap for another one before deciding how many taps there were.
+	boopTapGap = 400 * time.Millisecond
+)
+
+// BoopEvent is a kind of boop: a tap, a double or triple tap, or a long boop.
+type BoopEvent = animation.BoopEvent
+
+const (
+	BoopEventNone      = animation.BoopEventNone
+	BoopEventTap       = animation.BoopEventTap
+	BoopEventDoubleTap = animation.BoopEventDoubleTap
+	BoopEventTripleTap = animation.BoopEventTripleTap
+	BoopEventLong      = animation.BoopEventLong
+)
+
+// boopClassifier turns the continuous boop reading into boop events.
+type boopClassifier struct {
+	// raw is the last reading and when it changed; down is the debounced reading and when it started
+	raw       bool
+	rawSince  time.Time
+	down      bool
+	downSince time.Time
+	// long is set once a long boop has been reported for the boop being held
+	long bool
+	// taps counted so far, and when the last one ended
+	taps   uint8
+	lastUp time.Time
+	// event is what happened this tick
+	event BoopEvent
+}
+
+// BoopEvent returns the boop event that happened this tick, if any. Each event is only reported for one tick.
+func (g *Gotogen) BoopEvent() BoopEvent {
+	g.owner.check()
+	return g.boopClass.event
+}
+
+// updateBoopEvents works out this tick's boop event from the boop reading.
+func (g *Gotogen) updateBoopEvents() {
+	c := &g.boopClass
+	c.event = BoopEventNone
+	now := time.Now()
+	if raw := g.booped(); raw != c.raw {
+		c.raw, c.rawSince = raw, now
+	}
+	if c.raw != c.down && now.Sub(c.rawSince) >= boopDebounce {
+		c.down = c.raw
+		if c.down {
+			c.downSince, c.long = now, false
+		} else if !c.long {
+			c.taps++
+			c.lastUp = now
+		}
+	}
+
+	switch {
+	case c.down && !c.long && now.Sub(c.downSince) >= boopLongTime:
+		// any taps just before are part of the long boop
+		c.long, c.taps = true, 0
+		g.emitBoopEvent(BoopEventLong)
+	case !c.down && c.taps > 0 && now.Sub(c.lastUp) >= boopTapGap:
+		e := BoopEventTripleTap
+		if c.taps < 3 {
+			e = BoopEventTap + BoopEvent(c.taps-1)
+		}
+		c.taps = 0
+		g.emitBoopEvent(e)
+	}
+}
+
+// emitBoopEvent reports a boop event to the rules and the running animation.
+func (g *Gotogen) emitBoopEvent(e BoopEvent) {
+	g.boopClass.event = e
+	g.log(LogDebug, "boop event: "+e.String())
+	if bl, ok := g.activeAnim.(animation.BoopListener); ok {
+		bl.Boop(e)
+	}
+}
+
+// parseBoopEvent parses the name of a boop event, as used in rules.
+func parseBoopEvent(name string) (BoopEvent, bool) {
+	for e := BoopEventTap; e < animation.BoopEventCount; e++ {
+		if e.String() == name {
+			return e, true
+		}
+	}
+	return BoopEventNone, false
+}
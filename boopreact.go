@@ -0,0 +1,189 @@
+package gotogen
+
+import (
+	"time"
+)
+
+const (
+	boopReactionSetting = "boopreact"
+	// boopReactionEye and boopReactionNose are the expression the face changes to when it is booped.
+	boopReactionEye  = "heart"
+	boopReactionNose = "scrunch"
+	// boopRelease is how far below the boop threshold the reading has to drop before another boop can set off the
+	// reaction, so that a hand hovering at the edge of the threshold doesn't make the face flicker.
+	boopRelease = 8
+)
+
+// boopReactionFrames and boopReactionTimes are the options for the Boop frames and Boop time settings.
+var (
+	boopReactionFrames = [...]uint8{1, 3, 6}
+	boopReactionTimes  = [...]time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+)
+
+// boopReaction changes the default face to a booped expression, heart eyes and a scrunched nose, for a few seconds
+// when the nose is booped.
+type boopReaction struct {
+	enabled bool
+	frames  uint8
+	hold    time.Duration
+
+	// count is how many frames in a row the sensor has read a boop
+	count uint8
+	// armed is cleared when the reaction starts, and set again once the reading has dropped well clear of the threshold
+	armed  bool
+	active bool
+	until  time.Time
+	// eye and nose are the parts to put back afterwards
+	eye, nose string
+}
+
+// boopReactionAllowed reports whether the face can react to boops right now. Like micro-expressions, only the idle
+// face reacts, and the expression lock and do not disturb keep the face as it is.
+func (g *Gotogen) boopReactionAllowed() bool {
+	return g.faceState == faceStateDefault && !g.dnd && !g.photoMode && !g.exprLocked() && !g.asleep()
+}
+
+// updateBoopReaction starts the reaction once the boop has been held for enough frames, and ends it once the boop has
+// been gone for long enough.
+func (g *Gotogen) updateBoopReaction() {
+	r := &g.boopReact
+	now := time.Now()
+	if r.active {
+		if g.booped() {
+			r.until = now.Add(r.hold)
+		}
+		if now.After(r.until) || !g.boopReactionAllowed() {
+			g.endBoopReaction()
+		}
+	}
+	if !r.enabled || g.boopCal.running {
+		r.count = 0
+		return
+	}
+
+	switch {
+	case g.booped():
+		if r.count < 0xFF {
+			r.count++
+		}
+	case int(g.boopDist)+boopRelease <= int(g.boopCal.threshold):
+		r.count, r.armed = 0, true
+	}
+	if r.armed && !r.active && r.count >= r.frames && g.boopReactionAllowed() {
+		r.armed = false
+		g.react(g.startBoopReaction)
+	}
+}
+
+// startBoopReaction changes to the booped expression. It doesn't go through Command, since it is a reaction to this
+// unit's own sensor rather than something to record or synchronize with peers.
+func (g *Gotogen) startBoopReaction() {
+	r := &g.boopReact
+	// the reaction may have been queued, so things could have changed since it was set off
+	if r.active || !g.boopReactionAllowed() {
+		return
+	}
+	eye, nose, _ := f.Parts()
+	if err := f.SetParts(boopReactionEye, boopReactionNose, ""); err != nil {
+		g.reportError(err)
+		return
+	}
+	g.log(LogInfo, "boop reaction")
+	r.active, r.eye, r.nose = true, eye, nose
+	r.until = time.Now().Add(r.hold)
+}
+
+// endBoopReaction puts the expression back how it was, unless something else has changed it in the meantime.
+func (g *Gotogen) endBoopReaction() {
+	r := &g.boopReact
+	if !r.active {
+		return
+	}
+	r.active = false
+	if eye, nose, _ := f.Parts(); eye != boopReactionEye || nose != boopReactionNose {
+		return
+	}
+	g.reportError(f.SetParts(r.eye, r.nose, ""))
+}
+
+func (g *Gotogen) loadBoopReaction() {
+	r := &g.boopReact
+	r.enabled, r.frames, r.hold = true, boopReactionFrames[1], boopReactionTimes[1]
+	b, ok := g.loadSetting(boopReactionSetting)
+	if !ok || len(b) != 3 || int(b[1]) >= len(boopReactionFrames) || int(b[2]) >= len(boopReactionTimes) {
+		return
+	}
+	r.enabled = b[0] == 1
+	r.frames = boopReactionFrames[b[1]]
+	r.hold = boopReactionTimes[b[2]]
+}
+
+func (g *Gotogen) saveBoopReaction() {
+	r := &g.boopReact
+	on := uint8(0)
+	if r.enabled {
+		on = 1
+	}
+	g.saveSetting(boopReactionSetting, []byte{on, g.boopReactionFramesActive(), g.boopReactionTimeActive()})
+}
+
+func (g *Gotogen) boopReactionFramesActive() uint8 {
+	for i, n := range boopReactionFrames {
+		if n == g.boopReact.frames {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+func (g *Gotogen) boopReactionTimeActive() uint8 {
+	for i, d := range boopReactionTimes {
+		if d == g.boopReact.hold {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+// boopReactionItems are the settings for the boop reaction, in the Boop sensor menu.
+func (g *Gotogen) boopReactionItems() []Item {
+	on := uint8(0)
+	if g.boopReact.enabled {
+		on = 1
+	}
+	return []Item{
+		&SettingItem{
+			Name:    "Boop face",
+			Help:    "Show heart eyes and a scrunched nose for a few seconds when the nose is booped.",
+			Options: []string{"off", "on"},
+			Active:  on,
+			Apply: func(selected uint8) {
+				g.boopReact.enabled = selected == 1
+				if !g.boopReact.enabled {
+					g.endBoopReaction()
+				}
+				g.saveBoopReaction()
+			},
+		},
+		&SettingItem{
+			Name:    "Boop frames",
+			Help:    "How many frames in a row the sensor has to see a boop for the face to react. More ignores brushes past the nose.",
+			Options: []string{"1", "3", "6"},
+			Active:  g.boopReactionFramesActive(),
+			Apply: func(selected uint8) {
+				g.boopReact.frames = boopReactionFrames[selected]
+				g.saveBoopReaction()
+			},
+		},
+		&SettingItem{
+			Name:    "Boop time",
+			Help:    "How long the face stays booped after the boop ends.",
+			Options: []string{"2s", "4s", "8s"},
+			Active:  g.boopReactionTimeActive(),
+			Apply: func(selected uint8) {
+				g.boopReact.hold = boopReactionTimes[selected]
+				g.saveBoopReaction()
+			},
+		},
+	}
+}
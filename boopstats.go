@@ -0,0 +1,126 @@
+package gotogen
+
+import (
+	"encoding/binary"
+	"strconv"
+	"time"
+)
+
+const (
+	boopStatsSetting = "boops"
+	// boopStatsSaveInterval limits how often the counters are saved, to spare the flash.
+	boopStatsSaveInterval = time.Minute
+)
+
+// boopStats counts boops for the stats page. Without the time of day, "today" and hours are counted from boot.
+type boopStats struct {
+	lifetime uint32
+	today    uint32
+	// day is when today is, as YYYYMMDD, or 0 if the time of day isn't known
+	day      uint32
+	hour     uint32
+	bestHour uint32
+	// hourKey identifies the hour being counted: hours since the epoch, or since boot without the time of day
+	hourKey  int64
+	dirty    bool
+	nextSave time.Time
+}
+
+// boopPeriod works out which day and hour it is, for the boop counters.
+func (g *Gotogen) boopPeriod() (day uint32, hour int64) {
+	if t, ok := g.wallClock(); ok {
+		return uint32(t.Year()*10000 + int(t.Month())*100 + t.Day()), t.Unix() / 3600
+	}
+	return 0, int64(time.Since(g.start) / time.Hour)
+}
+
+// rollBoopPeriod starts new day and hour counts if the day or hour has changed.
+func (g *Gotogen) rollBoopPeriod() {
+	s := &g.boopStats
+	day, hour := g.boopPeriod()
+	if day != s.day {
+		s.day, s.today = day, 0
+		s.dirty = true
+	}
+	if hour != s.hourKey {
+		s.hourKey, s.hour = hour, 0
+	}
+}
+
+// countBoop is called for every boop.
+func (g *Gotogen) countBoop() {
+	s := &g.boopStats
+	g.rollBoopPeriod()
+	s.lifetime++
+	s.today++
+	s.hour++
+	if s.hour > s.bestHour {
+		s.bestHour = s.hour
+	}
+	s.dirty = true
+}
+
+// updateBoopStats saves the counters every so often if they changed.
+func (g *Gotogen) updateBoopStats() {
+	s := &g.boopStats
+	if !s.dirty || time.Now().Before(s.nextSave) {
+		return
+	}
+	s.dirty = false
+	s.nextSave = time.Now().Add(boopStatsSaveInterval)
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint32(b, s.lifetime)
+	binary.LittleEndian.PutUint32(b[4:], s.bestHour)
+	binary.LittleEndian.PutUint32(b[8:], s.today)
+	binary.LittleEndian.PutUint32(b[12:], s.day)
+	g.saveSetting(boopStatsSetting, b)
+}
+
+func (g *Gotogen) loadBoopStats() {
+	s := &g.boopStats
+	s.day, s.hourKey = g.boopPeriod()
+	b, ok := g.loadSetting(boopStatsSetting)
+	if !ok || len(b) != 16 {
+		return
+	}
+	s.lifetime = binary.LittleEndian.Uint32(b)
+	s.bestHour = binary.LittleEndian.Uint32(b[4:])
+	// today only carries over a reboot if it is still the same day
+	if day := binary.LittleEndian.Uint32(b[12:]); day != 0 && day == s.day {
+		s.today = binary.LittleEndian.Uint32(b[8:])
+	}
+}
+
+// boopStatLines are the counters, as shown on the stats page and replied to the boops command.
+func (g *Gotogen) boopStatLines() [][2]string {
+	g.rollBoopPeriod()
+	s := &g.boopStats
+	return [][2]string{
+		{"this hour", strconv.FormatUint(uint64(s.hour), 10)},
+		{"today", strconv.FormatUint(uint64(s.today), 10)},
+		{"best hour", strconv.FormatUint(uint64(s.bestHour), 10)},
+		{"lifetime", strconv.FormatUint(uint64(s.lifetime), 10)},
+	}
+}
+
+func (g *Gotogen) showBoopStats() {
+	w, _ := g.statusText.Size()
+	var lines []string
+	for _, l := range g.boopStatLines() {
+		lines = append(lines, padLine(l[0], l[1], int(w)))
+	}
+	g.showPage("BOOPS", lines)
+}
+
+// replyBoopStats replies with the counters, as "boops NAME COUNT" lines, e.g. for a public boop counter web page.
+func (g *Gotogen) replyBoopStats() {
+	for _, l := range g.boopStatLines() {
+		name := []byte(l[0])
+		for i, c := range name {
+			if c == ' ' {
+				name[i] = '_'
+			}
+		}
+		g.reply("boops " + string(name) + " " + l[1])
+	}
+}
@@ -0,0 +1,198 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// BootProfile is an optional interface that a Driver may implement to customize what happens while booting.
+type BootProfile interface {
+	// BootAnimation returns the kind of animation (as in the full-screen animations menu, e.g. "slide") and the
+	// full-screen image to play on the face while booting. If kind is empty, the static "wait" image is used.
+	BootAnimation() (kind, file string)
+	// BootSound returns the name of the sound to play via AudioOutput once booting is complete, or empty for none.
+	BootSound() string
+}
+
+// AudioOutput is an optional interface that a Driver may implement if it is able to play sounds.
+type AudioOutput interface {
+	// PlaySound starts playing the named sound. It should not wait for the sound to finish.
+	PlaySound(name string) error
+}
+
+// BootReporter receives boot (and other long-running operation) messages from drivers, to display on the status
+// screen. This keeps drivers from depending on how the status screen is actually drawn. The messages are logged too.
+type BootReporter interface {
+	// Println displays a line of text.
+	Println(s string)
+	// PrintlnInverse displays a line of text in a way that stands out, typically for errors.
+	PrintlnInverse(s string)
+	// Progress indicates how far along a lengthy operation is.
+	Progress(done, total uint8)
+}
+
+// textBootReporter is the BootReporter for the status text buffer.
+type textBootReporter struct {
+	buf *glyphText
+	g   *Gotogen
+}
+
+func (r textBootReporter) Println(s string) {
+	r.g.log(LogInfo, s)
+	_ = r.buf.Println(s)
+	r.g.bootFrame()
+}
+
+func (r textBootReporter) PrintlnInverse(s string) {
+	r.g.log(LogWarning, s)
+	_ = r.buf.PrintlnInverse(s)
+	r.g.bootFrame()
+}
+
+// Progress draws a progress bar on the last line of the text buffer.
+func (r textBootReporter) Progress(done, total uint8) {
+	if total == 0 {
+		return
+	}
+	if done > total {
+		done = total
+	}
+	w, h := r.buf.Size()
+	bar := make([]byte, w)
+	bar[0], bar[w-1] = '[', ']'
+	fill := 1 + int(done)*int(w-2)/int(total)
+	for i := 1; i < int(w-1); i++ {
+		if i < fill {
+			bar[i] = '#'
+		} else {
+			bar[i] = ' '
+		}
+	}
+	_ = r.buf.SetLine(h-1, string(bar))
+	r.g.bootFrame()
+}
+
+// startBootAnimation puts the boot animation from the driver's BootProfile on the face, or the busy image if there
+// isn't one.
+func (g *Gotogen) startBootAnimation() error {
+	bp, ok := g.driver.(BootProfile)
+	if !ok {
+		g.busy()
+		return nil
+	}
+	kind, file := bp.BootAnimation()
+	if kind == "" {
+		g.busy()
+		return nil
+	}
+	k, ok := findAnimationKind(kind)
+	if !ok {
+		return errors.New("unknown boot animation " + kind)
+	}
+	a, err := k.new(file)
+	if err != nil {
+		return errcode.Context("boot animation", err)
+	}
+
+	g.faceState = faceStateBusy
+	a.Activate(g.faceMirror)
+	_ = g.faceDisplay.Display()
+	g.activeAnim = a
+	return nil
+}
+
+// bootStage is a step of Init. While booting, the status LED blinks out the current stage: the stage number as a count
+// of blinks, in a different color for each stage if the LED is RGB. If booting fails, Halt keeps blinking out the
+// stage it failed at, so that it can be seen from outside the head.
+type bootStage uint8
+
+const (
+	bootStageNone bootStage = iota
+	bootStageStatus
+	bootStageEarlyInit
+	bootStageAnimation
+	bootStageLateInit
+	bootStageSettings
+	bootStageFace
+	bootStageCount
+)
+
+func (s bootStage) String() string {
+	switch s {
+	case bootStageNone:
+		return "none"
+	case bootStageStatus:
+		return "status display"
+	case bootStageEarlyInit:
+		return "early init"
+	case bootStageAnimation:
+		return "boot animation"
+	case bootStageLateInit:
+		return "late init"
+	case bootStageSettings:
+		return "settings"
+	case bootStageFace:
+		return "face"
+	default:
+		return "INVALID"
+	}
+}
+
+// bootStageColors are the indexes into ledColors for each bootStage.
+var bootStageColors = [bootStageCount]uint8{0, 7, 1, 5, 3, 4, 6}
+
+// setBootStage moves on to the next stage of booting.
+func (g *Gotogen) setBootStage(s bootStage) {
+	g.log(LogInfo, "boot stage "+strconv.Itoa(int(s))+" "+s.String())
+	g.bootStage = s
+	g.setLEDColorIndex(bootStageColors[s])
+	g.Blink(CodePattern(uint8(s)), true)
+	g.updateBlinker()
+}
+
+// Halt does not return. It blinks out the stage of booting that failed on the status LED forever; call it if Init
+// returns an error and there's nothing better to do.
+func (g *Gotogen) Halt() {
+	for {
+		g.updateBlinker()
+		time.Sleep(blinkUnit / 2)
+	}
+}
+
+// bootFrame advances the boot animation, if it's time for another frame. Since nothing is running the main loop while
+// booting, this is called whenever there is boot progress.
+func (g *Gotogen) bootFrame() {
+	if !g.init {
+		g.updateBlinker()
+	}
+	if g.init || g.faceState != faceStateBusy || g.activeAnim == nil || time.Since(g.lastBootFrame) < g.frameTime {
+		return
+	}
+	g.lastBootFrame = time.Now()
+	g.tick++
+	g.activeAnim.DrawFrame(g.faceMirror, g.tick)
+	_ = g.faceDisplay.Display()
+}
+
+// bootSound plays the boot sound from the driver's BootProfile, if there is one and the driver can play sounds.
+func (g *Gotogen) bootSound() {
+	bp, ok := g.driver.(BootProfile)
+	if !ok {
+		return
+	}
+	ao, ok := g.driver.(AudioOutput)
+	if !ok {
+		return
+	}
+	sound := bp.BootSound()
+	if sound == "" {
+		return
+	}
+	err := ao.PlaySound(sound)
+	if err != nil {
+		g.log(LogWarning, "boot sound: "+err.Error())
+	}
+}
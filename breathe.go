@@ -0,0 +1,74 @@
+package gotogen
+
+import (
+	"time"
+)
+
+// breathAmplitudes are how much each Breathing setting dims the face at the bottom of a breath, out of 256.
+var breathAmplitudes = [...]uint8{0, 10, 24, 48}
+
+// breathPeriods are how long one breath takes for each Breath speed setting.
+var breathPeriods = [...]time.Duration{3 * time.Second, 5 * time.Second, 8 * time.Second}
+
+// breathing slowly pulses the brightness of the idle face, so it looks like it is resting rather than switched off.
+type breathing struct {
+	amplitude uint8
+	period    time.Duration
+	// level is how much the brightness is currently reduced by, out of 256.
+	level uint8
+}
+
+// breathActive reports whether the face should be breathing. Talking and animations have their own movement, and
+// breathing over them just makes them look unsteady.
+func (g *Gotogen) breathActive() bool {
+	return g.breath.amplitude > 0 && g.faceState == faceStateDefault && !g.Talking() && !g.dnd && !g.photoMode &&
+		!g.asleep()
+}
+
+// updateBreathing moves the breath along. When breathing stops, the brightness eases back up to full rather than
+// jumping.
+func (g *Gotogen) updateBreathing() {
+	b := &g.breath
+	if !g.breathActive() {
+		if b.level > 0 {
+			g.setBreath(b.level - 1)
+		}
+		return
+	}
+
+	// phase goes 0 to 255 and back down over one period, then is eased so that it slows at the ends like a sine
+	period := uint32(b.period / time.Millisecond)
+	phase := uint32(time.Since(g.start)%b.period/time.Millisecond) * 512 / period
+	if phase >= 256 {
+		phase = 511 - phase
+	}
+	eased := phase * phase * (3*256 - 2*phase) / (256 * 256)
+	target := uint8(eased * uint32(b.amplitude) / 256)
+
+	// ease into a new amplitude, or into breathing after it was suspended, the same way it eases out
+	switch {
+	case target > b.level+1:
+		g.setBreath(b.level + 1)
+	case target+1 < b.level:
+		g.setBreath(b.level - 1)
+	default:
+		g.setBreath(target)
+	}
+}
+
+// setBreath changes how much the brightness is reduced for breathing. The face is redrawn for it by updateDimming.
+func (g *Gotogen) setBreath(level uint8) {
+	g.breath.level = level
+}
+
+func (g *Gotogen) setBreathAmplitude(selected uint8) {
+	if int(selected) < len(breathAmplitudes) {
+		g.breath.amplitude = breathAmplitudes[selected]
+	}
+}
+
+func (g *Gotogen) setBreathPeriod(selected uint8) {
+	if int(selected) < len(breathPeriods) {
+		g.breath.period = breathPeriods[selected]
+	}
+}
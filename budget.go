@@ -0,0 +1,47 @@
+package gotogen
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+)
+
+// animBudgets are the selectable memory budgets for loading an animation, in kilobytes. Zero is unlimited.
+var animBudgets = []uint64{0, 16, 32, 64, 128}
+
+// allocated returns how much memory has been allocated since boot. Unlike the size of the heap, this never goes down,
+// so the difference between two calls is how much was allocated in between regardless of garbage collection.
+func allocated() uint64 {
+	mem := runtime.MemStats{}
+	runtime.ReadMemStats(&mem)
+	return mem.TotalAlloc
+}
+
+// checkAnimBudget returns an error if an animation that allocated used bytes while loading doesn't fit in the memory
+// budget.
+func (g *Gotogen) checkAnimBudget(file string, used uint64) error {
+	if g.animBudget == 0 || used <= g.animBudget {
+		return nil
+	}
+	// get rid of what it loaded before anything else needs the memory
+	runtime.GC()
+	return errors.New(file + " too big: " + strconv.FormatUint(used/1024, 10) + "k")
+}
+
+func (g *Gotogen) setAnimBudget(selected uint8) {
+	if int(selected) < len(animBudgets) {
+		g.animBudget = animBudgets[selected] * 1024
+	}
+}
+
+func animBudgetNames() []string {
+	names := make([]string, len(animBudgets))
+	for i, b := range animBudgets {
+		if b == 0 {
+			names[i] = "off"
+		} else {
+			names[i] = strconv.FormatUint(b, 10) + "k"
+		}
+	}
+	return names
+}
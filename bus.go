@@ -0,0 +1,63 @@
+package gotogen
+
+import (
+	"errors"
+)
+
+// busSize is how many messages from background goroutines can be waiting for the main loop.
+const busSize = 16
+
+// Concurrency model: everything in Gotogen belongs to the goroutine that calls RunTick (the main loop), and nothing
+// locks. Drivers and background modules (networking, audio analysis, etc.) that run their own goroutines must not call
+// Gotogen's methods directly; instead they hand work to the main loop with Post or Do, which are the only methods that
+// are safe to call from any goroutine. Builds with the gotogendebug tag check this and panic on a violation.
+
+// busMessage is a command line or function waiting to be run on the main loop.
+type busMessage struct {
+	cmd string
+	f   func()
+}
+
+// errBusFull is returned when the main loop isn't keeping up with messages from other goroutines.
+var errBusFull = errors.New("command bus full")
+
+// Post queues a command (see Command) to be run on the main loop. It is safe to call from any goroutine, and does not
+// block; if too many messages are already waiting, it returns an error.
+func (g *Gotogen) Post(line string) error {
+	select {
+	case g.bus <- busMessage{cmd: line}:
+		return nil
+	default:
+		return errBusFull
+	}
+}
+
+// Do queues a function to be run on the main loop, where it may freely use the Gotogen. It is safe to call from any
+// goroutine, and does not block; if too many messages are already waiting, it returns an error.
+func (g *Gotogen) Do(f func()) error {
+	select {
+	case g.bus <- busMessage{f: f}:
+		return nil
+	default:
+		return errBusFull
+	}
+}
+
+// drainBus runs everything posted from other goroutines.
+func (g *Gotogen) drainBus() {
+	for {
+		select {
+		case m := <-g.bus:
+			if m.f != nil {
+				m.f()
+				continue
+			}
+			err := g.Command(m.cmd)
+			if err != nil {
+				g.log(LogWarning, "posted command error: "+errorText(err))
+			}
+		default:
+			return
+		}
+	}
+}
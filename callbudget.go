@@ -0,0 +1,84 @@
+package gotogen
+
+import (
+	"strconv"
+	"time"
+)
+
+// driverCallBudget is how long a driver callback that is called every frame should take at most. Anything slower is
+// probably doing a blocking bus transaction in the main loop, which should be done in the background instead.
+const driverCallBudget = 2 * time.Millisecond
+
+// driverCall is a driver callback that is called every frame, and timed.
+type driverCall uint8
+
+const (
+	driverCallPressedButton driverCall = iota
+	driverCallBoopDistance
+	driverCallAccelerometer
+	driverCallTalking
+	driverCallTalkingLevel
+	driverCallCount
+)
+
+func (c driverCall) String() string {
+	switch c {
+	case driverCallPressedButton:
+		return "PressedButton"
+	case driverCallBoopDistance:
+		return "BoopDistance"
+	case driverCallAccelerometer:
+		return "Accelerometer"
+	case driverCallTalking:
+		return "Talking"
+	case driverCallTalkingLevel:
+		return "TalkingLevel"
+	default:
+		return "INVALID"
+	}
+}
+
+// driverCallTimes are the slowest time and how many times each driver callback went over the budget, since boot.
+type driverCallTimes struct {
+	max  [driverCallCount]time.Duration
+	over [driverCallCount]uint32
+}
+
+// timeDriverCall records how long a driver callback took, given when it started. Each time a callback sets a new record
+// over the budget, it is logged.
+func (g *Gotogen) timeDriverCall(c driverCall, start time.Time) {
+	d := time.Since(start)
+	t := &g.driverCalls
+	if d <= driverCallBudget {
+		if d > t.max[c] {
+			t.max[c] = d
+		}
+		return
+	}
+	t.over[c]++
+	if d > t.max[c] {
+		t.max[c] = d
+		g.log(LogWarning, "slow driver: "+c.String()+" took "+d.String()+", budget "+driverCallBudget.String())
+	}
+}
+
+// slowDriverCalls returns how many times driver callbacks went over the budget, all together.
+func (g *Gotogen) slowDriverCalls() uint32 {
+	var n uint32
+	for _, o := range g.driverCalls.over {
+		n += o
+	}
+	return n
+}
+
+// showDriverCalls shows the slowest time and how many slow calls there were for each driver callback.
+func (g *Gotogen) showDriverCalls() {
+	w, _ := g.statusText.Size()
+	var lines []string
+	for c := driverCall(0); c < driverCallCount; c++ {
+		t := &g.driverCalls
+		lines = append(lines, c.String(), padLine(" max "+t.max[c].Round(10*time.Microsecond).String(),
+			"slow "+strconv.FormatUint(uint64(t.over[c]), 10), int(w)))
+	}
+	g.showPage("DRIVER CALLS", lines)
+}
@@ -0,0 +1,141 @@
+package gotogen
+
+import (
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation/marquee"
+)
+
+const (
+	// maxCaptions is how many captions can be waiting to be shown before the oldest are dropped.
+	maxCaptions = 8
+	// captionBaseTime is how long every caption is shown for, plus captionCharTime for each character.
+	captionBaseTime = 2 * time.Second
+	captionCharTime = 60 * time.Millisecond
+)
+
+// caption is text waiting to be shown on the status display.
+type caption struct {
+	text string
+	// whether to also scroll it across the face
+	face bool
+	// extra time to show it for, on top of the time based on its length
+	extra time.Duration
+}
+
+// Caption queues text (e.g. from a speech-to-text app on a phone) to be shown on the status display, word-wrapped. If
+// enabled in the menu, it also scrolls across the face. Captions are shown one after another; pressing any button
+// clears them.
+//
+// Caption must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) Caption(text string) {
+	g.owner.check()
+	g.queueCaption(caption{text: text, face: g.captionMarquee})
+}
+
+func (g *Gotogen) queueCaption(c caption) {
+	c.text = strings.TrimSpace(c.text)
+	if c.text == "" {
+		return
+	}
+	if len(g.captions) >= maxCaptions {
+		g.captions = g.captions[1:]
+	}
+	g.captions = append(g.captions, c)
+	if g.statusState != statusStateCaption && g.statusState != statusStateMenu && g.statusState != statusStateEditor &&
+		g.statusState != statusStateRemap && g.statusState != statusStateHelp && g.statusState != statusStateCurve &&
+		g.statusState != statusStatePrompter && g.statusState != statusStatePreview && g.statusState != statusStateQR {
+		g.changeStatusState(statusStateCaption)
+		g.nextCaption()
+	}
+}
+
+// nextCaption shows the next caption in the queue, or returns to the idle screen if there are none left.
+func (g *Gotogen) nextCaption() {
+	if len(g.captions) == 0 {
+		g.changeStatusState(statusStateIdle)
+		return
+	}
+	c := g.captions[0]
+	g.captions = g.captions[1:]
+	text := c.text
+	g.captionUntil = time.Now().Add(captionBaseTime + time.Duration(len(text))*captionCharTime + c.extra)
+
+	g.statusText.Clear()
+	w, h := g.statusText.Size()
+	for i, line := range wordWrap(text, int(w)) {
+		if i >= int(h) {
+			break
+		}
+		_ = g.statusText.SetLine(int16(i), line)
+	}
+
+	if c.face && !g.dnd && g.faceState != faceStateBusy {
+		g.startAnimation(marquee.New(text, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, 2))
+	}
+}
+
+// updateCaption is called every frame while captions are being shown.
+func (g *Gotogen) updateCaption() {
+	if g.pressedButton() != MenuButtonNone {
+		g.captions = g.captions[:0]
+		g.changeStatusState(statusStateIdle)
+		return
+	}
+	if time.Now().After(g.captionUntil) {
+		g.nextCaption()
+	}
+}
+
+// wordWrap splits the text into lines no longer than width, breaking between words where possible.
+func wordWrap(text string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		for len(word) > width {
+			// words that don't fit on a line at all get broken up
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (g *Gotogen) captionsMenu() *Menu {
+	return &Menu{
+		Name: "Captions",
+		Items: []Item{
+			&SettingItem{
+				Name:    "Show on face",
+				Options: []string{"off", "on"},
+				Active:  0,
+				Apply:   func(selected uint8) { g.captionMarquee = selected == 1 },
+			},
+			&SettingItem{
+				Name:    "Art credits",
+				Help:    "Briefly show who made the art when it goes on the face, if the media credits.txt says.",
+				Options: []string{"off", "on"},
+				Active:  1,
+				Apply:   func(selected uint8) { g.artCredits = selected == 1 },
+			},
+		},
+	}
+}
@@ -0,0 +1,179 @@
+package gotogen
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+const characterSetting = "character"
+
+// Character is a complete character that the head can play: its art, where the parts of its face go, its colors, and
+// how it reacts. Switching characters doesn't reboot, so one head can be different characters at different events.
+type Character struct {
+	Name string
+	// Media is the character's face pack, used in preference to all other media (including its media/layout.txt), or
+	// nil to use the shared media.
+	Media MediaProvider
+	// Layout is where the parts of the default face go, or nil for the face pack's manifest or the driver's layout.
+	Layout *FaceLayout
+	// Palette is the name of the palette to use, as in the Palette setting, or empty for the saved one.
+	Palette string
+	// Rules are the character's own reaction rules, in the same form as "rule add", used as well as the saved ones.
+	Rules []string
+}
+
+// CharacterProvider is an optional interface that a Driver may implement to offer characters to switch between. The
+// first one is the default. The last one used is remembered across reboots.
+type CharacterProvider interface {
+	Characters() []Character
+}
+
+// characters are the driver's characters and which one is being played.
+type characters struct {
+	list    []Character
+	current int
+	// rules are the current character's reaction rules
+	rules []*rule
+}
+
+// loadCharacters gets the driver's characters, if it has any, and switches to the one that was last used.
+func (g *Gotogen) loadCharacters() {
+	cp, ok := g.driver.(CharacterProvider)
+	if !ok {
+		return
+	}
+	g.characters.list = cp.Characters()
+	if len(g.characters.list) == 0 {
+		return
+	}
+	i := 0
+	if b, ok := g.loadSetting(characterSetting); ok {
+		if j := g.findCharacter(string(b)); j >= 0 {
+			i = j
+		}
+	}
+	g.reportError(g.switchCharacter(i))
+}
+
+// findCharacter returns the index of the character with the name, ignoring case, or -1 if there isn't one.
+func (g *Gotogen) findCharacter(name string) int {
+	for i, c := range g.characters.list {
+		if strings.EqualFold(c.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SwitchCharacter switches to the named character, and remembers it for the next boot.
+//
+// SwitchCharacter must be called from the same goroutine as RunTick; other goroutines can use Do.
+func (g *Gotogen) SwitchCharacter(name string) error {
+	g.owner.check()
+	i := g.findCharacter(name)
+	if i < 0 {
+		return errors.New("unknown character " + name)
+	}
+	return g.setCharacter(i)
+}
+
+// setCharacter switches to a character and saves it as the one to use next boot.
+func (g *Gotogen) setCharacter(i int) error {
+	if err := g.switchCharacter(i); err != nil {
+		return err
+	}
+	g.saveSetting(characterSetting, []byte(g.characters.list[i].Name))
+	return nil
+}
+
+// switchCharacter puts everything that makes up the character in place. The face picks up the new face pack on its
+// next frame, so switching is quick.
+func (g *Gotogen) switchCharacter(i int) error {
+	if i < 0 || i >= len(g.characters.list) {
+		return errors.New("no such character")
+	}
+	c := g.characters.list[i]
+	var rules []*rule
+	for _, line := range c.Rules {
+		r, err := parseRule(line)
+		if err != nil {
+			return errcode.Context(c.Name, err)
+		}
+		rules = append(rules, r)
+	}
+
+	g.log(LogInfo, "character: "+c.Name)
+	g.characters.current = i
+	g.characters.rules = rules
+	media.SetPack(c.Media)
+	g.loadPalette()
+	if c.Palette != "" {
+		for j, p := range palettes {
+			if strings.EqualFold(p.name, c.Palette) {
+				g.palette, g.paletteRemap = uint8(j), p.remap
+			}
+		}
+	}
+	if f != nil {
+		// while booting, the face stage does this once the face is loaded
+		g.applyFaceLayout()
+		g.redrawFrame()
+	}
+	return nil
+}
+
+// characterLayout is the current character's face layout, if it has one.
+func (g *Gotogen) characterLayout() (FaceLayout, bool) {
+	if len(g.characters.list) == 0 {
+		return FaceLayout{}, false
+	}
+	l := g.characters.list[g.characters.current].Layout
+	if l == nil {
+		return FaceLayout{}, false
+	}
+	return *l, true
+}
+
+// characterCommand handles "character [NAME|next]". Without a name, it replies with the characters, marking the
+// current one.
+func (g *Gotogen) characterCommand(args []string) error {
+	if len(g.characters.list) == 0 {
+		return errors.New("character: no characters")
+	}
+	switch {
+	case len(args) == 0:
+		for i, c := range g.characters.list {
+			line := "character " + c.Name
+			if i == g.characters.current {
+				line += " *"
+			}
+			g.reply(line)
+		}
+		return nil
+	case args[0] == "next":
+		return g.setCharacter((g.characters.current + 1) % len(g.characters.list))
+	}
+	return g.SwitchCharacter(strings.Join(args, " "))
+}
+
+func (g *Gotogen) characterMenuSetting() *SettingItem {
+	if len(g.characters.list) < 2 {
+		return nil
+	}
+	var names []string
+	for _, c := range g.characters.list {
+		names = append(names, c.Name)
+	}
+	return &SettingItem{
+		Name:    "Switch character",
+		Help:    "Changes the whole character: the face art, layout, colors and reaction rules. It is remembered across reboots.",
+		Options: names,
+		Active:  uint8(g.characters.current),
+		Apply: func(selected uint8) {
+			g.reportError(g.setCharacter(int(selected)))
+		},
+	}
+}
@@ -0,0 +1,349 @@
+// Command gotogenctl controls a Gotogen from a host computer, over a serial port or a TCP connection (e.g. to a
+// BLE-to-TCP or web bridge). It speaks the same text commands as Gotogen.Command, so the unit's driver must implement
+// CommandSource and CommandReplier on that connection.
+//
+// Usage:
+//
+//	gotogenctl [-port /dev/ttyACM0 | -addr host:port] COMMAND [ARGS...]
+//
+// Commands:
+//
+//	push TYPE FILE       push an image file (BMP, PNG or GIF) as media of the type (eye, nose, mouth, full)
+//	anim KIND NAME       start a full-screen animation, e.g. "anim slide wait"
+//	logs                 print everything the unit sends, until interrupted
+//	settings [OUT]       export the stored settings, to OUT or standard output
+//	capture OUT.png      save the current face frame as a PNG
+//	screenshots DIR      save screenshots sent by the unit into DIR, until interrupted
+//	bench [OUT.csv]      benchmark the unit with different settings, saving CSV to OUT or standard output
+//	send COMMAND...      send any other command, e.g. "send preset 2"
+//
+// USB serial ports on the supported boards ignore the baud rate, so the port is used as-is.
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/pixel"
+)
+
+// chunkSize is how many bytes of a file are sent in each putmedia command.
+const chunkSize = 192
+
+// replyTimeout is how long to wait for the unit to finish a command.
+const replyTimeout = 10 * time.Second
+
+func main() {
+	port := flag.String("port", "/dev/ttyACM0", "serial port the unit is connected to")
+	addr := flag.String("addr", "", "TCP address of a bridge to the unit, instead of a serial port")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: gotogenctl [flags] push|anim|logs|settings|capture|screenshots|bench|send [args...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	conn, err := dial(*port, *addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gotogenctl:", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+
+	err = run(newUnit(conn), flag.Arg(0), flag.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gotogenctl:", err)
+		os.Exit(1)
+	}
+}
+
+func dial(port, addr string) (io.ReadWriteCloser, error) {
+	if addr != "" {
+		return net.Dial("tcp", addr)
+	}
+	return os.OpenFile(port, os.O_RDWR, 0)
+}
+
+func run(u *unit, cmd string, args []string) error {
+	switch cmd {
+	case "push":
+		if len(args) != 2 {
+			return errors.New("push: need type and file")
+		}
+		return push(u, args[0], args[1])
+	case "anim":
+		if len(args) != 2 {
+			return errors.New("anim: need kind and name")
+		}
+		_, err := u.command("anim " + args[0] + " " + args[1])
+		return err
+	case "logs":
+		for {
+			line, err := u.readLine()
+			if err != nil {
+				return err
+			}
+			fmt.Println(line)
+		}
+	case "settings":
+		out := os.Stdout
+		if len(args) == 1 {
+			f, err := os.Create(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		lines, err := u.command("settings")
+		if err != nil {
+			return err
+		}
+		for _, l := range lines {
+			if strings.HasPrefix(l, "setting ") {
+				fmt.Fprintln(out, strings.TrimPrefix(l, "setting "))
+			}
+		}
+		return nil
+	case "capture":
+		if len(args) != 1 {
+			return errors.New("capture: need output file")
+		}
+		return capture(u, args[0])
+	case "screenshots":
+		if len(args) != 1 {
+			return errors.New("screenshots: need directory")
+		}
+		return screenshots(u, args[0])
+	case "bench":
+		out := os.Stdout
+		if len(args) == 1 {
+			f, err := os.Create(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			out = f
+		}
+		return bench(u, out)
+	case "send":
+		if len(args) == 0 {
+			return errors.New("send: need command")
+		}
+		lines, err := u.command(strings.Join(args, " "))
+		for _, l := range lines {
+			fmt.Println(l)
+		}
+		return err
+	default:
+		return errors.New("unknown command " + cmd)
+	}
+}
+
+func push(u *unit, typ, file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(file)
+	for len(data) > 0 {
+		n := chunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		_, err = u.command("putmedia " + typ + " " + name + " " + base64.StdEncoding.EncodeToString(data[:n]))
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	_, err = u.command("putmedia " + typ + " " + name + " end")
+	return err
+}
+
+func capture(u *unit, file string) error {
+	lines, err := u.command("capture")
+	if err != nil {
+		return err
+	}
+	var img *image.RGBA
+	for _, l := range lines {
+		f := strings.Fields(l)
+		switch {
+		case len(f) == 3 && f[0] == "frame":
+			w, _ := strconv.Atoi(f[1])
+			h, _ := strconv.Atoi(f[2])
+			img = image.NewRGBA(image.Rect(0, 0, w, h))
+		case len(f) == 3 && f[0] == "row" && img != nil:
+			y, _ := strconv.Atoi(f[1])
+			for x := 0; (x+1)*4 <= len(f[2]); x++ {
+				v, err := strconv.ParseUint(f[2][x*4:(x+1)*4], 16, 16)
+				if err != nil {
+					return errors.New("capture: bad pixel data")
+				}
+				img.SetRGBA(x, y, pixel.RGB565(v).RGBA())
+			}
+		}
+	}
+	if img == nil {
+		return errors.New("capture: no frame received")
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	err = png.Encode(out, img)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// benchFields are the columns of the benchmark results, in the order the unit sends them.
+var benchFields = []string{"anim", "skip", "downmix", "brightness", "fps", "headroom"}
+
+// bench runs the benchmark on the unit and writes each "bench KEY=VALUE..." line it sends back as a row of CSV, until
+// it sends "bench done". Other lines are printed to standard error.
+func bench(u *unit, out io.Writer) error {
+	_, err := u.command("bench")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, strings.Join(benchFields, ","))
+	for {
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		if line == "bench done" {
+			return nil
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 || f[0] != "bench" {
+			fmt.Fprintln(os.Stderr, line)
+			continue
+		}
+		values := make(map[string]string)
+		for _, kv := range f[1:] {
+			k, v, _ := strings.Cut(kv, "=")
+			values[k] = strings.TrimSuffix(v, "%")
+		}
+		row := make([]string, len(benchFields))
+		for i, k := range benchFields {
+			row[i] = values[k]
+		}
+		fmt.Fprintln(out, strings.Join(row, ","))
+	}
+}
+
+// screenshots saves screenshots sent by the unit as "screenshot NAME DATA" lines. Other lines are printed.
+func screenshots(u *unit, dir string) error {
+	files := make(map[string][]byte)
+	for {
+		line, err := u.readLine()
+		if err != nil {
+			return err
+		}
+		f := strings.Fields(line)
+		if len(f) != 3 || f[0] != "screenshot" {
+			fmt.Println(line)
+			continue
+		}
+		name := filepath.Base(f[1])
+		if f[2] == "end" {
+			err = os.WriteFile(filepath.Join(dir, name), files[name], 0o644)
+			if err != nil {
+				return err
+			}
+			delete(files, name)
+			fmt.Println("saved", name)
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(f[2])
+		if err != nil {
+			return errors.New("screenshot " + name + ": " + err.Error())
+		}
+		files[name] = append(files[name], b...)
+	}
+}
+
+// unit is a connection to a Gotogen.
+type unit struct {
+	w     io.Writer
+	lines chan string
+	err   chan error
+}
+
+func newUnit(conn io.ReadWriter) *unit {
+	u := &unit{
+		w:     conn,
+		lines: make(chan string, 64),
+		err:   make(chan error, 1),
+	}
+	go func() {
+		s := bufio.NewScanner(conn)
+		s.Buffer(make([]byte, 4096), 64*1024)
+		for s.Scan() {
+			u.lines <- strings.TrimRight(s.Text(), "\r")
+		}
+		err := s.Err()
+		if err == nil {
+			err = io.EOF
+		}
+		u.err <- err
+	}()
+	return u
+}
+
+func (u *unit) readLine() (string, error) {
+	select {
+	case l := <-u.lines:
+		return l, nil
+	case err := <-u.err:
+		return "", err
+	}
+}
+
+// command sends a command and returns the lines the unit sent before it finished. Log output from the unit may be
+// mixed in with the lines.
+func (u *unit) command(cmd string) ([]string, error) {
+	_, err := io.WriteString(u.w, cmd+"\n")
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	timeout := time.After(replyTimeout)
+	for {
+		select {
+		case l := <-u.lines:
+			switch {
+			case l == "ok":
+				return lines, nil
+			case strings.HasPrefix(l, "error: "):
+				return lines, errors.New(strings.TrimPrefix(l, "error: "))
+			}
+			lines = append(lines, l)
+		case err := <-u.err:
+			return lines, err
+		case <-timeout:
+			return lines, errors.New("timed out waiting for " + strings.Fields(cmd)[0])
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Command gotogenscenario runs scenario files (see package scenario) against Gotogen on the host computer, and reports
+// which passed. It exits with status 1 if any failed, so it can be used from a script.
+//
+// Usage:
+//
+//	gotogenscenario FILE.json...
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ajanata/gotogen/scenario"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: gotogenscenario FILE.json...")
+		os.Exit(2)
+	}
+
+	failed := 0
+	for _, file := range os.Args[1:] {
+		res, err := runFile(file)
+		if err != nil {
+			fmt.Println("ERROR", file+":", err)
+			failed++
+			continue
+		}
+		if res.Passed() {
+			fmt.Println("PASS ", file, res.Name)
+			continue
+		}
+		failed++
+		fmt.Println("FAIL ", file, res.Name)
+		for _, f := range res.Failures {
+			fmt.Println("     ", f)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d scenarios failed\n", failed, len(os.Args)-1)
+		os.Exit(1)
+	}
+}
+
+func runFile(file string) (scenario.Result, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return scenario.Result{}, err
+	}
+	defer f.Close()
+	s, err := scenario.Parse(f)
+	if err != nil {
+		return scenario.Result{}, err
+	}
+	return scenario.Run(s), nil
+}
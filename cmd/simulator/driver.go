@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"image"
+	"net"
+	"sync"
+
+	"github.com/ajanata/gotogen"
+	"github.com/ajanata/gotogen/internal/framebuf"
+)
+
+// maxLogLines is how many lines of replies the page is shown.
+const maxLogLines = 200
+
+// driver is a Driver whose buttons and sensors are set from the page, and which takes commands from the page and from
+// gotogenctl. Everything is set from HTTP handlers and read from the main loop, so it is all behind the mutex.
+type driver struct {
+	face *display
+
+	mu       sync.Mutex
+	buttons  []gotogen.MenuButton
+	boop     uint8
+	accel    [3]int32
+	mic      uint8
+	commands []string
+	log      []string
+	// ctl is the gotogenctl connection, if there is one, which gets the replies as well as the page
+	ctl net.Conn
+}
+
+func newDriver(face *display) *driver {
+	return &driver{
+		face: face,
+		// upright, so that the head isn't considered set down when it boots
+		accel: [3]int32{0, 1000, 0},
+	}
+}
+
+func (d *driver) EarlyInit() (gotogen.Display, error) { return d.face, nil }
+
+func (d *driver) LateInit(gotogen.BootReporter) {}
+
+// PressedButton returns the key presses from the page, one per frame.
+func (d *driver) PressedButton() gotogen.MenuButton {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.buttons) == 0 {
+		return gotogen.MenuButtonNone
+	}
+	b := d.buttons[0]
+	d.buttons = d.buttons[1:]
+	return b
+}
+
+func (d *driver) MenuItems() []gotogen.Item { return nil }
+
+func (d *driver) BoopDistance() (uint8, gotogen.SensorStatus) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.boop, gotogen.SensorStatusAvailable
+}
+
+func (d *driver) Accelerometer() (x, y, z int32, status gotogen.SensorStatus) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.accel[0], d.accel[1], d.accel[2], gotogen.SensorStatusAvailable
+}
+
+func (d *driver) Talking() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.mic > 0
+}
+
+func (d *driver) TalkingLevel() (uint8, gotogen.SensorStatus) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.mic, gotogen.SensorStatusAvailable
+}
+
+func (d *driver) StatusLine() string { return "simulator" }
+
+func (d *driver) PollCommand() (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.commands) == 0 {
+		return "", false
+	}
+	c := d.commands[0]
+	d.commands = d.commands[1:]
+	return c, true
+}
+
+func (d *driver) Reply(line string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.addLog(line)
+	if d.ctl != nil {
+		_, _ = d.ctl.Write([]byte(line + "\n"))
+	}
+}
+
+// addLog adds a line to what the page shows. The mutex must be held.
+func (d *driver) addLog(line string) {
+	d.log = append(d.log, line)
+	if len(d.log) > maxLogLines {
+		d.log = d.log[len(d.log)-maxLogLines:]
+	}
+}
+
+// command queues a command for the main loop.
+func (d *driver) command(line string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.addLog("> " + line)
+	d.commands = append(d.commands, line)
+}
+
+// acceptControl takes gotogenctl connections, one at a time, and queues the commands sent on them.
+func (d *driver) acceptControl(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		d.mu.Lock()
+		if d.ctl != nil {
+			_ = d.ctl.Close()
+		}
+		d.ctl = conn
+		d.mu.Unlock()
+
+		s := bufio.NewScanner(conn)
+		for s.Scan() {
+			d.command(s.Text())
+		}
+		d.mu.Lock()
+		if d.ctl == conn {
+			d.ctl = nil
+		}
+		d.mu.Unlock()
+		_ = conn.Close()
+	}
+}
+
+// display is an in-memory display. Each time it is displayed, a copy is taken for the page to show, since the main
+// loop keeps drawing into the buffer.
+type display struct {
+	*framebuf.Buffer
+
+	mu    sync.Mutex
+	shown *image.RGBA
+}
+
+func newDisplay(w, h int16) *display {
+	return &display{
+		Buffer: framebuf.New(w, h),
+		shown:  image.NewRGBA(image.Rect(0, 0, int(w), int(h))),
+	}
+}
+
+func (*display) CanUpdateNow() bool { return true }
+
+func (d *display) Display() error {
+	w, h := d.Size()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			c := d.At(x, y)
+			c.A = 0xFF
+			d.shown.SetRGBA(int(x), int(y), c)
+		}
+	}
+	return nil
+}
+
+// snapshot returns a copy of what was last displayed.
+func (d *display) snapshot() *image.RGBA {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	img := image.NewRGBA(d.shown.Rect)
+	copy(img.Pix, d.shown.Pix)
+	return img
+}
+
+type blinker struct{}
+
+func (blinker) Low() {}
+
+func (blinker) High() {}
@@ -0,0 +1,90 @@
+// Command simulator runs Gotogen on the host computer, with the face and the status display shown in a web browser,
+// so that animations and menus can be worked on without flashing a board. The keyboard works the menu buttons, and
+// sliders on the page stand in for the boop sensor, the accelerometer and the microphone.
+//
+// Usage:
+//
+//	simulator [-http localhost:8080] [-ctl localhost:7777] [-fps 30] [-face 64x32]
+//
+// Then open http://localhost:8080. The keys are:
+//
+//	Enter, Right  Menu
+//	Esc, Left     Back
+//	Up, Down      Up, Down
+//	D             Default
+//	N             Next face
+//
+// Commands (see Gotogen.Command) can be typed into the page, or sent with gotogenctl -addr to the -ctl address.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ajanata/gotogen"
+)
+
+func main() {
+	httpAddr := flag.String("http", "localhost:8080", "address to serve the simulator page on")
+	ctlAddr := flag.String("ctl", "localhost:7777", "TCP address to accept gotogenctl connections on, or empty for none")
+	fps := flag.Uint("fps", 30, "framerate to run at")
+	faceSize := flag.String("face", "64x32", "size of the face display, as WxH")
+	flag.Parse()
+
+	w, h, err := parseSize(*faceSize)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "simulator:", err)
+		os.Exit(2)
+	}
+
+	d := newDriver(newDisplay(w, h))
+	status := newDisplay(128, 64)
+	g, err := gotogen.New(*fps, status, blinker{}, d)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "simulator:", err)
+		os.Exit(1)
+	}
+
+	http.Handle("/", newServer(d, status))
+	go func() {
+		fmt.Fprintln(os.Stderr, "simulator: serving on http://"+*httpAddr)
+		fmt.Fprintln(os.Stderr, "simulator:", http.ListenAndServe(*httpAddr, nil))
+		os.Exit(1)
+	}()
+	if *ctlAddr != "" {
+		l, err := net.Listen("tcp", *ctlAddr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "simulator:", err)
+			os.Exit(1)
+		}
+		go d.acceptControl(l)
+	}
+
+	if err = g.Init(); err != nil {
+		fmt.Fprintln(os.Stderr, "simulator: init:", err)
+		g.Halt()
+	}
+	g.Run()
+}
+
+// parseSize parses a size like 64x32.
+func parseSize(s string) (w, h int16, err error) {
+	ws, hs, ok := strings.Cut(s, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("bad size %q, expected WxH", s)
+	}
+	wi, err := strconv.ParseInt(ws, 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad size %q: %w", s, err)
+	}
+	hi, err := strconv.ParseInt(hs, 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad size %q: %w", s, err)
+	}
+	return int16(wi), int16(hi), nil
+}
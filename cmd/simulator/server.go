@@ -0,0 +1,97 @@
+package main
+
+import (
+	_ "embed"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ajanata/gotogen"
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// buttonNames are the names of the buttons as the page sends them.
+var buttonNames = map[string]gotogen.MenuButton{
+	"menu":    gotogen.MenuButtonMenu,
+	"back":    gotogen.MenuButtonBack,
+	"up":      gotogen.MenuButtonUp,
+	"down":    gotogen.MenuButtonDown,
+	"default": gotogen.MenuButtonDefault,
+	"next":    gotogen.MenuButtonNextFace,
+}
+
+// newServer serves the page, the displays as PNGs for it to show, and the endpoints that it sends input to.
+func newServer(d *driver, status *display) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(indexHTML)
+	})
+	mux.HandleFunc("/face.png", func(w http.ResponseWriter, r *http.Request) {
+		servePNG(w, d.face)
+	})
+	mux.HandleFunc("/status.png", func(w http.ResponseWriter, r *http.Request) {
+		servePNG(w, status)
+	})
+	mux.HandleFunc("/button", func(w http.ResponseWriter, r *http.Request) {
+		b, ok := buttonNames[r.FormValue("name")]
+		if !ok {
+			http.Error(w, "unknown button", http.StatusBadRequest)
+			return
+		}
+		d.mu.Lock()
+		d.buttons = append(d.buttons, b)
+		d.mu.Unlock()
+	})
+	mux.HandleFunc("/sensors", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if v, ok := formInt(r, "boop", 0, 255); ok {
+			d.boop = uint8(v)
+		}
+		if v, ok := formInt(r, "mic", 0, 255); ok {
+			d.mic = uint8(v)
+		}
+		for i, axis := range [...]string{"x", "y", "z"} {
+			if v, ok := formInt(r, axis, -4000, 4000); ok {
+				d.accel[i] = int32(v)
+			}
+		}
+	})
+	mux.HandleFunc("/command", func(w http.ResponseWriter, r *http.Request) {
+		if line := strings.TrimSpace(r.FormValue("line")); line != "" {
+			d.command(line)
+		}
+	})
+	mux.HandleFunc("/log", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		text := strings.Join(d.log, "\n")
+		d.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(text))
+	})
+	return mux
+}
+
+// servePNG sends what the display last showed.
+func servePNG(w http.ResponseWriter, disp *display) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = png.Encode(w, disp.snapshot())
+}
+
+// formInt returns the form value as an integer, if it is there and in range.
+func formInt(r *http.Request, key string, lo, hi int) (int, bool) {
+	v, err := strconv.Atoi(r.FormValue(key))
+	if err != nil || v < lo || v > hi {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,86 @@
+package gotogen
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// BrightnessDisplay is an optional interface that a face Display may implement if it can control its brightness in
+// hardware. Otherwise, brightness is applied in software by scaling every pixel.
+type BrightnessDisplay interface {
+	SetBrightness(brightness uint8) error
+}
+
+// SetTint multiplies every pixel on the face by the color. White is no tint.
+func (g *Gotogen) SetTint(c color.RGBA) {
+	g.owner.check()
+	g.tint = c
+	g.updateColorScale()
+}
+
+// SetBrightness sets the brightness of the face, from 0 (off) to 255 (full).
+func (g *Gotogen) SetBrightness(b uint8) error {
+	g.owner.check()
+	g.brightness = b
+	if bd, ok := g.faceDisplay.(BrightnessDisplay); ok {
+		err := bd.SetBrightness(b)
+		if err != nil {
+			return errcode.Context("set brightness", errcode.Wrap(errcode.DriverCall, err))
+		}
+	}
+	g.updateColorScale()
+	return nil
+}
+
+// dimInterval is the least time between redraws of the whole face for breathing and pulsing to music, which would
+// otherwise send all of it to the display nearly every frame.
+const dimInterval = 100 * time.Millisecond
+
+// dimming is the breathing and music pulse that the face was last drawn with.
+type dimming struct {
+	breath, pulse uint8
+	at            time.Time
+}
+
+// updateDimming redraws the face for changes to breathing and the music pulse, but no more often than dimInterval.
+// Changes in between are picked up by the next redraw.
+func (g *Gotogen) updateDimming() {
+	d := &g.dimming
+	if d.breath == g.breath.level && d.pulse == g.music.pulse || time.Since(d.at) < dimInterval {
+		return
+	}
+	d.breath, d.pulse, d.at = g.breath.level, g.music.pulse, time.Now()
+	g.updateColorScale()
+}
+
+// updateColorScale works out how much to scale each color channel for the tint, software brightness, breathing, and
+// music pulse, and redraws the face with the new scale.
+func (g *Gotogen) updateColorScale() {
+	b := uint16(g.brightness)
+	if _, ok := g.faceDisplay.(BrightnessDisplay); ok {
+		// the hardware is doing it
+		b = 0xFF
+	}
+	// breathing and pulsing to music are always done in software, since they change too often to bother the hardware
+	b = b * (256 - uint16(g.dimming.breath)) / 256
+	b = b * (256 - uint16(g.dimming.pulse)) / 256
+	g.colorScale = [3]uint16{
+		uint16(g.tint.R) * b / 0xFF,
+		uint16(g.tint.G) * b / 0xFF,
+		uint16(g.tint.B) * b / 0xFF,
+	}
+	g.colorScaled = g.colorScale != [3]uint16{0xFF, 0xFF, 0xFF}
+	if g.frame != nil {
+		g.redrawFrame()
+	}
+}
+
+// scaleColor applies the tint and software brightness to the color.
+func (g *Gotogen) scaleColor(c color.RGBA) color.RGBA {
+	c.R = uint8(uint16(c.R) * g.colorScale[0] / 0xFF)
+	c.G = uint8(uint16(c.G) * g.colorScale[1] / 0xFF)
+	c.B = uint8(uint16(c.B) * g.colorScale[2] / 0xFF)
+	return c
+}
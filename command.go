@@ -0,0 +1,328 @@
+package gotogen
+
+import (
+	"errors"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCommandsPerTick limits how many commands are run each frame, so a flood of commands can't stall the face.
+const maxCommandsPerTick = 4
+
+// CommandSource is an optional interface that a Driver may implement to receive text commands from outside, e.g. from
+// a serial console, BLE, or an IR remote translated to commands. See Command for the available commands.
+type CommandSource interface {
+	// PollCommand returns the next received command, if there is one. It must not block.
+	PollCommand() (string, bool)
+}
+
+// pollCommands runs any commands waiting from the driver.
+func (g *Gotogen) pollCommands() {
+	cs, ok := g.driver.(CommandSource)
+	if !ok {
+		return
+	}
+	for i := 0; i < maxCommandsPerTick; i++ {
+		cmd, ok := cs.PollCommand()
+		if !ok {
+			return
+		}
+		err := g.Command(cmd)
+		if err != nil {
+			g.log(LogWarning, "command error: "+errorText(err))
+		}
+		if r, ok := cs.(CommandReplier); ok {
+			if err != nil {
+				r.Reply("error: " + errorText(err))
+			} else {
+				r.Reply("ok")
+			}
+		}
+	}
+}
+
+// Command runs a text command. The available commands are:
+//
+//	preset N               recall expression preset N (1-9)
+//	savepreset N           save the current expression as preset N
+//	face EYE NOSE MOUTH    change the images for the parts of the face ("-" leaves a part unchanged)
+//	expr NAME              change to the expression, e.g. "expr happy"; the vector face morphs, and the bitmap face blinks
+//	anim KIND FILE         start a full-screen animation, e.g. "anim slide wait", or "anim sequence wave" for wave_000...
+//	stop                   stop the full-screen animation and go back to the face
+//	effect NAME            trigger an effect, e.g. "effect glitch"
+//	tint RRGGBB            tint the face with the hex color
+//	brightness N           set the face brightness (0-255)
+//	caption TEXT...        show a caption on the status display (and optionally the face)
+//	clock                  show the time of day on the face for a few seconds
+//	putmedia TYPE FILE B64 upload part of a media file; "end" instead of data stores it (see MediaStorage)
+//	settings               reply with every stored setting
+//	capture                reply with the current face frame
+//	screenshot             save a screenshot (see ScreenshotStorage)
+//	macro N                play macro N (1-3)
+//	record N|stop          start recording macro N, or stop recording and save it
+//	sound NAME             play a sound (see AudioOutput)
+//	blink TEXT             blink the text in Morse code on the status LED
+//	rule add RULE...       add a reaction rule, e.g. "rule add boop 30s 3s anim slide wait"
+//	rule clear             remove every rule
+//	rules                  reply with every rule
+//	behavior load NAME     run a behavior script from media storage (media/behavior/NAME.txt; see LoadBehavior)
+//	behavior stop          stop the behavior script
+//	behavior fire EVENT    send an event to the behavior script
+//	behavior               reply with the behavior scripts in media storage, marking the running one
+//	alarm HH:MM LABEL...   set an alarm at the time of day
+//	timer DUR LABEL...     set a timer, e.g. "timer 20m hydrate"
+//	alarm clear            remove every alarm and timer
+//	alarms                 reply with every alarm and timer
+//	boops                  reply with the boop counters
+//	lock [MINUTES|off]     lock the expression against automatic changes, for 10 minutes if no time is given
+//	character [NAME|next]  switch to the character, or the next one; without either, reply with the characters
+//	qr [NAME]              show the QR code on the status display (and optionally the face); without one, reply with them
+//	qr add NAME TEXT...    save a QR code to show from the menu
+//	qr del NAME            delete a saved QR code
+//	stats                  reply with main loop timing statistics
+//	bench [stop]           benchmark the framerate and CPU headroom with different settings, replying as it goes
+//	prompt load NAME       load a teleprompter script from media storage (media/script/NAME.txt)
+//	prompt add LINE...     add a line to the teleprompter script, optionally starting with a time like "[1:23]"
+//	prompt clear           remove the teleprompter script
+//	prompt play|pause|stop show the teleprompter and start it, pause it, or rewind it and hide it
+//	mouth SHAPES           queue mouth shapes for lip-sync, one character per frame (see QueueMouthShapes)
+//	music MS LEVELS        queue a music envelope from a phone, one hex digit per MS milliseconds (see QueueMusicEnvelope)
+//	music stop             forget the queued music envelope
+//	debug pause|resume     pause the main loop (still taking commands) or carry on at full speed (debug builds only)
+//	debug step [N]         run N ticks (1 if not given) while paused, replying with the state after each
+//	debug state            reply with the tick, face, animation, status screen, menu and expression
+//
+// Commands that change the expression are refused while do not disturb is on, and recorded into the macro being
+// recorded, if any. Some of them (preset, face, expr, anim, effect, tint) are also synchronized with peers when this unit is
+// the peer sync leader; see PeerLink. StartAnimationByName and SetExpression are typed wrappers around anim and face.
+//
+// Command must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) Command(line string) error {
+	g.owner.check()
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return nil
+	}
+	cmd := strings.ToLower(args[0])
+	if g.dnd && recordedCommand(cmd) {
+		return errDND
+	}
+	var err error
+	switch cmd {
+	case "preset", "face", "expr", "anim", "effect", "tint":
+		if !g.syncCommand(line) {
+			err = g.runCommand(line)
+		}
+	default:
+		err = g.runCommand(line)
+	}
+	if err == nil && recordedCommand(cmd) {
+		g.record(line)
+	}
+	return err
+}
+
+// runCommand runs a text command immediately, without synchronizing it with peers.
+func (g *Gotogen) runCommand(line string) error {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return nil
+	}
+	cmd, args := strings.ToLower(args[0]), args[1:]
+
+	switch cmd {
+	case "preset", "savepreset":
+		if len(args) != 1 {
+			return errors.New(cmd + ": need preset number")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return errors.New(cmd + ": " + err.Error())
+		}
+		if cmd == "preset" {
+			return g.RecallPreset(n)
+		}
+		return g.SavePreset(n)
+	case "face":
+		if len(args) != 3 {
+			return errors.New("face: need eye, nose, and mouth")
+		}
+		for i := range args {
+			if args[i] == "-" {
+				args[i] = ""
+			}
+		}
+		return g.setFaceParts(args[0], args[1], args[2])
+	case "expr":
+		if len(args) != 1 {
+			return errors.New("expr: need expression name")
+		}
+		return g.setExpression(args[0])
+	case "anim":
+		if len(args) != 2 {
+			return errors.New("anim: need kind and file")
+		}
+		k, ok := findAnimationKind(args[0])
+		if !ok {
+			return errors.New("anim: unknown kind " + args[0])
+		}
+		return g.newAnimation(args[1], k)
+	case "stop":
+		if g.faceState != faceStateDefault {
+			g.returnToFace()
+		}
+		return nil
+	case "effect":
+		if len(args) != 1 {
+			return errors.New("effect: need effect name")
+		}
+		return g.TriggerEffect(args[0])
+	case "tint":
+		if len(args) != 1 {
+			return errors.New("tint: need color")
+		}
+		v, err := strconv.ParseUint(strings.TrimPrefix(args[0], "#"), 16, 32)
+		if err != nil {
+			return errors.New("tint: " + err.Error())
+		}
+		g.SetTint(color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF})
+		return nil
+	case "brightness":
+		if len(args) != 1 {
+			return errors.New("brightness: need value")
+		}
+		v, err := strconv.ParseUint(args[0], 10, 8)
+		if err != nil {
+			return errors.New("brightness: " + err.Error())
+		}
+		return g.SetBrightness(uint8(v))
+	case "caption":
+		if len(args) == 0 {
+			return errors.New("caption: need text")
+		}
+		// keep the original spacing and case of the text, just without the command
+		text := strings.TrimSpace(line)
+		g.Caption(text[len(cmd):])
+		return nil
+	case "clock":
+		return g.ShowClock()
+	case "lock":
+		return g.lockCommand(args)
+	case "character":
+		return g.characterCommand(args)
+	case "qr":
+		return g.qrCommand(args)
+	case "putmedia":
+		return g.putMedia(args)
+	case "settings":
+		g.exportSettings()
+		return nil
+	case "capture":
+		g.captureFrame()
+		return nil
+	case "screenshot":
+		return g.Screenshot()
+	case "sound":
+		if len(args) != 1 {
+			return errors.New("sound: need sound name")
+		}
+		ao, ok := g.driver.(AudioOutput)
+		if !ok {
+			return errors.New("sound: no audio output")
+		}
+		return ao.PlaySound(args[0])
+	case "blink":
+		if len(args) == 0 {
+			return errors.New("blink: need text")
+		}
+		g.BlinkMorse(strings.Join(args, " "))
+		return nil
+	case "prompt":
+		return g.promptCommand(args)
+	case "debug":
+		return g.debugCommand(args)
+	case "rule":
+		switch {
+		case len(args) == 1 && args[0] == "clear":
+			g.ClearRules()
+			return nil
+		case len(args) > 1 && args[0] == "add":
+			return g.AddRule(strings.Join(args[1:], " "))
+		default:
+			return errors.New("rule: need add or clear")
+		}
+	case "behavior":
+		return g.behaviorCommand(args)
+	case "boops":
+		g.replyBoopStats()
+		return nil
+	case "rules":
+		for _, r := range g.rules {
+			g.reply("rule " + r.text)
+		}
+		return nil
+	case "alarm":
+		if len(args) == 1 && args[0] == "clear" {
+			g.ClearAlarms()
+			return nil
+		}
+		if len(args) == 0 {
+			return errors.New("alarm: need time")
+		}
+		return g.SetAlarm(args[0], strings.Join(args[1:], " "))
+	case "timer":
+		if len(args) == 0 {
+			return errors.New("timer: need duration")
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			return errors.New("timer: bad duration " + args[0])
+		}
+		return g.SetTimer(d, strings.Join(args[1:], " "))
+	case "alarms":
+		for _, a := range g.alarms {
+			if a.minute < 0 {
+				g.reply("timer " + time.Until(a.deadline).Round(time.Second).String() + " " + a.label)
+			} else {
+				g.reply("alarm " + alarmTime(a.minute) + " " + a.label)
+			}
+		}
+		return nil
+	case "bench":
+		return g.benchCommand(args)
+	case "stats":
+		st := g.Stats()
+		g.reply("stats fps=" + strconv.Itoa(int(st.FPS)) + " frame=" + st.MinFrame.String() + "/" + st.AvgFrame.String() +
+			"/" + st.MaxFrame.String() + " jitter=" + st.AvgJitter.String() + "/" + st.MaxJitter.String() +
+			" heap=" + strconv.FormatUint(st.HeapFree, 10) + " errors=" + strconv.FormatUint(uint64(st.FaceErrors), 10) +
+			"/" + strconv.FormatUint(uint64(st.StatusErrors), 10) + " slow=" + strconv.FormatUint(uint64(st.SlowDriverCalls), 10))
+		return nil
+	case "mouth":
+		if len(args) != 1 {
+			return errors.New("mouth: need shapes")
+		}
+		return g.QueueMouthShapes(args[0])
+	case "music":
+		return g.musicCommand(args)
+	case "macro", "record":
+		if len(args) != 1 {
+			return errors.New(cmd + ": need macro number")
+		}
+		if cmd == "record" && args[0] == "stop" {
+			g.StopMacro()
+			return nil
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return errors.New(cmd + ": " + err.Error())
+		}
+		if cmd == "record" {
+			return g.RecordMacro(n)
+		}
+		return g.PlayMacro(n)
+	default:
+		return errors.New("unknown command " + cmd)
+	}
+}
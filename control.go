@@ -0,0 +1,183 @@
+package gotogen
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+	"github.com/ajanata/gotogen/internal/media"
+	"github.com/ajanata/gotogen/pixel"
+)
+
+// maxMediaUpload is the largest media file that can be pushed from a host, to keep it from using up all the memory.
+const maxMediaUpload = 16 * 1024
+
+// CommandReplier is an optional interface that a CommandSource may also implement if it can send text back to where
+// the commands came from, e.g. a serial console. When implemented, every command is answered with "ok" or
+// "error: ..." after any other output, so that host tools (like gotogenctl) know when the command is done. Errors that
+// have a code (see Error) start with it, e.g. "error: E12 MEDIA_SIZE: ...".
+type CommandReplier interface {
+	// Reply sends a line of text back. It must not block for long.
+	Reply(line string)
+}
+
+// PeerLink is an optional interface that a Driver may implement if it can talk to other Gotogens (e.g. over a radio),
+// so that several units can blink and change expressions together.
+type PeerLink interface {
+	// SendPeer sends a message to every connected peer. It must not block.
+	SendPeer(msg string)
+	// PollPeer returns the next message received from a peer, if there is one. It must not block.
+	PollPeer() (string, bool)
+}
+
+// MediaStorage is an optional interface that a Driver may implement if it has writable storage for media, e.g. an SD
+// card, so that media can be pushed from a host. The stored media is used in preference to the built-in media.
+type MediaStorage interface {
+	// MediaFS returns the filesystem holding the stored media, with the same layout as the built-in media (e.g.
+	// media/eye/default.bmp).
+	MediaFS() fs.FS
+	// WriteMedia stores a media file. The path is relative to the root of MediaFS.
+	WriteMedia(path string, data []byte) error
+}
+
+// MediaProvider is a read-only source of media, e.g. an SD card or a LittleFS partition, so that faces can be changed
+// without reflashing. Paths have the same layout as the built-in media, e.g. media/eye/default.bmp.
+type MediaProvider = media.Provider
+
+// MediaProviderDriver is an optional interface that a Driver may implement to provide media. It is asked for right
+// after EarlyInit, so the storage has to be ready by then; the boot animation can come from it too. Media stored with
+// MediaStorage is used first, then the provider's, then the built-in media.
+type MediaProviderDriver interface {
+	MediaProvider() MediaProvider
+}
+
+// MediaProviderFromFS makes a MediaProvider from a filesystem.
+func MediaProviderFromFS(fsys fs.FS) MediaProvider {
+	return media.FromFS(fsys)
+}
+
+// mediaUpload is a media file being pushed from a host, one chunk at a time.
+type mediaUpload struct {
+	path string
+	data []byte
+}
+
+// reply sends a line back to where commands come from, or to the Logger if the driver can't reply. Replies were asked
+// for, so they aren't held back by the log level, and they aren't kept for the Boot log page.
+func (g *Gotogen) reply(line string) {
+	if r, ok := g.driver.(CommandReplier); ok {
+		r.Reply(line)
+		return
+	}
+	g.logTo(LogInfo, line)
+}
+
+// initMediaProvider uses the driver's media provider, if it has one.
+func (g *Gotogen) initMediaProvider() {
+	if mpd, ok := g.driver.(MediaProviderDriver); ok {
+		if p := mpd.MediaProvider(); p != nil {
+			media.SetProvider(p)
+		}
+	}
+}
+
+// initMediaStorage uses the driver's stored media, if it has any.
+func (g *Gotogen) initMediaStorage() {
+	if ms, ok := g.driver.(MediaStorage); ok {
+		media.SetOverride(ms.MediaFS())
+	}
+}
+
+// putMedia handles a chunk of a media upload. A file is sent as any number of "putmedia TYPE FILE BASE64" commands
+// (where FILE includes the extension), followed by "putmedia TYPE FILE end" to store it.
+func (g *Gotogen) putMedia(args []string) error {
+	ms, ok := g.driver.(MediaStorage)
+	if !ok {
+		return errors.New("putmedia: no media storage")
+	}
+	if len(args) != 3 {
+		return errors.New("putmedia: need type, file, and data")
+	}
+	// files have to go straight in the type's directory, and can't be hidden or be .. to go up out of it
+	if args[0] == "" || strings.ContainsAny(args[0], "/.") || args[1] == "" || args[1][0] == '.' ||
+		strings.ContainsAny(args[1], "/\\") {
+		return errors.New("putmedia: bad file name")
+	}
+	path := "media/" + args[0] + "/" + args[1]
+	if g.upload.path != path {
+		g.upload = mediaUpload{path: path}
+	}
+
+	if args[2] == "end" {
+		data := g.upload.data
+		g.upload = mediaUpload{}
+		err := ms.WriteMedia(path, data)
+		if err != nil {
+			return errcode.Context("putmedia", errcode.Wrap(errcode.DriverCall, err))
+		}
+		// reload with the new file
+		media.SetOverride(ms.MediaFS())
+		return nil
+	}
+
+	chunk, err := base64.StdEncoding.DecodeString(args[2])
+	if err != nil {
+		g.upload = mediaUpload{}
+		return errors.New("putmedia: " + err.Error())
+	}
+	if len(g.upload.data)+len(chunk) > maxMediaUpload {
+		g.upload = mediaUpload{}
+		return errors.New("putmedia: file too big")
+	}
+	g.upload.data = append(g.upload.data, chunk...)
+	return nil
+}
+
+// settingKeys returns the keys of every setting that may be stored.
+func settingKeys() []string {
+	keys := []string{buttonMapSetting, bindingsSetting, screenshotSetting, rulesSetting, alarmsSetting, fitSetting,
+		faceStyleSetting, sensorLogSetting, boopStatsSetting, boopCalSetting,
+		reactionsSetting, largePrintSetting, characterSetting, boopReactionSetting,
+		qrCodesSetting, heartbeatSetting, behaviorSetting}
+	for i := 1; i <= presetCount; i++ {
+		keys = append(keys, presetSetting(i))
+	}
+	for i := 0; i < artSlots; i++ {
+		keys = append(keys, artSetting(i))
+	}
+	for i := 0; i < macroSlots; i++ {
+		keys = append(keys, macroSetting(i))
+	}
+	return keys
+}
+
+// exportSettings replies with every stored setting, as "setting KEY HEX" lines.
+func (g *Gotogen) exportSettings() {
+	// some keys depend on the face display
+	for _, k := range append(settingKeys(), g.profileSetting(curveSetting), g.profileSetting(paletteSetting)) {
+		if b, ok := g.loadSetting(k); ok {
+			g.reply("setting " + k + " " + hex.EncodeToString(b))
+		}
+	}
+}
+
+// captureFrame replies with the current face frame as it is being shown, as a "frame W H" line followed by a
+// "row Y PIXELS" line for each row, with each pixel as 4 hex digits of RGB565.
+func (g *Gotogen) captureFrame() {
+	w, h := g.frame.Size()
+	g.reply("frame " + strconv.Itoa(int(w)) + " " + strconv.Itoa(int(h)))
+	const digits = "0123456789abcdef"
+	row := make([]byte, 0, int(w)*4)
+	for y := int16(0); y < h; y++ {
+		row = row[:0]
+		for x := int16(0); x < w; x++ {
+			p := pixel.ToRGB565(g.composedPixel(x, y))
+			row = append(row, digits[p>>12], digits[p>>8&0xF], digits[p>>4&0xF], digits[p&0xF])
+		}
+		g.reply("row " + strconv.Itoa(int(y)) + " " + string(row))
+	}
+}
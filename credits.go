@@ -0,0 +1,51 @@
+package gotogen
+
+import (
+	"strings"
+
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+// mediaRef is a media file, for looking up who made it.
+type mediaRef struct {
+	typ  media.Type
+	name string
+}
+
+// showCredits briefly shows who made the media on the status display, if the media manifest says and credits are
+// turned on. Each artist is only listed once.
+func (g *Gotogen) showCredits(refs ...mediaRef) {
+	if !g.artCredits {
+		return
+	}
+	var who []string
+	for _, r := range refs {
+		if r.name == "" {
+			continue
+		}
+		c := media.Credit(r.typ, r.name)
+		if c == "" {
+			continue
+		}
+		dup := false
+		for _, w := range who {
+			dup = dup || w == c
+		}
+		if !dup {
+			who = append(who, c)
+		}
+	}
+	if len(who) > 0 {
+		g.queueCaption(caption{text: "art by " + strings.Join(who, ", ")})
+	}
+}
+
+// setFaceParts changes the images used for each part of the face, and credits whoever made them. An empty name leaves
+// that part unchanged.
+func (g *Gotogen) setFaceParts(eye, nose, mouth string) error {
+	if err := f.SetParts(eye, nose, mouth); err != nil {
+		return err
+	}
+	g.showCredits(mediaRef{media.TypeEye, eye}, mediaRef{media.TypeNose, nose}, mediaRef{media.TypeMouth, mouth})
+	return nil
+}
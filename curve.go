@@ -0,0 +1,249 @@
+package gotogen
+
+import (
+	"errors"
+	"image/color"
+	"strconv"
+
+	"tinygo.org/x/drivers"
+)
+
+const (
+	curveSetting = "curve"
+	// curvePoints is how many control points the brightness curve has.
+	curvePoints = 5
+	// curveStep is how much Up and Down change a control point by.
+	curveStep = 4
+)
+
+// curveInputs are the input levels of the control points. They are bunched up towards the bottom, since that is where
+// cheap panels are the furthest from linear.
+var curveInputs = [curvePoints]uint8{16, 48, 96, 160, 255}
+
+// DisplayProfile is an optional interface that a face Display may implement to identify which kind of panel it is, so
+// that calibration like the brightness curve is kept separately for each kind of panel.
+type DisplayProfile interface {
+	// ProfileName is a short ASCII name for the panel, e.g. "p3-64x32".
+	ProfileName() string
+}
+
+// brightnessCurve corrects the response of the face panels, which (especially HUB75 panels at low duty cycles) is
+// often far from linear. It maps every channel through a lookup table built from a few control points.
+type brightnessCurve struct {
+	points [curvePoints]uint8
+	lut    [256]uint8
+	// active is false for a linear curve, so that it can be skipped entirely
+	active bool
+}
+
+// reset makes the curve linear.
+func (c *brightnessCurve) reset() {
+	c.points = curveInputs
+	c.build()
+}
+
+// build works out the lookup table from the control points, interpolating linearly between them starting from 0.
+func (c *brightnessCurve) build() {
+	var x0, y0 int
+	for i, p := range c.points {
+		x1, y1 := int(curveInputs[i]), int(p)
+		for x := x0; x <= x1; x++ {
+			c.lut[x] = uint8(y0 + (y1-y0)*(x-x0)/(x1-x0))
+		}
+		x0, y0 = x1, y1
+	}
+	c.active = c.points != curveInputs
+}
+
+// apply maps the color through the curve.
+func (c *brightnessCurve) apply(col color.RGBA) color.RGBA {
+	col.R = c.lut[col.R]
+	col.G = c.lut[col.G]
+	col.B = c.lut[col.B]
+	return col
+}
+
+// profileSetting is the key for a setting that is kept separately for each face display profile.
+func (g *Gotogen) profileSetting(key string) string {
+	if dp, ok := g.faceDisplay.(DisplayProfile); ok {
+		return key + "-" + dp.ProfileName()
+	}
+	return key
+}
+
+// loadCurve loads the brightness curve for the face display, or uses a linear curve if there isn't one.
+func (g *Gotogen) loadCurve() {
+	g.curve.reset()
+	b, ok := g.loadSetting(g.profileSetting(curveSetting))
+	if !ok {
+		return
+	}
+	if len(b) != curvePoints {
+		g.reportError(errors.New("corrupt brightness curve"))
+		return
+	}
+	copy(g.curve.points[:], b)
+	g.curve.build()
+}
+
+func (g *Gotogen) saveCurve() {
+	g.saveSetting(g.profileSetting(curveSetting), g.curve.points[:])
+}
+
+// curveEditor is the state of the brightness curve calibration.
+type curveEditor struct {
+	selected int
+	// the curve before editing started, to go back to if the calibration is cancelled
+	saved brightnessCurve
+}
+
+// calibrateCurve starts calibrating the brightness curve. The face shows a bar at the input level of each control
+// point, which are adjusted until the bars look evenly spaced.
+func (g *Gotogen) calibrateCurve() {
+	g.curveEdit = curveEditor{saved: g.curve}
+	g.startAnimation(&curvePattern{editor: &g.curveEdit, drawn: -1})
+	g.changeStatusState(statusStateCurve)
+	g.renderCurveStatus()
+}
+
+func (g *Gotogen) renderCurveStatus() {
+	g.statusText.Clear()
+	_ = g.statusText.SetLineInverse(0, "BRIGHTNESS CURVE")
+	e := &g.curveEdit
+	_ = g.statusText.SetLine(1, "point ", strconv.Itoa(e.selected+1), "/", strconv.Itoa(curvePoints),
+		" in ", strconv.Itoa(int(curveInputs[e.selected])))
+	if g.compactStatus {
+		// the output goes on the same line as the point, to leave room for the buttons
+		_ = g.statusText.SetLine(1, "pt ", strconv.Itoa(e.selected+1), " in ", strconv.Itoa(int(curveInputs[e.selected])),
+			" out ", strconv.Itoa(int(g.curve.points[e.selected])))
+		_ = g.statusText.SetLine(2, "Up/Dn adj Menu next")
+		_ = g.statusText.SetLine(3, "Dflt save Back cancel")
+		g.statusDirty = true
+		return
+	}
+	_ = g.statusText.SetLine(2, "out ", strconv.Itoa(int(g.curve.points[e.selected])))
+	_ = g.statusText.SetLine(4, "Up/Dn: adjust")
+	_ = g.statusText.SetLine(5, "Menu: next point")
+	_ = g.statusText.SetLine(6, "Dflt: save")
+	_ = g.statusText.SetLine(7, "Back: cancel")
+	g.statusDirty = true
+}
+
+// updateCurve is called every frame while the brightness curve is being calibrated.
+func (g *Gotogen) updateCurve() {
+	e := &g.curveEdit
+	p := &g.curve.points[e.selected]
+	switch g.repeatedButton(g.pressedButton()) {
+	case MenuButtonNone:
+		return
+	case MenuButtonUp:
+		if *p <= 0xFF-curveStep {
+			*p += curveStep
+		} else {
+			*p = 0xFF
+		}
+	case MenuButtonDown:
+		if *p >= curveStep {
+			*p -= curveStep
+		} else {
+			*p = 0
+		}
+	case MenuButtonMenu:
+		e.selected = (e.selected + 1) % curvePoints
+	case MenuButtonDefault:
+		g.saveCurve()
+		g.finishCurve()
+		return
+	case MenuButtonBack:
+		g.curve = e.saved
+		g.finishCurve()
+		return
+	}
+	g.curve.build()
+	g.redrawFrame()
+	g.renderCurveStatus()
+}
+
+// finishCurve leaves the calibration, putting the face back.
+func (g *Gotogen) finishCurve() {
+	g.returnToFace()
+	g.changeStatusState(statusStateMenu)
+}
+
+// resetCurve goes back to a linear brightness curve.
+func (g *Gotogen) resetCurve() {
+	g.curve.reset()
+	g.saveCurve()
+	g.redrawFrame()
+}
+
+// panelMenu has the settings that are kept separately for each face display profile.
+func (g *Gotogen) panelMenu() *Menu {
+	m := &Menu{
+		Name: "Face panel",
+		Items: []Item{
+			&ActionItem{
+				Name:   "Calibrate curve",
+				Help:   "Corrects panels that get too bright too quickly, or stay dark too long, at low brightness.",
+				Invoke: g.calibrateCurve,
+			},
+			&ActionItem{
+				Name:   "Reset curve",
+				Invoke: g.resetCurve,
+			},
+			&SettingItem{
+				Name:    "Palette",
+				Help:    "Remaps the colors of everything on the face, for panels with odd colors or for easier viewing.",
+				Options: paletteNames(),
+				Active:  g.palette,
+				Apply:   g.setPalette,
+			},
+			g.fitMenu(),
+			g.testPatternMenu(),
+		},
+	}
+	m.Items = append(m.Items, g.seamItems()...)
+	m.Items = append(m.Items, g.panelColorsItems()...)
+	return m
+}
+
+// curvePattern draws a bar for each control point of the brightness curve, with the selected one marked underneath.
+type curvePattern struct {
+	editor *curveEditor
+	// the selected point when the bars were last drawn, or -1 to draw them next frame
+	drawn int
+}
+
+func (p *curvePattern) Activate(disp drivers.Displayer) {
+	w, h := disp.Size()
+	for x := int16(0); x < w; x++ {
+		for y := int16(0); y < h; y++ {
+			disp.SetPixel(x, y, color.RGBA{})
+		}
+	}
+	p.drawn = -1
+}
+
+func (p *curvePattern) DrawFrame(disp drivers.Displayer, _ uint32) bool {
+	if p.drawn == p.editor.selected {
+		return true
+	}
+	p.drawn = p.editor.selected
+	w, h := disp.Size()
+	bar := w / curvePoints
+	for i, in := range curveInputs {
+		c := color.RGBA{R: in, G: in, B: in, A: 0xFF}
+		for x := int16(i) * bar; x < int16(i+1)*bar-1; x++ {
+			for y := int16(0); y < h-3; y++ {
+				disp.SetPixel(x, y, c)
+			}
+			mark := color.RGBA{}
+			if i == p.drawn {
+				mark = color.RGBA{R: 0xFF, A: 0xFF}
+			}
+			disp.SetPixel(x, h-2, mark)
+			disp.SetPixel(x, h-1, mark)
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+package gotogen
+
+import (
+	"strconv"
+	"time"
+)
+
+const sensorLogSetting = "sensorlog"
+
+// sensorLogIntervals are how often a row is logged for each Sensor log setting. The first is off.
+var sensorLogIntervals = [...]time.Duration{0, 10 * time.Second, time.Minute, 5 * time.Minute}
+
+// sensorLogHeader is the first row of every sensor log.
+const sensorLogHeader = "uptime,time,battery,temp,fps,boops,motion\n"
+
+// LogStorage is an optional interface that a Driver may implement if it can store logs, e.g. on an SD card. It is used
+// for the sensor log, which records battery, temperature, framerate, and activity at a low rate so that wearers can
+// look at battery life and how busy the suit was after an event.
+type LogStorage interface {
+	// AppendLog adds the data to the end of the named log, creating it if it doesn't exist.
+	AppendLog(name string, data []byte) error
+}
+
+// TemperatureSensor is an optional interface that a Driver may implement if it can measure the temperature inside the
+// head, e.g. from the microcontroller's internal sensor.
+type TemperatureSensor interface {
+	// Temperature returns the temperature in thousandths of a degree Celsius.
+	Temperature() (milliCelsius int32, status SensorStatus)
+}
+
+// activityCounter counts boops and movement. Each boop or shake is counted once, when it starts.
+type activityCounter struct {
+	booped, moving bool
+	boops, motion  uint32
+}
+
+// sensorLog is the state of the sensor log.
+type sensorLog struct {
+	interval time.Duration
+	// name is the log being written this boot, chosen when the first row is written
+	name string
+	next time.Time
+	// the activity counts when the last row was written, so each row has the counts since the one before
+	boops, motion uint32
+}
+
+// countActivity counts boops and movement from this frame's sensor readings.
+func (g *Gotogen) countActivity() {
+	a := &g.activity
+	booped := g.booped()
+	if booped && !a.booped {
+		a.boops++
+		g.countBoop()
+	}
+	if g.shaking && !a.moving {
+		a.motion++
+	}
+	a.booped, a.moving = booped, g.shaking
+}
+
+// updateSensorLog writes a row to the sensor log when one is due.
+func (g *Gotogen) updateSensorLog() {
+	l := &g.sensorLog
+	if l.interval == 0 {
+		return
+	}
+	now := time.Now()
+	if now.Before(l.next) {
+		return
+	}
+	l.next = now.Add(l.interval)
+	ls, ok := g.driver.(LogStorage)
+	if !ok {
+		return
+	}
+
+	var row []byte
+	if l.name == "" {
+		// one log per boot when the time of day is known to tell them apart, otherwise they all go in the same one
+		l.name = "sensors.csv"
+		if t, ok := g.wallClock(); ok {
+			l.name = "sensors-" + t.Format("20060102-150405") + ".csv"
+		}
+		row = append(row, sensorLogHeader...)
+	}
+
+	row = strconv.AppendInt(row, int64(now.Sub(g.start)/time.Second), 10)
+	row = append(row, ',')
+	if t, ok := g.wallClock(); ok {
+		row = append(row, t.Format("15:04:05")...)
+	}
+	row = append(row, ',')
+	if g.hasBattery {
+		row = strconv.AppendUint(row, uint64(g.battery), 10)
+	}
+	row = append(row, ',')
+	if ts, ok := g.driver.(TemperatureSensor); ok {
+		if mc, st := ts.Temperature(); st == SensorStatusAvailable {
+			// tenths of a degree are plenty
+			row = strconv.AppendFloat(row, float64(mc/100)/10, 'f', 1, 32)
+		}
+	}
+	row = append(row, ',')
+	row = strconv.AppendUint(row, uint64(g.lastFPS), 10)
+	row = append(row, ',')
+	row = strconv.AppendUint(row, uint64(g.activity.boops-l.boops), 10)
+	row = append(row, ',')
+	row = strconv.AppendUint(row, uint64(g.activity.motion-l.motion), 10)
+	row = append(row, '\n')
+	l.boops, l.motion = g.activity.boops, g.activity.motion
+
+	if err := ls.AppendLog(l.name, row); err != nil {
+		g.log(LogWarning, "sensor log: "+err.Error())
+	}
+}
+
+func (g *Gotogen) loadSensorLog() {
+	b, ok := g.loadSetting(sensorLogSetting)
+	if ok && len(b) == 1 && int(b[0]) < len(sensorLogIntervals) {
+		g.setSensorLog(b[0])
+	}
+}
+
+func (g *Gotogen) sensorLogActive() uint8 {
+	for i, d := range sensorLogIntervals {
+		if d == g.sensorLog.interval {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+func (g *Gotogen) setSensorLog(selected uint8) {
+	if int(selected) >= len(sensorLogIntervals) {
+		return
+	}
+	g.sensorLog.interval = sensorLogIntervals[selected]
+	g.sensorLog.next = time.Now()
+	g.saveSetting(sensorLogSetting, []byte{selected})
+}
@@ -0,0 +1,49 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+)
+
+// errDND is returned for commands that would change the expression while do not disturb is on.
+var errDND = errors.New("do not disturb is on")
+
+// SetDoNotDisturb turns do not disturb mode on or off. While it is on, the face is locked to the current expression:
+// boop and other rules, automatic effects, face overlays, and commands that change the expression are all ignored.
+//
+// SetDoNotDisturb must be called from the same goroutine as RunTick; other goroutines can use Do.
+func (g *Gotogen) SetDoNotDisturb(on bool) {
+	g.owner.check()
+	if on == g.dnd {
+		return
+	}
+	g.dnd = on
+	g.log(LogInfo, "do not disturb: "+strconv.FormatBool(on))
+	if g.dndItem != nil {
+		// it can also be changed with a quick action, so keep the menu in sync
+		g.dndItem.Active = 0
+		if on {
+			g.dndItem.Active = 1
+		}
+	}
+	if on {
+		g.setWarning("do not disturb")
+	} else if g.warning == "do not disturb" {
+		g.setWarning("")
+	}
+}
+
+// DoNotDisturb reports whether do not disturb mode is on.
+func (g *Gotogen) DoNotDisturb() bool {
+	return g.dnd
+}
+
+func (g *Gotogen) dndSetting() *SettingItem {
+	g.dndItem = &SettingItem{
+		Name:    "Do not disturb",
+		Help:    "Locks the face to the current expression. Boops, rules, random effects and face captions are ignored until it is turned off.",
+		Options: []string{"off", "on"},
+		Apply:   func(selected uint8) { g.SetDoNotDisturb(selected == 1) },
+	}
+	return g.dndItem
+}
@@ -0,0 +1,56 @@
+package gotogen
+
+import (
+	"image/color"
+)
+
+// bayer4 is the 4x4 ordered dither matrix.
+var bayer4 = [4][4]uint8{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// downmix turns a face pixel into what is shown for it on the preview of the face on the status screen, which is
+// usually a 1-bit display.
+func (g *Gotogen) downmix(x, y int16, c color.RGBA) color.RGBA {
+	switch g.statusDownmixChannel {
+	case colorChannelRed:
+		if c.R < g.statusDownmixCutoff {
+			c.R = 0
+		} else {
+			c.R = 0xFF
+		}
+		c.G = 0
+		c.B = 0
+	case colorChannelGreen:
+		if c.G < g.statusDownmixCutoff {
+			c.G = 0
+		} else {
+			c.G = 0xFF
+		}
+		c.R = 0
+		c.B = 0
+	case colorChannelBlue:
+		if c.B < g.statusDownmixCutoff {
+			c.B = 0
+		} else {
+			c.B = 0xFF
+		}
+		c.R = 0
+		c.G = 0
+	case colorChannelLuma:
+		if luma(c) < uint16(g.statusDownmixCutoff) {
+			return color.RGBA{A: 0xFF}
+		}
+		return color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	case colorChannelDither:
+		// the cutoff isn't used; every level of brightness gets its own pattern
+		if luma(c) <= uint16(bayer4[y&3][x&3])*16+8 {
+			return color.RGBA{A: 0xFF}
+		}
+		return color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	}
+	return c
+}
@@ -0,0 +1,184 @@
+package gotogen
+
+import (
+	"errors"
+
+	"github.com/ajanata/gotogen/internal/effect"
+)
+
+// shakeThreshold is how much the accelerometer has to change between frames to count as a shake.
+// TODO this depends on the normalization of the accelerometer values, which isn't defined yet
+const shakeThreshold = 1000
+
+// TriggerEffect momentarily applies the named effect (e.g. "glitch") over whatever is on the face.
+func (g *Gotogen) TriggerEffect(name string) error {
+	g.owner.check()
+	e := effect.Find(name)
+	if e == nil {
+		return errors.New("unknown effect " + name)
+	}
+	g.startEffect(e)
+	return nil
+}
+
+func (g *Gotogen) startEffect(e effect.Effect) {
+	e.Start(g.tick)
+	g.effect = e
+}
+
+// randomEffect starts a random effect.
+func (g *Gotogen) randomEffect() {
+	g.effectRNG = g.effectRNG*1664525 + 1013904223
+	n := effect.All[(g.effectRNG>>16)%uint32(len(effect.All))]
+	g.startEffect(n.New())
+}
+
+// checkEffectTriggers starts effects from gestures or at random, if enabled.
+func (g *Gotogen) checkEffectTriggers(dx, dy, dz int32) {
+	if g.effect != nil || g.photoMode || g.dnd || g.exprLocked() || !g.effectsAllowed() {
+		return
+	}
+	if g.shakeEffects && g.shaking {
+		g.react(func() { g.startEffect(effect.Find("glitch")) })
+		return
+	}
+	if g.randomEffectChance > 0 {
+		g.effectRNG = g.effectRNG*1664525 + 1013904223
+		if (g.effectRNG>>8)%g.randomEffectChance == 0 {
+			g.randomEffect()
+		}
+	}
+}
+
+// applyEffect redraws the face through the active effect, if there is one. When the effect finishes, the undisturbed
+// frame is redrawn.
+func (g *Gotogen) applyEffect() {
+	if g.effect == nil {
+		return
+	}
+	if !g.effect.Frame(g.tick) {
+		g.effect = nil
+		g.redrawFrame()
+		return
+	}
+	if g.transitioning() {
+		// the transition draws the effect's pixels into its blend
+		return
+	}
+	w, h := g.frame.Size()
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			g.outputPixel(x, y, g.effect.Pixel(g.frame, x, y))
+		}
+	}
+}
+
+// redrawFrame sends the entire framebuffer to the face again.
+func (g *Gotogen) redrawFrame() {
+	w, h := g.frame.Size()
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			g.outputPixel(x, y, g.frame.At(x, y))
+		}
+	}
+	g.fullFlush = true
+}
+
+func (g *Gotogen) setRandomEffects(selected uint8) {
+	switch selected {
+	case 0:
+		g.randomEffectChance = 0
+	case 1:
+		// about once every 5 minutes at 60fps
+		g.randomEffectChance = 18000
+	case 2:
+		// about once a minute at 60fps
+		g.randomEffectChance = 3600
+	}
+}
+
+func (g *Gotogen) setShakeEffects(selected uint8) {
+	g.shakeEffects = selected == 1
+}
+
+func (g *Gotogen) effectsMenu() *Menu {
+	m := &Menu{Name: "Effects"}
+	for _, n := range effect.All {
+		e := n
+		m.Items = append(m.Items, &ActionItem{
+			Name:   e.Name,
+			Invoke: func() { g.reportError(g.Command("effect " + e.Name)) },
+		})
+	}
+	m.Items = append(m.Items,
+		&SettingItem{
+			Name:    "Random effects",
+			Help:    "Every so often, briefly play a random effect over the face.",
+			Options: []string{"off", "rare", "often"},
+			Active:  0,
+			Apply:   g.setRandomEffects,
+		},
+		&SettingItem{
+			Name:    "Glitch on shake",
+			Options: []string{"off", "on"},
+			Active:  0,
+			Apply:   g.setShakeEffects,
+		},
+		&SettingItem{
+			Name:    "Micro-expr.",
+			Help:    "Small random movements of the idle face: the eyes shifting and an occasional blush.",
+			Options: []string{"off", "subtle", "lively"},
+			Active:  0,
+			Apply:   g.setMicroIntensity,
+		},
+		&SettingItem{
+			Name:    "Tilt gaze",
+			Help:    "The idle face's eyes look the way the head is tilted. Mirrored is for accelerometers mounted the other way around.",
+			Options: []string{"off", "on", "mirrored"},
+			Active:  g.gaze.mode,
+			Apply:   g.setGazeMode,
+		},
+		&SettingItem{
+			Name:    "Blinking",
+			Help:    "How often the idle face blinks, on average.",
+			Options: []string{"off", "2s", "4s", "8s"},
+			Active:  2,
+			Apply:   g.setEyeBlinkInterval,
+		},
+		&SettingItem{
+			Name:    "Blink random",
+			Help:    "How much the time between blinks varies, so that they don't come like clockwork.",
+			Options: []string{"none", "some", "lots"},
+			Active:  1,
+			Apply:   g.setEyeBlinkSpread,
+		},
+		&SettingItem{
+			Name:    "Breathing",
+			Help:    "Slowly pulse the brightness of the idle face. Pauses while talking or animating.",
+			Options: []string{"off", "subtle", "medium", "deep"},
+			Active:  0,
+			Apply:   g.setBreathAmplitude,
+		},
+		&SettingItem{
+			Name:    "Breath speed",
+			Options: []string{"fast", "normal", "slow"},
+			Active:  1,
+			Apply:   g.setBreathPeriod,
+		},
+		&SettingItem{
+			Name:    "Music sync",
+			Help:    "What music playing on a connected phone does: moves the mouth to the music, or pulses the brightness.",
+			Options: []string{"off", "mouth", "pulse"},
+			Active:  musicSyncMouth,
+			Apply:   g.setMusicSync,
+		},
+	)
+	return m
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
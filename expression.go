@@ -0,0 +1,119 @@
+package gotogen
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ajanata/gotogen/internal/animation/face"
+	"github.com/ajanata/gotogen/internal/animation/vector"
+)
+
+// ExpressionSource is an optional interface that a Driver may implement to change the expression itself, e.g. from a
+// mood button or a sensor. It is polled every frame, and the expression is changed whenever the name it returns
+// changes. Like rules, it is held off while the expression is locked.
+type ExpressionSource interface {
+	// Expression returns the name of the expression to show, as in the expr command, or empty for no preference.
+	Expression() string
+}
+
+// StartAnimationByName starts a full-screen animation of the kind (as in the full-screen animations menu, e.g.
+// "slide") using the full-screen image file. It goes through Command, so it is refused while do not disturb is on,
+// recorded into macros, and synchronized with peers, just like the menu and remotes.
+//
+// StartAnimationByName must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) StartAnimationByName(kind, file string) error {
+	if _, ok := findAnimationKind(kind); !ok {
+		return errors.New("unknown animation kind " + kind)
+	}
+	if err := checkName("file", file); err != nil {
+		return err
+	}
+	return g.Command("anim " + kind + " " + file)
+}
+
+// SetExpression changes the images used for each part of the face. An empty name leaves that part unchanged. Like
+// StartAnimationByName, it goes through Command.
+//
+// SetExpression must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) SetExpression(eye, nose, mouth string) error {
+	parts := [...]string{eye, nose, mouth}
+	for i, p := range parts {
+		if p == "" {
+			parts[i] = "-"
+			continue
+		}
+		if err := checkName("part", p); err != nil {
+			return err
+		}
+	}
+	return g.Command("face " + parts[0] + " " + parts[1] + " " + parts[2])
+}
+
+// ShowExpression changes to the named expression, e.g. "happy", on both the bitmap face and the vector face. Like
+// StartAnimationByName, it goes through Command.
+//
+// ShowExpression must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) ShowExpression(name string) error {
+	if err := checkName("expression", name); err != nil {
+		return err
+	}
+	return g.Command("expr " + name)
+}
+
+// setExpression changes both faces to the named expression, so that it shows whichever face style is in use.
+func (g *Gotogen) setExpression(name string) error {
+	_, bitmap := face.FindExpression(name)
+	_, vec := vector.Find(name)
+	if !bitmap && !vec {
+		return errors.New("unknown expression " + name)
+	}
+	if bitmap {
+		if err := f.SetExpression(name); err != nil {
+			return err
+		}
+	}
+	if vec {
+		return g.vectorFace.SetExpression(name)
+	}
+	return nil
+}
+
+// updateExpressionSource changes the expression when the driver asks for a different one.
+func (g *Gotogen) updateExpressionSource() {
+	es, ok := g.driver.(ExpressionSource)
+	if !ok {
+		return
+	}
+	name := es.Expression()
+	// while locked, the change waits until the lock is over
+	if name == "" || name == g.sourceExpression || g.exprLocked() {
+		return
+	}
+	g.sourceExpression = name
+	if err := g.ShowExpression(name); err != nil {
+		g.reportError(errors.New("expression source: " + err.Error()))
+	}
+}
+
+func (g *Gotogen) expressionsMenu() *Menu {
+	m := &Menu{
+		Name: "Expressions",
+		Help: "Changes the expression of the bitmap face and the vector face, whichever is showing.",
+	}
+	for _, e := range face.Expressions {
+		name := e.Name
+		m.Items = append(m.Items, &ActionItem{
+			Name:   name,
+			Invoke: func() { g.reportError(g.Command("expr " + name)) },
+		})
+	}
+	return m
+}
+
+// checkName makes sure a media name can be passed through a command line.
+func checkName(what, name string) error {
+	if name == "" || name == "-" || strings.ContainsAny(name, " \t\r\n") {
+		return errors.New("invalid " + what + " name \"" + name + "\"")
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/fmtlite"
+)
+
+// defaultExprLockTime is how long the expression is locked for by the quick action, and the lock command without a
+// time.
+const defaultExprLockTime = 10 * time.Minute
+
+// exprLockTimes are the options for the Lock expression setting, after off.
+var exprLockTimes = [...]time.Duration{5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute}
+
+// errExprLocked is returned for automatic changes to the expression while it is locked.
+var errExprLocked = errors.New("expression is locked")
+
+// LockExpression holds the current expression for a while, e.g. for a posed photo session: rules, automatic effects,
+// and micro-expressions leave the face alone, but the expression can still be changed by hand from the menu, buttons,
+// or commands. The time left is shown on the status screen. A duration of 0 unlocks it.
+//
+// LockExpression must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) LockExpression(d time.Duration) {
+	g.owner.check()
+	if d <= 0 {
+		g.unlockExpression()
+		return
+	}
+	g.exprLockUntil = time.Now().Add(d)
+	g.log(LogInfo, "expression locked for "+d.String())
+	if g.exprLockItem != nil {
+		g.exprLockItem.Active = 0
+		for i, t := range exprLockTimes {
+			if t == d {
+				g.exprLockItem.Active = uint8(i + 1)
+			}
+		}
+	}
+}
+
+func (g *Gotogen) unlockExpression() {
+	if g.exprLockUntil.IsZero() {
+		return
+	}
+	g.exprLockUntil = time.Time{}
+	g.log(LogInfo, "expression unlocked")
+	if g.exprLockItem != nil {
+		g.exprLockItem.Active = 0
+	}
+}
+
+// exprLocked returns whether the expression is locked.
+func (g *Gotogen) exprLocked() bool {
+	return !g.exprLockUntil.IsZero()
+}
+
+// updateExprLock unlocks the expression when its time is up.
+func (g *Gotogen) updateExprLock() {
+	if g.exprLocked() && time.Now().After(g.exprLockUntil) {
+		g.unlockExpression()
+	}
+}
+
+// expressionCommand returns whether the command changes what is on the face, and so is held off by the expression lock
+// when it comes from something automatic.
+func expressionCommand(cmd string) bool {
+	switch cmd {
+	case "preset", "face", "expr", "anim", "stop", "effect", "tint", "clock":
+		return true
+	}
+	return false
+}
+
+// appendExprLock adds the time left on the expression lock to a status line.
+func (g *Gotogen) appendExprLock(buf []byte) []byte {
+	left := time.Until(g.exprLockUntil)
+	if left < 0 {
+		left = 0
+	}
+	buf = append(buf, "lock "...)
+	secs := int(left / time.Second)
+	return fmtlite.AppendClock(buf, secs/60, secs%60)
+}
+
+// lockCommand is the lock command: "lock" for the default time, "lock MINUTES", or "lock off".
+func (g *Gotogen) lockCommand(args []string) error {
+	switch {
+	case len(args) == 0:
+		g.LockExpression(defaultExprLockTime)
+	case args[0] == "off":
+		g.LockExpression(0)
+	default:
+		n, err := strconv.ParseUint(args[0], 10, 16)
+		if err != nil || n == 0 {
+			return errors.New("lock: need minutes or off")
+		}
+		g.LockExpression(time.Duration(n) * time.Minute)
+	}
+	return nil
+}
+
+func (g *Gotogen) exprLockSetting() *SettingItem {
+	options := []string{"off"}
+	for _, t := range exprLockTimes {
+		options = append(options, strconv.Itoa(int(t/time.Minute))+"m")
+	}
+	g.exprLockItem = &SettingItem{
+		Name:    "Lock expression",
+		Help:    "Holds the current expression for a while, e.g. for photos. Rules, random effects and micro-expressions leave the face alone, but it can still be changed by hand.",
+		Options: options,
+		Apply: func(selected uint8) {
+			if selected == 0 {
+				g.LockExpression(0)
+				return
+			}
+			g.LockExpression(exprLockTimes[selected-1])
+		},
+	}
+	return g.exprLockItem
+}
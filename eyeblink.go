@@ -0,0 +1,103 @@
+package gotogen
+
+import (
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation/face"
+)
+
+// eyeBlinkIntervals are the average time between blinks for each Blinking setting. The first is off.
+var eyeBlinkIntervals = [...]time.Duration{0, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+
+// eyeBlinkSpreads are how far each Blink randomness setting lets the time between blinks stray from the average, in
+// percent either way.
+var eyeBlinkSpreads = [...]uint32{0, 50, 90}
+
+// eyeBlinkFrames are the eyelid levels of one blink, each shown for eyeBlinkFrame: quickly down, held closed for a
+// moment, and back up.
+var eyeBlinkFrames = [...]uint8{1, 2, face.EyelidClosed, face.EyelidClosed, 2, 1}
+
+// eyeBlinkFrame is how long each frame of a blink is shown for.
+const eyeBlinkFrame = 40 * time.Millisecond
+
+// eyeBlinking closes and reopens the eyes of the idle face every so often, so that it doesn't stare. See also
+// microExpressions, whose noise source it shares.
+type eyeBlinking struct {
+	interval time.Duration
+	spread   uint32
+	// start is when the current blink started, or zero between blinks
+	start time.Time
+	next  time.Time
+	level uint8
+}
+
+// eyeBlinkActive reports whether the face should be blinking on its own. As with micro-expressions, only the idle face
+// blinks, and do not disturb keeps the face as it is.
+func (g *Gotogen) eyeBlinkActive() bool {
+	return g.eyeBlink.interval > 0 && g.faceState == faceStateDefault && !g.dnd && !g.photoMode && !g.asleep()
+}
+
+// updateEyeBlink moves the current blink along, or starts one when it is time to.
+func (g *Gotogen) updateEyeBlink() {
+	b := &g.eyeBlink
+	if !g.eyeBlinkActive() {
+		b.start, b.next, b.level = time.Time{}, time.Time{}, 0
+		return
+	}
+
+	now := time.Now()
+	if b.next.IsZero() {
+		b.next = now.Add(g.nextEyeBlink())
+	}
+	if b.start.IsZero() {
+		if now.Before(b.next) {
+			return
+		}
+		b.start = now
+	}
+	frame := int(now.Sub(b.start) / eyeBlinkFrame)
+	if frame >= len(eyeBlinkFrames) {
+		b.start, b.level = time.Time{}, 0
+		b.next = now.Add(g.nextEyeBlink())
+		return
+	}
+	b.level = eyeBlinkFrames[frame]
+}
+
+// nextEyeBlink returns how long to wait until the next blink: the average interval, strayed from by up to the spread.
+func (g *Gotogen) nextEyeBlink() time.Duration {
+	b := &g.eyeBlink
+	d := b.interval * time.Duration(b.spread) / 100
+	return g.micro.randDuration(b.interval-d, b.interval+d)
+}
+
+// Eyelid returns how far closed the eyes should be right now, from 0 for open to face.EyelidClosed. While synchronized
+// with peers, the eyes blink on the shared schedule instead, so that units blink together.
+func (g *Gotogen) Eyelid() uint8 {
+	if g.peer.role != peerRoleOff {
+		if g.syncBlinking() {
+			return face.EyelidClosed
+		}
+		return 0
+	}
+	return g.eyeBlink.level
+}
+
+// Blinking returns whether the eyes should be closed right now.
+func (g *Gotogen) Blinking() bool {
+	return g.Eyelid() == face.EyelidClosed
+}
+
+func (g *Gotogen) setEyeBlinkInterval(selected uint8) {
+	if int(selected) < len(eyeBlinkIntervals) {
+		g.eyeBlink.interval = eyeBlinkIntervals[selected]
+		g.eyeBlink.next = time.Time{}
+	}
+}
+
+func (g *Gotogen) setEyeBlinkSpread(selected uint8) {
+	if int(selected) < len(eyeBlinkSpreads) {
+		g.eyeBlink.spread = eyeBlinkSpreads[selected]
+		g.eyeBlink.next = time.Time{}
+	}
+}
@@ -0,0 +1,71 @@
+package gotogen
+
+import (
+	"errors"
+	"image/color"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation/clock"
+)
+
+// clockLength is how long the clock is shown on the face.
+const clockLength = 5 * time.Second
+
+// WallClock is an optional interface that a Driver may implement if it knows the time of day, e.g. from an RTC or GPS.
+// Without it, the system clock is used if it looks like it has been set.
+type WallClock interface {
+	// Now returns the current time of day, or false if it isn't known (yet).
+	Now() (time.Time, bool)
+}
+
+// wallClock returns the time of day, if it is known.
+func (g *Gotogen) wallClock() (time.Time, bool) {
+	if wc, ok := g.driver.(WallClock); ok {
+		return wc.Now()
+	}
+	// an unset clock on a microcontroller starts at the epoch
+	now := time.Now()
+	return now, now.Year() >= 2020
+}
+
+// ShowClock temporarily shows the time of day on the face.
+//
+// ShowClock must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) ShowClock() error {
+	g.owner.check()
+	if g.faceState == faceStateBusy {
+		return errors.New("face is busy")
+	}
+	if _, ok := g.wallClock(); !ok {
+		return errors.New("time not known")
+	}
+	now := func() time.Time {
+		t, _ := g.wallClock()
+		return t
+	}
+	g.startAnimation(clock.New(now, g.clockDate, g.clock12h, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, clockLength))
+	return nil
+}
+
+func (g *Gotogen) clockMenu() *Menu {
+	return &Menu{
+		Name: "Clock",
+		Items: []Item{
+			&ActionItem{
+				Name:   "Show clock",
+				Invoke: func() { g.reportError(g.Command("clock")) },
+			},
+			&SettingItem{
+				Name:    "Show date",
+				Options: []string{"off", "on"},
+				Active:  1,
+				Apply:   func(selected uint8) { g.clockDate = selected == 1 },
+			},
+			&SettingItem{
+				Name:    "Format",
+				Options: []string{"24h", "12h"},
+				Apply:   func(selected uint8) { g.clock12h = selected == 1 },
+			},
+		},
+	}
+}
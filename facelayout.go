@@ -0,0 +1,33 @@
+package gotogen
+
+import (
+	"image"
+
+	"github.com/ajanata/gotogen/internal/animation/face"
+)
+
+// FaceLayout is where each part of the default face goes on the face display, as the top-left corner of its image.
+// Negative positions count from the right or bottom edge of the display instead.
+type FaceLayout struct {
+	Eye, Nose, Mouth image.Point
+}
+
+// FaceLayoutProvider is an optional interface that a Driver may implement if its head is a different shape than the
+// default face was laid out for, e.g. to move the nose down or the mouth over. The media manifest (media/layout.txt)
+// can also move parts, and takes precedence, since it goes with the art.
+type FaceLayoutProvider interface {
+	FaceLayout() FaceLayout
+}
+
+// applyFaceLayout gives the default face the character's layout or the driver's, if there is one.
+func (g *Gotogen) applyFaceLayout() {
+	if l, ok := g.characterLayout(); ok {
+		f.SetLayout(face.Layout(l))
+		return
+	}
+	if flp, ok := g.driver.(FaceLayoutProvider); ok {
+		f.SetLayout(face.Layout(flp.FaceLayout()))
+		return
+	}
+	f.SetLayout(face.DefaultLayout)
+}
@@ -0,0 +1,73 @@
+package gotogen
+
+import (
+	"image/color"
+	"time"
+)
+
+const (
+	// failsafeTime is how long Back and Menu have to be held together to reset the face.
+	failsafeTime = 3 * time.Second
+	// failsafeBrightness is the brightness the face is reset to, bright enough to see but not to blind anyone.
+	failsafeBrightness = 0x40
+)
+
+// failsafeButtons is the failsafe chord. It is checked before the button map, so a bad remap can't stop it working.
+var failsafeButtons = ButtonSet(0).With(MenuButtonBack).With(MenuButtonMenu)
+
+// failsafeState tracks how long the failsafe chord has been held.
+type failsafeState struct {
+	since time.Time
+	fired bool
+}
+
+// checkFailsafe resets the face if the failsafe chord has been held long enough, whatever state everything is in. It
+// is the escape hatch for when something misbehaves in the middle of an event. Only drivers that implement
+// ButtonStateDriver can trigger it.
+func (g *Gotogen) checkFailsafe() {
+	bs, ok := g.driver.(ButtonStateDriver)
+	if !ok {
+		return
+	}
+	if bs.ButtonState()&failsafeButtons != failsafeButtons {
+		g.failsafe = failsafeState{}
+		return
+	}
+	switch {
+	case g.failsafe.fired:
+	case g.failsafe.since.IsZero():
+		g.failsafe.since = time.Now()
+	case time.Since(g.failsafe.since) >= failsafeTime:
+		g.failsafe.fired = true
+		g.Failsafe()
+	}
+}
+
+// Failsafe puts everything back to a known good state: it stops any animation, effect, macro, or queued lip-sync and
+// captions, wakes the face, resets the tint and brightness to safe values, and goes back to the default face with the
+// status display idle. A main loop paused for debugging is resumed. Settings that are saved are left alone.
+//
+// Failsafe must be called from the same goroutine as RunTick; other goroutines can use Do.
+func (g *Gotogen) Failsafe() {
+	g.owner.check()
+	g.log(LogInfo, "failsafe reset")
+	g.stepper = stepper{}
+	g.StopMacro()
+	g.stopBench()
+	g.reactions.pending = nil
+	g.effect = nil
+	g.lipSync = lipSync{}
+	g.captions = g.captions[:0]
+	g.photoMode = false
+	g.setPromptPlaying(false)
+	g.setWarning("")
+	g.unlockExpression()
+	g.wake(g.sleepReasons)
+	g.tint = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	g.reportError(g.SetBrightness(failsafeBrightness))
+	// a transition would keep showing whatever went wrong for a moment
+	g.transition.start = time.Time{}
+	g.cutToFace()
+	g.fullFlush = true
+	g.changeStatusState(statusStateIdle)
+}
@@ -0,0 +1,51 @@
+package gotogen
+
+import (
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+const fitSetting = "fit"
+
+// loadFits loads what to do with images that are the wrong size for each media type.
+func (g *Gotogen) loadFits() {
+	b, ok := g.loadSetting(fitSetting)
+	if !ok || len(b) != len(media.Types) {
+		return
+	}
+	for i, t := range media.Types {
+		media.SetFit(t, media.Fit(b[i]))
+	}
+}
+
+func (g *Gotogen) saveFits() {
+	b := make([]byte, len(media.Types))
+	for i, t := range media.Types {
+		b[i] = uint8(media.FitFor(t))
+	}
+	g.saveSetting(fitSetting, b)
+}
+
+// fitMenu has a setting for each media type for what to do with images that are the wrong size for it.
+func (g *Gotogen) fitMenu() *Menu {
+	names := make([]string, media.FitCount)
+	for i := range names {
+		names[i] = media.Fit(i).String()
+	}
+	m := &Menu{
+		Name: "Image fit",
+		Help: "What to do with images that aren't the right size: refuse them, center them as they are, or scale them to fit.",
+	}
+	for _, t := range media.Types {
+		typ := t
+		m.Items = append(m.Items, &SettingItem{
+			Name:    string(typ),
+			Options: names,
+			Active:  uint8(media.FitFor(typ)),
+			Apply: func(selected uint8) {
+				media.SetFit(typ, media.Fit(selected))
+				g.saveFits()
+			},
+		})
+	}
+	return m
+}
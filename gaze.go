@@ -0,0 +1,85 @@
+package gotogen
+
+import (
+	"math"
+)
+
+const (
+	// gazeDeadzone is how far the head has to tilt from upright, in thousandths of gravity, before the eyes follow it,
+	// so that they don't jitter with every small movement. It is about 6 degrees.
+	gazeDeadzone = 100
+	// gazeFull is how far the head has to tilt, in thousandths of gravity, for the eyes to look as far as they can. It
+	// is about 30 degrees.
+	gazeFull = 500
+	// gazeSmoothing is how slowly the eyes catch up with the head: each frame, they go 1/gazeSmoothing of the way.
+	gazeSmoothing = 8
+	// gazeMaxX and gazeMaxY are how far the eyes can look, which is as far as the face can move them.
+	gazeMaxX = 2
+	gazeMaxY = 1
+)
+
+const (
+	gazeOff uint8 = iota
+	gazeFollow
+	gazeMirrored
+)
+
+// gazing moves the eyes a little in the direction the head is tilted, from the accelerometer, so that the face looks
+// like it is looking where it is going.
+type gazing struct {
+	mode uint8
+	// tiltX and tiltY are the smoothed tilt from upright, in thousandths of gravity, sideways and forwards
+	tiltX, tiltY int32
+	x, y         int8
+}
+
+// Gaze returns how far the eyes should look in the direction that the head is tilted.
+func (g *Gotogen) Gaze() (x, y int8) {
+	return g.gaze.x, g.gaze.y
+}
+
+// updateGaze follows the accelerometer. It needs the upright reading from the orientation to know which way is level.
+func (g *Gotogen) updateGaze() {
+	z := &g.gaze
+	o := &g.orientation
+	if z.mode == gazeOff || !o.hasUpright || !g.idleFaceMoves() {
+		z.tiltX, z.tiltY, z.x, z.y = 0, 0, 0, 0
+		return
+	}
+	up := o.upright
+	mag := int64(math.Sqrt(float64(up[0])*float64(up[0]) + float64(up[1])*float64(up[1]) + float64(up[2])*float64(up[2])))
+	if mag == 0 {
+		return
+	}
+	// tilting the head sideways moves gravity onto X, and nodding moves it onto Z
+	tx := int32((int64(g.aX) - int64(up[0])) * 1000 / mag)
+	ty := int32((int64(g.aZ) - int64(up[2])) * 1000 / mag)
+	z.tiltX += (tx - z.tiltX) / gazeSmoothing
+	z.tiltY += (ty - z.tiltY) / gazeSmoothing
+
+	z.x, z.y = gazeShift(z.tiltX, gazeMaxX), gazeShift(z.tiltY, gazeMaxY)
+	if z.mode == gazeMirrored {
+		z.x = -z.x
+	}
+}
+
+// gazeShift turns a tilt into how many pixels to move the eyes, up to most, with no movement inside the deadzone.
+func gazeShift(tilt, most int32) int8 {
+	a := abs32(tilt)
+	if a <= gazeDeadzone {
+		return 0
+	}
+	const span = gazeFull - gazeDeadzone
+	s := ((a-gazeDeadzone)*most + span/2) / span
+	if s > most {
+		s = most
+	}
+	if tilt < 0 {
+		s = -s
+	}
+	return int8(s)
+}
+
+func (g *Gotogen) setGazeMode(selected uint8) {
+	g.gaze.mode = selected
+}
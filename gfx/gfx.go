@@ -0,0 +1,302 @@
+// Package gfx has drawing primitives for procedural animations on small, low resolution displays like LED matrices.
+//
+// Shapes come in plain versions, and anti-aliased (AA) versions that approximate partial coverage of a pixel by drawing
+// it dimmer. Since displays can't be read back, AA assumes a black background, which is what an LED matrix looks like
+// where nothing is lit; AA edges drawn over other shapes will darken them slightly.
+package gfx
+
+import (
+	"image"
+	"image/color"
+)
+
+// Canvas is anything that can be drawn on. Every drivers.Displayer is a Canvas; use FromImage to draw on an image.
+type Canvas interface {
+	Size() (x, y int16)
+	SetPixel(x, y int16, c color.RGBA)
+}
+
+// imageCanvas adapts an image to a Canvas.
+type imageCanvas struct {
+	img *image.RGBA
+}
+
+// FromImage returns a Canvas that draws on the image.
+func FromImage(img *image.RGBA) Canvas {
+	return imageCanvas{img: img}
+}
+
+func (c imageCanvas) Size() (x, y int16) {
+	b := c.img.Bounds()
+	return int16(b.Dx()), int16(b.Dy())
+}
+
+func (c imageCanvas) SetPixel(x, y int16, col color.RGBA) {
+	c.img.SetRGBA(c.img.Rect.Min.X+int(x), c.img.Rect.Min.Y+int(y), col)
+}
+
+// set draws a pixel, skipping it if it is off the canvas.
+func set(c Canvas, x, y int, col color.RGBA) {
+	w, h := c.Size()
+	if x < 0 || y < 0 || x >= int(w) || y >= int(h) {
+		return
+	}
+	c.SetPixel(int16(x), int16(y), col)
+}
+
+// dim scales the color by coverage, from 0 (none) to 255 (all).
+func dim(col color.RGBA, coverage uint8) color.RGBA {
+	return color.RGBA{
+		R: uint8(uint16(col.R) * uint16(coverage) / 0xFF),
+		G: uint8(uint16(col.G) * uint16(coverage) / 0xFF),
+		B: uint8(uint16(col.B) * uint16(coverage) / 0xFF),
+		A: col.A,
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// FillRect fills the rectangle from (x0, y0) up to but not including (x1, y1).
+func FillRect(c Canvas, x0, y0, x1, y1 int, col color.RGBA) {
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			set(c, x, y, col)
+		}
+	}
+}
+
+// Rect outlines the rectangle from (x0, y0) up to but not including (x1, y1).
+func Rect(c Canvas, x0, y0, x1, y1 int, col color.RGBA) {
+	FillRect(c, x0, y0, x1, y0+1, col)
+	FillRect(c, x0, y1-1, x1, y1, col)
+	FillRect(c, x0, y0, x0+1, y1, col)
+	FillRect(c, x1-1, y0, x1, y1, col)
+}
+
+// Line draws a line from (x0, y0) to (x1, y1), including both ends.
+func Line(c Canvas, x0, y0, x1, y1 int, col color.RGBA) {
+	// Bresenham
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		set(c, x0, y0, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// LineAA draws an anti-aliased line from (x0, y0) to (x1, y1), including both ends.
+func LineAA(c Canvas, x0, y0, x1, y1 int, col color.RGBA) {
+	// Xiaolin Wu, in 8 bit fixed point
+	steep := abs(y1-y0) > abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	plot := func(x, y int, coverage uint8) {
+		if steep {
+			x, y = y, x
+		}
+		set(c, x, y, dim(col, coverage))
+	}
+	dx, dy := x1-x0, y1-y0
+	if dx == 0 {
+		plot(x0, y0, 0xFF)
+		return
+	}
+	// y in 8.8 fixed point, and how much it changes for each x
+	gradient := dy * 256 / dx
+	y := y0 * 256
+	for x := x0; x <= x1; x++ {
+		frac := uint8(y & 0xFF)
+		plot(x, y>>8, 0xFF-frac)
+		if frac != 0 {
+			plot(x, y>>8+1, frac)
+		}
+		y += gradient
+	}
+}
+
+// FillEllipse fills the ellipse centered on (cx, cy) with radii rx and ry.
+func FillEllipse(c Canvas, cx, cy, rx, ry int, col color.RGBA) {
+	if rx <= 0 || ry <= 0 {
+		return
+	}
+	for y := -ry; y <= ry; y++ {
+		for x := -rx; x <= rx; x++ {
+			if x*x*ry*ry+y*y*rx*rx <= rx*rx*ry*ry {
+				set(c, cx+x, cy+y, col)
+			}
+		}
+	}
+}
+
+// FillEllipseAA fills the ellipse centered on (cx, cy) with radii rx and ry, with anti-aliased edges.
+func FillEllipseAA(c Canvas, cx, cy, rx, ry int, col color.RGBA) {
+	if rx <= 0 || ry <= 0 {
+		return
+	}
+	// sample each pixel on a 4x4 grid, which is plenty for the sizes that fit on an LED matrix. The samples are at
+	// the centers of the grid cells, so work in units of half a cell.
+	const sub = 4
+	r2 := rx * rx * ry * ry * 4 * sub * sub
+	for y := -ry - 1; y <= ry+1; y++ {
+		for x := -rx - 1; x <= rx+1; x++ {
+			hits := 0
+			for sy := 0; sy < sub; sy++ {
+				py := 2*(y*sub+sy) + 1 - sub
+				for sx := 0; sx < sub; sx++ {
+					px := 2*(x*sub+sx) + 1 - sub
+					if px*px*ry*ry+py*py*rx*rx <= r2 {
+						hits++
+					}
+				}
+			}
+			if hits > 0 {
+				set(c, cx+x, cy+y, dim(col, uint8(hits*0xFF/(sub*sub))))
+			}
+		}
+	}
+}
+
+// Circle outlines the circle centered on (cx, cy) with radius r.
+func Circle(c Canvas, cx, cy, r int, col color.RGBA) {
+	// midpoint circle
+	x, y, e := r, 0, 1-r
+	for x >= y {
+		for _, p := range [...][2]int{{x, y}, {y, x}, {-y, x}, {-x, y}, {-x, -y}, {-y, -x}, {y, -x}, {x, -y}} {
+			set(c, cx+p[0], cy+p[1], col)
+		}
+		y++
+		if e < 0 {
+			e += 2*y + 1
+		} else {
+			x--
+			e += 2*(y-x) + 1
+		}
+	}
+}
+
+// FillCircle fills the circle centered on (cx, cy) with radius r.
+func FillCircle(c Canvas, cx, cy, r int, col color.RGBA) {
+	FillEllipse(c, cx, cy, r, r, col)
+}
+
+// Arc draws part of the ellipse centered on (cx, cy) with radii rx and ry, from angle start to end in degrees,
+// counterclockwise from the right (with y pointing down the screen, as usual for displays, so 90 is the top).
+func Arc(c Canvas, cx, cy, rx, ry, start, end int, col color.RGBA) {
+	for end < start {
+		end += 360
+	}
+	// enough steps that there are no gaps at these sizes
+	steps := 4 * (rx + ry)
+	if steps < 8 {
+		steps = 8
+	}
+	px, py := 0, 0
+	for i := 0; i <= steps; i++ {
+		a := start + (end-start)*i/steps
+		x := cx + rx*cos(a)/1024
+		y := cy - ry*sin(a)/1024
+		if i > 0 {
+			Line(c, px, py, x, y, col)
+		}
+		px, py = x, y
+	}
+}
+
+// FillPolygon fills the polygon with the points as its corners, in order. Pixels are filled if their centers are inside.
+func FillPolygon(c Canvas, points []image.Point, col color.RGBA) {
+	if len(points) < 3 {
+		return
+	}
+	minY, maxY := points[0].Y, points[0].Y
+	for _, p := range points[1:] {
+		if p.Y < minY {
+			minY = p.Y
+		}
+		if p.Y > maxY {
+			maxY = p.Y
+		}
+	}
+	// scanline fill with the even-odd rule, working in half pixels so that pixel centers are exact
+	var xs [16]int
+	for y := minY; y <= maxY; y++ {
+		cy := 2*y + 1
+		n := 0
+		for i := range points {
+			a, b := points[i], points[(i+1)%len(points)]
+			ay, by := 2*a.Y, 2*b.Y
+			if (ay <= cy) == (by <= cy) || n == len(xs) {
+				continue
+			}
+			xs[n] = (2*a.X + (cy-ay)*(2*b.X-2*a.X)/(by-ay)) / 2
+			n++
+		}
+		// insertion sort, there are only ever a few crossings
+		for i := 1; i < n; i++ {
+			for j := i; j > 0 && xs[j-1] > xs[j]; j-- {
+				xs[j-1], xs[j] = xs[j], xs[j-1]
+			}
+		}
+		for i := 0; i+1 < n; i += 2 {
+			FillRect(c, xs[i], y, xs[i+1], y+1, col)
+		}
+	}
+}
+
+// sinTable is sin of 0 to 90 degrees in 10 bit fixed point.
+var sinTable = [91]int16{
+	0, 18, 36, 54, 71, 89, 107, 125, 143, 160, 178, 195, 213, 230, 248, 265, 282, 299, 316, 333, 350, 367, 384, 400,
+	416, 433, 449, 465, 481, 496, 512, 527, 543, 558, 573, 587, 602, 616, 630, 644, 658, 672, 685, 698, 711, 724, 737,
+	749, 761, 773, 784, 796, 807, 818, 828, 839, 849, 859, 868, 878, 887, 896, 904, 912, 920, 928, 935, 943, 949, 956,
+	962, 968, 974, 979, 984, 989, 994, 998, 1002, 1005, 1008, 1011, 1014, 1016, 1018, 1020, 1022, 1023, 1023, 1024,
+	1024,
+}
+
+// sin returns the sine of the angle in degrees, in 10 bit fixed point.
+func sin(deg int) int {
+	deg %= 360
+	if deg < 0 {
+		deg += 360
+	}
+	switch {
+	case deg <= 90:
+		return int(sinTable[deg])
+	case deg <= 180:
+		return int(sinTable[180-deg])
+	case deg <= 270:
+		return -int(sinTable[deg-180])
+	default:
+		return -int(sinTable[360-deg])
+	}
+}
+
+// cos returns the cosine of the angle in degrees, in 10 bit fixed point.
+func cos(deg int) int {
+	return sin(deg + 90)
+}
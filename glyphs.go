@@ -0,0 +1,189 @@
+package gotogen
+
+import (
+	"image/color"
+	"unicode/utf8"
+
+	"github.com/ajanata/textbuf"
+)
+
+// Glyphs for the status display that Unicode doesn't have, in the private use area. Drivers can use them in their
+// StatusLine and status pages like any other character.
+const (
+	GlyphBatteryEmpty rune = 0xE000 + iota
+	GlyphBatteryLow
+	GlyphBatteryHalf
+	GlyphBatteryFull
+	GlyphBatteryCharging
+)
+
+// glyphHeight is how tall the glyphs drawn over the text are, the same as the default font. They are 5 pixels wide,
+// leaving a column between characters.
+const glyphHeight = 8
+
+// glyphs are the characters that the status display's font doesn't have, drawn as pixels over a space. Each row's
+// pixels go from the top bit to the right.
+var glyphs = map[rune][glyphHeight]uint8{
+	'°':                  {0x60, 0x90, 0x90, 0x60, 0x00, 0x00, 0x00, 0x00},
+	'±':                  {0x20, 0x20, 0xF8, 0x20, 0x20, 0x00, 0xF8, 0x00},
+	'²':                  {0x60, 0x90, 0x20, 0x40, 0xF0, 0x00, 0x00, 0x00},
+	'³':                  {0xE0, 0x10, 0x60, 0x10, 0xE0, 0x00, 0x00, 0x00},
+	'µ':                  {0x00, 0x88, 0x88, 0x88, 0x98, 0xE8, 0x80, 0x80},
+	'·':                  {0x00, 0x00, 0x00, 0x20, 0x00, 0x00, 0x00, 0x00},
+	'×':                  {0x00, 0x88, 0x50, 0x20, 0x50, 0x88, 0x00, 0x00},
+	'÷':                  {0x00, 0x20, 0x00, 0xF8, 0x00, 0x20, 0x00, 0x00},
+	'£':                  {0x30, 0x48, 0x40, 0xF0, 0x40, 0x40, 0xF8, 0x00},
+	'€':                  {0x38, 0x40, 0xF0, 0x40, 0xF0, 0x40, 0x38, 0x00},
+	'←':                  {0x00, 0x20, 0x40, 0xF8, 0x40, 0x20, 0x00, 0x00},
+	'↑':                  {0x20, 0x70, 0xA8, 0x20, 0x20, 0x20, 0x20, 0x00},
+	'→':                  {0x00, 0x20, 0x10, 0xF8, 0x10, 0x20, 0x00, 0x00},
+	'↓':                  {0x20, 0x20, 0x20, 0x20, 0xA8, 0x70, 0x20, 0x00},
+	'✓':                  {0x00, 0x08, 0x10, 0xA0, 0x40, 0x00, 0x00, 0x00},
+	GlyphBatteryEmpty:    {0x70, 0xF8, 0x88, 0x88, 0x88, 0x88, 0x88, 0xF8},
+	GlyphBatteryLow:      {0x70, 0xF8, 0x88, 0x88, 0x88, 0x88, 0xF8, 0xF8},
+	GlyphBatteryHalf:     {0x70, 0xF8, 0x88, 0x88, 0xF8, 0xF8, 0xF8, 0xF8},
+	GlyphBatteryFull:     {0x70, 0xF8, 0xF8, 0xF8, 0xF8, 0xF8, 0xF8, 0xF8},
+	GlyphBatteryCharging: {0x70, 0xF8, 0x98, 0xA8, 0xF8, 0xA8, 0xC8, 0xF8},
+}
+
+// latin1Fallbacks are the closest plain ASCII characters to Latin-1, from U+00A0, for the ones without a glyph.
+const latin1Fallbacks = ` !cL*Y|S"ca<--R-` + `o+23'uP.,1o>????` + `AAAAAAACEEEEIIII` + `DNOOOOOxOUUUUYPs` +
+	`aaaaaaaceeeeiiii` + `dnooooo/ouuuuypy`
+
+// BatteryGlyph returns the battery glyph for how full the battery is.
+func BatteryGlyph(percent uint8) rune {
+	switch {
+	case percent >= 80:
+		return GlyphBatteryFull
+	case percent >= 40:
+		return GlyphBatteryHalf
+	case percent >= 10:
+		return GlyphBatteryLow
+	}
+	return GlyphBatteryEmpty
+}
+
+// glyphText is the status display's text buffer, with the characters its font doesn't have mapped to ones it does,
+// or drawn over the text.
+type glyphText struct {
+	*textbuf.Buffer
+	canvas Display
+	// drawn are the columns of each line that glyphs were drawn in, to clean up when the line changes
+	drawn [][]int16
+	line  []byte
+}
+
+func newGlyphText(buf *textbuf.Buffer, canvas Display) *glyphText {
+	_, th := buf.Size()
+	return &glyphText{Buffer: buf, canvas: canvas, drawn: make([][]int16, th)}
+}
+
+func (t *glyphText) SetLine(y int16, s ...string) error {
+	return t.setLine(y, false, s)
+}
+
+func (t *glyphText) SetLineInverse(y int16, s ...string) error {
+	return t.setLine(y, true, s)
+}
+
+func (t *glyphText) Clear() {
+	t.Buffer.Clear()
+	for i := range t.drawn {
+		t.drawn[i] = t.drawn[i][:0]
+	}
+}
+
+func (t *glyphText) setLine(y int16, inverse bool, parts []string) error {
+	if y < 0 || int(y) >= len(t.drawn) {
+		// let the text buffer say what is wrong with it
+		return t.set(y, inverse, parts...)
+	}
+	if len(t.drawn[y]) == 0 && plainASCII(parts) {
+		return t.set(y, inverse, parts...)
+	}
+
+	fg, bg := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, color.RGBA{}
+	if inverse {
+		fg, bg = bg, fg
+	}
+	for _, col := range t.drawn[y] {
+		t.drawGlyph(col, y, nil, fg, bg)
+	}
+	t.drawn[y] = t.drawn[y][:0]
+
+	t.line = t.line[:0]
+	for _, p := range parts {
+		for _, r := range p {
+			switch {
+			case r < utf8.RuneSelf:
+				t.line = append(t.line, byte(r))
+			case hasGlyph(r):
+				t.drawn[y] = append(t.drawn[y], int16(len(t.line)))
+				t.line = append(t.line, ' ')
+			case r >= 0xA0 && r <= 0xFF:
+				t.line = append(t.line, latin1Fallbacks[r-0xA0])
+			default:
+				t.line = append(t.line, '?')
+			}
+		}
+	}
+	err := t.set(y, inverse, string(t.line))
+
+	tw, _ := t.Size()
+	col := int16(0)
+	for _, p := range parts {
+		for _, r := range p {
+			if g, ok := glyphs[r]; ok && col < tw {
+				t.drawGlyph(col, y, &g, fg, bg)
+			}
+			col++
+		}
+	}
+	return err
+}
+
+func (t *glyphText) set(y int16, inverse bool, s ...string) error {
+	if inverse {
+		return t.Buffer.SetLineInverse(y, s...)
+	}
+	return t.Buffer.SetLine(y, s...)
+}
+
+// drawGlyph draws a glyph in a character cell, or blanks it if there is no glyph. Glyphs are scaled up to fill bigger
+// fonts' cells.
+func (t *glyphText) drawGlyph(col, row int16, g *[glyphHeight]uint8, fg, bg color.RGBA) {
+	w, h := t.canvas.Size()
+	tw, th := t.Size()
+	cw, ch := w/tw, h/th
+	scale := ch / glyphHeight
+	if scale < 1 {
+		scale = 1
+	}
+	for y := int16(0); y < ch; y++ {
+		for x := int16(0); x < cw; x++ {
+			c := bg
+			gx, gy := x/scale, y/scale
+			if g != nil && gy < glyphHeight && gx < 8 && g[gy]&(0x80>>gx) != 0 {
+				c = fg
+			}
+			t.canvas.SetPixel(col*cw+x, row*ch+y, c)
+		}
+	}
+}
+
+func hasGlyph(r rune) bool {
+	_, ok := glyphs[r]
+	return ok
+}
+
+// plainASCII is whether the text can go to the text buffer as it is.
+func plainASCII(parts []string) bool {
+	for _, p := range parts {
+		for i := 0; i < len(p); i++ {
+			if p[i] >= utf8.RuneSelf {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -7,13 +7,14 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/ajanata/textbuf"
-
 	"github.com/ajanata/gotogen/internal/animation"
 	"github.com/ajanata/gotogen/internal/animation/face"
-	"github.com/ajanata/gotogen/internal/animation/peek"
-	"github.com/ajanata/gotogen/internal/animation/slide"
 	"github.com/ajanata/gotogen/internal/animation/static"
+	"github.com/ajanata/gotogen/internal/animation/vector"
+	"github.com/ajanata/gotogen/internal/effect"
+	"github.com/ajanata/gotogen/internal/errcode"
+	"github.com/ajanata/gotogen/internal/fmtlite"
+	"github.com/ajanata/gotogen/internal/framebuf"
 	"github.com/ajanata/gotogen/internal/media"
 	"github.com/ajanata/gotogen/internal/mirror"
 )
@@ -31,39 +32,196 @@ const (
 	SensorStatusBusy
 )
 
+// Gotogen is the core of a protogen head. It is not safe for concurrent use: see Post for how other goroutines can
+// interact with it.
 type Gotogen struct {
-	framerate  uint
-	frameTime  time.Duration
-	blinker    Blinker
-	boopDist   uint8
-	aX, aY, aZ int32 // accelerometer
-
-	faceDisplay Display
-	faceMirror  Display
-	faceState   faceState
-	activeAnim  animation.Animation
-
+	framerate     uint
+	baseFramerate uint
+	frameTime     time.Duration
+	blinker       Blinker
+	boopDist      uint8
+	aX, aY, aZ    int32 // accelerometer
+
+	faceDisplay  Display
+	faceMirror   Display
+	faceState    faceState
+	activeAnim   animation.Animation
+	faceHealth   displayHealth
+	statusHealth displayHealth
+	// compactStatus is set for status displays with only four lines of text, like 128x32 OLEDs
+	compactStatus bool
+	driverCalls   driverCallTimes
+	frame         *framebuf.Buffer
+	fullFlush     bool
+	seam          mirror.Seam
+	bezelGap      uint8
+
+	// largePrint doubles the size of the status display's text
+	largePrint     bool
+	largePrintItem *SettingItem
+	// statusCanvas is what the status display's text is drawn on: the display itself, or scaled up for large print
+	statusCanvas Display
+
+	faceImages    []string
+	nextFaceIndex int
+	// the kind and file of full-screen animation playing, if it was started by name
+	animKind, animFile string
+	// set by StartAnimation's options
+	onComplete func()
+	animUntil  time.Time
+
+	tint        color.RGBA
+	brightness  uint8
+	colorScale  [3]uint16
+	colorScaled bool
+	dimming     dimming
+
+	effect             effect.Effect
+	transition         transition
+	effectRNG          uint32
+	randomEffectChance uint32
+	shakeEffects       bool
+
+	powerPolicy uint8
+	powerState  powerState
+	battery     uint8
+	hasBattery  bool
+
+	headless             bool
 	statusDisplay        Display
 	statusMirror         Display
 	statusFrameSkip      uint8
+	statusAutoSkip       bool
+	statusTiming         statusTiming
 	statusDownmixChannel colorChannel
 	statusDownmixCutoff  uint8
-	statusText           *textbuf.Buffer // TODO interface
+	statusText           *glyphText // TODO interface
+	menuRenderer         MenuRenderer
 	statusState          statusState
 	statusStateChange    time.Time
 	rootMenu             Menu
 	activeMenu           Menuable
 	statusForceUpdate    bool
+	repeat               buttonRepeat
+	buttonMap            buttonMap
+	remap                remapWizard
+	chord                chordState
+	failsafe             failsafeState
+	activity             activityCounter
+	sensorLog            sensorLog
+	heartbeat            heartbeat
+	boopStats            boopStats
+	boopCal              boopCalibration
+	boopClass            boopClassifier
+	boopReact            boopReaction
+	reactions            reactionLimits
+	// exprLockUntil is when the expression lock runs out, or zero if the expression isn't locked
+	exprLockUntil    time.Time
+	exprLockItem     *SettingItem
+	bindings         []quickAction
+	photoMode        bool
+	sim              simState
+	stepper          stepper
+	art              artEditor
+	captions         []caption
+	captionUntil     time.Time
+	captionMarquee   bool
+	artCredits       bool
+	peer             peerSync
+	clockDate        bool
+	upload           mediaUpload
+	screenshotPNG    bool
+	screenshotStatus bool
+	macro            macroState
+	rules            []*rule
+	behavior         behavior
+	behaviorMenuItem *Menu
+	characters       characters
+	bench            benchmark
+	rulesEnabled     bool
+	shaking          bool
+	alarms           []alarm
+	alarmAlerts      uint8
+	lipSync          lipSync
+	talkLevel        talkLevel
+	music            music
+	statusIcons      []StatusIcon
+	iconStates       [maxStatusIcons]int
+	pager            statusPager
+	noPreview        bool
+	animStart        time.Time
+	previewLines     [2]statusLine
+	preview          previewAlign
+	previewEdit      previewAlignEditor
+	frameWindow      frameWindow
+	animBudget       uint64
+	bus              chan busMessage
+	owner            owner
+	logger           Logger
+	logLevel         LogLevel
+	logRing          logRing
+	orientation      orientation
+	tiltSetting      uint8
+	sleepReasons     sleepReason
+	proximitySleep   proximitySleep
+	wornReading      bool
+	wornSince        time.Time
+	dnd              bool
+	dndItem          *SettingItem
+	vectorFace       *vector.Anim
+	faceStyle        uint8
+	help             helpPage
+	curve            brightnessCurve
+	curveEdit        curveEditor
+	palette          uint8
+	paletteRemap     func(color.RGBA) color.RGBA
+	// rawFace sends the face to the panels exactly as it is drawn, for test patterns
+	rawFace bool
+	// panelColors are the only colors the face panels can show, or nil if they can show full color
+	panelColors color.Palette
+	panelSpread uint16
+	micro       microExpressions
+	eyeBlink    eyeBlinking
+	gaze        gazing
+	breath      breathing
+	prompt      prompter
+	promptMenu  *Menu
+	qr          qrState
+	qrMenuItem  *Menu
+	stats       FrameStats
+	clock12h    bool
+	micMuted    bool
+	repeatDelay time.Duration
+	repeatAccel uint8
+	theme       *Theme
+	idleLines   [4]statusLine
+	warning     string
+	statusDirty bool
+
+	// profileBuf is where the lines of the profiling page are built
+	profileBuf []byte
+	// savedQRCodes are the QR codes saved with the qr command
+	savedQRCodes []QRCode
+	// sourceExpression is the last expression the driver's ExpressionSource asked for
+	sourceExpression string
 
 	driver Driver
 
-	init  bool
-	start time.Time
+	init          bool
+	start         time.Time
+	lastBootFrame time.Time
 
 	tick      uint32
 	lastSec   time.Time
 	lastTicks uint32
 	lastFPS   uint32
+	heapIdle  uint64
+
+	blinkPattern  blinkPlayer
+	bootStage     bootStage
+	capabilities  []capability
+	ledColors     [ledStateCount]uint8
+	ledBrightness uint8
 
 	// storing this once could be inaccurate on OS-based implementations, but you also don't really care in that case
 	totalRAM string
@@ -72,14 +230,13 @@ type Gotogen struct {
 type Driver interface {
 	// EarlyInit initializes secondary devices after the primary menu display has been initialized for boot
 	// messages. Hardware drivers shall configure any buses (SPI, etc.) that are required to communicate with these
-	// devices at this point, and should only configure the bare minimum to call New.
+	// devices at this point, and should only configure the bare minimum to call New. Storage for a MediaProviderDriver
+	// must be mounted by the time it returns.
 	EarlyInit() (faceDisplay Display, err error)
 
 	// LateInit performs any late initialization (e.g. connecting to wifi to set the clock). The failure of anything in
 	// LateInit should not cause the failure of the entire process. Boot messages may be freely logged.
-	//
-	// TODO interface
-	LateInit(buffer *textbuf.Buffer)
+	LateInit(boot BootReporter)
 
 	// PressedButton returns the currently-pressed menu button. The implementation is responsible for prioritizing
 	// multiple buttons being pressed at the same time however it sees fit (or implement some buttons as a chord of
@@ -103,7 +260,8 @@ type Driver interface {
 	// The second return value indicates the status of the accelerometer: does not exist, valid data, or busy.
 	Accelerometer() (x, y, z int32, status SensorStatus)
 
-	// Talking indicates if the driver has detected speech and the face should animate talking.
+	// Talking indicates if the driver has detected speech and the face should animate talking. Drivers that can tell
+	// how loud it is should also implement TalkingLevelSensor.
 	Talking() bool
 
 	// StatusLine returns a textual status indicator that the driver may use for whatever it wishes.
@@ -114,30 +272,63 @@ type Driver interface {
 	StatusLine() string
 }
 
+// ButtonStateDriver is an optional interface that a Driver may implement to report which buttons are physically held
+// down right now. Unlike PressedButton, this should not apply any repeating; it is used by the core to implement
+// accelerated repeat when holding up or down while scrolling through long option lists.
+type ButtonStateDriver interface {
+	ButtonState() ButtonSet
+}
+
 type Blinker interface {
 	Low()
 	High()
 }
 
+// New creates a new Gotogen. The status display may be nil for headless builds that only drive the face; in that case,
+// boot messages and the status screen are discarded, but the menu can still be navigated.
 func New(framerate uint, status Display, blinker Blinker, driver Driver) (*Gotogen, error) {
 	if framerate == 0 {
 		return nil, errors.New("must run at least one frame per second")
 	}
-	if status == nil {
-		return nil, errors.New("must provide status display")
-	}
 	if driver == nil {
 		return nil, errors.New("must provide driver")
 	}
+	headless := status == nil
+	if headless {
+		status = headlessDisplay{}
+	}
 
 	return &Gotogen{
+		headless:      headless,
 		framerate:     framerate,
+		baseFramerate: framerate,
 		frameTime:     time.Second / time.Duration(framerate),
 		statusDisplay: status,
 		statusMirror:  mirror.New(status),
 		blinker:       blinker,
 		driver:        driver,
+		theme:         Themes[0],
+		ledColors:     defaultLEDColors,
+		ledBrightness: 0x80,
+		tint:          color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		brightness:    0xFF,
+		clockDate:     true,
+		artCredits:    true,
+		macro:         macroState{recording: -1},
+		breath:        breathing{period: breathPeriods[1]},
+		eyeBlink:      eyeBlinking{interval: eyeBlinkIntervals[2], spread: eyeBlinkSpreads[1]},
+		gaze:          gazing{mode: gazeFollow},
+		music:         music{mode: musicSyncMouth},
+		transition:    transition{kind: transitionFade},
+		rulesEnabled:  true,
+		alarmAlerts:   3,
+		colorScale:    [3]uint16{0xFF, 0xFF, 0xFF},
 		start:         time.Now(),
+		bus:           make(chan busMessage, busSize),
+		logger:        printlnLogger{},
+		logLevel:      LogInfo,
+		logRing:       logRing{lines: make([]string, defaultLogLines)},
+		tiltSetting:   defaultTiltSetting,
 	}, nil
 }
 
@@ -145,51 +336,55 @@ func (g *Gotogen) Init() error {
 	if g.init {
 		return errors.New("already initialized")
 	}
-	println("starting init")
-	g.blink()
-
-	var err error
-	// TODO font size configurable
-	g.statusText, err = textbuf.New(g.statusDisplay, textbuf.FontSize6x8)
-	if err != nil {
-		return errors.New("init status: " + err.Error())
+	g.log(LogInfo, "starting init")
+	media.SetLogger(g.logMedia)
+	face.SetLogger(func(msg string) { g.log(LogWarning, msg) })
+	if g.headless {
+		g.log(LogInfo, "no status display, running headless")
 	}
-	g.statusText.AutoFlush = true
+	g.setBootStage(bootStageStatus)
 
-	w, h := g.statusDisplay.Size()
-	tw, th := g.statusText.Size()
 	// TODO make this more graceful
-	if tw < 20 || th < 8 || w < 128 || h < 64 {
-		return errors.New("unusably small status display")
+	if w, h := g.statusDisplay.Size(); w < 128 || h < 32 {
+		return errcode.New(errcode.DisplaySize, "unusably small status display")
+	}
+	err := g.newStatusText()
+	if err != nil {
+		return errcode.Context("init status", errcode.Wrap(errcode.DisplayStatus, err))
 	}
 
 	err = g.statusText.SetLineInverse(0, "GOTOGEN BOOTING")
 	if err != nil {
-		return errors.New("boot msg: " + err.Error())
+		return errcode.Context("boot msg", errcode.Wrap(errcode.DisplayStatus, err))
 	}
 	// we already validated it has at least 4 lines
 	_ = g.statusText.SetY(1)
 	// we already know it was possible to print text so don't bother checking every time
 	_ = g.statusText.Print("Initialize devices")
 
+	g.setBootStage(bootStageEarlyInit)
 	faceDisplay, err := g.driver.EarlyInit()
 	if err != nil {
+		err = errcode.Context("early init", errcode.Wrap(errcode.DriverInit, err))
 		_ = g.statusText.PrintlnInverse(err.Error())
-		return errors.New("early init: " + err.Error())
+		return err
 	}
 	if faceDisplay == nil {
-		return errors.New("init did not provide face")
+		return errcode.New(errcode.DriverInit, "init did not provide face")
 	}
 
+	g.initMediaProvider()
 	g.faceDisplay = faceDisplay
 	g.faceMirror = mirror.New(faceDisplay)
+	g.frame = framebuf.New(g.faceMirror.Size())
 	_ = g.statusText.Println(".")
 
-	// now that we have the face panels set up, we can put a loading image on them while LateInit runs
-	err = g.busy()
+	// now that we have the face panels set up, we can put a loading animation on them while LateInit runs
+	g.setBootStage(bootStageAnimation)
+	err = g.startBootAnimation()
 	if err != nil {
-		_ = g.statusText.PrintlnInverse("load busy: " + err.Error())
-		return errors.New("load busy: " + err.Error())
+		_ = g.statusText.PrintlnInverse(err.Error())
+		return err
 	}
 
 	_ = g.statusText.Println("CPUs: " + strconv.Itoa(runtime.NumCPU()))
@@ -198,45 +393,112 @@ func (g *Gotogen) Init() error {
 	g.totalRAM = strconv.Itoa(int(mem.HeapSys / 1024))
 	_ = g.statusText.Println(strconv.Itoa(int(mem.HeapSys/1024)) + "k RAM, " + strconv.Itoa(int(mem.HeapIdle/1024)) + "k free")
 
-	g.driver.LateInit(g.statusText)
+	g.setBootStage(bootStageLateInit)
+	g.driver.LateInit(textBootReporter{buf: g.statusText, g: g})
+	g.checkCapabilities()
+	g.reportCapabilities()
+	// the driver has had a chance to initialize its storage by now
+	g.setBootStage(bootStageSettings)
+	g.initMediaStorage()
+	g.loadFits()
+	g.loadCurve()
+	g.loadPalette()
+	g.loadPanelColors()
+	g.loadPreviewAlign()
+	g.loadSeam()
+	g.loadButtonMap()
+	g.loadBindings()
+	g.loadRules()
+	g.loadAlarms()
+	g.loadQRCodes()
+	g.loadStatusIcons()
+	g.loadStatusPages()
+	g.loadFaceStyle()
+	g.loadSensorLog()
+	g.loadHeartbeat()
+	g.loadBehavior()
+	g.loadBoopStats()
+	g.loadBoopCal()
+	g.loadReactions()
+	g.loadBoopReaction()
+	g.loadLargePrint()
+	g.loadCharacters()
+	if _, st := g.driver.BoopDistance(); st != SensorStatusUnavailable {
+		g.startBoopCal(false)
+	}
 	g.initMainMenu()
 
+	g.setBootStage(bootStageFace)
 	_ = g.statusText.Print("Loading face")
 	f, err = face.New(g)
 	if err != nil {
 		_ = g.statusText.PrintlnInverse(": " + err.Error())
-		return errors.New("load face: " + err.Error())
+		return errcode.Context("load face", err)
 	}
+	g.applyFaceLayout()
+	g.vectorFace = vector.New(g, vectorFaceColor)
 
 	_ = g.statusText.Println(".\nThe time is now")
 	_ = g.statusText.Println(time.Now().Format(time.Stamp))
 	_ = g.statusText.Println("Booted in " + time.Now().Sub(g.start).Round(100*time.Millisecond).String())
 	_ = g.statusText.Println("Gotogen online.")
+	g.bootSound()
 
 	// TODO load from settings storage; these is also defined in initMainMenu
 	g.statusDownmixChannel = colorChannelRed
 	g.statusDownmixCutoff = 0xA0
 	g.statusFrameSkip = 0
+	g.statusAutoSkip = true
+	g.powerPolicy = 1
+	g.repeatDelay = 400 * time.Millisecond
+	g.repeatAccel = 2
 
 	g.statusText.AutoFlush = false
 	g.statusStateChange = time.Now()
 
+	// back to the normal heartbeat
+	g.Blink(nil, false)
 	g.blink()
 	g.init = true
-	println("init complete in", time.Now().Sub(g.start).Round(100*time.Millisecond).String())
+	g.log(LogInfo, "init complete in "+time.Now().Sub(g.start).Round(100*time.Millisecond).String())
 	return nil
 }
 
-// Run does not return. It attempts to run the main loop at the framerate specified in New.
+// Run does not return. It attempts to run the main loop at the framerate specified in New (or changed in the menu),
+// which may be reduced to save power.
 func (g *Gotogen) Run() {
-	for range time.Tick(g.frameTime) {
+	var sched frameScheduler
+	for {
 		err := g.RunTick()
 		if err != nil {
 			g.panic(err)
 		}
+		time.Sleep(sched.untilNext(g.frameTime, time.Now()))
 	}
 }
 
+// frameScheduler works out when each frame should start, keeping a steady cadence even if individual frames take
+// different amounts of time.
+type frameScheduler struct {
+	next      time.Time
+	frameTime time.Duration
+}
+
+// untilNext returns how long to wait until the next frame should start. The frame time can change from one frame to
+// the next (from the menu or the power policy), in which case the new cadence starts from the current frame.
+func (s *frameScheduler) untilNext(frameTime time.Duration, now time.Time) time.Duration {
+	if s.next.IsZero() || frameTime != s.frameTime {
+		s.next = now
+		s.frameTime = frameTime
+	}
+	s.next = s.next.Add(frameTime)
+	if s.next.Before(now) {
+		// we fell behind; don't try to catch up by running a bunch of frames back-to-back
+		s.next = now
+	}
+	return s.next.Sub(now)
+}
+
 var s animation.Animation
 var f *face.Anim
 
@@ -245,78 +507,238 @@ func (g *Gotogen) RunTick() error {
 	if !g.init {
 		return errors.New("not initialized")
 	}
+	// whichever goroutine runs the first tick owns the Gotogen from now on, whatever the tick counter says, since boot
+	// frames count ticks too
+	g.owner.enter()
+	// the failsafe has to work while paused, so that it can't be locked out
+	g.checkFailsafe()
+	if g.pausedTick() {
+		return nil
+	}
+	if g.stepping() {
+		defer g.replyDebugState()
+	}
 
-	g.blinkerOff()
+	patterned := g.updateBlinker()
+	if !patterned {
+		g.blinkerOff()
+	}
+	tickStart := time.Now()
+	g.recordFrameStart(tickStart)
 	g.tick++
 	g.statusForceUpdate = false
 
 	// busy states clear when we get back to the run loop
 	if g.faceState == faceStateBusy {
-		g.faceState = faceStateDefault
-		f.Activate(g)
-		g.activeAnim = f
+		g.returnToFace()
 	}
 
 	if time.Since(g.lastSec) >= time.Second {
 		g.lastFPS = g.tick - g.lastTicks
 		g.lastSec = time.Now()
 		g.lastTicks = g.tick
+
+		// memory usage changes rarely enough that we don't need to check it every frame
+		mem := runtime.MemStats{}
+		runtime.ReadMemStats(&mem)
+		g.heapIdle = mem.HeapIdle
+
+		g.updatePowerPolicy()
+		g.publishFrameStats()
 	}
 
 	// read sensors
+	callStart := time.Now()
 	d, st := g.driver.BoopDistance()
+	g.timeDriverCall(driverCallBoopDistance, callStart)
 	if st == SensorStatusAvailable {
 		g.boopDist = d
+		g.updateBoopCal(d)
+		g.updateProximity()
 	}
 
+	callStart = time.Now()
 	x, y, z, st := g.driver.Accelerometer()
+	g.timeDriverCall(driverCallAccelerometer, callStart)
 	if st == SensorStatusAvailable {
+		dx, dy, dz := x-g.aX, y-g.aY, z-g.aZ
+		g.shaking = abs32(dx)+abs32(dy)+abs32(dz) > shakeThreshold
+		g.checkEffectTriggers(dx, dy, dz)
 		g.aX, g.aY, g.aZ = x, y, z
+		g.updateOrientation(x, y, z)
 	}
+	g.simulate()
+	g.countActivity()
+	g.updateBoopEvents()
+	g.updateBoopReaction()
+	g.pollCommands()
+	g.drainBus()
+	g.updatePeers()
+	g.updateMacro()
+	g.updateExprLock()
+	g.updateExpressionSource()
+	g.updateReactions()
+	g.updateRules()
+	g.updateBehavior()
+	g.updateAlarms()
+	g.updateLipSync()
+	g.updateTalkLevel()
+	g.updateMusic()
+	g.updateMicro()
+	g.updateGaze()
+	g.updateEyeBlink()
+	g.updateBreathing()
+	g.updateDimming()
+	g.updateSensorLog()
+	g.updateHeartbeat()
+	g.updateBoopStats()
 
 	// TODO better way to framerate limit the status screen
-	canRedrawStatus := g.statusDisplay.CanUpdateNow()
+	canRedrawStatus := !g.headless && g.statusDisplay.CanUpdateNow()
 	if g.statusFrameSkip > 0 {
 		canRedrawStatus = canRedrawStatus && uint8(g.tick)%g.statusFrameSkip == 0
 	}
 	// we always need to call this tho since the menu handling code is in here
 	g.updateStatus(canRedrawStatus)
 
-	cont := g.activeAnim.DrawFrame(g, g.tick)
-	if !cont {
-		g.faceState = faceStateDefault
-		g.statusForceUpdate = true
-		f.Activate(g)
-		g.activeAnim = f
-	}
-
-	err := g.faceDisplay.Display()
-	if err != nil {
-		g.panic(err)
-	}
+	// while asleep, the face is blank and the animation is paused
+	if !g.asleep() {
+		cont := g.activeAnim.DrawFrame(g, g.tick)
+		if !cont || (!g.animUntil.IsZero() && tickStart.After(g.animUntil)) {
+			g.finishAnimation()
+		}
+		g.applyEffect()
+		g.applyTransition()
 
-	if g.statusState != statusStateBlank && canRedrawStatus {
-		err = g.statusText.Display()
-		if err != nil {
-			g.panic(err)
+		if g.faceHealth.ready(tickStart) {
+			g.faceDisplayed(g.flushFace())
 		}
 	}
 
-	g.blinkerOn()
+	// the idle screen only needs to be sent to the display when something on it actually changed
+	var statusTime time.Duration
+	if !g.headless && g.statusState != statusStateBlank && canRedrawStatus && (g.statusState != statusStateIdle || g.statusDirty) &&
+		g.statusHealth.ready(tickStart) {
+		statusStart := time.Now()
+		g.statusDisplayed(g.statusText.Display())
+		statusTime = time.Since(statusStart)
+	}
+	tickTime := time.Since(tickStart)
+	g.recordTickTiming(tickTime, statusTime)
+	g.recordFrameTime(tickTime)
+	g.recordBench(tickTime)
+
+	if !patterned && !g.photoMode {
+		g.setLEDColor(g.currentLEDState())
+		g.blinkerOn()
+	}
 	return nil
 }
 
 func (g *Gotogen) drawIdleStatus() {
-	mem := runtime.MemStats{}
-	runtime.ReadMemStats(&mem)
+	g.drawPreviewInfo()
+	if g.pager.current > 0 {
+		g.drawStatusPage()
+		return
+	}
+	sep := g.theme.Separator
+
 	// TODO switch which line this is on every minute or so for burn-in protection
-	_ = g.statusText.SetLine(0, time.Now().Format("03:04"), " ", strconv.Itoa(int(g.lastFPS)), "Hz ", strconv.Itoa(int(mem.HeapIdle/1024)), "k/", g.totalRAM, "k")
-	// TODO temp hack
-	_ = g.statusText.SetLine(1, strconv.Itoa(int(g.boopDist)), " ", strconv.Itoa(int(g.aX)), " ", strconv.Itoa(int(g.aY)), " ", strconv.Itoa(int(g.aZ)))
+	l := &g.idleLines[0]
+	l.reset()
+	l.buf = appendClock12(l.buf, time.Now())
+	l.buf = append(l.buf, sep...)
+	l.buf = fmtlite.AppendUint(l.buf, uint64(g.lastFPS))
+	l.buf = append(l.buf, "Hz"...)
+	if !g.compactStatus {
+		// the compact layout leaves out memory, to make room for the status icons
+		l.buf = append(l.buf, sep...)
+		l.buf = fmtlite.AppendUint(l.buf, g.heapIdle/1024)
+		l.buf = append(l.buf, "k/"...)
+		l.buf = append(l.buf, g.totalRAM...)
+		l.buf = append(l.buf, 'k')
+	}
+	iconsChanged, icons := g.updateIconStates()
+	if n := g.headerChars(icons); len(l.buf) > n {
+		l.buf = l.buf[:n]
+	}
+	if iconsChanged {
+		l.invalidate()
+	}
+	changed := l.flush(g.statusText, 0)
+	if changed && icons > 0 {
+		// the icons are drawn over the line, so have to be redrawn every time the line is
+		g.drawStatusIcons()
+	}
+
+	// large print leaves out the sensor readings, so the lines after them move up
+	row := int16(1)
+	if !g.largePrint {
+		// TODO temp hack
+		l = &g.idleLines[1]
+		l.reset()
+		l.buf = fmtlite.AppendUint(l.buf, uint64(g.boopDist))
+		l.buf = append(l.buf, sep...)
+		l.buf = fmtlite.AppendInt(l.buf, int64(g.aX))
+		l.buf = append(l.buf, sep...)
+		l.buf = fmtlite.AppendInt(l.buf, int64(g.aY))
+		l.buf = append(l.buf, sep...)
+		l.buf = fmtlite.AppendInt(l.buf, int64(g.aZ))
+		changed = l.flush(g.statusText, row) || changed
+		row++
+	}
+
+	l = &g.idleLines[2]
+	l.reset()
+	if g.warning != "" {
+		l.buf = append(l.buf, g.warning...)
+	} else {
+		if g.hasBattery {
+			if g.powerState >= powerStateLow {
+				l.buf = append(l.buf, g.theme.Icon(IconBatteryLow)...)
+			} else {
+				l.buf = append(l.buf, g.theme.Icon(IconBattery)...)
+			}
+			l.buf = fmtlite.AppendUint(l.buf, uint64(g.battery))
+			l.buf = append(l.buf, '%')
+			if g.powerState != powerStateNormal {
+				l.buf = append(l.buf, sep...)
+				l.buf = append(l.buf, g.powerState.String()...)
+				l.buf = append(l.buf, sep...)
+				l.buf = fmtlite.AppendUint(l.buf, uint64(time.Second/g.frameTime))
+				l.buf = append(l.buf, "Hz"...)
+			}
+		}
+		l.buf = g.appendRadios(l.buf, sep)
+	}
+	if g.exprLocked() {
+		if len(l.buf) > 0 {
+			l.buf = append(l.buf, sep...)
+		}
+		l.buf = g.appendExprLock(l.buf)
+	}
+	changed = l.flush(g.statusText, row) || changed
+	row++
 
-	_ = g.statusText.SetLine(3, g.driver.StatusLine())
+	l = &g.idleLines[3]
+	l.reset()
+	l.buf = append(l.buf, g.driver.StatusLine()...)
+	changed = l.flush(g.statusText, row) || changed
 
-	// println(time.Now().Format("03:04"), g.lastFPS, "Hz", mem.HeapIdle/1024, "k/", g.totalRAM)
+	if changed {
+		g.statusDirty = true
+	}
+}
+
+// appendClock12 appends the time on a 12-hour clock, as "03:04" in time.Format.
+func appendClock12(b []byte, t time.Time) []byte {
+	h := t.Hour() % 12
+	if h == 0 {
+		h = 12
+	}
+	b = fmtlite.AppendPadded(b, uint64(h), 2, '0')
+	b = append(b, ':')
+	return fmtlite.AppendPadded(b, uint64(t.Minute()), 2, '0')
 }
 
 func (g *Gotogen) updateStatus(updateIdleStatus bool) {
@@ -327,22 +749,27 @@ func (g *Gotogen) updateStatus(updateIdleStatus bool) {
 			break
 		}
 		// any button press clears the boot log
-		if g.driver.PressedButton() != MenuButtonNone {
+		if g.pressedButton() != MenuButtonNone {
 			g.changeStatusState(statusStateIdle)
 		}
 	case statusStateIdle:
-		but := g.driver.PressedButton()
+		g.checkBindings()
+		but := g.pressedButton()
 		switch but {
 		case MenuButtonBack:
 			if g.faceState != faceStateDefault {
-				g.faceState = faceStateDefault
-				g.statusForceUpdate = true
-				f.Activate(g)
-				g.activeAnim = f
+				g.returnToFace()
 			}
 		case MenuButtonMenu:
 			g.changeStatusState(statusStateMenu)
+		case MenuButtonNextFace:
+			g.nextFace()
+		case MenuButtonUp:
+			g.changeStatusPage(-1)
+		case MenuButtonDown:
+			g.changeStatusPage(1)
 		default:
+			g.rotateStatusPage()
 			if updateIdleStatus {
 				g.drawIdleStatus()
 			}
@@ -353,7 +780,17 @@ func (g *Gotogen) updateStatus(updateIdleStatus bool) {
 			break
 		}
 
-		switch g.driver.PressedButton() {
+		if g.help.pending {
+			g.checkHelpPress()
+			break
+		}
+
+		but := g.pressedButton()
+		if _, ok := g.activeMenu.(*SettingItem); ok {
+			but = g.repeatedButton(but)
+		}
+
+		switch but {
 		case MenuButtonBack:
 			g.statusStateChange = time.Now()
 			if g.activeMenu.Prev() == nil {
@@ -363,37 +800,14 @@ func (g *Gotogen) updateStatus(updateIdleStatus bool) {
 				m := g.activeMenu
 				g.activeMenu = g.activeMenu.Prev()
 				m.SetPrev(nil)
-				g.activeMenu.Render(g.statusText)
+				// the menu may have changed while a submenu was open
+				clampMenu(g.activeMenu, g.menuRenderer.Rows())
+				g.activeMenu.Render(g.menuRenderer)
 			}
 		case MenuButtonMenu:
 			g.statusStateChange = time.Now()
-			switch active := g.activeMenu.(type) {
-			case *Menu:
-				// in case a menu is empty for some reason
-				if len(active.Items) == 0 || int(active.selected) > len(active.Items) {
-					break
-				}
-				switch item := active.Items[active.selected].(type) {
-				case *Menu:
-					item.prev, g.activeMenu = g.activeMenu, item
-					item.Render(g.statusText)
-				case *ActionItem:
-					item.Invoke()
-				case *SettingItem:
-					item.prev, g.activeMenu = g.activeMenu, item
-					item.selected = item.Active
-					_, h := g.statusText.Size()
-					if item.selected > item.top+uint8(h)-2 {
-						// TODO avoid empty lines at the bottom?
-						item.top = item.selected
-					}
-					item.Render(g.statusText)
-				}
-			case *SettingItem:
-				active.Active = active.selected
-				active.Apply(active.selected)
-				g.activeMenu, active.prev = active.prev, nil
-				g.activeMenu.Render(g.statusText)
+			if !g.startHelpPress() {
+				g.menuSelect()
 			}
 		case MenuButtonUp:
 			g.statusStateChange = time.Now()
@@ -403,29 +817,103 @@ func (g *Gotogen) updateStatus(updateIdleStatus bool) {
 			if g.activeMenu.Selected() < g.activeMenu.Top() {
 				g.activeMenu.SetTop(g.activeMenu.Selected())
 			}
-			g.activeMenu.Render(g.statusText)
+			g.activeMenu.Render(g.menuRenderer)
 		case MenuButtonDown:
 			g.statusStateChange = time.Now()
-			g.activeMenu.SetSelected(g.activeMenu.Selected() + 1)
-			if g.activeMenu.Selected() > g.activeMenu.Len()-1 {
-				g.activeMenu.SetSelected(g.activeMenu.Len() - 1)
+			if g.activeMenu.Selected()+1 < g.activeMenu.Len() {
+				g.activeMenu.SetSelected(g.activeMenu.Selected() + 1)
 			}
-			_, h := g.statusText.Size()
-			if g.activeMenu.Selected() > g.activeMenu.Top()+uint8(h)-2 {
+			if g.activeMenu.Selected() > g.activeMenu.Top()+g.menuRenderer.Rows()-1 {
 				g.activeMenu.SetTop(g.activeMenu.Top() + 1)
 			}
-			g.activeMenu.Render(g.statusText)
+			g.activeMenu.Render(g.menuRenderer)
 		}
 	case statusStateBlank:
-		if g.driver.PressedButton() != MenuButtonNone {
+		if g.pressedButton() != MenuButtonNone {
 			g.changeStatusState(statusStateIdle)
 		}
+	case statusStateRemap:
+		g.updateRemap()
+	case statusStateEditor:
+		g.updateArt()
+	case statusStateCaption:
+		g.updateCaption()
+	case statusStateProfile:
+		g.updateProfile()
+	case statusStateHelp:
+		g.updateHelp()
+	case statusStateCurve:
+		g.updateCurve()
+	case statusStatePrompter:
+		g.updatePrompter()
+	case statusStatePreview:
+		g.updatePreviewAlign()
+	case statusStateQR:
+		g.updateQRCode()
+	}
+}
+
+// menuSelect acts on the Menu button in the menu: entering the highlighted submenu or setting, invoking the highlighted
+// action, or choosing the highlighted option of a setting.
+func (g *Gotogen) menuSelect() {
+	switch active := g.activeMenu.(type) {
+	case *Menu:
+		// in case a menu is empty for some reason
+		if len(active.Items) == 0 || int(active.selected) >= len(active.Items) {
+			break
+		}
+		switch item := active.Items[active.selected].(type) {
+		case *Menu:
+			item.prev, g.activeMenu = g.activeMenu, item
+			clampMenu(item, g.menuRenderer.Rows())
+			item.Render(g.menuRenderer)
+		case *ActionItem:
+			if item.Invoke != nil {
+				item.Invoke()
+			}
+		case *SettingItem:
+			if len(item.Options) == 0 {
+				break
+			}
+			item.prev, g.activeMenu = g.activeMenu, item
+			item.selected = item.Active
+			if item.top > item.selected || item.selected > item.top+g.menuRenderer.Rows()-1 {
+				// TODO avoid empty lines at the bottom?
+				item.top = item.selected
+			}
+			clampMenu(item, g.menuRenderer.Rows())
+			item.Render(g.menuRenderer)
+		}
+	case *SettingItem:
+		prev := active.prev
+		active.prev = nil
+		if active.selected < active.Len() {
+			active.Active = active.selected
+			if active.Apply != nil {
+				active.Apply(active.selected)
+			}
+		}
+		if g.statusState != statusStateMenu {
+			// applying it left the menu
+			return
+		}
+		g.activeMenu = prev
+		// applying it may have changed how big the menu is drawn
+		clampMenu(g.activeMenu, g.menuRenderer.Rows())
+		g.activeMenu.Render(g.menuRenderer)
 	}
 }
 
 func (g *Gotogen) clearStatusScreen() {
 	// clear text buffer
 	g.statusText.Clear()
+	for i := range g.idleLines {
+		g.idleLines[i].invalidate()
+	}
+	for i := range g.previewLines {
+		g.previewLines[i].invalidate()
+	}
+	g.statusDirty = true
 	// but make sure we clear the *entire* screen, including pixels outside the coverage of the text buffer
 	w, h := g.statusDisplay.Size()
 	for x := int16(0); x < w; x++ {
@@ -437,7 +925,7 @@ func (g *Gotogen) clearStatusScreen() {
 }
 
 func (g *Gotogen) changeStatusState(state statusState) {
-	println("changing to status state", state.String())
+	g.log(LogDebug, "changing to status state "+state.String())
 	g.activeMenu = nil
 	g.statusState = state
 	g.statusStateChange = time.Now()
@@ -445,31 +933,91 @@ func (g *Gotogen) changeStatusState(state statusState) {
 
 	switch state {
 	case statusStateIdle:
+		if len(g.captions) > 0 {
+			// captions (or error banners) that arrived while the status screen was busy with something else
+			g.changeStatusState(statusStateCaption)
+			g.nextCaption()
+			return
+		}
 		g.drawIdleStatus()
-	case statusStateBlank:
+		// the face preview on the idle screen was just cleared, so make sure the face redraws all of it
+		if g.faceState == faceStateDefault {
+			f.Invalidate()
+			g.vectorFace.Invalidate()
+		}
+	case statusStateBlank, statusStateRemap, statusStateEditor, statusStateCaption, statusStateHelp, statusStateCurve,
+		statusStatePrompter, statusStatePreview, statusStateQR:
 		// nothing special to do
+	case statusStateProfile:
+		g.drawProfile()
 	case statusStateMenu:
 		// hardware submenu is required to be the first item in the menu
 		m := g.rootMenu.Items[0].(*Menu)
-		m.Items = g.driver.MenuItems()
+		m.Items = append(g.driver.MenuItems(), g.orientationItems()...)
+		g.refreshPrompterMenu()
+		g.refreshBehaviorMenu()
+		g.refreshQRMenu()
 		g.activeMenu = &g.rootMenu
-		g.rootMenu.Render(g.statusText)
+		g.rootMenu.Render(g.menuRenderer)
 	}
 }
 
 func (g *Gotogen) startAnimation(a animation.Animation) {
+	g.startTransition()
 	g.faceState = faceStateAnimation
+	g.rawFace = rawHinted(a)
 	a.Activate(g)
 	g.activeAnim = a
+	g.animKind, g.animFile = "", ""
+	g.onComplete, g.animUntil = nil, time.Time{}
+	g.animStart = time.Now()
+	g.setNoPreview(previewHinted(a))
+}
+
+// returnToFace puts the default face back, ending any animation without completing it.
+func (g *Gotogen) returnToFace() {
+	if g.faceState != faceStateDefault {
+		g.startTransition()
+	}
+	g.cutToFace()
+}
+
+// cutToFace goes back to the default face straight away, without a transition.
+func (g *Gotogen) cutToFace() {
+	g.faceState = faceStateDefault
+	g.statusForceUpdate = true
+	def := g.defaultFace()
+	g.rawFace = false
+	def.Activate(g)
+	g.activeAnim = def
+	g.onComplete, g.animUntil = nil, time.Time{}
+	g.setNoPreview(previewHinted(def))
+}
+
+// finishAnimation is called when the animation on the face has run its course. The face is put back first, so that
+// the animation's OnComplete hook can start another one.
+func (g *Gotogen) finishAnimation() {
+	done := g.onComplete
+	g.returnToFace()
+	if done != nil {
+		done()
+	}
 }
 
 // unfortunately you can't recover runtime panics in tinygo, so this is just going to be used for things we detect
 // that are fatal
-func (g *Gotogen) panic(v any) {
-	println(v)
+func (g *Gotogen) panic(err error) {
+	msg := errorText(err)
+	g.log(LogError, msg)
+	g.showCrash(err)
+	// SOS is easy to recognize from the outside, even when the status display isn't visible
+	g.setLEDColor(ledStateError)
+	g.Blink(MorsePattern("SOS"), true)
 	for {
-		println(v)
-		g.blink()
+		// repeated for a console attached afterwards, but not into the boot log
+		g.logger.Log(msg)
+		g.updateBlinker()
+		time.Sleep(blinkUnit / 2)
 	}
 }
 
@@ -492,41 +1040,84 @@ func (g *Gotogen) blinkerOff() {
 	}
 }
 
-func (g *Gotogen) newAnimation(file string, f func(string) (animation.Animation, error)) {
-	a, err := f(file)
+// newAnimation starts a full-screen animation. If it can't be started, whatever was on the face before stays there.
+func (g *Gotogen) newAnimation(file string, k animationKind) error {
+	before := allocated()
+	a, err := k.new(file)
+	loaded := err == nil
+	if err != nil {
+		// show something rather than nothing, and let the wearer know why
+		g.log(LogWarning, "loading animation "+file+": "+errorText(err))
+		g.setWarning("missing " + file)
+		a = static.FromImage(media.Placeholder(media.TypeFull, file))
+	}
+	err = g.checkAnimBudget(file, allocated()-before)
 	if err != nil {
-		g.panic(err)
+		return err
 	}
 	g.startAnimation(a)
+	g.animKind, g.animFile = k.name, file
+	if loaded {
+		g.showCredits(mediaRef{media.TypeFull, file})
+	}
 	// TODO exit the menu?
+	return nil
 }
 
 func (g *Gotogen) initMainMenu() {
 	imgs, err := media.Enumerate(media.TypeFull)
 	if err != nil {
-		g.panic("enumerating images for animations: " + err.Error())
+		// the rest of the menu is still useful without them
+		g.reportError(errors.New("enumerating images for animations: " + err.Error()))
 	}
+	seqs := media.Sequences(imgs)
+	// the frames of a sequence are only offered together, as the sequence
+	imgs = withoutSequenceFrames(imgs, seqs)
+	g.faceImages = imgs
 	var anims []Item
 	for _, i := range imgs {
 		f := i
+		var items []Item
+		for _, k := range animationKinds {
+			if k.sequence {
+				continue
+			}
+			kind := k
+			items = append(items, &ActionItem{
+				Name:   k.name,
+				Invoke: func() { g.reportError(g.StartAnimationByName(kind.name, f)) },
+			})
+		}
 		anims = append(anims, &Menu{
-			Name: i,
-			Items: []Item{
-				&ActionItem{
-					Name:   "Static",
-					Invoke: func() { g.newAnimation(f, static.New) },
-				},
-				&ActionItem{
-					Name:   "Slide",
-					Invoke: func() { g.newAnimation(f, slide.New) },
-				},
-				&ActionItem{
-					Name:   "Peek",
-					Invoke: func() { g.newAnimation(f, peek.New) },
-				},
-			},
+			Name:  i,
+			Items: items,
 		})
 	}
+	for _, s := range seqs {
+		seq := s
+		var items []Item
+		for _, k := range animationKinds {
+			if !k.sequence {
+				continue
+			}
+			kind := k
+			items = append(items, &ActionItem{
+				Name:   k.name,
+				Invoke: func() { g.reportError(g.StartAnimationByName(kind.name, seq)) },
+			})
+		}
+		anims = append(anims, &Menu{
+			Name:  seq,
+			Items: items,
+		})
+	}
+	anims = append(anims, g.transitionSetting(), &SettingItem{
+		Name:    "Memory budget",
+		Help:    "Full-screen animations that need more memory than this to load are refused, instead of risking running out.",
+		Options: animBudgetNames(),
+		Active:  0,
+		Apply:   g.setAnimBudget,
+	})
 
 	g.rootMenu = Menu{
 		Name: "GOTOGEN MENU",
@@ -539,6 +1130,56 @@ func (g *Gotogen) initMainMenu() {
 				Name:  "Full-screen anims.",
 				Items: anims,
 			},
+			g.dndSetting(),
+			g.exprLockSetting(),
+			g.presetsMenu(),
+			g.expressionsMenu(),
+			g.vectorFaceMenu(),
+			g.macroMenu(),
+			g.rulesMenu(),
+			g.effectsMenu(),
+			g.captionsMenu(),
+			g.qrMenu(),
+			g.prompterMenu(),
+			g.clockMenu(),
+			g.alarmsMenu(),
+			g.screenshotMenu(),
+			g.panelMenu(),
+			&Menu{
+				Name: "Power",
+				Items: []Item{
+					&SettingItem{
+						Name:    "Power saving",
+						Help:    "Lowers the framerate, and then turns off effects, as the battery runs down.",
+						Options: []string{"off", "gentle", "aggressive"},
+						Active:  1,
+						Apply:   g.setPowerPolicy,
+					},
+					&SettingItem{
+						Name:    "Framerate",
+						Help:    "How many times a second the face is redrawn. Power saving may lower it further.",
+						Options: framerateNames(g.baseFramerate),
+						Active:  0,
+						Apply:   g.setFramerate,
+					},
+					&SettingItem{
+						Name:    "Sensor log",
+						Help:    "How often to log battery, temperature, framerate, and boops and movement to storage, for looking at after an event.",
+						Options: []string{"off", "10s", "1m", "5m"},
+						Active:  g.sensorLogActive(),
+						Apply:   g.setSensorLog,
+					},
+					&SettingItem{
+						Name:    "Heartbeat",
+						Help:    "How often to send a short line with the battery, temperature, framerate and expression, for a handler's receiver to show.",
+						Options: []string{"off", "1s", "5s", "30s"},
+						Active:  g.heartbeatActive(),
+						Apply:   g.setHeartbeat,
+					},
+				},
+			},
+			g.ledMenu(),
+			g.buttonsMenu(),
 			&Menu{
 				Name: "Internal screen",
 				Items: []Item{
@@ -546,28 +1187,100 @@ func (g *Gotogen) initMainMenu() {
 						Name:   "Blank screen",
 						Invoke: func() { g.changeStatusState(statusStateBlank) },
 					},
+					&ActionItem{
+						Name:   "Profiling",
+						Invoke: func() { g.changeStatusState(statusStateProfile) },
+					},
+					&ActionItem{
+						Name:   "Driver calls",
+						Help:   "How long the driver's buttons, boop sensor, accelerometer and talking checks take. Slow ones hold up every frame.",
+						Invoke: g.showDriverCalls,
+					},
+					&ActionItem{
+						Name:   "About",
+						Help:   "What this build supports. Features that need something it doesn't have won't do anything.",
+						Invoke: g.showAbout,
+					},
+					&ActionItem{
+						Name:   "Boot log",
+						Help:   "The last few things logged since booting, oldest first, with how many seconds after booting they happened. Problems are marked with a !.",
+						Invoke: g.showLog,
+					},
+					&ActionItem{
+						Name:   "Boop stats",
+						Invoke: g.showBoopStats,
+					},
 					&SettingItem{
 						Name:    "Frame skip",
-						Options: []string{"0", "1", "2", "4", "8", "16"},
+						Help:    "How many frames to skip between status screen updates. Auto skips more when the face is falling behind.",
+						Options: []string{"auto", "0", "1", "2", "4", "8", "16"},
 						Active:  0, // TODO load from setting storage
 						Apply:   g.setStatusFrameSkip,
 					},
 					&SettingItem{
 						Name:    "Face dupl. color",
-						Options: []string{"full", "red", "green", "blue"},
+						Help:    "How the face is turned into black and white for the copy on the status screen: one color channel, the brightness (luma), or the brightness dithered.",
+						Options: []string{"full", "red", "green", "blue", "luma", "dither"},
 						Active:  1,
 						Apply:   g.setStatusDuplicateColor,
 					},
 					&SettingItem{
 						Name:    "Face dupl. cutoff",
+						Help:    "How bright a face pixel has to be to show up in the copy of the face on the status screen.",
 						Options: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "A", "B", "D", "E", "F"},
 						Active:  9,
 						Apply:   g.setStatusDuplicateCutoff,
 					},
+					&ActionItem{
+						Name:   "Align preview",
+						Help:   "Moves the copy of the face on the status screen, or shrinks it, to fit the screen.",
+						Invoke: g.alignPreview,
+					},
+					g.largePrintMenuSetting(),
+					&SettingItem{
+						Name:    "Page rotation",
+						Help:    "How long to show each page of the idle screen before moving to the next. Up and Down change pages too.",
+						Options: []string{"off", "5s", "10s", "30s"},
+						Active:  g.statusPageRotateActive(),
+						Apply:   g.setStatusPageRotate,
+					},
+					&SettingItem{
+						Name:    "Theme",
+						Options: themeNames(),
+						Active:  0,
+						Apply:   g.setTheme,
+					},
+					&SettingItem{
+						Name:    "Hold repeat delay",
+						Options: []string{"off", "250ms", "400ms", "600ms"},
+						Active:  2,
+						Apply:   g.setRepeatDelay,
+					},
+					&SettingItem{
+						Name:    "Hold repeat accel.",
+						Options: []string{"none", "slow", "fast"},
+						Active:  1,
+						Apply:   g.setRepeatAccel,
+					},
 				},
 			},
 		},
 	}
+	if ch := g.characterMenuSetting(); ch != nil {
+		g.rootMenu.Items = append(g.rootMenu.Items, ch)
+	}
+	if boop := g.boopMenu(); boop != nil {
+		g.rootMenu.Items = append(g.rootMenu.Items, boop)
+	}
+	if art := g.artMenu(); art != nil {
+		g.rootMenu.Items = append(g.rootMenu.Items, art)
+	}
+	if peer := g.peerMenu(); peer != nil {
+		g.rootMenu.Items = append(g.rootMenu.Items, peer)
+	}
+	if sim := g.simMenu(); sim != nil {
+		g.rootMenu.Items = append(g.rootMenu.Items, sim)
+	}
 }
 
 func (g *Gotogen) setStatusDuplicateCutoff(selected uint8) {
@@ -579,27 +1292,34 @@ func (g *Gotogen) setStatusDuplicateColor(selected uint8) {
 }
 
 func (g *Gotogen) setStatusFrameSkip(selected uint8) {
-	if selected == 0 {
+	g.statusAutoSkip = selected == 0
+	switch selected {
+	case 0:
+		// start from no skip and let the adaptive redraw work it out from there
 		g.statusFrameSkip = 0
-	} else {
-		g.statusFrameSkip = 1 << (selected - 1)
+		g.statusTiming.next = time.Time{}
+	case 1:
+		g.statusFrameSkip = 0
+	default:
+		g.statusFrameSkip = 1 << (selected - 2)
 	}
 }
 
-func (g *Gotogen) Busy(f func(buffer *textbuf.Buffer)) {
+// Busy shows the busy image on the face and runs f, which may report what it is doing on the status screen. The
+// messages are left up for a few seconds (or until a button is pressed) after f returns.
+//
+// Busy must be called from the same goroutine as RunTick; other goroutines can use Do.
+func (g *Gotogen) Busy(f func(boot BootReporter)) {
+	g.owner.check()
 	g.statusText.AutoFlush = true
 	g.statusText.Clear()
 
-	err := g.busy()
-	if err != nil {
-		print("unable to load busy", err)
-		_ = g.statusText.PrintlnInverse("loading busy: " + err.Error())
-	}
-	f(g.statusText)
+	g.busy()
+	f(textBootReporter{buf: g.statusText, g: g})
 
 	s := time.Now()
 	for time.Now().Before(s.Add(5 * time.Second)) {
-		if g.driver.PressedButton() != MenuButtonNone {
+		if g.pressedButton() != MenuButtonNone {
 			break
 		}
 	}
@@ -608,18 +1328,17 @@ func (g *Gotogen) Busy(f func(buffer *textbuf.Buffer)) {
 	g.changeStatusState(statusStateIdle)
 }
 
-func (g *Gotogen) busy() error {
+func (g *Gotogen) busy() {
 	g.faceState = faceStateBusy
 
 	busy, err := static.New("wait")
 	if err != nil {
-		return errors.New("load busy: " + err.Error())
+		g.log(LogWarning, "loading busy image: "+errorText(err))
+		busy = static.FromImage(media.Placeholder(media.TypeFull, "wait"))
 	}
 	busy.Activate(g.faceMirror)
 	_ = g.faceDisplay.Display()
 	g.activeAnim = busy
-
-	return nil
 }
 
 func (g *Gotogen) Size() (x, y int16) {
@@ -632,39 +1351,54 @@ func (g *Gotogen) Display() error {
 }
 
 func (g *Gotogen) SetPixel(x, y int16, c color.RGBA) {
-	g.faceMirror.SetPixel(x, y, c)
-	if g.statusForceUpdate || (g.statusState == statusStateIdle && (g.statusFrameSkip == 0 || uint8(g.tick)%g.statusFrameSkip == 0 && g.statusDisplay.CanUpdateNow())) {
-		switch g.statusDownmixChannel {
-		case colorChannelRed:
-			if c.R < g.statusDownmixCutoff {
-				c.R = 0
-			} else {
-				c.R = 0xFF
-			}
-			c.G = 0
-			c.B = 0
-		case colorChannelGreen:
-			if c.G < g.statusDownmixCutoff {
-				c.G = 0
-			} else {
-				c.G = 0xFF
-			}
-			c.R = 0
-			c.B = 0
-		case colorChannelBlue:
-			if c.B < g.statusDownmixCutoff {
-				c.B = 0
-			} else {
-				c.B = 0xFF
-			}
-			c.R = 0
-			c.G = 0
-		}
-		// TODO remove hardcoded offset
-		g.statusMirror.SetPixel(x, y+32, c)
+	g.frame.SetPixel(x, y, c)
+	// while an effect or a transition is running, the whole frame is redrawn through it after the animation is done
+	// drawing
+	if g.effect == nil && !g.transitioning() {
+		g.outputPixel(x, y, c)
+	}
+}
+
+// outputPixel sends a pixel to the face display, and the preview of the face on the status display.
+func (g *Gotogen) outputPixel(x, y int16, c color.RGBA) {
+	raw := g.rawFace
+	if g.colorScaled && !raw {
+		c = g.scaleColor(c)
+	}
+	if g.paletteRemap != nil && !raw {
+		c = g.paletteRemap(c)
+	}
+	if g.panelColors != nil && !raw {
+		c = g.constrainColor(x, y, c)
+	}
+	if g.curve.active && !raw {
+		// only the face panels need correcting, not the preview of the face
+		g.faceMirror.SetPixel(x, y, g.curve.apply(c))
+	} else {
+		g.faceMirror.SetPixel(x, y, c)
+	}
+	if g.headless {
+		return
+	}
+	if g.noPreview {
+		return
+	}
+	if g.statusForceUpdate || ((g.statusState == statusStateIdle || g.statusState == statusStatePreview) && (g.statusFrameSkip == 0 || uint8(g.tick)%g.statusFrameSkip == 0 && g.statusDisplay.CanUpdateNow())) {
+		c = g.downmix(x, y, c)
+		g.previewPixel(x, y, c)
+		g.statusDirty = true
 	}
 }
 
 func (g *Gotogen) Talking() bool {
-	return g.driver.Talking()
+	if t, ok := g.simTalking(); ok {
+		return t
+	}
+	if g.micMuted {
+		return false
+	}
+	start := time.Now()
+	t := g.driver.Talking()
+	g.timeDriverCall(driverCallTalking, start)
+	return t
 }
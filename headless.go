@@ -0,0 +1,25 @@
+package gotogen
+
+import (
+	"image/color"
+)
+
+// headlessDisplay stands in for the status display on builds that don't have one, like a chest badge that only
+// renders animations. It is big enough to satisfy the status screen's size requirements, and discards everything.
+//
+// Using a stand-in rather than checking for a nil status display everywhere means that the menu system still works as
+// normal, so it can still be driven blind or by remote control.
+type headlessDisplay struct{}
+
+func (headlessDisplay) Size() (x, y int16) { return 128, 64 }
+
+func (headlessDisplay) SetPixel(int16, int16, color.RGBA) {}
+
+func (headlessDisplay) Display() error { return nil }
+
+func (headlessDisplay) CanUpdateNow() bool { return true }
+
+// Headless returns whether this Gotogen is running without a status display.
+func (g *Gotogen) Headless() bool {
+	return g.headless
+}
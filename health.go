@@ -0,0 +1,162 @@
+package gotogen
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+const (
+	// faceResetThreshold is how many consecutive face display errors are tolerated before trying to reset the panels.
+	faceResetThreshold = 5
+	// displayRetryMin and displayRetryMax bound how long to wait before trying a display again after an error. The wait
+	// doubles with each consecutive error, so a flaky bus isn't hammered while it recovers.
+	displayRetryMin = 20 * time.Millisecond
+	displayRetryMax = 2 * time.Second
+)
+
+// Resetter is an optional interface that a Display may implement to allow Gotogen to re-initialize the hardware
+// after repeated errors (e.g. a glitch on the SPI bus leaving a panel in a bad state).
+type Resetter interface {
+	// Reset re-initializes the display hardware. The contents of the display do not need to be preserved.
+	Reset() error
+}
+
+// displayHealth tracks errors from a display so that transient glitches don't take down the whole unit.
+type displayHealth struct {
+	// consecutive errors since the last successful update
+	consecutive uint16
+	// total errors since boot
+	total uint32
+	// resets attempted since boot
+	resets uint16
+	// how long to wait after the last error, and when the display can be tried again
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// ready returns whether the display can be tried now, or is still backing off after an error.
+func (h *displayHealth) ready(now time.Time) bool {
+	return h.consecutive == 0 || !now.Before(h.retryAt)
+}
+
+// failed records an error, and backs off before the next try.
+func (h *displayHealth) failed(now time.Time) {
+	h.consecutive++
+	h.total++
+	h.backoff *= 2
+	if h.backoff < displayRetryMin {
+		h.backoff = displayRetryMin
+	}
+	if h.backoff > displayRetryMax {
+		h.backoff = displayRetryMax
+	}
+	h.retryAt = now.Add(h.backoff)
+}
+
+// succeeded records a successful update, and returns how many errors in a row there were before it.
+func (h *displayHealth) succeeded() uint16 {
+	n := h.consecutive
+	h.consecutive, h.backoff = 0, 0
+	return n
+}
+
+// flushFace sends the face to the display. If both the display and the active animation support it, only the parts
+// of the face that changed are sent, unless an effect or transition is drawing over all of it.
+func (g *Gotogen) flushFace() error {
+	ra, ok := g.activeAnim.(animation.Regioned)
+	if !ok || g.effect != nil || g.transitioning() || g.fullFlush {
+		g.fullFlush = false
+		return g.faceDisplay.Display()
+	}
+	if _, ok := g.faceDisplay.(PartialDisplay); !ok {
+		return g.faceDisplay.Display()
+	}
+	pd := g.faceMirror.(PartialDisplay)
+	for _, r := range ra.DirtyRegions() {
+		err := pd.DisplayRegion(r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// faceDisplayed is called with the result of every attempt to update the face display.
+func (g *Gotogen) faceDisplayed(err error) {
+	h := &g.faceHealth
+	if err == nil {
+		if n := h.succeeded(); n > 0 {
+			g.log(LogInfo, "face display recovered after "+strconv.Itoa(int(n))+" errors")
+			g.setWarning("")
+		}
+		return
+	}
+
+	h.failed(time.Now())
+	// whatever was skipped while backing off has to be sent when it works again
+	g.fullFlush = true
+	g.log(LogWarning, errcode.Wrap(errcode.DisplayFace, err).Error())
+	if h.consecutive%faceResetThreshold != 0 {
+		return
+	}
+
+	r, ok := g.faceDisplay.(Resetter)
+	if !ok {
+		g.setWarning("Face err x" + strconv.Itoa(int(h.consecutive)))
+		return
+	}
+
+	h.resets++
+	g.log(LogWarning, "resetting face display, attempt "+strconv.Itoa(int(h.resets)))
+	err = r.Reset()
+	if err != nil {
+		g.log(LogError, errcode.Context("face reset", errcode.Wrap(errcode.DisplayReset, err)).Error())
+		g.setWarning("Face reset failed")
+		return
+	}
+	g.setWarning("Face reset x" + strconv.Itoa(int(h.resets)))
+
+	// the panels lost whatever was on them, so have the animation draw everything again
+	g.activeAnim.Activate(g)
+}
+
+// statusDisplayed is called with the result of every attempt to update the status display. Errors are retried with a
+// backoff, and the display is reset if it keeps failing, rather than stopping everything over a glitch; the face is
+// more important than the status screen.
+func (g *Gotogen) statusDisplayed(err error) {
+	h := &g.statusHealth
+	if err == nil {
+		if n := h.succeeded(); n > 0 {
+			g.log(LogInfo, "status display recovered after "+strconv.Itoa(int(n))+" errors")
+		}
+		g.statusDirty = false
+		return
+	}
+
+	h.failed(time.Now())
+	g.log(LogWarning, errcode.Wrap(errcode.DisplayStatus, err).Error())
+	if h.consecutive%faceResetThreshold != 0 {
+		return
+	}
+	r, ok := g.statusDisplay.(Resetter)
+	if !ok {
+		return
+	}
+	h.resets++
+	g.log(LogWarning, "resetting status display, attempt "+strconv.Itoa(int(h.resets)))
+	if err = r.Reset(); err != nil {
+		g.log(LogError, errcode.Context("status reset", errcode.Wrap(errcode.DisplayReset, err)).Error())
+		return
+	}
+	// the display lost whatever was on it
+	g.clearStatusScreen()
+	g.statusForceUpdate = true
+}
+
+// setWarning shows a warning on the idle status screen, or clears it if msg is empty.
+func (g *Gotogen) setWarning(msg string) {
+	g.warning = msg
+}
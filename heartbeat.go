@@ -0,0 +1,119 @@
+package gotogen
+
+import (
+	"time"
+
+	"github.com/ajanata/gotogen/internal/fmtlite"
+)
+
+const heartbeatSetting = "heartbeat"
+
+// heartbeatIntervals are how often a heartbeat is sent for each Heartbeat setting. The first is off.
+var heartbeatIntervals = [...]time.Duration{0, time.Second, 5 * time.Second, 30 * time.Second}
+
+// HeartbeatSink is an optional interface that a Driver may implement to send a short status line every few seconds,
+// e.g. over a radio or a serial port, for a cheap receiver to show to a handler. It has nothing to do with the command
+// protocol: nothing is sent back, and the receiver doesn't have to understand anything else.
+//
+// Each line is "HB SEQ BATTERY TEMP FPS EXPRESSION", separated by single spaces: SEQ counts up from 0 so that missed
+// lines can be noticed, BATTERY is percent, TEMP is tenths of a degree Celsius, FPS is the last second's framerate,
+// and EXPRESSION is the name of the face's expression. Anything that isn't known is "-".
+type HeartbeatSink interface {
+	// SendHeartbeat sends a line, which doesn't end in a newline. It must not block, and must not keep the slice.
+	SendHeartbeat(line []byte)
+}
+
+// heartbeat is the state of the heartbeat.
+type heartbeat struct {
+	interval time.Duration
+	next     time.Time
+	seq      uint32
+	// buf is reused for every line, so sending doesn't make garbage
+	buf []byte
+}
+
+// updateHeartbeat sends a heartbeat when one is due.
+func (g *Gotogen) updateHeartbeat() {
+	h := &g.heartbeat
+	if h.interval == 0 {
+		return
+	}
+	now := time.Now()
+	if now.Before(h.next) {
+		return
+	}
+	h.next = now.Add(h.interval)
+	hs, ok := g.driver.(HeartbeatSink)
+	if !ok {
+		return
+	}
+
+	b := append(h.buf[:0], "HB "...)
+	b = fmtlite.AppendUint(b, uint64(h.seq))
+	b = append(b, ' ')
+	if g.hasBattery {
+		b = fmtlite.AppendUint(b, uint64(g.battery))
+	} else {
+		b = append(b, '-')
+	}
+	b = append(b, ' ')
+	b = appendHeartbeatTemp(b, g.driver)
+	b = append(b, ' ')
+	b = fmtlite.AppendUint(b, uint64(g.lastFPS))
+	b = append(b, ' ')
+	if e := g.currentExpression(); e != "" {
+		b = append(b, e...)
+	} else {
+		b = append(b, '-')
+	}
+	h.buf = b
+	h.seq++
+	hs.SendHeartbeat(b)
+}
+
+// appendHeartbeatTemp appends the temperature in tenths of a degree, or "-" if the driver can't measure it.
+func appendHeartbeatTemp(b []byte, d Driver) []byte {
+	if ts, ok := d.(TemperatureSensor); ok {
+		if mc, st := ts.Temperature(); st == SensorStatusAvailable {
+			return fmtlite.AppendInt(b, int64(mc/100))
+		}
+	}
+	return append(b, '-')
+}
+
+// currentExpression is the name of the expression on whichever face style is showing, or empty if the face has been
+// put together from parts that aren't an expression.
+func (g *Gotogen) currentExpression() string {
+	if g.faceStyle == faceStyleVector && g.vectorFace != nil {
+		return g.vectorFace.Expression()
+	}
+	if f == nil {
+		return ""
+	}
+	return f.Expression()
+}
+
+func (g *Gotogen) loadHeartbeat() {
+	b, ok := g.loadSetting(heartbeatSetting)
+	if ok && len(b) == 1 && int(b[0]) < len(heartbeatIntervals) {
+		g.setHeartbeat(b[0])
+	}
+}
+
+func (g *Gotogen) heartbeatActive() uint8 {
+	for i, d := range heartbeatIntervals {
+		if d == g.heartbeat.interval {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+func (g *Gotogen) setHeartbeat(selected uint8) {
+	if int(selected) >= len(heartbeatIntervals) {
+		return
+	}
+	g.heartbeat.interval = heartbeatIntervals[selected]
+	g.heartbeat.next = time.Now()
+	g.saveSetting(heartbeatSetting, []byte{selected})
+}
@@ -0,0 +1,126 @@
+package gotogen
+
+import (
+	"time"
+)
+
+// helpPage is the scrollable help text for a menu item, shown by long-pressing Menu on it. Other pages of text opened
+// from the menu, like About, use it too.
+type helpPage struct {
+	// pending is set while Menu is held on an item with help, until it is either released (select the item as usual)
+	// or held long enough to show the help.
+	pending bool
+	since   time.Time
+	// the menu to go back to afterwards
+	menu  Menuable
+	title string
+	lines []string
+	top   int
+}
+
+// startHelpPress is called when Menu is pressed in the menu. If the highlighted item has help, and the driver can
+// tell us when the button is released, acting on the press is held off until we know whether it is a long-press.
+func (g *Gotogen) startHelpPress() bool {
+	if _, ok := g.driver.(ButtonStateDriver); !ok {
+		return false
+	}
+	m, ok := g.activeMenu.(*Menu)
+	if !ok || int(m.selected) >= len(m.Items) || m.Items[m.selected].help() == "" {
+		return false
+	}
+	g.help.pending = true
+	g.help.since = time.Now()
+	return true
+}
+
+// checkHelpPress is called every frame while waiting to see if Menu is being long-pressed.
+func (g *Gotogen) checkHelpPress() {
+	// the driver may repeat the press while it's held, which doesn't mean anything here
+	g.pressedButton()
+	held := g.buttonMap.applySet(g.driver.(ButtonStateDriver).ButtonState())
+	switch {
+	case !held.Has(MenuButtonMenu):
+		g.help.pending = false
+		g.menuSelect()
+	case time.Since(g.help.since) >= longPressTime:
+		g.help.pending = false
+		g.showHelp()
+	}
+}
+
+// showHelp shows the help for the highlighted menu item.
+func (g *Gotogen) showHelp() {
+	m := g.activeMenu.(*Menu)
+	item := m.Items[m.selected]
+	w, _ := g.statusText.Size()
+	g.showPage(item.name(), wordWrap(item.help(), int(w)))
+}
+
+// showPage shows the lines as a scrollable page, going back to the current menu afterwards.
+func (g *Gotogen) showPage(title string, lines []string) {
+	if g.activeMenu != nil {
+		// otherwise this page is replacing another one, and goes back to the same menu it would have
+		g.help.menu = g.activeMenu
+	}
+	g.help.title = title
+	g.help.lines = lines
+	g.help.top = 0
+	g.changeStatusState(statusStateHelp)
+	g.drawHelp()
+}
+
+// drawHelp draws the visible part of the help page, with the item name as the header.
+func (g *Gotogen) drawHelp() {
+	_, h := g.statusText.Size()
+	g.statusText.Clear()
+	if g.theme.HeaderInverse {
+		_ = g.statusText.SetLineInverse(0, g.theme.HeaderPrefix, g.help.title, g.theme.HeaderSuffix)
+	} else {
+		_ = g.statusText.SetLine(0, g.theme.HeaderPrefix, g.help.title, g.theme.HeaderSuffix)
+	}
+	for i := 0; i < int(h)-1; i++ {
+		line := ""
+		if g.help.top+i < len(g.help.lines) {
+			line = g.help.lines[g.help.top+i]
+		}
+		_ = g.statusText.SetLine(int16(i+1), line)
+	}
+	g.statusDirty = true
+}
+
+// updateHelp is called every frame while a help page is shown. Up and Down scroll, and anything else goes back to the
+// menu where it was.
+func (g *Gotogen) updateHelp() {
+	if time.Now().After(g.statusStateChange.Add(menuTimeout)) {
+		g.help.menu = nil
+		g.changeStatusState(statusStateIdle)
+		return
+	}
+
+	_, h := g.statusText.Size()
+	rows := int(h) - 1
+	switch g.pressedButton() {
+	case MenuButtonNone:
+	case MenuButtonUp:
+		g.statusStateChange = time.Now()
+		if g.help.top > 0 {
+			g.help.top--
+			g.drawHelp()
+		}
+	case MenuButtonDown:
+		g.statusStateChange = time.Now()
+		if g.help.top+rows < len(g.help.lines) {
+			g.help.top++
+			g.drawHelp()
+		}
+	default:
+		// back to the top of the menu if the page wasn't opened from one
+		g.changeStatusState(statusStateMenu)
+		if g.help.menu != nil {
+			g.activeMenu = g.help.menu
+			g.help.menu = nil
+			clampMenu(g.activeMenu, g.menuRenderer.Rows())
+			g.activeMenu.Render(g.menuRenderer)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Package animated plays the frames of an animated image, like a GIF exported from Aseprite, with each frame's delay.
+package animated
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+type Anim struct {
+	frames *media.Frames
+	frame  int
+	loop   int
+	// next is when to move on to the next frame
+	next time.Time
+}
+
+func New(file string) (animation.Animation, error) {
+	frames, err := media.LoadFrames(media.TypeFull, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Anim{
+		frames: frames,
+	}, nil
+}
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	a.frame, a.loop = 0, 0
+	a.show(disp)
+}
+
+// show draws the current frame, and works out when the next one is due.
+func (a *Anim) show(disp drivers.Displayer) {
+	f := a.frames.Frames[a.frame]
+	animation.DrawImage(disp, 0, 0, f.Image, false)
+	a.next = time.Now().Add(f.Delay)
+}
+
+func (a *Anim) DrawFrame(disp drivers.Displayer, _ uint32) bool {
+	if len(a.frames.Frames) == 1 || time.Now().Before(a.next) {
+		return true
+	}
+	a.frame++
+	if a.frame == len(a.frames.Frames) {
+		a.frame = 0
+		a.loop++
+		if a.frames.Loops > 0 && a.loop >= a.frames.Loops {
+			return false
+		}
+	}
+	a.show(disp)
+	return true
+}
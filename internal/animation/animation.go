@@ -20,7 +20,63 @@ type Animation interface {
 	DrawFrame(disp drivers.Displayer, tick uint32) bool
 }
 
-// TODO register all of them for menu purposes
+// Regioned is an optional interface for animations that only draw parts of the display each frame, so that only those
+// parts need to be sent to displays that support partial updates.
+type Regioned interface {
+	// DirtyRegions returns the areas of the display that were drawn on in the last call to DrawFrame. An empty result
+	// means nothing changed.
+	DirtyRegions() []image.Rectangle
+}
+
+// NoStatusPreview is an optional hint for animations that are expensive to draw, like heavy procedural full-screen
+// animations. While it returns true, the preview of the face on the status screen isn't drawn, which saves the work of
+// downmixing every pixel; the status screen shows the animation's name and how long it has been running instead.
+type NoStatusPreview interface {
+	NoStatusPreview() bool
+}
+
+// RawOutput is an optional hint for animations that have to reach the panels exactly as they are drawn, like test
+// patterns, without the tint, brightness, palette or brightness curve.
+type RawOutput interface {
+	RawOutput() bool
+}
+
+// BoopEvent is a kind of boop, worked out from how long and how often the boop sensor is triggered.
+type BoopEvent uint8
+
+const (
+	BoopEventNone BoopEvent = iota
+	BoopEventTap
+	BoopEventDoubleTap
+	// BoopEventTripleTap is three or more taps in a row.
+	BoopEventTripleTap
+	// BoopEventLong is a boop held for a while. It happens while the boop is still held.
+	BoopEventLong
+	BoopEventCount
+)
+
+func (e BoopEvent) String() string {
+	switch e {
+	case BoopEventNone:
+		return "none"
+	case BoopEventTap:
+		return "tap"
+	case BoopEventDoubleTap:
+		return "double"
+	case BoopEventTripleTap:
+		return "triple"
+	case BoopEventLong:
+		return "long"
+	default:
+		return "INVALID"
+	}
+}
+
+// BoopListener is an optional interface for animations that react to boops, so that different kinds of boops can get
+// different reactions while the animation is running.
+type BoopListener interface {
+	Boop(e BoopEvent)
+}
 
 // DrawImage draws the image on the display at the given coordinates.
 // If wrap is true, off-screen coordinates will wrap around to the other side of the display.
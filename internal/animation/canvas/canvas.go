@@ -0,0 +1,106 @@
+package canvas
+
+import (
+	"errors"
+	"image/color"
+
+	"tinygo.org/x/drivers"
+)
+
+// Palette is the colors that can be painted on a canvas. Index 0 is always black.
+var Palette = [8]color.RGBA{
+	{A: 0xFF},
+	{R: 0xFF, A: 0xFF},
+	{R: 0xFF, G: 0x80, A: 0xFF},
+	{R: 0xFF, G: 0xFF, A: 0xFF},
+	{G: 0xFF, A: 0xFF},
+	{G: 0xFF, B: 0xFF, A: 0xFF},
+	{B: 0xFF, A: 0xFF},
+	{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+}
+
+// Anim is a paintable image, which can be shown as an animation. While editing, a blinking cursor is drawn.
+type Anim struct {
+	w, h    int16
+	pix     []uint8
+	Editing bool
+	CursorX int16
+	CursorY int16
+}
+
+func New(w, h int16) *Anim {
+	return &Anim{
+		w:   w,
+		h:   h,
+		pix: make([]uint8, int(w)*int(h)),
+	}
+}
+
+// Load restores a canvas from the output of Bytes.
+func Load(w, h int16, b []byte) (*Anim, error) {
+	a := New(w, h)
+	if len(b) != (len(a.pix)+1)/2 {
+		return nil, errors.New("wrong size for canvas")
+	}
+	for i := range a.pix {
+		v := b[i/2]
+		if i%2 == 1 {
+			v >>= 4
+		}
+		a.pix[i] = v & 0x07
+	}
+	return a, nil
+}
+
+// Bytes returns the canvas packed two pixels per byte.
+func (a *Anim) Bytes() []byte {
+	b := make([]byte, (len(a.pix)+1)/2)
+	for i, v := range a.pix {
+		if i%2 == 1 {
+			v <<= 4
+		}
+		b[i/2] |= v
+	}
+	return b
+}
+
+func (a *Anim) Size() (w, h int16) {
+	return a.w, a.h
+}
+
+// At returns the palette index at x, y.
+func (a *Anim) At(x, y int16) uint8 {
+	return a.pix[int(y)*int(a.w)+int(x)]
+}
+
+// Paint sets the palette index at the cursor.
+func (a *Anim) Paint(c uint8) {
+	a.pix[int(a.CursorY)*int(a.w)+int(a.CursorX)] = c % uint8(len(Palette))
+}
+
+// Move moves the cursor, wrapping around the edges.
+func (a *Anim) Move(dx, dy int16) {
+	a.CursorX = (a.CursorX + dx + a.w) % a.w
+	a.CursorY = (a.CursorY + dy + a.h) % a.h
+}
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	for y := int16(0); y < a.h; y++ {
+		for x := int16(0); x < a.w; x++ {
+			disp.SetPixel(x, y, Palette[a.At(x, y)])
+		}
+	}
+}
+
+func (a *Anim) DrawFrame(disp drivers.Displayer, tick uint32) bool {
+	if !a.Editing {
+		return true
+	}
+	// redraw everything, since the cursor may have moved or been painted under
+	a.Activate(disp)
+	if tick/8%2 == 0 {
+		c := Palette[a.At(a.CursorX, a.CursorY)]
+		disp.SetPixel(a.CursorX, a.CursorY, color.RGBA{R: ^c.R, G: ^c.G, B: ^c.B, A: 0xFF})
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package clock
+
+import (
+	"image/color"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers"
+
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/font"
+)
+
+// scale is how much bigger than the normal font the time is drawn.
+const scale = 2
+
+var months = [...]string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// Anim shows the current time (and optionally the date) for a while.
+type Anim struct {
+	now    func() time.Time
+	date   bool
+	hour12 bool
+	color  color.RGBA
+	shadow color.RGBA
+	length time.Duration
+	until  time.Time
+	drawn  string
+}
+
+// New creates a clock that shows the time from now for length. The time is drawn with a shadow in a darker shade of c.
+func New(now func() time.Time, date, hour12 bool, c color.RGBA, length time.Duration) *Anim {
+	return &Anim{
+		now:    now,
+		date:   date,
+		hour12: hour12,
+		color:  c,
+		shadow: color.RGBA{R: c.R / 4, G: c.G / 4, B: c.B / 4, A: c.A},
+		length: length,
+	}
+}
+
+var _ animation.Animation = (*Anim)(nil)
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	a.until = time.Now().Add(a.length)
+	a.drawn = ""
+}
+
+func (a *Anim) DrawFrame(disp drivers.Displayer, _ uint32) bool {
+	if time.Now().After(a.until) {
+		return false
+	}
+
+	t := a.now()
+	hour := t.Hour()
+	if a.hour12 {
+		hour %= 12
+		if hour == 0 {
+			hour = 12
+		}
+	}
+	sep := ":"
+	if t.Second()%2 == 1 {
+		sep = " "
+	}
+	text := pad(hour) + sep + pad(t.Minute())
+	var date string
+	if a.date {
+		date = months[t.Month()-1] + " " + strconv.Itoa(t.Day())
+	}
+	// only redraw when something visible changed
+	if text+date == a.drawn {
+		return true
+	}
+	a.drawn = text + date
+
+	w, h := disp.Size()
+	for x := int16(0); x < w; x++ {
+		for y := int16(0); y < h; y++ {
+			disp.SetPixel(x, y, color.RGBA{})
+		}
+	}
+
+	// the last glyph doesn't need the spacing after it
+	tw := font.TextWidthScaled(text, scale) - (font.Advance-font.Width)*scale
+	x := (w - tw) / 2
+	y := (h - font.Height*scale) / 2
+	if a.date {
+		y = (h-font.Height*scale-font.Height)/3 - 1
+	}
+	font.DrawScaled(disp, x+1, y+1, text, a.shadow, scale)
+	font.DrawScaled(disp, x, y, text, a.color, scale)
+
+	if a.date {
+		dw := font.TextWidth(date) - (font.Advance - font.Width)
+		font.Draw(disp, (w-dw)/2, h-font.Height-y, date, a.color)
+	}
+	return true
+}
+
+// pad formats n with a leading zero if needed.
+func pad(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,102 @@
+package face
+
+import (
+	"errors"
+	"image"
+
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+// Expression is a named set of images for the parts of the face.
+type Expression struct {
+	Name             string
+	Eye, Nose, Mouth string
+}
+
+// Expressions are the built-in expressions, with the same names as the vector face's so that one command changes
+// either. The first one is the default parts.
+var Expressions = []Expression{
+	{Name: "neutral", Eye: "default", Nose: "default", Mouth: "default"},
+	{Name: "happy", Eye: "happy", Nose: "default", Mouth: "default"},
+	{Name: "sad", Eye: "sad", Nose: "default", Mouth: "default"},
+	{Name: "angry", Eye: "angry", Nose: "scrunch", Mouth: "default"},
+	{Name: "surprised", Eye: "surprised", Nose: "default", Mouth: "open"},
+	{Name: "sleepy", Eye: "sleepy", Nose: "default", Mouth: "default"},
+}
+
+// FindExpression finds the built-in expression by name.
+func FindExpression(name string) (Expression, bool) {
+	for _, e := range Expressions {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Expression{}, false
+}
+
+// transitionFrames are the eyelid levels of a change of expression, one per frame: the eyes close, the parts are
+// swapped while they are closed, and the eyes open on the new expression.
+var transitionFrames = [...]uint8{1, 2, EyelidClosed, EyelidClosed, 2, 1}
+
+// transitionSwap is the frame of the change when the parts are swapped.
+const transitionSwap = 3
+
+// transition is a change of expression in progress.
+type transition struct {
+	active bool
+	frame  int
+	to     Expression
+	// the new images, loaded ahead of time so the change can't fail partway through
+	eye, nose, mouth image.Image
+}
+
+// SetExpression changes to the named expression, with a blink over the change. The images are loaded straight away,
+// so if any of them can't be, the face is left as it is.
+func (a *Anim) SetExpression(name string) error {
+	e, ok := FindExpression(name)
+	if !ok {
+		return errors.New("unknown expression " + name)
+	}
+	eye, err := loadPart(media.TypeEye, e.Eye, true)
+	if err != nil {
+		return err
+	}
+	nose, err := loadPart(media.TypeNose, e.Nose, true)
+	if err != nil {
+		return err
+	}
+	mouth, err := loadPart(media.TypeMouth, e.Mouth, true)
+	if err != nil {
+		return err
+	}
+	a.trans = transition{active: true, to: e, eye: eye, nose: nose, mouth: mouth}
+	return nil
+}
+
+// Expression returns the name of the expression being shown, or being changed to. It is empty if the parts were set
+// with SetParts instead.
+func (a *Anim) Expression() string {
+	if a.trans.active {
+		return a.trans.to.Name
+	}
+	return a.expression
+}
+
+// stepTransition moves a change of expression along by a frame, and returns how far closed it has the eyes.
+func (a *Anim) stepTransition() uint8 {
+	t := &a.trans
+	if !t.active {
+		return 0
+	}
+	if t.frame == transitionSwap {
+		a.eyeName, a.noseName, a.mouthName = t.to.Eye, t.to.Nose, t.to.Mouth
+		a.expression = t.to.Name
+		a.setImages(t.eye, t.nose, t.mouth)
+	}
+	level := transitionFrames[t.frame]
+	t.frame++
+	if t.frame == len(transitionFrames) {
+		*t = transition{}
+	}
+	return level
+}
@@ -7,42 +7,191 @@ import (
 
 	"tinygo.org/x/drivers"
 
+	"github.com/ajanata/gotogen/gfx"
 	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/framebuf"
 	"github.com/ajanata/gotogen/internal/media"
 )
 
 // TODO more
 type Sensors interface {
 	Talking() bool
+	// Eyelid returns how far closed the eyes should be right now, from 0 for open to EyelidClosed.
+	Eyelid() uint8
+	// MouthShape returns the index of the talking mouth image to show (or -1 for the closed mouth) if it is being
+	// controlled externally, e.g. for lip-sync. If it returns false, Talking is used instead.
+	MouthShape() (int8, bool)
+	// Micro returns small idle movements to apply: how far to shift the eyes, and whether to show a blush.
+	Micro() (eyeX, eyeY int8, blush bool)
+	// Gaze returns how far to shift the eyes to look the way the head is tilted, on top of Micro.
+	Gaze() (x, y int8)
+}
+
+// EyelidClosed is the Eyelid level for fully closed eyes, which shows the closed eye image. The levels in between are
+// the frames of a blink, as the eyelid comes down.
+const EyelidClosed = 3
+
+// maxTalkShapes is how many talking mouth shapes there can be, since lip-sync shapes are single digits.
+const maxTalkShapes = 10
+
+// maxEyeShift is the furthest that Micro and Gaze together may move the eyes sideways. There's only room below the eye
+// to move it down by one pixel, and moving it up would cut off the top of it.
+const maxEyeShift = 2
+
+// blushColor is drawn in a dotted pattern over the cheek.
+var blushColor = color.RGBA{R: 0xFF, G: 0x40, B: 0x60, A: 0xFF}
+
+// Layout is where each part of the face goes on the display, as the top-left corner of its image. Negative positions
+// count from the right or bottom edge of the display instead, so that a layout works for any size of panel.
+type Layout struct {
+	Eye, Nose, Mouth image.Point
+}
+
+// DefaultLayout is the layout the default art was drawn for: the nose in the top right corner and the mouth one pixel
+// up from the bottom.
+var DefaultLayout = Layout{
+	Eye:   image.Pt(10, 0),
+	Nose:  image.Pt(-12, 8),
+	Mouth: image.Pt(13, -19),
 }
 
 type Anim struct {
-	eye     image.Image
+	eye    image.Image
+	closed image.Image
+	// lids are the eye images for the Eyelid levels between open and closed. They come from media/eye/blink_N if there
+	// is such an image, or otherwise are the open eye with the top covered by the eyelid.
+	lids    [EyelidClosed - 1]image.Image
 	nose    image.Image
 	mouth   image.Image
 	sensors Sensors
+	// talk are the talking mouth images that have been used, by shape, so they are only loaded once
+	talk [maxTalkShapes]image.Image
+	// still is the whole face with the mouth closed, composed whenever anything but the talking mouth changes. While
+	// talking, only the mouth is drawn, and the rest of the face is copied from here when it needs redrawing.
+	still *framebuf.Buffer
+	// names of the images for each part
+	eyeName, noseName, mouthName string
+	// expression is the name of the expression the parts make up, or empty if they were set some other way
+	expression string
+	trans      transition
+	// media generation the images were loaded from
+	gen uint32
+	// base is the layout from SetLayout, and layout is that with the media manifest's positions applied
+	base, layout Layout
+	// relayout is set when the layout changes, so that the whole display is cleared on the next frame
+	relayout bool
+
+	regions    [regionCount]region
+	flushed    []image.Rectangle
+	wasTalking bool
+	lastEyelid uint8
+	eyeX, eyeY int8
+	blush      bool
+	// the last lip-sync mouth shape drawn, or -2 if the mouth wasn't lip-synced
+	lastShape int8
+}
+
+// region is a part of the face that is only redrawn (and sent to the display) when it changes.
+type region struct {
+	bounds image.Rectangle
+	dirty  bool
 }
 
+const (
+	regionEye = iota
+	regionNose
+	regionMouth
+	regionBlush
+	regionCount
+)
+
 func New(sensors Sensors) (*Anim, error) {
-	eye, err := media.LoadImage(media.TypeEye, "default")
+	a := &Anim{
+		sensors:   sensors,
+		eyeName:   "default",
+		noseName:  "default",
+		mouthName: "default",
+		base:      DefaultLayout,
+		// the default parts
+		expression: Expressions[0].Name,
+	}
+	err := a.load(false)
 	if err != nil {
 		return nil, err
 	}
-	nose, err := media.LoadImage(media.TypeNose, "default")
+	return a, nil
+}
+
+// load (re)loads the face images. If strict, any image that fails to load is an error and the existing images are
+// kept; otherwise, placeholders are used for the missing images.
+func (a *Anim) load(strict bool) error {
+	gen := media.Generation()
+	eye, err := loadPart(media.TypeEye, a.eyeName, strict)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	mouth, err := media.LoadImage(media.TypeMouth, "default")
+	closed, err := loadPart(media.TypeEye, "closed", strict)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	nose, err := loadPart(media.TypeNose, a.noseName, strict)
+	if err != nil {
+		return err
+	}
+	mouth, err := loadPart(media.TypeMouth, a.mouthName, strict)
+	if err != nil {
+		return err
+	}
+
+	a.closed = closed
+	a.talk = [maxTalkShapes]image.Image{}
+	for i := range a.lids {
+		lid, err := media.LoadImage(media.TypeEye, "blink_"+strconv.Itoa(i+1))
+		if err != nil {
+			lid = lidded{Image: eye, level: uint8(i + 1)}
+		}
+		a.lids[i] = lid
+	}
+	a.setImages(eye, nose, mouth)
+	a.gen = gen
+	a.applyLayout()
+	return nil
+}
+
+// setImages changes the images for the parts of the face, and has the whole face redrawn with them.
+func (a *Anim) setImages(eye, nose, mouth image.Image) {
+	a.eye, a.nose, a.mouth = eye, nose, mouth
+	for i, lid := range a.lids {
+		// eyelids that come from the open eye have to follow it
+		if _, ok := lid.(lidded); ok {
+			a.lids[i] = lidded{Image: eye, level: uint8(i + 1)}
+		}
 	}
+	a.Invalidate()
+}
+
+// logger gets warnings about the face, like parts that couldn't be loaded; see SetLogger.
+var logger = func(msg string) {
+	println(msg)
+}
+
+// SetLogger sends warnings about the face, like parts that couldn't be loaded and are shown as placeholders, to the
+// function instead of the console.
+func SetLogger(f func(msg string)) {
+	logger = f
+}
 
-	return &Anim{
-		eye:     eye,
-		nose:    nose,
-		mouth:   mouth,
-		sensors: sensors,
-	}, nil
+// loadPart loads an image for part of the face. If it can't be loaded and not strict, a placeholder is used instead.
+func loadPart(typ media.Type, name string, strict bool) (image.Image, error) {
+	img, err := media.LoadImage(typ, name)
+	if err != nil {
+		if strict {
+			return nil, err
+		}
+		logger("using placeholder for " + string(typ) + " " + name + ": " + err.Error())
+		return media.Placeholder(typ, name), nil
+	}
+	return img, nil
 }
 
 func (a *Anim) Activate(disp drivers.Displayer) {
@@ -52,24 +201,282 @@ func (a *Anim) Activate(disp drivers.Displayer) {
 			disp.SetPixel(x, y, color.RGBA{})
 		}
 	}
+	a.Invalidate()
+}
+
+// Parts returns the names of the images currently used for each part of the face.
+func (a *Anim) Parts() (eye, nose, mouth string) {
+	return a.eyeName, a.noseName, a.mouthName
+}
+
+// SetParts changes the images used for each part of the face. An empty name leaves that part unchanged. If any of the
+// images can't be loaded, the face is left unchanged.
+func (a *Anim) SetParts(eye, nose, mouth string) error {
+	oldEye, oldNose, oldMouth := a.eyeName, a.noseName, a.mouthName
+	if eye != "" {
+		a.eyeName = eye
+	}
+	if nose != "" {
+		a.noseName = nose
+	}
+	if mouth != "" {
+		a.mouthName = mouth
+	}
+	err := a.load(true)
+	if err != nil {
+		a.eyeName, a.noseName, a.mouthName = oldEye, oldNose, oldMouth
+		return err
+	}
+	// the parts no longer make up a named expression, and any change to one is overtaken
+	a.expression, a.trans = "", transition{}
+	return nil
+}
+
+// Layout returns where the parts of the face go, including any changes from the media manifest.
+func (a *Anim) Layout() Layout {
+	return a.layout
+}
+
+// SetLayout changes where the parts of the face go, e.g. for a head with a different shape. Positions in the media
+// manifest still take precedence, since they go with the art.
+func (a *Anim) SetLayout(l Layout) {
+	a.base = l
+	a.applyLayout()
+}
+
+// applyLayout works out the layout from the base layout and the media manifest.
+func (a *Anim) applyLayout() {
+	l := a.base
+	for part, p := range media.PartPositions() {
+		switch part {
+		case "eye":
+			l.Eye = p
+		case "nose":
+			l.Nose = p
+		case "mouth":
+			l.Mouth = p
+		}
+	}
+	if l != a.layout {
+		a.layout = l
+		a.relayout = true
+	}
+}
+
+// place works out where a part goes on a display of the given size.
+func place(p image.Point, w, h int16) image.Point {
+	if p.X < 0 {
+		p.X += int(w)
+	}
+	if p.Y < 0 {
+		p.Y += int(h)
+	}
+	return p
+}
+
+// Invalidate causes every part of the face to be redrawn on the next frame.
+func (a *Anim) Invalidate() {
+	for i := range a.regions {
+		a.regions[i].dirty = true
+	}
+}
+
+// DirtyRegions returns the parts of the face that were drawn in the last frame.
+func (a *Anim) DirtyRegions() []image.Rectangle {
+	return a.flushed
 }
 
 func (a *Anim) DrawFrame(disp drivers.Displayer, tick uint32) bool {
+	if a.gen != media.Generation() {
+		// the media changed underneath us (only happens during development), so pick up the new images
+		err := a.load(false)
+		if err != nil {
+			logger("reloading face: " + err.Error())
+			// don't keep trying every frame
+			a.gen = media.Generation()
+		}
+		a.Activate(disp)
+	}
+	if a.relayout {
+		// parts have moved, so clear where they were
+		a.relayout = false
+		a.Activate(disp)
+	}
+
 	w, h := disp.Size()
-	// TODO jitter or something, will need other sensors. the face is allowed to be special-cased for those
-	animation.DrawImage(disp, 10, 0, a.eye, false)
-	nw, _ := media.TypeNose.Size()
-	animation.DrawImage(disp, w-nw, 8, a.nose, false)
-	_, mh := media.TypeMouth.Size()
-	// TODO better animation
-	if a.sensors.Talking() {
-		// reduce width by 13
-		i, err := media.LoadImage(media.TypeMouth, "talk_"+strconv.Itoa(int(tick%4)))
-		if err == nil {
-			animation.DrawImage(disp, 13, h-mh-1, i, false)
+	ew, eh := media.TypeEye.Size()
+	nw, nh := media.TypeNose.Size()
+	mw, mh := media.TypeMouth.Size()
+	screen := image.Rect(0, 0, int(w), int(h))
+	eyePos := place(a.layout.Eye, w, h)
+	eye := &a.regions[regionEye]
+	// leave room around the eye for it to move, so that moving it also erases where it was
+	eye.bounds = image.Rect(eyePos.X-maxEyeShift, eyePos.Y, eyePos.X+int(ew)+maxEyeShift, eyePos.Y+int(eh)+1).Intersect(screen)
+	nose := &a.regions[regionNose]
+	nosePos := place(a.layout.Nose, w, h)
+	nose.bounds = image.Rect(0, 0, int(nw), int(nh)).Add(nosePos).Intersect(screen)
+	mouth := &a.regions[regionMouth]
+	mouthPos := place(a.layout.Mouth, w, h)
+	mouth.bounds = image.Rect(0, 0, int(mw), int(mh)).Add(mouthPos).Intersect(screen)
+	blush := &a.regions[regionBlush]
+	blush.bounds = image.Rect(eye.bounds.Max.X+1, eyePos.Y+int(eh)-2, eye.bounds.Max.X+9, eyePos.Y+int(eh)+1).Intersect(screen)
+
+	// the mouth changes every frame while talking, and needs one more frame after talking stops to close it
+	shape, external := a.sensors.MouthShape()
+	talking := external || a.sensors.Talking()
+	// lip-sync holding the same shape doesn't need a redraw though
+	if (talking || a.wasTalking) && !(external && shape == a.lastShape) {
+		mouth.dirty = true
+	}
+	a.wasTalking = talking
+	a.lastShape = -2
+	if external {
+		a.lastShape = shape
+	}
+
+	eyelid := a.sensors.Eyelid()
+	if t := a.stepTransition(); t > eyelid {
+		eyelid = t
+	}
+	if eyelid > EyelidClosed {
+		eyelid = EyelidClosed
+	}
+	if eyelid != a.lastEyelid {
+		eye.dirty = true
+	}
+	a.lastEyelid = eyelid
+
+	eyeX, eyeY, blushing := a.sensors.Micro()
+	gazeX, gazeY := a.sensors.Gaze()
+	eyeX, eyeY = clamp(eyeX+gazeX, -maxEyeShift, maxEyeShift), clamp(eyeY+gazeY, 0, 1)
+	if eyeX != a.eyeX || eyeY != a.eyeY {
+		eye.dirty = true
+		a.eyeX, a.eyeY = eyeX, eyeY
+	}
+	if blushing != a.blush {
+		blush.dirty = true
+		a.blush = blushing
+	}
+
+	if sw, sh := a.stillSize(); sw != w || sh != h {
+		a.still = framebuf.New(w, h)
+		eye.dirty, nose.dirty, blush.dirty, mouth.dirty = true, true, true, true
+	}
+	if eye.dirty || nose.dirty || blush.dirty {
+		a.compose(eyePos, nosePos, mouthPos, eyelid)
+	}
+
+	a.flushed = a.flushed[:0]
+	for i := range a.regions {
+		r := &a.regions[i]
+		if r.dirty && i != regionMouth {
+			blit(disp, a.still, r.bounds)
+		}
+	}
+	if mouth.dirty {
+		if talking && !(external && shape < 0) {
+			if !external {
+				// all that's known is that there's talking, so keep the mouth moving
+				shape = int8(tick % 4)
+			}
+			animation.DrawImage(disp, int16(mouthPos.X), int16(mouthPos.Y), a.talkImage(shape), false)
+		} else {
+			blit(disp, a.still, mouth.bounds)
+		}
+	}
+
+	for i := range a.regions {
+		if a.regions[i].dirty {
+			a.flushed = append(a.flushed, a.regions[i].bounds)
+			a.regions[i].dirty = false
 		}
-	} else {
-		animation.DrawImage(disp, 13, h-mh-1, a.mouth, false)
 	}
 	return true
 }
+
+// compose draws the still face, with the mouth closed, into the still buffer.
+func (a *Anim) compose(eyePos, nosePos, mouthPos image.Point, eyelid uint8) {
+	w, h := a.still.Size()
+	blank(a.still, image.Rect(0, 0, int(w), int(h)))
+	i := a.eye
+	switch {
+	case eyelid == EyelidClosed:
+		i = a.closed
+	case eyelid > 0:
+		i = a.lids[eyelid-1]
+	}
+	animation.DrawImage(a.still, int16(eyePos.X)+int16(a.eyeX), int16(eyePos.Y)+int16(a.eyeY), i, false)
+	if a.blush {
+		b := a.regions[regionBlush].bounds
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			// offset every other row for a dotted, slightly diagonal look
+			for x := b.Min.X + y%2; x < b.Max.X; x += 2 {
+				a.still.SetPixel(int16(x), int16(y), blushColor)
+			}
+		}
+	}
+	animation.DrawImage(a.still, int16(nosePos.X), int16(nosePos.Y), a.nose, false)
+	animation.DrawImage(a.still, int16(mouthPos.X), int16(mouthPos.Y), a.mouth, false)
+}
+
+// stillSize is the size of the still buffer, or zero if there isn't one yet.
+func (a *Anim) stillSize() (w, h int16) {
+	if a.still == nil {
+		return 0, 0
+	}
+	return a.still.Size()
+}
+
+// talkImage returns the talking mouth image for the shape, loading it the first time it is used.
+func (a *Anim) talkImage(shape int8) image.Image {
+	if int(shape) < len(a.talk) && a.talk[shape] != nil {
+		return a.talk[shape]
+	}
+	name := "talk_" + strconv.Itoa(int(shape))
+	i, err := media.LoadImage(media.TypeMouth, name)
+	if err != nil {
+		i = media.Placeholder(media.TypeMouth, name)
+	}
+	if int(shape) < len(a.talk) {
+		a.talk[shape] = i
+	}
+	return i
+}
+
+// blit copies the rectangle of the still face to the display.
+func blit(disp drivers.Displayer, still *framebuf.Buffer, r image.Rectangle) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			disp.SetPixel(int16(x), int16(y), still.At(int16(x), int16(y)))
+		}
+	}
+}
+
+// lidded is an open eye with the eyelid partway down, for the frames of a blink that have no image of their own.
+type lidded struct {
+	image.Image
+	level uint8
+}
+
+func (l lidded) At(x, y int) color.Color {
+	b := l.Bounds()
+	if y < b.Min.Y+b.Dy()*int(l.level)/EyelidClosed {
+		return color.RGBA{}
+	}
+	return l.Image.At(x, y)
+}
+
+// blank clears the rectangle on the display.
+func blank(disp drivers.Displayer, r image.Rectangle) {
+	gfx.FillRect(disp, r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, color.RGBA{})
+}
+
+func clamp(v, lo, hi int8) int8 {
+	if v > hi {
+		return hi
+	}
+	if v < lo {
+		return lo
+	}
+	return v
+}
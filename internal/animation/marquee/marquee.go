@@ -0,0 +1,58 @@
+package marquee
+
+import (
+	"image/color"
+
+	"tinygo.org/x/drivers"
+
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/font"
+)
+
+// Anim scrolls a line of text across the display from right to left, once.
+type Anim struct {
+	text  string
+	color color.RGBA
+	x     int16
+	// how many frames to wait between moving one pixel
+	speed uint32
+}
+
+func New(text string, c color.RGBA, speed uint32) *Anim {
+	if speed == 0 {
+		speed = 1
+	}
+	return &Anim{
+		text:  text,
+		color: c,
+		speed: speed,
+	}
+}
+
+var _ animation.Animation = (*Anim)(nil)
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	w, _ := disp.Size()
+	a.x = w
+	blank(disp)
+}
+
+func (a *Anim) DrawFrame(disp drivers.Displayer, tick uint32) bool {
+	if tick%a.speed != 0 {
+		return true
+	}
+	_, h := disp.Size()
+	blank(disp)
+	font.Draw(disp, a.x, (h-font.Height)/2, a.text, a.color)
+	a.x--
+	return a.x+font.TextWidth(a.text) > 0
+}
+
+func blank(disp drivers.Displayer) {
+	w, h := disp.Size()
+	for x := int16(0); x < w; x++ {
+		for y := int16(0); y < h; y++ {
+			disp.SetPixel(x, y, color.RGBA{})
+		}
+	}
+}
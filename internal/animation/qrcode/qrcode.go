@@ -0,0 +1,53 @@
+// Package qrcode shows a QR code on the face for a while, so that someone can scan it straight off the wearer's head.
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"tinygo.org/x/drivers"
+
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/qr"
+)
+
+type Anim struct {
+	code   *qr.Code
+	light  color.RGBA
+	length time.Duration
+	until  time.Time
+}
+
+// New creates an animation that shows the code for length, with its light modules in c.
+func New(code *qr.Code, c color.RGBA, length time.Duration) *Anim {
+	return &Anim{
+		code:   code,
+		light:  c,
+		length: length,
+	}
+}
+
+var _ animation.Animation = (*Anim)(nil)
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	a.until = time.Now().Add(a.length)
+	w, h := disp.Size()
+	// only the middle square is the code, so the rest of the face stays dark
+	side := int(h)
+	if int(w) < side {
+		side = int(w)
+	}
+	x := (int(w) - side) / 2
+	y := (int(h) - side) / 2
+	for xx := int16(0); xx < w; xx++ {
+		for yy := int16(0); yy < h; yy++ {
+			disp.SetPixel(xx, yy, color.RGBA{})
+		}
+	}
+	a.code.Draw(disp, image.Rect(x, y, x+side, y+side), a.light)
+}
+
+func (a *Anim) DrawFrame(_ drivers.Displayer, _ uint32) bool {
+	return time.Now().Before(a.until)
+}
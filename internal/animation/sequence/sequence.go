@@ -0,0 +1,67 @@
+// Package sequence plays a numbered sequence of full-screen images, like media/full/wave_000.bmp to wave_029.bmp, so
+// that animations can be drawn frame by frame without any code. The media manifest sets the framerate and whether it
+// loops, ping-pongs, or plays once; see media.LoadSequence.
+package sequence
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+type Anim struct {
+	seq   *media.Sequence
+	frame int
+	// step is 1 while playing forwards, and -1 while playing backwards in ping-pong mode
+	step int
+	// next is when to move on to the next frame
+	next time.Time
+}
+
+func New(name string) (animation.Animation, error) {
+	seq, err := media.LoadSequence(media.TypeFull, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Anim{
+		seq: seq,
+	}, nil
+}
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	a.frame, a.step = 0, 1
+	a.show(disp)
+}
+
+// show draws the current frame, and works out when the next one is due.
+func (a *Anim) show(disp drivers.Displayer) {
+	animation.DrawImage(disp, 0, 0, a.seq.Frames[a.frame], false)
+	a.next = time.Now().Add(time.Second / time.Duration(a.seq.FPS))
+}
+
+func (a *Anim) DrawFrame(disp drivers.Displayer, _ uint32) bool {
+	n := len(a.seq.Frames)
+	if time.Now().Before(a.next) {
+		return true
+	}
+	if n == 1 {
+		return a.seq.Mode != media.SequenceOnce
+	}
+	a.frame += a.step
+	switch {
+	case a.frame >= 0 && a.frame < n:
+	case a.seq.Mode == media.SequenceOnce:
+		return false
+	case a.seq.Mode == media.SequencePingPong:
+		a.step = -a.step
+		a.frame += 2 * a.step
+	default:
+		a.frame = 0
+	}
+	a.show(disp)
+	return true
+}
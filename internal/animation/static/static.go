@@ -24,6 +24,13 @@ func New(file string) (animation.Animation, error) {
 	}, nil
 }
 
+// FromImage creates a static animation of an image that is already loaded.
+func FromImage(img image.Image) animation.Animation {
+	return &Anim{
+		img: img,
+	}
+}
+
 func (a *Anim) Activate(disp drivers.Displayer) {
 	animation.DrawImage(disp, 0, 0, a.img, false)
 }
@@ -0,0 +1,134 @@
+// Package testpattern draws patterns for checking the face panels after assembly: alignment, mirroring, dead pixels,
+// and color order.
+package testpattern
+
+import (
+	"image/color"
+
+	"tinygo.org/x/drivers"
+
+	"github.com/ajanata/gotogen/internal/animation"
+)
+
+// Pattern is one of the test patterns.
+type Pattern uint8
+
+const (
+	// Grid draws lines every 8 pixels, for checking that panels line up with each other.
+	Grid Pattern = iota
+	// Border outlines the display, with the top left corner red, top right green, and bottom left blue, for checking
+	// mirroring, rotation, and color order.
+	Border
+	// Crosshair draws lines through the middle of the display.
+	Crosshair
+	// ColorBars draws bars of white, the primary and secondary colors, and black.
+	ColorBars
+	// Gradient sweeps a white to black gradient across the display, for finding dead pixels and checking brightness.
+	Gradient
+	PatternCount
+)
+
+func (p Pattern) String() string {
+	switch p {
+	case Grid:
+		return "Grid"
+	case Border:
+		return "Border"
+	case Crosshair:
+		return "Crosshair"
+	case ColorBars:
+		return "Color bars"
+	case Gradient:
+		return "Gradient sweep"
+	default:
+		return "INVALID"
+	}
+}
+
+var (
+	white = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	red   = color.RGBA{R: 0xFF, A: 0xFF}
+	green = color.RGBA{G: 0xFF, A: 0xFF}
+	blue  = color.RGBA{B: 0xFF, A: 0xFF}
+	black = color.RGBA{A: 0xFF}
+)
+
+var bars = [...]color.RGBA{
+	white,
+	red,
+	green,
+	blue,
+	{R: 0xFF, G: 0xFF, A: 0xFF},
+	{G: 0xFF, B: 0xFF, A: 0xFF},
+	{R: 0xFF, B: 0xFF, A: 0xFF},
+	black,
+}
+
+// Anim shows a test pattern until it is stopped.
+type Anim struct {
+	pattern Pattern
+}
+
+// New creates an animation of the test pattern.
+func New(p Pattern) *Anim {
+	return &Anim{pattern: p}
+}
+
+var _ animation.Animation = (*Anim)(nil)
+
+// RawOutput is true, since colors that were changed on the way to the panels would hide what is being checked for.
+func (a *Anim) RawOutput() bool { return true }
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	w, h := disp.Size()
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			disp.SetPixel(x, y, a.pixel(x, y, w, h))
+		}
+	}
+}
+
+func (a *Anim) DrawFrame(disp drivers.Displayer, tick uint32) bool {
+	if a.pattern != Gradient {
+		// everything else is drawn once in Activate
+		return true
+	}
+	w, h := disp.Size()
+	for x := int16(0); x < w; x++ {
+		// one full sweep every 256 frames
+		v := uint8(uint32(x)*256/uint32(w) + tick)
+		c := color.RGBA{R: v, G: v, B: v, A: 0xFF}
+		for y := int16(0); y < h; y++ {
+			disp.SetPixel(x, y, c)
+		}
+	}
+	return true
+}
+
+// pixel works out the color of a pixel of the pattern.
+func (a *Anim) pixel(x, y, w, h int16) color.RGBA {
+	switch a.pattern {
+	case Grid:
+		if x%8 == 0 || y%8 == 0 || x == w-1 || y == h-1 {
+			return white
+		}
+	case Border:
+		switch {
+		case x < 3 && y < 3:
+			return red
+		case x >= w-3 && y < 3:
+			return green
+		case x < 3 && y >= h-3:
+			return blue
+		case x == 0 || y == 0 || x == w-1 || y == h-1:
+			return white
+		}
+	case Crosshair:
+		if x == w/2 || x == w/2-1 || y == h/2 || y == h/2-1 {
+			return white
+		}
+	case ColorBars:
+		return bars[int(x)*len(bars)/int(w)]
+	}
+	return black
+}
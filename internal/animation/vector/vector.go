@@ -0,0 +1,259 @@
+// Package vector is a face drawn from shapes rather than bitmaps. Each expression is a set of parameters (where the
+// eye is, how open it is, how the mouth curves, and so on) relative to the size of the display, so the same face works
+// on any size of panel and can morph smoothly from one expression to another.
+package vector
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	"tinygo.org/x/drivers"
+
+	"github.com/ajanata/gotogen/gfx"
+	"github.com/ajanata/gotogen/internal/animation"
+)
+
+// morphFrames is how many frames it takes to morph from one expression to another.
+const morphFrames = 12
+
+// Sensors are what the vector face reacts to.
+type Sensors interface {
+	Talking() bool
+	// Blinking returns whether the eyes should be closed right now.
+	Blinking() bool
+}
+
+// Expression is a parametric face. Positions and sizes are fractions of the display's width or height, out of 255.
+type Expression struct {
+	Name string
+
+	// EyeX and EyeY are the center of the eye.
+	EyeX, EyeY uint8
+	// EyeW and EyeH are the radii of the eye, as a fraction of the width and height.
+	EyeW, EyeH uint8
+	// EyeOpen is how open the eye is, from 0 (closed) to 255.
+	EyeOpen uint8
+	// EyeSlant tilts the top of the eye: positive lowers the side nearest the nose (angry), negative the other side
+	// (sad).
+	EyeSlant int8
+
+	NoseX, NoseY, NoseSize uint8
+
+	// MouthX and MouthY are the middle of the mouth, and MouthW is half its width.
+	MouthX, MouthY, MouthW uint8
+	// MouthCurve bends the mouth: positive for a smile, negative for a frown.
+	MouthCurve int8
+	// MouthOpen is how far the mouth is open, as a fraction of the height.
+	MouthOpen uint8
+}
+
+// Expressions are the built-in expressions. The first one is the default.
+var Expressions = []Expression{
+	{Name: "neutral", EyeX: 90, EyeY: 60, EyeW: 45, EyeH: 50, EyeOpen: 255, NoseX: 225, NoseY: 85, NoseSize: 20,
+		MouthX: 145, MouthY: 190, MouthW: 90},
+	{Name: "happy", EyeX: 90, EyeY: 60, EyeW: 45, EyeH: 50, EyeOpen: 160, EyeSlant: -30, NoseX: 225, NoseY: 85,
+		NoseSize: 20, MouthX: 145, MouthY: 185, MouthW: 95, MouthCurve: 100},
+	{Name: "sad", EyeX: 90, EyeY: 65, EyeW: 45, EyeH: 45, EyeOpen: 200, EyeSlant: -80, NoseX: 225, NoseY: 85,
+		NoseSize: 20, MouthX: 145, MouthY: 200, MouthW: 75, MouthCurve: -80},
+	{Name: "angry", EyeX: 90, EyeY: 65, EyeW: 45, EyeH: 45, EyeOpen: 220, EyeSlant: 90, NoseX: 225, NoseY: 85,
+		NoseSize: 20, MouthX: 145, MouthY: 195, MouthW: 85, MouthCurve: -40, MouthOpen: 20},
+	{Name: "surprised", EyeX: 90, EyeY: 60, EyeW: 50, EyeH: 60, EyeOpen: 255, NoseX: 225, NoseY: 85, NoseSize: 20,
+		MouthX: 145, MouthY: 190, MouthW: 40, MouthOpen: 70},
+	{Name: "sleepy", EyeX: 90, EyeY: 70, EyeW: 45, EyeH: 45, EyeOpen: 60, NoseX: 225, NoseY: 85, NoseSize: 20,
+		MouthX: 145, MouthY: 190, MouthW: 70, MouthCurve: 20},
+}
+
+// Find finds the built-in expression by name.
+func Find(name string) (Expression, bool) {
+	for _, e := range Expressions {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Expression{}, false
+}
+
+// Anim draws the vector face, morphing between expressions.
+type Anim struct {
+	sensors Sensors
+	color   color.RGBA
+
+	from, to Expression
+	// frames left in the morph from from to to
+	morph uint8
+
+	dirty       bool
+	wasTalking  bool
+	wasBlinking bool
+	bounds      []image.Rectangle
+}
+
+var _ animation.Animation = (*Anim)(nil)
+
+// New creates a vector face with the default expression, drawn in the color.
+func New(sensors Sensors, c color.RGBA) *Anim {
+	return &Anim{
+		sensors: sensors,
+		color:   c,
+		from:    Expressions[0],
+		to:      Expressions[0],
+		dirty:   true,
+	}
+}
+
+// SetExpression starts morphing to the named built-in expression.
+func (a *Anim) SetExpression(name string) error {
+	e, ok := Find(name)
+	if !ok {
+		return errors.New("unknown expression " + name)
+	}
+	a.Morph(e)
+	return nil
+}
+
+// Morph starts morphing from whatever is showing now to the expression.
+func (a *Anim) Morph(e Expression) {
+	a.from = a.current()
+	a.to = e
+	a.morph = morphFrames
+	a.dirty = true
+}
+
+// Expression returns the name of the expression being shown, or being morphed to.
+func (a *Anim) Expression() string {
+	return a.to.Name
+}
+
+// Invalidate causes the face to be redrawn on the next frame.
+func (a *Anim) Invalidate() {
+	a.dirty = true
+}
+
+func (a *Anim) Activate(disp drivers.Displayer) {
+	a.dirty = true
+}
+
+// DirtyRegions returns the whole display if the face was drawn in the last frame.
+func (a *Anim) DirtyRegions() []image.Rectangle {
+	return a.bounds
+}
+
+// current works out the expression partway through the morph.
+func (a *Anim) current() Expression {
+	if a.morph == 0 {
+		return a.to
+	}
+	// t is how far along the morph is, out of morphFrames
+	t := int(morphFrames - a.morph)
+	u := func(from, to uint8) uint8 { return uint8(int(from) + (int(to)-int(from))*t/morphFrames) }
+	s := func(from, to int8) int8 { return int8(int(from) + (int(to)-int(from))*t/morphFrames) }
+	f, e := &a.from, &a.to
+	return Expression{
+		Name:       e.Name,
+		EyeX:       u(f.EyeX, e.EyeX),
+		EyeY:       u(f.EyeY, e.EyeY),
+		EyeW:       u(f.EyeW, e.EyeW),
+		EyeH:       u(f.EyeH, e.EyeH),
+		EyeOpen:    u(f.EyeOpen, e.EyeOpen),
+		EyeSlant:   s(f.EyeSlant, e.EyeSlant),
+		NoseX:      u(f.NoseX, e.NoseX),
+		NoseY:      u(f.NoseY, e.NoseY),
+		NoseSize:   u(f.NoseSize, e.NoseSize),
+		MouthX:     u(f.MouthX, e.MouthX),
+		MouthY:     u(f.MouthY, e.MouthY),
+		MouthW:     u(f.MouthW, e.MouthW),
+		MouthCurve: s(f.MouthCurve, e.MouthCurve),
+		MouthOpen:  u(f.MouthOpen, e.MouthOpen),
+	}
+}
+
+func (a *Anim) DrawFrame(disp drivers.Displayer, tick uint32) bool {
+	talking := a.sensors.Talking()
+	blinking := a.sensors.Blinking()
+	// the mouth moves every frame while talking, and needs one more frame after talking stops to close it
+	if a.morph > 0 || talking || a.wasTalking || blinking != a.wasBlinking {
+		a.dirty = true
+	}
+	a.wasTalking, a.wasBlinking = talking, blinking
+
+	a.bounds = a.bounds[:0]
+	if !a.dirty {
+		return true
+	}
+	e := a.current()
+	if a.morph > 0 {
+		a.morph--
+	}
+	if blinking {
+		e.EyeOpen = 0
+	}
+	if talking {
+		// open and close the mouth, at least as far as the expression already has it open
+		open := uint8(tick%4) * 25
+		if open > e.MouthOpen {
+			e.MouthOpen = open
+		}
+	}
+
+	w, h := disp.Size()
+	gfx.FillRect(disp, 0, 0, int(w), int(h), color.RGBA{})
+	a.draw(disp, &e, int(w), int(h))
+	a.bounds = append(a.bounds, image.Rect(0, 0, int(w), int(h)))
+	a.dirty = false
+	return true
+}
+
+// draw draws the expression, scaled to the display.
+func (a *Anim) draw(disp drivers.Displayer, e *Expression, w, h int) {
+	sx := func(v uint8) int { return int(v) * w / 255 }
+	sy := func(v uint8) int { return int(v) * h / 255 }
+	black := color.RGBA{}
+
+	// eye
+	ex, ey, erx, ery := sx(e.EyeX), sy(e.EyeY), sx(e.EyeW), sy(e.EyeH)
+	open := ery * int(e.EyeOpen) / 255
+	if open < 1 {
+		gfx.FillRect(disp, ex-erx, ey, ex+erx, ey+1, a.color)
+	} else {
+		gfx.FillEllipseAA(disp, ex, ey+ery-open, erx, open, a.color)
+		// the slant cuts off the top of the eye, deeper on one side
+		top := ey + ery - 2*open - 1
+		cut := int(e.EyeSlant) * 2 * open / 127
+		left, right := top, top
+		if cut > 0 {
+			right += cut
+		} else {
+			left -= cut
+		}
+		gfx.FillPolygon(disp, []image.Point{
+			{ex - erx - 1, top}, {ex + erx + 2, top}, {ex + erx + 2, right}, {ex - erx - 1, left},
+		}, black)
+	}
+
+	// nose, a small triangle pointing down
+	nx, ny, nr := sx(e.NoseX), sy(e.NoseY), sx(e.NoseSize)
+	gfx.FillPolygon(disp, []image.Point{{nx - nr, ny - nr/2}, {nx + nr, ny - nr/2}, {nx, ny + nr}}, a.color)
+
+	// mouth, a parabola with the ends bent up for a smile or down for a frown
+	mx, my, mw := sx(e.MouthX), sy(e.MouthY), sx(e.MouthW)
+	if mw < 1 {
+		mw = 1
+	}
+	bend := int(e.MouthCurve) * h / 6 / 127
+	if e.MouthOpen > 0 {
+		gfx.FillEllipseAA(disp, mx, my, mw*2/3, sy(e.MouthOpen)/2+1, a.color)
+	}
+	const segments = 8
+	px, py := 0, 0
+	for i := 0; i <= segments; i++ {
+		dx := mw * (2*i - segments) / segments
+		y := my - bend*dx*dx/(mw*mw) + bend/2
+		x := mx + dx
+		if i > 0 {
+			gfx.LineAA(disp, px, py, x, y, a.color)
+			gfx.LineAA(disp, px, py+1, x, y+1, a.color)
+		}
+		px, py = x, y
+	}
+}
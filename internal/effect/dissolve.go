@@ -0,0 +1,41 @@
+package effect
+
+import (
+	"image/color"
+)
+
+const dissolveFrames = 60
+
+// Dissolve makes pixels drop out one at a time until the face is gone, then brings them back.
+type Dissolve struct {
+	start uint32
+	seed  uint32
+	// pixels whose hash is below this are hidden
+	cutoff uint32
+}
+
+func (e *Dissolve) Start(tick uint32) {
+	e.start = tick
+	e.seed = tick
+}
+
+func (e *Dissolve) Frame(tick uint32) bool {
+	f := tick - e.start
+	if f >= dissolveFrames {
+		return false
+	}
+	// out for the first half, back in for the second half
+	half := uint32(dissolveFrames / 2)
+	if f >= half {
+		f = dissolveFrames - f
+	}
+	e.cutoff = f * (0xFFFF / half)
+	return true
+}
+
+func (e *Dissolve) Pixel(src Source, x, y int16) color.RGBA {
+	if hash(x, y, e.seed)&0xFFFF < e.cutoff {
+		return color.RGBA{}
+	}
+	return src.At(x, y)
+}
@@ -0,0 +1,79 @@
+package effect
+
+import (
+	"image/color"
+)
+
+// Source is where an effect reads the undisturbed frame from.
+type Source interface {
+	Size() (x, y int16)
+	At(x, y int16) color.RGBA
+}
+
+// Effect is a short-lived transformation applied over whatever animation is on the face.
+type Effect interface {
+	// Start is called when the effect is triggered. Effects are re-used, so this should reset any state.
+	Start(tick uint32)
+	// Frame is called once before drawing each frame, and returns whether the effect should continue.
+	Frame(tick uint32) bool
+	// Pixel returns the color to display at x, y.
+	Pixel(src Source, x, y int16) color.RGBA
+}
+
+// Named is an effect with the name it is triggered by.
+type Named struct {
+	Name string
+	New  func() Effect
+}
+
+// All is every available effect.
+var All = []Named{
+	{"glitch", func() Effect { return &Glitch{} }},
+	{"scanline", func() Effect { return &Scanline{} }},
+	{"flicker", func() Effect { return &Flicker{} }},
+	{"dissolve", func() Effect { return &Dissolve{} }},
+}
+
+// Find returns a new instance of the named effect, or nil if there is no such effect.
+func Find(name string) Effect {
+	for _, n := range All {
+		if n.Name == name {
+			return n.New()
+		}
+	}
+	return nil
+}
+
+// rng is a tiny xorshift random number generator, to avoid pulling in math/rand for some visual noise.
+type rng uint32
+
+func (r *rng) next() uint32 {
+	if *r == 0 {
+		*r = 0x2545F491
+	}
+	x := uint32(*r)
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	*r = rng(x)
+	return x
+}
+
+// hash mixes a pixel position and a seed into a pseudo-random value that is stable for that combination.
+func hash(x, y int16, seed uint32) uint32 {
+	h := uint32(x)*73856093 ^ uint32(y)*19349663 ^ seed*83492791
+	h ^= h >> 16
+	h *= 0x45d9f3b
+	h ^= h >> 16
+	return h
+}
+
+// scale multiplies the color by n/256.
+func scale(c color.RGBA, n uint16) color.RGBA {
+	return color.RGBA{
+		R: uint8(uint16(c.R) * n >> 8),
+		G: uint8(uint16(c.G) * n >> 8),
+		B: uint8(uint16(c.B) * n >> 8),
+		A: c.A,
+	}
+}
@@ -0,0 +1,39 @@
+package effect
+
+import (
+	"image/color"
+)
+
+const flickerFrames = 45
+
+// Flicker randomly dips the brightness of the whole face, like a failing CRT.
+type Flicker struct {
+	start  uint32
+	rng    rng
+	bright uint16
+}
+
+func (e *Flicker) Start(tick uint32) {
+	e.start = tick
+	e.rng = rng(tick)
+}
+
+func (e *Flicker) Frame(tick uint32) bool {
+	if tick-e.start >= flickerFrames {
+		return false
+	}
+	r := e.rng.next()
+	if r%4 == 0 {
+		e.bright = uint16(r>>8%160) + 16
+	} else {
+		e.bright = 256
+	}
+	return true
+}
+
+func (e *Flicker) Pixel(src Source, x, y int16) color.RGBA {
+	if e.bright >= 256 {
+		return src.At(x, y)
+	}
+	return scale(src.At(x, y), e.bright)
+}
@@ -0,0 +1,53 @@
+package effect
+
+import (
+	"image/color"
+)
+
+const glitchFrames = 30
+
+// Glitch tears bands of the face sideways and splits the color channels, like a bad video signal.
+type Glitch struct {
+	start uint32
+	rng   rng
+	// horizontal offset of each row this frame
+	shift [64]int8
+	// offset of the red channel this frame
+	split int16
+}
+
+func (e *Glitch) Start(tick uint32) {
+	e.start = tick
+	e.rng = rng(tick)
+}
+
+func (e *Glitch) Frame(tick uint32) bool {
+	if tick-e.start >= glitchFrames {
+		return false
+	}
+	for i := range e.shift {
+		e.shift[i] = 0
+	}
+	// a few bands of rows get torn every frame
+	for band := 0; band < 3; band++ {
+		r := e.rng.next()
+		row := int(r % uint32(len(e.shift)))
+		height := int(r>>8%6) + 1
+		offset := int8(r>>16%9) - 4
+		for y := row; y < row+height && y < len(e.shift); y++ {
+			e.shift[y] = offset
+		}
+	}
+	e.split = int16(e.rng.next()%5) - 2
+	return true
+}
+
+func (e *Glitch) Pixel(src Source, x, y int16) color.RGBA {
+	sx := x
+	if int(y) < len(e.shift) {
+		sx += int16(e.shift[y])
+	}
+	c := src.At(sx, y)
+	c.R = src.At(sx+e.split, y).R
+	return c
+}
@@ -0,0 +1,37 @@
+package effect
+
+import (
+	"image/color"
+)
+
+const scanlineFrames = 90
+
+// Scanline darkens every other row like an old CRT, with a brighter band rolling down the face.
+type Scanline struct {
+	start uint32
+	band  int16
+}
+
+func (e *Scanline) Start(tick uint32) {
+	e.start = tick
+}
+
+func (e *Scanline) Frame(tick uint32) bool {
+	f := tick - e.start
+	if f >= scanlineFrames {
+		return false
+	}
+	e.band = int16(f % 48)
+	return true
+}
+
+func (e *Scanline) Pixel(src Source, x, y int16) color.RGBA {
+	c := src.At(x, y)
+	if y == e.band || y == e.band-1 {
+		return c
+	}
+	if y%2 == 1 {
+		return scale(c, 96)
+	}
+	return scale(c, 200)
+}
@@ -0,0 +1,192 @@
+// Package errcode gives errors a short, numbered code, so that the crash screen, the logs and replies to host tools can
+// say what went wrong in a few characters (e.g. E12 MEDIA_SIZE) that can be looked up or matched on, rather than only
+// a sentence built up from whatever each layer added.
+//
+// The tens digit of a code is its subsystem:
+//
+//	E10 MEDIA_MISSING   an image isn't in any of the media, or is a directory
+//	E11 MEDIA_DECODE    an image couldn't be decoded, or has no frames
+//	E12 MEDIA_SIZE      an image is empty or can't be fitted to its type's size
+//	E13 MEDIA_TYPE      the media type doesn't exist
+//	E14 MEDIA_COLORS    an image is blank in the panels' colors
+//	E15 MEDIA_STORAGE   there is no stored media to read from
+//	E20 DISPLAY_FACE    the face display couldn't be updated
+//	E21 DISPLAY_STATUS  the status display couldn't be updated
+//	E22 DISPLAY_RESET   a display couldn't be reset after repeated errors
+//	E23 DISPLAY_SIZE    the status display is too small to use
+//	E30 DRIVER_INIT     the driver failed to initialize the hardware
+//	E31 DRIVER_CALL     a call into the driver failed
+//	E40 SETTINGS_SAVE   a setting couldn't be saved
+package errcode
+
+import "errors"
+
+// Subsystem is the part of Gotogen that an error came from.
+type Subsystem uint8
+
+const (
+	SubsystemNone Subsystem = iota
+	SubsystemMedia
+	SubsystemDisplay
+	SubsystemDriver
+	SubsystemSettings
+)
+
+func (s Subsystem) String() string {
+	switch s {
+	case SubsystemNone:
+		return "none"
+	case SubsystemMedia:
+		return "media"
+	case SubsystemDisplay:
+		return "display"
+	case SubsystemDriver:
+		return "driver"
+	case SubsystemSettings:
+		return "settings"
+	default:
+		return "INVALID"
+	}
+}
+
+// Code identifies what went wrong. Codes are never renumbered, so that they mean the same thing in every version.
+type Code uint8
+
+const (
+	MediaMissing  Code = 10
+	MediaDecode   Code = 11
+	MediaSize     Code = 12
+	MediaType     Code = 13
+	MediaColors   Code = 14
+	MediaStorage  Code = 15
+	DisplayFace   Code = 20
+	DisplayStatus Code = 21
+	DisplayReset  Code = 22
+	DisplaySize   Code = 23
+	DriverInit    Code = 30
+	DriverCall    Code = 31
+	SettingsSave  Code = 40
+)
+
+// Subsystem is the part of Gotogen that the code belongs to.
+func (c Code) Subsystem() Subsystem {
+	return Subsystem(c / 10)
+}
+
+// Name is the code's name, e.g. MEDIA_SIZE.
+func (c Code) Name() string {
+	switch c {
+	case MediaMissing:
+		return "MEDIA_MISSING"
+	case MediaDecode:
+		return "MEDIA_DECODE"
+	case MediaSize:
+		return "MEDIA_SIZE"
+	case MediaType:
+		return "MEDIA_TYPE"
+	case MediaColors:
+		return "MEDIA_COLORS"
+	case MediaStorage:
+		return "MEDIA_STORAGE"
+	case DisplayFace:
+		return "DISPLAY_FACE"
+	case DisplayStatus:
+		return "DISPLAY_STATUS"
+	case DisplayReset:
+		return "DISPLAY_RESET"
+	case DisplaySize:
+		return "DISPLAY_SIZE"
+	case DriverInit:
+		return "DRIVER_INIT"
+	case DriverCall:
+		return "DRIVER_CALL"
+	case SettingsSave:
+		return "SETTINGS_SAVE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// String is the compact form of the code, e.g. E12 MEDIA_SIZE.
+func (c Code) String() string {
+	return "E" + string(rune('0'+c/10%10)) + string(rune('0'+c%10)) + " " + c.Name()
+}
+
+// Error is an error with a code.
+type Error struct {
+	Code Code
+	// Msg says what went wrong, and may be empty if Err says it all.
+	Msg string
+	// Err is the underlying error, if there is one.
+	Err error
+}
+
+// New makes an error with the code.
+func New(code Code, msg string) error {
+	return &Error{Code: code, Msg: msg}
+}
+
+// Wrap gives an error a code, or returns nil if err is nil.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Context adds to the start of an error's message, like "load face: " + err.Error() would, but keeps its code at the
+// front so that it can still be found. Errors without a code are wrapped, so that errors.Is and errors.As still find
+// them.
+func Context(context string, err error) error {
+	if e, ok := err.(*Error); ok {
+		msg := context
+		if e.Msg != "" {
+			msg += ": " + e.Msg
+		}
+		return &Error{Code: e.Code, Msg: msg, Err: e.Err}
+	}
+	return &contextError{context: context, err: err}
+}
+
+// contextError is an error without a code that has had context added.
+type contextError struct {
+	context string
+	err     error
+}
+
+func (e *contextError) Error() string {
+	return e.context + ": " + e.err.Error()
+}
+
+func (e *contextError) Unwrap() error {
+	return e.err
+}
+
+func (e *Error) Error() string {
+	return e.Code.String() + ": " + e.text()
+}
+
+// text is the error's message without the code.
+func (e *Error) text() string {
+	switch {
+	case e.Err == nil:
+		return e.Msg
+	case e.Msg == "":
+		return e.Err.Error()
+	default:
+		return e.Msg + ": " + e.Err.Error()
+	}
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Of returns the code of the error, or of the first error with a code that it wraps.
+func Of(err error) (Code, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code, true
+	}
+	return 0, false
+}
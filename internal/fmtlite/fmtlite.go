@@ -0,0 +1,71 @@
+// Package fmtlite formats numbers into buffers that the caller provides, for text that is built every frame. Unlike
+// fmt and strconv's Format functions, nothing here allocates as long as the buffer has room, so the status screen
+// doesn't make garbage for the collector on TinyGo. It only does the little that the status screen needs.
+package fmtlite
+
+// maxDigits is the most digits a uint64 can have.
+const maxDigits = 20
+
+// AppendUint appends the decimal form of v to b.
+func AppendUint(b []byte, v uint64) []byte {
+	var digits [maxDigits]byte
+	i := len(digits)
+	for {
+		i--
+		digits[i] = byte('0' + v%10)
+		v /= 10
+		if v == 0 {
+			break
+		}
+	}
+	return append(b, digits[i:]...)
+}
+
+// AppendInt appends the decimal form of v to b, with a leading - if it is negative.
+func AppendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		// negating in uint64 works for the most negative int64 as well
+		return AppendUint(append(b, '-'), -uint64(v))
+	}
+	return AppendUint(b, uint64(v))
+}
+
+// AppendPadded appends the decimal form of v to b, padded on the left with pad to at least width characters, e.g. with
+// '0' for the minutes of a clock.
+func AppendPadded(b []byte, v uint64, width int, pad byte) []byte {
+	n := 1
+	for t := v; t >= 10; t /= 10 {
+		n++
+	}
+	for ; n < width; n++ {
+		b = append(b, pad)
+	}
+	return AppendUint(b, v)
+}
+
+// AppendFixed appends v as a fixed-point number with the given number of decimal places, i.e. v is in units of
+// 10^-decimals: AppendFixed(b, 1234, 1) appends "123.4", and AppendFixed(b, -5, 2) appends "-0.05".
+func AppendFixed(b []byte, v int64, decimals int) []byte {
+	if decimals <= 0 {
+		return AppendInt(b, v)
+	}
+	u := uint64(v)
+	if v < 0 {
+		b = append(b, '-')
+		u = -u
+	}
+	scale := uint64(1)
+	for i := 0; i < decimals; i++ {
+		scale *= 10
+	}
+	b = AppendUint(b, u/scale)
+	b = append(b, '.')
+	return AppendPadded(b, u%scale, decimals, '0')
+}
+
+// AppendClock appends a and b as "A:BB", e.g. hours and minutes, or minutes and seconds.
+func AppendClock(buf []byte, a, b int) []byte {
+	buf = AppendInt(buf, int64(a))
+	buf = append(buf, ':')
+	return AppendPadded(buf, uint64(b), 2, '0')
+}
@@ -0,0 +1,136 @@
+package font
+
+import (
+	"image/color"
+
+	"tinygo.org/x/drivers"
+)
+
+const (
+	// Width is the width of a glyph, not including spacing.
+	Width = 3
+	// Height is the height of a glyph.
+	Height = 5
+	// Advance is how far to move right after each glyph.
+	Advance = Width + 1
+)
+
+// glyphs is a tiny 3x5 font, as rows from top to bottom with the leftmost pixel in bit 2. It only has uppercase
+// letters; lowercase letters are drawn as uppercase.
+var glyphs = map[byte][Height]uint8{
+	' ':  {0, 0, 0, 0, 0},
+	'!':  {2, 2, 2, 0, 2},
+	'"':  {5, 5, 0, 0, 0},
+	'#':  {5, 7, 5, 7, 5},
+	'%':  {5, 1, 2, 4, 5},
+	'&':  {2, 5, 2, 5, 3},
+	'\'': {2, 2, 0, 0, 0},
+	'(':  {1, 2, 2, 2, 1},
+	')':  {4, 2, 2, 2, 4},
+	'*':  {0, 5, 2, 5, 0},
+	'+':  {0, 2, 7, 2, 0},
+	',':  {0, 0, 0, 2, 4},
+	'-':  {0, 0, 7, 0, 0},
+	'.':  {0, 0, 0, 0, 2},
+	'/':  {1, 1, 2, 4, 4},
+	'0':  {7, 5, 5, 5, 7},
+	'1':  {2, 6, 2, 2, 7},
+	'2':  {7, 1, 7, 4, 7},
+	'3':  {7, 1, 3, 1, 7},
+	'4':  {5, 5, 7, 1, 1},
+	'5':  {7, 4, 7, 1, 7},
+	'6':  {7, 4, 7, 5, 7},
+	'7':  {7, 1, 1, 2, 2},
+	'8':  {7, 5, 7, 5, 7},
+	'9':  {7, 5, 7, 1, 7},
+	':':  {0, 2, 0, 2, 0},
+	';':  {0, 2, 0, 2, 4},
+	'<':  {1, 2, 4, 2, 1},
+	'=':  {0, 7, 0, 7, 0},
+	'>':  {4, 2, 1, 2, 4},
+	'?':  {7, 1, 3, 0, 2},
+	'@':  {7, 5, 7, 4, 7},
+	'A':  {2, 5, 7, 5, 5},
+	'B':  {6, 5, 6, 5, 6},
+	'C':  {3, 4, 4, 4, 3},
+	'D':  {6, 5, 5, 5, 6},
+	'E':  {7, 4, 6, 4, 7},
+	'F':  {7, 4, 6, 4, 4},
+	'G':  {3, 4, 5, 5, 3},
+	'H':  {5, 5, 7, 5, 5},
+	'I':  {7, 2, 2, 2, 7},
+	'J':  {1, 1, 1, 5, 2},
+	'K':  {5, 5, 6, 5, 5},
+	'L':  {4, 4, 4, 4, 7},
+	'M':  {5, 7, 7, 5, 5},
+	'N':  {6, 5, 5, 5, 5},
+	'O':  {2, 5, 5, 5, 2},
+	'P':  {6, 5, 6, 4, 4},
+	'Q':  {2, 5, 5, 6, 3},
+	'R':  {6, 5, 6, 5, 5},
+	'S':  {3, 4, 2, 1, 6},
+	'T':  {7, 2, 2, 2, 2},
+	'U':  {5, 5, 5, 5, 7},
+	'V':  {5, 5, 5, 5, 2},
+	'W':  {5, 5, 7, 7, 5},
+	'X':  {5, 5, 2, 5, 5},
+	'Y':  {5, 5, 2, 2, 2},
+	'Z':  {7, 1, 2, 4, 7},
+	'_':  {0, 0, 0, 0, 7},
+}
+
+// unknown is drawn for characters not in the font.
+var unknown = [Height]uint8{7, 5, 5, 5, 7}
+
+// glyph returns the glyph for the character.
+func glyph(c byte) [Height]uint8 {
+	if 'a' <= c && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	g, ok := glyphs[c]
+	if !ok {
+		return unknown
+	}
+	return g
+}
+
+// Draw draws the text with its top left corner at x, y, clipping anything off the display. Background pixels are
+// not drawn. It returns the x coordinate after the end of the text.
+func Draw(disp drivers.Displayer, x, y int16, text string, c color.RGBA) int16 {
+	return DrawScaled(disp, x, y, text, c, 1)
+}
+
+// DrawScaled is like Draw, but draws every pixel of the font as a scale by scale square.
+func DrawScaled(disp drivers.Displayer, x, y int16, text string, c color.RGBA, scale int16) int16 {
+	w, h := disp.Size()
+	for i := 0; i < len(text); i++ {
+		g := glyph(text[i])
+		for row := int16(0); row < Height*scale; row++ {
+			yy := y + row
+			if yy < 0 || yy >= h {
+				continue
+			}
+			for col := int16(0); col < Width*scale; col++ {
+				xx := x + col
+				if xx < 0 || xx >= w {
+					continue
+				}
+				if g[row/scale]&(1<<(Width-1-col/scale)) != 0 {
+					disp.SetPixel(xx, yy, c)
+				}
+			}
+		}
+		x += Advance * scale
+	}
+	return x
+}
+
+// TextWidth returns how wide the text is when drawn.
+func TextWidth(text string) int16 {
+	return int16(len(text)) * Advance
+}
+
+// TextWidthScaled returns how wide the text is when drawn with DrawScaled.
+func TextWidthScaled(text string, scale int16) int16 {
+	return TextWidth(text) * scale
+}
@@ -0,0 +1,49 @@
+package framebuf
+
+import (
+	"image/color"
+)
+
+// Buffer is an in-memory display, used to keep a copy of what has been drawn on the face so that it can be
+// post-processed and redrawn without the animation having to draw it again.
+type Buffer struct {
+	w, h int16
+	pix  []color.RGBA
+}
+
+func New(w, h int16) *Buffer {
+	return &Buffer{
+		w:   w,
+		h:   h,
+		pix: make([]color.RGBA, int(w)*int(h)),
+	}
+}
+
+func (b *Buffer) Size() (x, y int16) {
+	return b.w, b.h
+}
+
+func (b *Buffer) SetPixel(x, y int16, c color.RGBA) {
+	if x < 0 || y < 0 || x >= b.w || y >= b.h {
+		return
+	}
+	b.pix[int(y)*int(b.w)+int(x)] = c
+}
+
+// At returns the color of the pixel. Out of bounds pixels are black.
+func (b *Buffer) At(x, y int16) color.RGBA {
+	if x < 0 || y < 0 || x >= b.w || y >= b.h {
+		return color.RGBA{}
+	}
+	return b.pix[int(y)*int(b.w)+int(x)]
+}
+
+// Display does nothing, as there is no hardware behind the buffer.
+func (b *Buffer) Display() error {
+	return nil
+}
+
+// CopyFrom copies the pixels of another buffer of the same size.
+func (b *Buffer) CopyFrom(src *Buffer) {
+	copy(b.pix, src.pix)
+}
@@ -0,0 +1,47 @@
+package media
+
+import "strings"
+
+// creditsFile is the media manifest crediting the artists, in stored media. Each line is a media file and who made
+// it, e.g. "full/wait Jane Doe", or "eye/* Jane Doe" for every file of a type. Lines starting with # are comments.
+const creditsFile = "media/credits.txt"
+
+var (
+	credits map[string]string
+	// creditsGen is the Generation the credits were read at
+	creditsGen uint32
+	creditsOK  bool
+)
+
+// Credit returns who made the media file, from the manifest, or "" if it doesn't say.
+func Credit(typ Type, name string) string {
+	if gen := Generation(); !creditsOK || gen != creditsGen {
+		loadCredits()
+		creditsGen, creditsOK = gen, true
+	}
+	if c, ok := credits[string(typ)+"/"+name]; ok {
+		return c
+	}
+	return credits[string(typ)+"/*"]
+}
+
+// loadCredits reads the manifest, if there is one.
+func loadCredits() {
+	credits = nil
+	b, err := readFile(creditsFile)
+	if err != nil {
+		return
+	}
+	credits = make(map[string]string)
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		file, who, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		credits[file] = strings.TrimSpace(who)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !minimalmedia
+
+package media
+
+import (
+	"image/gif"
+	"image/png"
+
+	"golang.org/x/image/bmp"
+)
+
+// decoders are the supported image formats, by file extension, in order of preference. Only the first frame of
+// animated GIFs is used, other than by LoadFrames.
+var decoders = []decoder{
+	{".bmp", bmp.Decode},
+	{".png", png.Decode},
+	{".gif", gif.Decode},
+}
@@ -0,0 +1,10 @@
+//go:build minimalmedia
+
+package media
+
+import "golang.org/x/image/bmp"
+
+// decoders are the supported image formats. Builds with the minimalmedia build tag only support BMP, to save space.
+var decoders = []decoder{
+	{".bmp", bmp.Decode},
+}
@@ -0,0 +1,112 @@
+package media
+
+import (
+	"image"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// Fit is what to do with an image that isn't the right size for its type.
+type Fit uint8
+
+const (
+	// FitReject refuses to load the image.
+	FitReject Fit = iota
+	// FitCenter centers the image as it is, leaving black bars around it if it is too small and cutting off the edges
+	// if it is too big.
+	FitCenter
+	// FitScale scales the image as large as it can go without changing its aspect ratio (nearest-neighbor, so pixel art
+	// stays sharp), and centers it.
+	FitScale
+	FitCount
+)
+
+func (f Fit) String() string {
+	switch f {
+	case FitReject:
+		return "reject"
+	case FitCenter:
+		return "center"
+	case FitScale:
+		return "scale"
+	default:
+		return "INVALID"
+	}
+}
+
+// Types are all the media types, in the order used for per-type settings.
+var Types = [...]Type{TypeEye, TypeNose, TypeMouth, TypeFull}
+
+// fits is the Fit for each of Types.
+var fits [len(Types)]Fit
+
+func typeIndex(typ Type) int {
+	for i, t := range Types {
+		if t == typ {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetFit changes what is done with images of the type that aren't the right size. Anything using the media reloads it.
+func SetFit(typ Type, f Fit) {
+	i := typeIndex(typ)
+	if i < 0 || f >= FitCount {
+		return
+	}
+	fits[i] = f
+	changed()
+}
+
+// FitFor returns what is done with images of the type that aren't the right size.
+func FitFor(typ Type) Fit {
+	i := typeIndex(typ)
+	if i < 0 {
+		return FitReject
+	}
+	return fits[i]
+}
+
+// fit makes the image the right size for the type, according to its Fit.
+func fit(img image.Image, typ Type) (image.Image, error) {
+	w, h := typ.Size()
+	W, H := int(w), int(h)
+	b := img.Bounds()
+	iw, ih := b.Dx(), b.Dy()
+	if iw == 0 || ih == 0 {
+		return nil, errcode.New(errcode.MediaSize, "empty image")
+	}
+
+	switch FitFor(typ) {
+	case FitCenter:
+		out := image.NewRGBA(image.Rect(0, 0, W, H))
+		ox, oy := (W-iw)/2, (H-ih)/2
+		for y := 0; y < ih; y++ {
+			for x := 0; x < iw; x++ {
+				// Set ignores anything outside of the image, which crops images that are too big
+				out.Set(ox+x, oy+y, img.At(b.Min.X+x, b.Min.Y+y))
+			}
+		}
+		return out, nil
+	case FitScale:
+		// the largest size with the same aspect ratio that fits both ways
+		sw, sh := W, W*ih/iw
+		if sh > H {
+			sw, sh = H*iw/ih, H
+		}
+		if sw == 0 || sh == 0 {
+			return nil, errcode.New(errcode.MediaSize, "image too small to scale for type "+string(typ))
+		}
+		out := image.NewRGBA(image.Rect(0, 0, W, H))
+		ox, oy := (W-sw)/2, (H-sh)/2
+		for y := 0; y < sh; y++ {
+			for x := 0; x < sw; x++ {
+				out.Set(ox+x, oy+y, img.At(b.Min.X+x*iw/sw, b.Min.Y+y*ih/sh))
+			}
+		}
+		return out, nil
+	default:
+		return nil, errcode.New(errcode.MediaSize, "invalid image size for type "+string(typ))
+	}
+}
@@ -0,0 +1,55 @@
+package media
+
+import (
+	"image"
+	"time"
+)
+
+// maxFramesBytes is about the most memory that the frames of an animated image or a sequence can take, since every
+// frame is kept in memory. Frames past it aren't loaded.
+const maxFramesBytes = 128 * 1024
+
+// imageBytes is about how much memory the pixels of an image take.
+func imageBytes(img image.Image) int {
+	switch img := img.(type) {
+	case *image.Paletted:
+		return len(img.Pix)
+	case *image.RGBA:
+		return len(img.Pix)
+	default:
+		b := img.Bounds()
+		return b.Dx() * b.Dy() * 4
+	}
+}
+
+// Frame is one frame of an animated image, and how long it is shown for.
+type Frame struct {
+	Image image.Image
+	Delay time.Duration
+}
+
+// Frames is every frame of an animated image.
+type Frames struct {
+	Frames []Frame
+	// Loops is how many times to play the frames, or 0 to play them forever.
+	Loops int
+}
+
+// LoadFrames loads every frame of the named image of the type. Animated GIFs (e.g. exported from Aseprite) are
+// put together frame by frame as a browser would show them; any other image is a single frame.
+func LoadFrames(typ Type, name string) (*Frames, error) {
+	r, d, err := open(typ, name)
+	if err != nil {
+		return nil, err
+	}
+	if d.ext == ".gif" {
+		defer r.Close()
+		return loadGIF(typ, name, r)
+	}
+	_ = r.Close()
+	img, err := LoadImage(typ, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Frames{Frames: []Frame{{Image: img}}}, nil
+}
@@ -0,0 +1,129 @@
+//go:build !minimalmedia
+
+package media
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+const (
+	// defaultFrameDelay is how long frames without a delay are shown, the same as most browsers.
+	defaultFrameDelay = 100 * time.Millisecond
+)
+
+// loadGIF puts together the frames of an animated GIF as a browser would show them.
+func loadGIF(typ Type, name string, r io.Reader) (*Frames, error) {
+	g, err := gif.DecodeAll(r)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.MediaDecode, err)
+	}
+	if len(g.Image) == 0 {
+		return nil, errcode.New(errcode.MediaDecode, "no frames")
+	}
+
+	out := &Frames{}
+	switch {
+	case g.LoopCount < 0:
+		out.Loops = 1
+	case g.LoopCount > 0:
+		out.Loops = g.LoopCount + 1
+	}
+	screen := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if screen.Empty() {
+		screen = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(screen)
+	var previous *image.RGBA
+	size := 0
+	for i, frame := range g.Image {
+		disposal := byte(gif.DisposalNone)
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		if disposal == gif.DisposalPrevious {
+			previous = image.NewRGBA(screen)
+			copy(previous.Pix, canvas.Pix)
+		}
+		draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+
+		img, err := prepare(typ, canvas, frame.Palette)
+		if err != nil {
+			return nil, err
+		}
+		if size += imageBytes(img); size > maxFramesBytes && i > 0 {
+			logger(true, string(typ)+" "+name+" is too big, only the first "+strconv.Itoa(i)+" frames are used")
+			break
+		}
+		delay := defaultFrameDelay
+		if i < len(g.Delay) && g.Delay[i] > 0 {
+			delay = time.Duration(g.Delay[i]) * 10 * time.Millisecond
+		}
+		out.Frames = append(out.Frames, Frame{Image: img, Delay: delay})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, frame.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			canvas = previous
+		}
+	}
+	return out, nil
+}
+
+// prepare makes a copy of a frame that is the right size for the type and in the palette, if there is one. Otherwise
+// it is kept in the GIF's palette if it can be, which takes a quarter of the memory.
+func prepare(typ Type, img *image.RGBA, p color.Palette) (image.Image, error) {
+	var out image.Image = img
+	w, h := typ.Size()
+	if b := img.Bounds(); b.Dx() != int(w) || b.Dy() != int(h) {
+		fitted, err := fit(img, typ)
+		if err != nil {
+			return nil, err
+		}
+		out = fitted
+	}
+	if palette != nil {
+		return quantize(out), nil
+	}
+	if pi, ok := inPalette(out, p); ok {
+		return pi, nil
+	}
+	if out != img {
+		return out, nil
+	}
+	// the canvas is drawn over for the next frame
+	cp := image.NewRGBA(img.Bounds())
+	copy(cp.Pix, img.Pix)
+	return cp, nil
+}
+
+// inPalette converts the image to the palette, if every pixel is exactly one of its colors.
+func inPalette(img image.Image, p color.Palette) (*image.Paletted, bool) {
+	if len(p) == 0 {
+		return nil, false
+	}
+	index := make(map[color.RGBA]uint8, len(p))
+	for i := len(p) - 1; i >= 0; i-- {
+		index[color.RGBAModel.Convert(p[i]).(color.RGBA)] = uint8(i)
+	}
+	b := img.Bounds()
+	out := image.NewPaletted(b, p)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i, ok := index[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]
+			if !ok {
+				return nil, false
+			}
+			out.Pix[out.PixOffset(x, y)] = i
+		}
+	}
+	return out, true
+}
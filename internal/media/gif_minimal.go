@@ -0,0 +1,14 @@
+//go:build minimalmedia
+
+package media
+
+import (
+	"io"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// loadGIF is never called in builds with the minimalmedia build tag, since GIFs aren't found without a decoder.
+func loadGIF(typ Type, name string, r io.Reader) (*Frames, error) {
+	return nil, errcode.New(errcode.MediaDecode, "GIF support not built in")
+}
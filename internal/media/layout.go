@@ -0,0 +1,42 @@
+package media
+
+import (
+	"image"
+	"strconv"
+	"strings"
+)
+
+// layoutFile is the media manifest that moves parts of the face, in stored media, for art drawn for a head with a
+// different shape. Each line is a part and where its top-left corner goes, e.g. "nose -12 8". Negative positions
+// count from the right or bottom edge of the display. Lines starting with # are comments.
+const layoutFile = "media/layout.txt"
+
+// PartPositions returns the positions of the parts of the face from the manifest, by part name ("eye", "nose", or
+// "mouth"), or nil if there is no manifest. Lines that can't be parsed are skipped.
+func PartPositions() map[string]image.Point {
+	b, err := readFile(layoutFile)
+	if err != nil {
+		return nil
+	}
+	pos := make(map[string]image.Point)
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 3 {
+			continue
+		}
+		x, err := strconv.Atoi(f[1])
+		if err != nil {
+			continue
+		}
+		y, err := strconv.Atoi(f[2])
+		if err != nil {
+			continue
+		}
+		pos[f[0]] = image.Pt(x, y)
+	}
+	return pos
+}
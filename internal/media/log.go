@@ -0,0 +1,13 @@
+package media
+
+// logger gets messages about the media; see SetLogger.
+var logger = func(warning bool, msg string) {
+	println(msg)
+}
+
+// SetLogger sends messages about the media, like images that can't be seen in the panels' colors, to the function
+// instead of the console. warning is false for news that isn't a problem, like the media changing on disk. The function
+// may be called from any goroutine, since Watch checks for changes in the background.
+func SetLogger(f func(warning bool, msg string)) {
+	logger = f
+}
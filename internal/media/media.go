@@ -4,20 +4,94 @@ import (
 	"embed"
 	"errors"
 	"image"
+	"io"
+	"io/fs"
 	"strings"
+	"sync/atomic"
 
-	"golang.org/x/image/bmp"
+	"github.com/ajanata/gotogen/internal/errcode"
 )
 
 //go:embed media/*/*.bmp
 var imgs embed.FS
 
-// LoadImage loads the specified image of the specified type.
+// override is an optional filesystem that is checked for media before falling back to the embedded media. It has the
+// same layout as the embedded media: one directory per Type.
+var override fs.FS
+
+// generation is incremented every time the media may have changed, so anything caching images knows to reload them.
+var generation uint32
+
+// Generation returns a counter that changes whenever the media may have changed.
+func Generation() uint32 {
+	return atomic.LoadUint32(&generation)
+}
+
+// changed signals that the media may have changed.
+func changed() {
+	atomic.AddUint32(&generation, 1)
+}
+
+// SetOverride uses media from the filesystem in preference to the embedded media. The filesystem has the same layout as
+// the embedded media, e.g. media/eye/default.bmp. Calling it again with the same filesystem makes anything using the
+// media reload it, e.g. after files were written to it.
+func SetOverride(fsys fs.FS) {
+	override = fsys
+	changed()
+}
+
+// decoder is a supported image format.
+type decoder struct {
+	ext    string
+	decode func(io.Reader) (image.Image, error)
+}
+
+// open finds the named image of the specified type, first in the override filesystem and the driver's provider (if
+// any) then in the embedded media, returning the file and its decoder.
+func open(typ Type, name string) (fs.File, decoder, error) {
+	var lastErr error
+	for _, p := range sources() {
+		for _, d := range decoders {
+			path := "media/" + string(typ) + "/" + name + d.ext
+			// checking first is cheaper than failing to open on slow storage like SD cards
+			fi, err := p.Stat(path)
+			if err == nil && fi.IsDir() {
+				err = errors.New("cannot open directory")
+			}
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			r, err := p.Open(path)
+			if err == nil {
+				return r, d, nil
+			}
+			lastErr = err
+		}
+	}
+	return nil, decoder{}, errcode.Wrap(errcode.MediaMissing, lastErr)
+}
+
+// LoadImage loads the specified image of the specified type. If a palette has been set, the image is limited to it.
 func LoadImage(typ Type, name string) (image.Image, error) {
-	r, err := imgs.Open("media/" + string(typ) + "/" + name + ".bmp")
+	img, err := loadImage(typ, name)
+	if err != nil || palette == nil {
+		return img, err
+	}
+	q := quantize(img)
+	if visible(img) && !visible(q) {
+		logger(true, string(typ)+" "+name+" is blank in the panel's colors")
+	}
+	return q, nil
+}
+
+// loadImage loads the image as it is, other than fitting it to the type's size.
+func loadImage(typ Type, name string) (image.Image, error) {
+	r, d, err := open(typ, name)
 	if err != nil {
 		return nil, err
 	}
+	defer r.Close()
 
 	fi, err := r.Stat()
 	if err != nil {
@@ -25,40 +99,89 @@ func LoadImage(typ Type, name string) (image.Image, error) {
 	}
 
 	if fi.IsDir() {
-		return nil, errors.New("cannot open directory")
+		return nil, errcode.New(errcode.MediaMissing, "cannot open directory")
 	}
 
 	w, h := typ.Size()
 	if w == 0 || h == 0 {
-		return nil, errors.New("invalid media type")
+		return nil, errcode.New(errcode.MediaType, "invalid media type")
 	}
 
-	img, err := bmp.Decode(r)
+	img, err := d.decode(r)
 	if err != nil {
-		return nil, err
+		return nil, errcode.Wrap(errcode.MediaDecode, err)
 	}
 
 	b := img.Bounds()
 	iw, ih := int16(b.Max.X-b.Min.X), int16(b.Max.Y-b.Min.Y)
 	if w != iw || h != ih {
-		return nil, errors.New("invalid image size for type " + string(typ))
+		return fit(img, typ)
 	}
 
 	return img, nil
 }
 
+// Enumerate lists the names of the images of the type, from all of the media.
 func Enumerate(typ Type) ([]string, error) {
-	dir, err := imgs.ReadDir("media/" + string(typ))
+	files, err := FromFS(imgs).Enumerate("media/" + string(typ))
 	if err != nil {
 		return nil, err
 	}
+	for _, p := range stored() {
+		// it's fine for stored media to not have every type
+		extra, err := p.Enumerate("media/" + string(typ))
+		if err == nil {
+			files = append(files, extra...)
+		}
+	}
 
 	var names []string
-	for _, f := range dir {
-		if !f.IsDir() {
-			names = append(names, strings.TrimSuffix(f.Name(), ".bmp"))
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for _, d := range decoders {
+			if !strings.HasSuffix(f, d.ext) {
+				continue
+			}
+			name := strings.TrimSuffix(f, d.ext)
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
 		}
 	}
 
 	return names, nil
 }
+
+// ReadFile reads a file that isn't an image, like a teleprompter script, from the override filesystem or the driver's
+// provider. The file name includes its extension.
+func ReadFile(typ Type, file string) ([]byte, error) {
+	return readFile("media/" + string(typ) + "/" + file)
+}
+
+// ReadFileMax is ReadFile for files that should be no longer than max bytes, like scripts with a size limit. Longer
+// files are refused without reading more than max bytes of them, so they can't run out of memory.
+func ReadFileMax(typ Type, file string, max int) ([]byte, error) {
+	return readFileMax("media/"+string(typ)+"/"+file, max)
+}
+
+// EnumerateFiles lists the files of the type with the extension in the override filesystem and the driver's provider,
+// without the extension.
+func EnumerateFiles(typ Type, ext string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range stored() {
+		files, err := p.Enumerate("media/" + string(typ))
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			name := strings.TrimSuffix(f, ext)
+			if strings.HasSuffix(f, ext) && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
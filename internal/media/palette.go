@@ -0,0 +1,55 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// palette is the colors that images are limited to when they are loaded, or nil for full color.
+var palette color.Palette
+
+// SetPalette limits images to the colors in the palette when they are loaded, for face panels that can only show a few
+// colors. Images are dithered, so that shading and colors the panels can't show still come through as patterns. A nil
+// palette loads images as they are. Anything using the media reloads it.
+func SetPalette(p color.Palette) {
+	palette = p
+	changed()
+}
+
+// quantize dithers the image into the palette.
+func quantize(img image.Image) image.Image {
+	b := img.Bounds()
+	out := image.NewPaletted(b, palette)
+	draw.FloydSteinberg.Draw(out, b, img, b.Min)
+	return out
+}
+
+// visible returns whether anything in the image isn't black.
+func visible(img image.Image) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, _ := img.At(x, y).RGBA()
+			if r|g|bl != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Check reports whether the image can be seen in the palette: an image with something in it that comes out all black
+// once it is limited to the palette's colors can't be seen on the panels at all. It always passes without a palette.
+func Check(typ Type, name string) error {
+	img, err := loadImage(typ, name)
+	if err != nil {
+		return err
+	}
+	if palette != nil && visible(img) && !visible(quantize(img)) {
+		return errcode.New(errcode.MediaColors, "blank in panel colors")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/ajanata/gotogen/gfx"
+)
+
+// placeholderColor is what placeholders are drawn in.
+var placeholderColor = color.RGBA{R: 0x00, G: 0xC0, B: 0xFF, A: 0xFF}
+
+// Placeholder draws a simple stand-in for an image of the type that is missing or can't be loaded, so that the face
+// still looks like a face. The name is used to pick the shape, e.g. "closed" eyes or "talk_2" mouths.
+func Placeholder(typ Type, name string) image.Image {
+	w, h := typ.Size()
+	img := image.NewRGBA(image.Rect(0, 0, int(w), int(h)))
+	c := gfx.FromImage(img)
+	W, H := int(w), int(h)
+	switch typ {
+	case TypeEye:
+		if name == "closed" {
+			gfx.FillRect(c, 2, H/2-1, W-2, H/2+1, placeholderColor)
+		} else {
+			gfx.FillEllipseAA(c, W/2, H/2, W/2-2, H/2-1, placeholderColor)
+		}
+	case TypeNose:
+		// a small triangle pointing down
+		gfx.FillPolygon(c, []image.Point{{W / 4, H / 4}, {W - W/4, H / 4}, {W / 2, H - H/4}}, placeholderColor)
+	case TypeMouth:
+		if strings.HasPrefix(name, "talk_") && len(name) > len("talk_") {
+			// open wider for higher-numbered shapes
+			n := int(name[len(name)-1]-'0') + 1
+			if n < 1 || n > 10 {
+				n = 1
+			}
+			gfx.FillEllipseAA(c, W/2, H/2, W/3, 1+n*(H/2-2)/10, placeholderColor)
+		} else {
+			gfx.FillRect(c, 4, H/2-1, W-4, H/2+1, placeholderColor)
+			gfx.FillRect(c, 2, H/2-3, 4, H/2, placeholderColor)
+			gfx.FillRect(c, W-4, H/2-3, W-2, H/2, placeholderColor)
+		}
+	default:
+		// a crossed-out box, like a missing texture
+		gfx.Rect(c, 0, 0, W, H, placeholderColor)
+		gfx.Line(c, 0, 0, W-1, H-1, placeholderColor)
+		gfx.Line(c, 0, H-1, W-1, 0, placeholderColor)
+	}
+	return img
+}
@@ -0,0 +1,120 @@
+package media
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strconv"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// Provider is a source of media besides the embedded media, e.g. an SD card or a flash filesystem, so that faces can
+// be changed without reflashing. Paths have the same layout as the embedded media, e.g. media/eye/default.bmp.
+type Provider interface {
+	// Open opens the file at the path.
+	Open(path string) (fs.File, error)
+	// Enumerate lists the names of the files in the directory, e.g. media/eye, not including subdirectories.
+	Enumerate(dir string) ([]string, error)
+	// Stat describes the file at the path, without opening it.
+	Stat(path string) (fs.FileInfo, error)
+}
+
+// provider is the media source from the driver, checked after the override filesystem but before the embedded media.
+var provider Provider
+
+// SetProvider uses media from the provider in preference to the embedded media. Calling it again with the same
+// provider makes anything using the media reload it.
+func SetProvider(p Provider) {
+	provider = p
+	changed()
+}
+
+// pack is the current character's face pack, checked before any other media.
+var pack Provider
+
+// SetPack uses the face pack in preference to all other media, or stops using one if it is nil.
+func SetPack(p Provider) {
+	pack = p
+	changed()
+}
+
+// FromFS makes a Provider from a filesystem.
+func FromFS(fsys fs.FS) Provider {
+	return fsProvider{fsys: fsys}
+}
+
+type fsProvider struct {
+	fsys fs.FS
+}
+
+func (p fsProvider) Open(path string) (fs.File, error) {
+	return p.fsys.Open(path)
+}
+
+func (p fsProvider) Enumerate(dir string) ([]string, error) {
+	entries, err := fs.ReadDir(p.fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+func (p fsProvider) Stat(path string) (fs.FileInfo, error) {
+	return fs.Stat(p.fsys, path)
+}
+
+// stored returns the media sources other than the embedded media, in order of preference.
+func stored() []Provider {
+	var ps []Provider
+	if pack != nil {
+		ps = append(ps, pack)
+	}
+	if override != nil {
+		ps = append(ps, FromFS(override))
+	}
+	if provider != nil {
+		ps = append(ps, provider)
+	}
+	return ps
+}
+
+// sources returns every media source, in order of preference, ending with the embedded media.
+func sources() []Provider {
+	return append(stored(), FromFS(imgs))
+}
+
+// readFile reads a file that isn't an image, like a manifest, from the first stored media that has it.
+func readFile(path string) ([]byte, error) {
+	return readFileMax(path, 0)
+}
+
+// readFileMax is readFile for files that are no longer than max bytes, unless it is 0. Longer files are refused
+// without reading any more of them than that.
+func readFileMax(path string, max int) ([]byte, error) {
+	lastErr := errcode.New(errcode.MediaStorage, "no media storage")
+	for _, p := range stored() {
+		f, err := p.Open(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var r io.Reader = f
+		if max > 0 {
+			r = io.LimitReader(f, int64(max)+1)
+		}
+		b, err := io.ReadAll(r)
+		_ = f.Close()
+		if err == nil && max > 0 && len(b) > max {
+			return nil, errors.New(path + ": longer than " + strconv.Itoa(max) + " bytes")
+		}
+		return b, err
+	}
+	return nil, lastErr
+}
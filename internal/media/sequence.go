@@ -0,0 +1,169 @@
+package media
+
+import (
+	"image"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// sequencesFile is the media manifest that says how to play frame sequences, in stored media. Each line is a sequence,
+// its framerate, and optionally loop, pingpong, or once, e.g. "wave 12 pingpong". Sequences that aren't listed play at
+// DefaultSequenceFPS and loop. Lines starting with # are comments.
+const sequencesFile = "media/sequences.txt"
+
+const (
+	// DefaultSequenceFPS is the framerate of sequences that the manifest doesn't list.
+	DefaultSequenceFPS = 10
+	// maxSequenceFPS is the fastest a sequence can be played.
+	maxSequenceFPS = 60
+)
+
+// SequenceMode is what a sequence does when it gets to its last frame.
+type SequenceMode uint8
+
+const (
+	// SequenceLoop starts again from the first frame.
+	SequenceLoop SequenceMode = iota
+	// SequencePingPong plays backwards to the first frame, and then forwards again.
+	SequencePingPong
+	// SequenceOnce stops.
+	SequenceOnce
+)
+
+func (m SequenceMode) String() string {
+	switch m {
+	case SequenceLoop:
+		return "loop"
+	case SequencePingPong:
+		return "pingpong"
+	case SequenceOnce:
+		return "once"
+	default:
+		return "INVALID"
+	}
+}
+
+// Sequence is a numbered sequence of images, like full/wave_000.bmp to full/wave_029.bmp, for animations drawn frame
+// by frame.
+type Sequence struct {
+	Frames []image.Image
+	FPS    int
+	Mode   SequenceMode
+}
+
+// SequenceFrame splits the name of a frame of a sequence, like wave_012, into the sequence's name and the frame
+// number. It returns false for names that don't end in an underscore and a number.
+func SequenceFrame(name string) (seq string, n int, ok bool) {
+	i := strings.LastIndexByte(name, '_')
+	if i <= 0 || i == len(name)-1 {
+		return "", 0, false
+	}
+	for _, c := range name[i+1:] {
+		if c < '0' || c > '9' {
+			return "", 0, false
+		}
+	}
+	n, err := strconv.Atoi(name[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return name[:i], n, true
+}
+
+// Sequences finds the sequences among the names of images, in the order they first appear. A name is only a sequence
+// if it has at least two frames, so that an image that happens to end in a number isn't mistaken for one.
+func Sequences(names []string) []string {
+	var seqs []string
+	count := make(map[string]int)
+	for _, name := range names {
+		if seq, _, ok := SequenceFrame(name); ok {
+			if count[seq]++; count[seq] == 2 {
+				seqs = append(seqs, seq)
+			}
+		}
+	}
+	return seqs
+}
+
+// LoadSequence loads the frames of the named sequence of the type, in order of their numbers, and how to play them from
+// the manifest. Every frame is kept in memory, so a long sequence is cut short once its frames take too much of it.
+func LoadSequence(typ Type, name string) (*Sequence, error) {
+	names, err := Enumerate(typ)
+	if err != nil {
+		return nil, errcode.Wrap(errcode.MediaMissing, err)
+	}
+	type numbered struct {
+		name string
+		n    int
+	}
+	var frames []numbered
+	for _, f := range names {
+		if seq, n, ok := SequenceFrame(f); ok && seq == name {
+			frames = append(frames, numbered{f, n})
+		}
+	}
+	if len(frames) == 0 {
+		return nil, errcode.New(errcode.MediaMissing, "no frames of sequence "+name)
+	}
+	sort.Slice(frames, func(i, j int) bool { return frames[i].n < frames[j].n })
+
+	s := &Sequence{}
+	size := 0
+	for i, f := range frames {
+		img, err := LoadImage(typ, f.name)
+		if err != nil {
+			return nil, errcode.Context(f.name, err)
+		}
+		if size += imageBytes(img); size > maxFramesBytes && i > 0 {
+			logger(true, string(typ)+" "+name+" is too big, only the first "+strconv.Itoa(i)+" frames are used")
+			break
+		}
+		s.Frames = append(s.Frames, img)
+	}
+	s.FPS, s.Mode = sequenceSettings(name)
+	return s, nil
+}
+
+// sequenceSettings reads how to play the sequence from the manifest. Lines that can't be parsed are skipped.
+func sequenceSettings(name string) (fps int, mode SequenceMode) {
+	fps, mode = DefaultSequenceFPS, SequenceLoop
+	b, err := readFile(sequencesFile)
+	if err != nil {
+		return fps, mode
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		f := strings.Fields(line)
+		if f[0] != name || len(f) < 2 || len(f) > 3 {
+			continue
+		}
+		n, err := strconv.Atoi(f[1])
+		if err != nil || n < 1 || n > maxSequenceFPS {
+			continue
+		}
+		m := SequenceLoop
+		if len(f) == 3 {
+			var ok bool
+			if m, ok = parseSequenceMode(f[2]); !ok {
+				continue
+			}
+		}
+		return n, m
+	}
+	return fps, mode
+}
+
+func parseSequenceMode(s string) (SequenceMode, bool) {
+	for m := SequenceLoop; m <= SequenceOnce; m++ {
+		if m.String() == s {
+			return m, true
+		}
+	}
+	return 0, false
+}
@@ -7,6 +7,11 @@ const (
 	TypeMouth Type = "mouth"
 	TypeNose  Type = "nose"
 	TypeFull  Type = "full"
+	// TypeScript is teleprompter scripts, which are text rather than images. They are only ever in stored media,
+	// not the embedded media.
+	TypeScript Type = "script"
+	// TypeBehavior is behavior scripts, which are also text and only in stored media.
+	TypeBehavior Type = "behavior"
 )
 
 func (t Type) Size() (w int16, h int16) {
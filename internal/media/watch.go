@@ -0,0 +1,69 @@
+//go:build !tinygo
+
+package media
+
+import (
+	"io/fs"
+	"os"
+	"time"
+)
+
+// watchInterval is how often the media directory is checked for changes.
+const watchInterval = 500 * time.Millisecond
+
+// Watch uses media from the directory on disk in preference to the embedded media, and watches it for changes. The
+// directory has the same layout as the embedded media, e.g. dir/media/eye/default.png. This is only available on
+// OS-based builds, as it is meant to speed up iterating on art during development.
+func Watch(dir string) error {
+	fsys := os.DirFS(dir)
+	mod, err := modTimes(fsys)
+	if err != nil {
+		return err
+	}
+	override = fsys
+	changed()
+
+	go func() {
+		for range time.Tick(watchInterval) {
+			cur, err := modTimes(fsys)
+			if err != nil {
+				logger(true, "watching media: "+err.Error())
+				continue
+			}
+			if !sameTimes(mod, cur) {
+				logger(false, "media changed on disk, reloading")
+				mod = cur
+				changed()
+			}
+		}
+	}()
+	return nil
+}
+
+func modTimes(fsys fs.FS) (map[string]time.Time, error) {
+	times := make(map[string]time.Time)
+	err := fs.WalkDir(fsys, "media", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		times[path] = fi.ModTime()
+		return nil
+	})
+	return times, err
+}
+
+func sameTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, t := range a {
+		if !b[k].Equal(t) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package mirror
 
 import (
+	"image"
 	"image/color"
 
 	"tinygo.org/x/drivers"
@@ -12,10 +13,22 @@ type Display interface {
 	CanUpdateNow() bool
 }
 
+// Seam is what to do with the middle column of a display with an odd width, which belongs to neither half.
+type Seam uint8
+
+const (
+	// SeamSkip leaves the middle column dark.
+	SeamSkip Seam = iota
+	// SeamDuplicate draws the column nearest the middle of each half into the middle column, so both halves share it.
+	SeamDuplicate
+)
+
 type Mirror struct {
 	d     Display
 	realW int16
-	w, h  int16
+	// half is how many columns of the display each half has
+	half int16
+	w, h int16
 }
 
 func New(d Display) *Mirror {
@@ -23,16 +36,35 @@ func New(d Display) *Mirror {
 	return &Mirror{
 		d:     d,
 		realW: w,
+		half:  w / 2,
 		w:     w / 2,
 		h:     h,
 	}
 }
 
+// SetSeam changes how the middle of the display is handled. The gap is how many pixels' worth of bezel there is
+// between the panels on each side of the middle; that many columns are added to the middle of each half that aren't
+// drawn anywhere, so that art lines up across the gap. This changes the Size.
+func (m *Mirror) SetSeam(s Seam, gap int16) {
+	m.half = m.realW / 2
+	if s == SeamDuplicate {
+		m.half = (m.realW + 1) / 2
+	}
+	if gap < 0 {
+		gap = 0
+	}
+	m.w = m.half + gap
+}
+
 func (m *Mirror) Size() (x, y int16) {
 	return m.w, m.h
 }
 
 func (m *Mirror) SetPixel(x, y int16, c color.RGBA) {
+	if x >= m.half {
+		// in the bezel gap
+		return
+	}
 	m.d.SetPixel(x, y, c)
 	m.d.SetPixel(m.realW-x-1, y, c)
 }
@@ -44,3 +76,29 @@ func (m *Mirror) Display() error {
 func (m *Mirror) CanUpdateNow() bool {
 	return m.d.CanUpdateNow()
 }
+
+// PartialDisplay is implemented by displays that can send only part of their contents to the hardware.
+type PartialDisplay interface {
+	DisplayRegion(r image.Rectangle) error
+}
+
+// DisplayRegion sends the region, and its mirror image, to the underlying display. If the underlying display does not
+// support partial updates, the entire display is updated.
+func (m *Mirror) DisplayRegion(r image.Rectangle) error {
+	pd, ok := m.d.(PartialDisplay)
+	if !ok {
+		return m.d.Display()
+	}
+	if r.Max.X > int(m.half) {
+		r.Max.X = int(m.half)
+	}
+	if r.Empty() {
+		return nil
+	}
+	err := pd.DisplayRegion(r)
+	if err != nil {
+		return err
+	}
+	mr := image.Rect(int(m.realW)-r.Max.X, r.Min.Y, int(m.realW)-r.Min.X, r.Max.Y)
+	return pd.DisplayRegion(mr)
+}
@@ -0,0 +1,172 @@
+// Package osc decodes Open Sound Control packets, as sent by VJ and show control software like TouchOSC, Resolume, or
+// QLab. Only decoding is supported, since gotogen only ever listens.
+package osc
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// maxBundleDepth limits how deeply bundles may be nested, so a malicious packet can't blow the stack.
+const maxBundleDepth = 4
+
+// Message is a single OSC message. Args are int32, float32, string, []byte, bool, int64, float64, or nil, according to
+// the type tags.
+type Message struct {
+	Address string
+	Args    []interface{}
+}
+
+var errShort = errors.New("osc: packet too short")
+
+// Decode decodes a packet, which is either a single message or a bundle of messages (and possibly other bundles).
+// Bundle time tags are ignored; everything is meant to happen right away.
+func Decode(packet []byte) ([]Message, error) {
+	return decode(packet, nil, 0)
+}
+
+func decode(b []byte, msgs []Message, depth int) ([]Message, error) {
+	if len(b) == 0 {
+		return msgs, errShort
+	}
+	if b[0] != '#' {
+		m, err := decodeMessage(b)
+		if err != nil {
+			return msgs, err
+		}
+		return append(msgs, m), nil
+	}
+
+	tag, b, err := readString(b)
+	if err != nil {
+		return msgs, err
+	}
+	if tag != "#bundle" {
+		return msgs, errors.New("osc: bad bundle")
+	}
+	if depth >= maxBundleDepth {
+		return msgs, errors.New("osc: bundles nested too deeply")
+	}
+	if len(b) < 8 {
+		return msgs, errShort
+	}
+	// skip the time tag
+	b = b[8:]
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return msgs, errShort
+		}
+		size := binary.BigEndian.Uint32(b)
+		b = b[4:]
+		if uint32(len(b)) < size {
+			return msgs, errShort
+		}
+		msgs, err = decode(b[:size], msgs, depth+1)
+		if err != nil {
+			return msgs, err
+		}
+		b = b[size:]
+	}
+	return msgs, nil
+}
+
+func decodeMessage(b []byte) (Message, error) {
+	var m Message
+	var err error
+	m.Address, b, err = readString(b)
+	if err != nil {
+		return m, err
+	}
+	if len(m.Address) == 0 || m.Address[0] != '/' {
+		return m, errors.New("osc: bad address")
+	}
+	if len(b) == 0 {
+		// very old senders leave out the type tags when there are no arguments
+		return m, nil
+	}
+	var tags string
+	tags, b, err = readString(b)
+	if err != nil {
+		return m, err
+	}
+	if len(tags) == 0 || tags[0] != ',' {
+		return m, errors.New("osc: bad type tags")
+	}
+
+	for _, t := range []byte(tags[1:]) {
+		switch t {
+		case 'i':
+			if len(b) < 4 {
+				return m, errShort
+			}
+			m.Args = append(m.Args, int32(binary.BigEndian.Uint32(b)))
+			b = b[4:]
+		case 'f':
+			if len(b) < 4 {
+				return m, errShort
+			}
+			m.Args = append(m.Args, math.Float32frombits(binary.BigEndian.Uint32(b)))
+			b = b[4:]
+		case 'h':
+			if len(b) < 8 {
+				return m, errShort
+			}
+			m.Args = append(m.Args, int64(binary.BigEndian.Uint64(b)))
+			b = b[8:]
+		case 'd':
+			if len(b) < 8 {
+				return m, errShort
+			}
+			m.Args = append(m.Args, math.Float64frombits(binary.BigEndian.Uint64(b)))
+			b = b[8:]
+		case 's', 'S':
+			var s string
+			s, b, err = readString(b)
+			if err != nil {
+				return m, err
+			}
+			m.Args = append(m.Args, s)
+		case 'b':
+			if len(b) < 4 {
+				return m, errShort
+			}
+			size := int(binary.BigEndian.Uint32(b))
+			b = b[4:]
+			// checking the size against the data first keeps pad from overflowing on 32-bit targets
+			if size < 0 || size > len(b) || len(b) < pad(size) {
+				return m, errShort
+			}
+			m.Args = append(m.Args, b[:size])
+			b = b[pad(size):]
+		case 'T':
+			m.Args = append(m.Args, true)
+		case 'F':
+			m.Args = append(m.Args, false)
+		case 'N', 'I':
+			m.Args = append(m.Args, nil)
+		default:
+			return m, errors.New("osc: unsupported type tag " + string(t))
+		}
+	}
+	return m, nil
+}
+
+// pad rounds up to a multiple of 4 bytes, which is how everything in OSC is aligned.
+func pad(n int) int {
+	return (n + 3) &^ 3
+}
+
+// readString reads a null-terminated, padded string, returning it and the rest of the data.
+func readString(b []byte) (string, []byte, error) {
+	for i, c := range b {
+		if c == 0 {
+			n := pad(i + 1)
+			if n > len(b) {
+				return "", nil, errShort
+			}
+			return string(b[:i]), b[n:], nil
+		}
+	}
+	return "", nil, errShort
+}
@@ -0,0 +1,172 @@
+package osc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// str encodes a null-terminated string, padded to 4 bytes.
+func str(s string) []byte {
+	b := append([]byte(s), 0)
+	for len(b)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func u32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32(nil, v)
+}
+
+func u64(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
+
+func cat(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+// bundle wraps the elements in a bundle, with a time tag of "immediately".
+func bundle(elems ...[]byte) []byte {
+	b := cat(str("#bundle"), u64(1))
+	for _, e := range elems {
+		b = cat(b, u32(uint32(len(e))), e)
+	}
+	return b
+}
+
+func TestDecode(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		packet []byte
+		want   []Message
+	}{
+		{
+			name:   "no arguments",
+			packet: cat(str("/gotogen/stop"), str(",")),
+			want:   []Message{{Address: "/gotogen/stop"}},
+		},
+		{
+			name:   "no type tags",
+			packet: str("/gotogen/stop"),
+			want:   []Message{{Address: "/gotogen/stop"}},
+		},
+		{
+			// the address is exactly 4 bytes, so its terminator takes another 4
+			name:   "padding after a multiple of 4",
+			packet: cat(str("/abc"), str(",i"), u32(7)),
+			want:   []Message{{Address: "/abc", Args: []interface{}{int32(7)}}},
+		},
+		{
+			name:   "int and float",
+			packet: cat(str("/gotogen/brightness"), str(",if"), u32(0xFFFFFFFF), u32(math.Float32bits(0.5))),
+			want:   []Message{{Address: "/gotogen/brightness", Args: []interface{}{int32(-1), float32(0.5)}}},
+		},
+		{
+			name:   "64-bit",
+			packet: cat(str("/x"), str(",hd"), u64(1<<40), u64(math.Float64bits(-2.25))),
+			want:   []Message{{Address: "/x", Args: []interface{}{int64(1 << 40), float64(-2.25)}}},
+		},
+		{
+			name:   "strings",
+			packet: cat(str("/gotogen/expr"), str(",sS"), str("happy"), str("")),
+			want:   []Message{{Address: "/gotogen/expr", Args: []interface{}{"happy", ""}}},
+		},
+		{
+			name:   "blob",
+			packet: cat(str("/x"), str(",bi"), u32(5), []byte{1, 2, 3, 4, 5, 0, 0, 0}, u32(9)),
+			want:   []Message{{Address: "/x", Args: []interface{}{[]byte{1, 2, 3, 4, 5}, int32(9)}}},
+		},
+		{
+			name:   "no-data tags",
+			packet: cat(str("/x"), str(",TFNI")),
+			want:   []Message{{Address: "/x", Args: []interface{}{true, false, nil, nil}}},
+		},
+		{
+			name:   "bundle",
+			packet: bundle(cat(str("/a"), str(",i"), u32(1)), bundle(cat(str("/b"), str(",")))),
+			want:   []Message{{Address: "/a", Args: []interface{}{int32(1)}}, {Address: "/b"}},
+		},
+		{
+			name:   "empty bundle",
+			packet: bundle(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Decode(tc.packet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	nested := cat(str("/x"), str(","))
+	for i := 0; i <= maxBundleDepth; i++ {
+		nested = bundle(nested)
+	}
+	for _, tc := range []struct {
+		name   string
+		packet []byte
+	}{
+		{"empty", nil},
+		{"unterminated address", []byte("/gotogen")},
+		{"unpadded address", []byte("/gotogen\x00")},
+		{"no slash", cat(str("gotogen"), str(","))},
+		{"empty address", cat(str(""), str(","))},
+		{"no comma", cat(str("/x"), str("i"), u32(1))},
+		{"unterminated type tags", cat(str("/x"), []byte(",iii"))},
+		{"short int", cat(str("/x"), str(",i"), []byte{0, 0})},
+		{"missing int", cat(str("/x"), str(",ii"), u32(1))},
+		{"short float", cat(str("/x"), str(",f"))},
+		{"short int64", cat(str("/x"), str(",h"), u32(1))},
+		{"short double", cat(str("/x"), str(",d"), u32(1))},
+		{"unterminated string", cat(str("/x"), str(",s"), []byte("abcd"))},
+		{"short blob size", cat(str("/x"), str(",b"), []byte{0, 0})},
+		{"short blob", cat(str("/x"), str(",b"), u32(8), []byte{1, 2, 3, 4})},
+		{"unpadded blob", cat(str("/x"), str(",b"), u32(5), []byte{1, 2, 3, 4, 5})},
+		{"huge blob", cat(str("/x"), str(",b"), u32(0xFFFFFFFF), []byte{1, 2, 3, 4})},
+		{"huge positive blob", cat(str("/x"), str(",b"), u32(0x7FFFFFFF), []byte{1, 2, 3, 4})},
+		{"unknown type tag", cat(str("/x"), str(",z"))},
+		{"not a bundle", cat(str("#bundlex"), u64(1))},
+		{"short time tag", cat(str("#bundle"), u32(1))},
+		{"short element size", cat(str("#bundle"), u64(1), []byte{0, 0})},
+		{"element past the end", cat(str("#bundle"), u64(1), u32(100), str("/x"))},
+		{"empty element", cat(str("#bundle"), u64(1), u32(0))},
+		{"bad element", bundle(str("x"))},
+		{"nested too deeply", nested},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if msgs, err := Decode(tc.packet); err == nil {
+				t.Errorf("no error, got %#v", msgs)
+			}
+		})
+	}
+}
+
+func TestDecodeBundleKeepsEarlierMessages(t *testing.T) {
+	msgs, err := Decode(bundle(cat(str("/a"), str(",")), cat(str("/b"), str(",z"))))
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if len(msgs) != 1 || msgs[0].Address != "/a" {
+		t.Errorf("got %#v, want just /a", msgs)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(cat(str("/gotogen/expr"), str(",s"), str("happy")))
+	f.Add(cat(str("/x"), str(",bifhdTFNI"), u32(1), []byte{9, 0, 0, 0}, u32(2), u32(3), u64(4), u64(5)))
+	f.Add(bundle(cat(str("/a"), str(",i"), u32(1)), bundle(str("/b"))))
+	f.Fuzz(func(t *testing.T, packet []byte) {
+		// anything at all may come in over UDP, so decoding mustn't panic
+		_, _ = Decode(packet)
+	})
+}
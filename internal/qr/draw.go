@@ -0,0 +1,40 @@
+package qr
+
+import (
+	"image"
+	"image/color"
+
+	"tinygo.org/x/drivers"
+)
+
+// Draw draws the code as big as it fits in the rectangle, centered, with the rest of the rectangle as the quiet zone.
+// Light modules are lit and dark modules are off, the way around that every reader can scan, even though it is the
+// opposite of how the rest of the display looks. If there isn't room for the whole quiet zone, as much as fits is
+// used; most readers manage with less.
+func (c *Code) Draw(disp drivers.Displayer, r image.Rectangle, light color.RGBA) {
+	side := r.Dx()
+	if r.Dy() < side {
+		side = r.Dy()
+	}
+	scale := side / (c.Size + 2*QuietZone)
+	if scale < 1 {
+		scale = 1
+	}
+	offX := r.Min.X + (r.Dx()-c.Size*scale)/2
+	offY := r.Min.Y + (r.Dy()-c.Size*scale)/2
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			mx, my := x-offX, y-offY
+			if mx < 0 || my < 0 {
+				// outside the code on the top or left, which would otherwise round towards the code
+				disp.SetPixel(int16(x), int16(y), light)
+				continue
+			}
+			if c.Black(mx/scale, my/scale) {
+				disp.SetPixel(int16(x), int16(y), color.RGBA{})
+			} else {
+				disp.SetPixel(int16(x), int16(y), light)
+			}
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package qr
+
+// set sets a module, and marks it as part of a fixed pattern if function is set.
+func (c *Code) set(x, y int, dark, function bool) {
+	c.modules[y*c.Size+x] = dark
+	if function {
+		c.function[y*c.Size+x] = true
+	}
+}
+
+// drawFunctionPatterns draws the fixed patterns: the finders in three corners, the timing lines between them, and the
+// alignment pattern. The format information is reserved, to be filled in once the mask is chosen.
+func (c *Code) drawFunctionPatterns(ver version) {
+	for i := 0; i < c.Size; i++ {
+		c.set(6, i, i%2 == 0, true)
+		c.set(i, 6, i%2 == 0, true)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+	if ver.align > 0 {
+		for dy := -2; dy <= 2; dy++ {
+			for dx := -2; dx <= 2; dx++ {
+				c.set(ver.align+dx, ver.align+dy, max(abs(dx), abs(dy)) != 1, true)
+			}
+		}
+	}
+	c.drawFormat(0)
+}
+
+// drawFinder draws a finder pattern and the light separator around it, centered on the module.
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+				continue
+			}
+			d := max(abs(dx), abs(dy))
+			c.set(x, y, d != 2 && d != 4, true)
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information: the error correction level and the mask.
+func (c *Code) drawFormat(mask int) {
+	// low error correction is 01
+	data := 1<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(8, i, bit(i), true)
+	}
+	c.set(8, 7, bit(6), true)
+	c.set(8, 8, bit(7), true)
+	c.set(7, 8, bit(8), true)
+	for i := 9; i < 15; i++ {
+		c.set(14-i, 8, bit(i), true)
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(c.Size-1-i, 8, bit(i), true)
+	}
+	for i := 8; i < 15; i++ {
+		c.set(8, c.Size-15+i, bit(i), true)
+	}
+	// always dark
+	c.set(8, c.Size-8, true, true)
+}
+
+// drawData fills the modules that aren't part of the fixed patterns with the codewords, in the zigzag order that
+// readers expect: up and down two columns at a time, from the right.
+func (c *Code) drawData(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// the vertical timing line takes up a whole column
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < c.Size; vert++ {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.function[y*c.Size+x] {
+					continue
+				}
+				// any modules left over after the codewords are light
+				if i < len(codewords)*8 {
+					c.modules[y*c.Size+x] = codewords[i>>3]>>(7-i&7)&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules picked out by the mask pattern. Applying the same mask twice undoes it.
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.function[y*c.Size+x] {
+				c.modules[y*c.Size+x] = !c.modules[y*c.Size+x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code would be to read, so the mask that makes it easiest can be used: long runs of the
+// same color, blocks of the same color, patterns that look like finders, and too much of one color.
+func (c *Code) penalty() int {
+	p := 0
+	dark := 0
+	for a := 0; a < c.Size; a++ {
+		// a is the row for the first pass and the column for the second
+		for _, horizontal := range [2]bool{true, false} {
+			at := func(b int) bool {
+				if horizontal {
+					return c.Black(b, a)
+				}
+				return c.Black(a, b)
+			}
+			run := 0
+			for b := 0; b < c.Size; b++ {
+				if b > 0 && at(b) == at(b-1) {
+					run++
+				} else {
+					run = 1
+				}
+				if run == 5 {
+					p += 3
+				} else if run > 5 {
+					p++
+				}
+				if b+11 <= c.Size && finderLike(at, b) {
+					p += 40
+				}
+			}
+		}
+		for b := 0; b < c.Size; b++ {
+			d := c.Black(b, a)
+			if d {
+				dark++
+			}
+			if a+1 < c.Size && b+1 < c.Size && d == c.Black(b+1, a) && d == c.Black(b, a+1) && d == c.Black(b+1, a+1) {
+				p += 3
+			}
+		}
+	}
+	total := c.Size * c.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return p + k*10
+}
+
+// finderLike returns whether the 11 modules starting at b are a finder pattern with four light modules on one side.
+func finderLike(at func(int) bool, b int) bool {
+	const pattern = 0b10111010000
+	var fwd, rev bool = true, true
+	for i := 0; i < 11; i++ {
+		d := at(b + i)
+		fwd = fwd && d == (pattern>>(10-i)&1 != 0)
+		rev = rev && d == (pattern>>i&1 != 0)
+	}
+	return fwd || rev
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,136 @@
+// Package qr encodes short text as QR codes, small enough to show on the status screen or the face. Only what that
+// needs is supported: versions 1 to 6, with low error correction, in byte mode. That is up to 134 bytes, plenty for a
+// social handle, a URL or a pairing token.
+package qr
+
+import (
+	"errors"
+)
+
+// ErrTooLong is returned for data that doesn't fit in the largest supported code.
+var ErrTooLong = errors.New("qr: too long")
+
+// QuietZone is how many light modules a reader needs around the code to find it.
+const QuietZone = 4
+
+// version describes the layout of a version of QR code with low error correction.
+type version struct {
+	blocks       int
+	dataPerBlock int
+	ecPerBlock   int
+	// align is the position of the alignment pattern in both directions, or 0 if there isn't one
+	align int
+}
+
+var versions = [...]version{
+	{blocks: 1, dataPerBlock: 19, ecPerBlock: 7},
+	{blocks: 1, dataPerBlock: 34, ecPerBlock: 10, align: 18},
+	{blocks: 1, dataPerBlock: 55, ecPerBlock: 15, align: 22},
+	{blocks: 1, dataPerBlock: 80, ecPerBlock: 20, align: 26},
+	{blocks: 1, dataPerBlock: 108, ecPerBlock: 26, align: 30},
+	{blocks: 2, dataPerBlock: 68, ecPerBlock: 18, align: 34},
+}
+
+// MaxLen is the most bytes that can be encoded.
+var MaxLen = maxLen(len(versions))
+
+// maxLen is how many bytes fit in a version, after the mode and length.
+func maxLen(v int) int {
+	ver := versions[v-1]
+	return ver.blocks*ver.dataPerBlock - 2
+}
+
+// Code is an encoded QR code.
+type Code struct {
+	// Size is how many modules wide and high the code is, not counting the quiet zone.
+	Size    int
+	modules []bool
+	// function marks the modules that are part of the fixed patterns rather than the data
+	function []bool
+}
+
+// Black returns whether the module is dark. Modules outside the code, in the quiet zone, are light.
+func (c *Code) Black(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// Encode encodes the data in the smallest code it fits in.
+func Encode(data []byte) (*Code, error) {
+	v := 1
+	for v <= len(versions) && len(data) > maxLen(v) {
+		v++
+	}
+	if v > len(versions) {
+		return nil, ErrTooLong
+	}
+	ver := versions[v-1]
+
+	codewords := codewords(data, ver.blocks*ver.dataPerBlock)
+	codewords = addErrorCorrection(codewords, ver)
+
+	size := 17 + 4*v
+	c := &Code{
+		Size:     size,
+		modules:  make([]bool, size*size),
+		function: make([]bool, size*size),
+	}
+	c.drawFunctionPatterns(ver)
+	c.drawData(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		c.applyMask(mask)
+		c.drawFormat(mask)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		// masking again undoes it
+		c.applyMask(mask)
+	}
+	c.applyMask(best)
+	c.drawFormat(best)
+	return c, nil
+}
+
+// codewords puts the data in byte mode and pads it out to the capacity.
+func codewords(data []byte, capacity int) []byte {
+	out := make([]byte, 0, capacity)
+	// byte mode is 0100, followed by the length in 8 bits and then the data, so everything after the mode is shifted
+	// along by 4 bits
+	prev := byte(len(data))
+	out = append(out, 0x40|prev>>4)
+	for _, b := range data {
+		out = append(out, prev<<4|b>>4)
+		prev = b
+	}
+	// the last 4 bits of the data, then the terminator, which is 4 zero bits
+	out = append(out, prev<<4)
+	for pad := byte(0xEC); len(out) < capacity; pad ^= 0xEC ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+// addErrorCorrection splits the data into blocks, adds error correction to each, and interleaves them.
+func addErrorCorrection(data []byte, ver version) []byte {
+	divisor := rsDivisor(ver.ecPerBlock)
+	ec := make([][]byte, ver.blocks)
+	for i := range ec {
+		ec[i] = rsRemainder(data[i*ver.dataPerBlock:(i+1)*ver.dataPerBlock], divisor)
+	}
+	out := make([]byte, 0, ver.blocks*(ver.dataPerBlock+ver.ecPerBlock))
+	for i := 0; i < ver.dataPerBlock; i++ {
+		for b := 0; b < ver.blocks; b++ {
+			out = append(out, data[b*ver.dataPerBlock+i])
+		}
+	}
+	for i := 0; i < ver.ecPerBlock; i++ {
+		for b := 0; b < ver.blocks; b++ {
+			out = append(out, ec[b][i])
+		}
+	}
+	return out
+}
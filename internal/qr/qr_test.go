@@ -0,0 +1,253 @@
+package qr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGFMul(t *testing.T) {
+	for _, tc := range []struct {
+		power int
+		want  byte
+	}{
+		{0, 1},
+		{8, 29},
+		{25, 3},
+		{255, 1},
+	} {
+		v := byte(1)
+		for i := 0; i < tc.power; i++ {
+			v = gfMul(v, 2)
+		}
+		if v != tc.want {
+			t.Errorf("2^%d = %d, want %d", tc.power, v, tc.want)
+		}
+	}
+	if v := gfMul(0, 0xFF); v != 0 {
+		t.Errorf("0 * 255 = %d, want 0", v)
+	}
+}
+
+func TestRSDivisor(t *testing.T) {
+	// x^7 + a^87 x^6 + a^229 x^5 + a^146 x^4 + a^149 x^3 + a^238 x^2 + a^102 x + a^21
+	want := []byte{127, 122, 154, 164, 11, 68, 117}
+	if got := rsDivisor(7); !bytes.Equal(got, want) {
+		t.Errorf("rsDivisor(7) = %v, want %v", got, want)
+	}
+}
+
+func TestRSRemainder(t *testing.T) {
+	// the data codewords of HELLO WORLD in a 1-M code, and their error correction
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestCodewords(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want []byte
+	}{
+		// 0100 00000000 0000, then padding
+		{"", []byte{0x40, 0x00, 0xEC, 0x11, 0xEC}},
+		// 0100 00000001 01000001 0000
+		{"A", []byte{0x40, 0x14, 0x10, 0xEC, 0x11}},
+		// 0100 00000010 01101000 01101001 0000
+		{"hi", []byte{0x40, 0x26, 0x86, 0x90, 0xEC}},
+		// exactly full, with no room for padding
+		{"hi!", []byte{0x40, 0x36, 0x86, 0x92, 0x10}},
+	} {
+		if got := codewords([]byte(tc.data), 5); !bytes.Equal(got, tc.want) {
+			t.Errorf("codewords(%q) = % X, want % X", tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestEncodeSize(t *testing.T) {
+	for _, tc := range []struct {
+		n    int
+		size int
+	}{
+		{0, 21},
+		{17, 21},
+		{18, 25},
+		{32, 25},
+		{33, 29},
+		{53, 29},
+		{54, 33},
+		{78, 33},
+		{79, 37},
+		{106, 37},
+		{107, 41},
+		{MaxLen, 41},
+	} {
+		c, err := Encode(bytes.Repeat([]byte{'a'}, tc.n))
+		if err != nil {
+			t.Errorf("Encode(%d bytes): %v", tc.n, err)
+			continue
+		}
+		if c.Size != tc.size {
+			t.Errorf("Encode(%d bytes) is %d modules, want %d", tc.n, c.Size, tc.size)
+		}
+	}
+	if MaxLen != 134 {
+		t.Errorf("MaxLen = %d, want 134", MaxLen)
+	}
+	if _, err := Encode(bytes.Repeat([]byte{'a'}, MaxLen+1)); err != ErrTooLong {
+		t.Errorf("Encode(MaxLen+1 bytes) = %v, want ErrTooLong", err)
+	}
+}
+
+// formats are the format information of low error correction codes, by mask.
+var formats = [8]int{
+	0b111011111000100,
+	0b111001011110011,
+	0b111110110101010,
+	0b111100010011101,
+	0b110011000101111,
+	0b110001100011000,
+	0b110110001000001,
+	0b110100101110110,
+}
+
+// readFormat reads both copies of the format information.
+func readFormat(c *Code) (first, second int) {
+	bit := func(x, y, i int) int {
+		if c.Black(x, y) {
+			return 1 << i
+		}
+		return 0
+	}
+	for i := 0; i <= 5; i++ {
+		first |= bit(8, i, i)
+	}
+	first |= bit(8, 7, 6) | bit(8, 8, 7) | bit(7, 8, 8)
+	for i := 9; i < 15; i++ {
+		first |= bit(14-i, 8, i)
+	}
+	for i := 0; i < 8; i++ {
+		second |= bit(c.Size-1-i, 8, i)
+	}
+	for i := 8; i < 15; i++ {
+		second |= bit(8, c.Size-15+i, i)
+	}
+	return first, second
+}
+
+func TestFunctionPatterns(t *testing.T) {
+	for v := 1; v <= len(versions); v++ {
+		c, err := Encode(bytes.Repeat([]byte{'a'}, maxLen(v)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+			for y := 0; y < 7; y++ {
+				for x := 0; x < 7; x++ {
+					ring := max(abs(x-3), abs(y-3))
+					if want := ring != 2; c.Black(corner[0]+x, corner[1]+y) != want {
+						t.Errorf("version %d: finder at %v, module %d,%d is %v", v, corner, x, y, !want)
+					}
+				}
+			}
+		}
+		for i := 8; i < c.Size-8; i++ {
+			if c.Black(i, 6) != (i%2 == 0) || c.Black(6, i) != (i%2 == 0) {
+				t.Errorf("version %d: timing module %d is wrong", v, i)
+			}
+		}
+		if !c.Black(8, c.Size-8) {
+			t.Errorf("version %d: dark module is light", v)
+		}
+	}
+}
+
+func TestDataModules(t *testing.T) {
+	// how many modules each version has for codewords, including the remainder bits left over at the end
+	want := [...]int{208, 359, 567, 807, 1079, 1383}
+	for v := 1; v <= len(versions); v++ {
+		c, err := Encode(bytes.Repeat([]byte{'a'}, maxLen(v)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, f := range c.function {
+			if !f {
+				n++
+			}
+		}
+		if n != want[v-1] {
+			t.Errorf("version %d has %d data modules, want %d", v, n, want[v-1])
+		}
+	}
+}
+
+// readData unmasks the code and reads its codewords back out in zigzag order.
+func readData(c *Code, mask int) []byte {
+	u := &Code{Size: c.Size, modules: append([]bool(nil), c.modules...), function: c.function}
+	u.applyMask(mask)
+	var out []byte
+	i := 0
+	for right := u.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < u.Size; vert++ {
+			y := vert
+			if (right+1)&2 == 0 {
+				y = u.Size - 1 - vert
+			}
+			for x := right; x > right-2; x-- {
+				if u.function[y*u.Size+x] {
+					continue
+				}
+				if i%8 == 0 {
+					out = append(out, 0)
+				}
+				if u.Black(x, y) {
+					out[i/8] |= 0x80 >> (i % 8)
+				}
+				i++
+			}
+		}
+	}
+	// drop the remainder bits
+	return out[:i/8]
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"@ajanata",
+		"https://example.com/gotogen",
+		strings.Repeat("0123456789", 5),
+		strings.Repeat("pairing token ", 7),
+		strings.Repeat("x", MaxLen),
+	} {
+		c, err := Encode([]byte(text))
+		if err != nil {
+			t.Fatalf("Encode(%q): %v", text, err)
+		}
+		first, second := readFormat(c)
+		if first != second {
+			t.Errorf("%q: format copies differ: %015b and %015b", text, first, second)
+		}
+		mask := -1
+		for m, f := range formats {
+			if f == first {
+				mask = m
+			}
+		}
+		if mask < 0 {
+			t.Errorf("%q: format %015b isn't low error correction", text, first)
+			continue
+		}
+		ver := versions[(c.Size-17)/4-1]
+		want := addErrorCorrection(codewords([]byte(text), ver.blocks*ver.dataPerBlock), ver)
+		if got := readData(c, mask); !bytes.Equal(got, want) {
+			t.Errorf("%q: codewords are\n% X\nwant\n% X", text, got, want)
+		}
+	}
+}
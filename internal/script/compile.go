@@ -0,0 +1,375 @@
+// Package script is a small language for behavior scripts, which are loaded from storage so that how the head reacts
+// can be changed without reflashing. Scripts are compiled to bytecode and run a little every frame on a Machine, which
+// limits how many instructions, variables and commands they can use, so that a broken script can't hang the head or
+// run it out of memory.
+//
+// A script is a list of event handlers and variables:
+//
+//	# count boops, and do something special every third one
+//	var boops = 0
+//
+//	on boop
+//	  boops = boops + 1
+//	  if boops % 3 == 0
+//	    do anim slide wait
+//	    wait 2s
+//	    do stop
+//	  else
+//	    do expr happy
+//	  end
+//	end
+//
+//	on every 10s
+//	  if battery >= 0 and battery < 15
+//	    do effect glitch
+//	  end
+//	end
+//
+// Handlers run when their event happens, unless they are still running from last time. Statements are:
+//
+//	do COMMAND...   run a command (see Gotogen.Command), which is the rest of the line, if the host allows it
+//	wait EXPR       pause this handler for EXPR milliseconds (numbers can be written like 2s or 500ms)
+//	NAME = EXPR     set a variable, which must have been declared with var
+//	if EXPR / else / end
+//	while EXPR / end
+//	repeat EXPR / end
+//	stop            end this handler
+//
+// Expressions are 32-bit integers, with the operators or, and, not, == != < <= > >=, + -, * / %, and unary -, from
+// lowest to highest precedence, and parentheses. They can use variables, the host's builtins (see Builtin), and
+// rand(N) for a random number from 0 to N-1. Comparisons and logic give 1 for true and 0 for false.
+package script
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxSource is the longest script, in bytes, that can be compiled.
+	MaxSource = 8 * 1024
+	// MaxCode is the most instructions a script can compile to.
+	MaxCode = 2048
+	// MaxVars is the most variables a script can have, including the hidden counters of repeat loops.
+	MaxVars = 32
+	// MaxHandlers is the most event handlers a script can have.
+	MaxHandlers = 16
+	// MaxText is the most bytes of commands a script can have.
+	MaxText = 4096
+	// minEvery is the shortest period of an every handler.
+	minEvery = 100 * time.Millisecond
+)
+
+// Program is a compiled script.
+type Program struct {
+	code []instr
+	// lines are the line of the script that each instruction came from
+	lines    []int
+	text     []string
+	vars     []int32
+	handlers []handler
+}
+
+// handler is the code for an event.
+type handler struct {
+	event string
+	// every is the period for every handlers, which have an empty event
+	every time.Duration
+	pc    int
+}
+
+// Events returns the names of the events that the script handles, not including every handlers.
+func (p *Program) Events() []string {
+	var events []string
+	for _, h := range p.handlers {
+		if h.event != "" {
+			events = append(events, h.event)
+		}
+	}
+	return events
+}
+
+// block is a statement that is waiting for its end.
+type block struct {
+	kind string
+	// top is where a while or repeat loop jumps back to
+	top int
+	// jumps are the jumps to point at the end (or for if, at the else)
+	jumps []int
+	// counter is the hidden variable of a repeat loop
+	counter int
+}
+
+// compiler turns a script into a Program, a line at a time.
+type compiler struct {
+	p      *Program
+	names  map[string]int
+	blocks []block
+	text   int
+	line   int
+}
+
+// Compile compiles a script.
+func Compile(src string) (*Program, error) {
+	if len(src) > MaxSource {
+		return nil, errors.New("script too long")
+	}
+	c := &compiler{p: &Program{}, names: make(map[string]int)}
+	for i, line := range strings.Split(src, "\n") {
+		c.line = i + 1
+		if err := c.compileLine(line); err != nil {
+			return nil, errors.New("line " + strconv.Itoa(c.line) + ": " + err.Error())
+		}
+	}
+	if len(c.blocks) > 0 {
+		return nil, errors.New("missing end for " + c.blocks[len(c.blocks)-1].kind)
+	}
+	return c.p, nil
+}
+
+func (c *compiler) compileLine(line string) error {
+	if i := strings.IndexByte(line, '#'); i >= 0 && !strings.HasPrefix(strings.TrimSpace(line), "do ") {
+		line = line[:i]
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	word, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	if len(c.blocks) == 0 {
+		switch word {
+		case "var":
+			return c.declare(rest)
+		case "on":
+			return c.on(rest)
+		default:
+			return errors.New("expected var or on, not " + word)
+		}
+	}
+
+	switch word {
+	case "on", "var":
+		return errors.New(word + " inside a handler")
+	case "end":
+		return c.end()
+	case "do":
+		if rest == "" {
+			return errors.New("do needs a command")
+		}
+		if c.text += len(rest); c.text > MaxText {
+			return errors.New("too much command text")
+		}
+		c.p.text = append(c.p.text, rest)
+		return c.emit(opDo, int32(len(c.p.text)-1))
+	case "wait":
+		if err := c.expr(rest); err != nil {
+			return err
+		}
+		return c.emit(opWait, 0)
+	case "stop":
+		return c.emit(opEnd, 0)
+	case "if":
+		if err := c.expr(rest); err != nil {
+			return err
+		}
+		c.blocks = append(c.blocks, block{kind: "if", jumps: []int{len(c.p.code)}})
+		return c.emit(opJumpIfZero, 0)
+	case "else":
+		b := &c.blocks[len(c.blocks)-1]
+		if b.kind != "if" {
+			return errors.New("else without if")
+		}
+		b.kind = "else"
+		// the end of the if part jumps over the else part
+		skip := len(c.p.code)
+		if err := c.emit(opJump, 0); err != nil {
+			return err
+		}
+		c.patch(b.jumps)
+		b.jumps = []int{skip}
+		return nil
+	case "while":
+		top := len(c.p.code)
+		if err := c.expr(rest); err != nil {
+			return err
+		}
+		c.blocks = append(c.blocks, block{kind: "while", top: top, jumps: []int{len(c.p.code)}})
+		return c.emit(opJumpIfZero, 0)
+	case "repeat":
+		if err := c.expr(rest); err != nil {
+			return err
+		}
+		counter, err := c.newVar("", 0)
+		if err != nil {
+			return err
+		}
+		if err = c.emit(opStore, int32(counter)); err != nil {
+			return err
+		}
+		top := len(c.p.code)
+		for _, in := range [...]instr{{opLoad, int32(counter)}, {opPush, 0}, {opGreater, 0}} {
+			if err = c.emit(in.op, in.arg); err != nil {
+				return err
+			}
+		}
+		c.blocks = append(c.blocks, block{kind: "repeat", top: top, jumps: []int{len(c.p.code)}, counter: counter})
+		return c.emit(opJumpIfZero, 0)
+	}
+
+	// anything else has to be an assignment
+	name, value, ok := strings.Cut(line, "=")
+	name = strings.TrimSpace(name)
+	if !ok || strings.HasPrefix(value, "=") {
+		return errors.New("unknown statement " + word)
+	}
+	v, ok := c.names[name]
+	if !ok {
+		return errors.New("undeclared variable " + name)
+	}
+	if err := c.expr(value); err != nil {
+		return err
+	}
+	return c.emit(opStore, int32(v))
+}
+
+// declare handles "var NAME [= VALUE]", where the value is a constant.
+func (c *compiler) declare(rest string) error {
+	name, value, hasValue := strings.Cut(rest, "=")
+	name = strings.TrimSpace(name)
+	if !validName(name) {
+		return errors.New("bad variable name " + name)
+	}
+	if _, ok := c.names[name]; ok {
+		return errors.New("variable " + name + " declared twice")
+	}
+	if _, ok := findBuiltin(name); ok || isKeyword(name) {
+		return errors.New(name + " is reserved")
+	}
+	var v int32
+	if hasValue {
+		value = strings.TrimSpace(value)
+		neg := strings.HasPrefix(value, "-")
+		n, ok := parseNumber(strings.TrimPrefix(value, "-"))
+		if !ok {
+			return errors.New("variables start as a number, not " + value)
+		}
+		if v = n; neg {
+			v = -n
+		}
+	}
+	_, err := c.newVar(name, v)
+	return err
+}
+
+// newVar adds a variable, or a hidden one if the name is empty.
+func (c *compiler) newVar(name string, v int32) (int, error) {
+	if len(c.p.vars) >= MaxVars {
+		return 0, errors.New("too many variables")
+	}
+	c.p.vars = append(c.p.vars, v)
+	i := len(c.p.vars) - 1
+	if name != "" {
+		c.names[name] = i
+	}
+	return i, nil
+}
+
+// on starts a handler: "on EVENT" or "on every DURATION".
+func (c *compiler) on(rest string) error {
+	if len(c.p.handlers) >= MaxHandlers {
+		return errors.New("too many handlers")
+	}
+	h := handler{event: rest, pc: len(c.p.code)}
+	if strings.HasPrefix(rest, "every ") {
+		ms, ok := parseNumber(strings.TrimSpace(rest[len("every "):]))
+		if !ok || time.Duration(ms)*time.Millisecond < minEvery {
+			return errors.New("every needs a period of at least " + minEvery.String())
+		}
+		h.event, h.every = "", time.Duration(ms)*time.Millisecond
+	} else if rest == "" || strings.ContainsAny(rest, " \t") {
+		return errors.New("on needs one event")
+	}
+	c.p.handlers = append(c.p.handlers, h)
+	c.blocks = append(c.blocks, block{kind: "on"})
+	return nil
+}
+
+// end finishes the innermost block.
+func (c *compiler) end() error {
+	b := c.blocks[len(c.blocks)-1]
+	c.blocks = c.blocks[:len(c.blocks)-1]
+	switch b.kind {
+	case "on":
+		return c.emit(opEnd, 0)
+	case "repeat":
+		for _, in := range [...]instr{{opLoad, int32(b.counter)}, {opPush, 1}, {opSub, 0}, {opStore, int32(b.counter)}} {
+			if err := c.emit(in.op, in.arg); err != nil {
+				return err
+			}
+		}
+		fallthrough
+	case "while":
+		if err := c.emit(opJump, int32(b.top)); err != nil {
+			return err
+		}
+	}
+	c.patch(b.jumps)
+	return nil
+}
+
+// patch points the jumps at the next instruction.
+func (c *compiler) patch(jumps []int) {
+	for _, j := range jumps {
+		c.p.code[j].arg = int32(len(c.p.code))
+	}
+}
+
+func (c *compiler) emit(op opcode, arg int32) error {
+	if len(c.p.code) >= MaxCode {
+		return errors.New("script too long")
+	}
+	c.p.code = append(c.p.code, instr{op, arg})
+	c.p.lines = append(c.p.lines, c.line)
+	return nil
+}
+
+// parseNumber parses a non-negative number, which may end in s or ms to be a number of milliseconds.
+func parseNumber(s string) (int32, bool) {
+	scale := int64(1)
+	switch {
+	case strings.HasSuffix(s, "ms"):
+		s = s[:len(s)-2]
+	case strings.HasSuffix(s, "s"):
+		s, scale = s[:len(s)-1], 1000
+	}
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil || n < 0 || n*scale > 1<<31-1 {
+		return 0, false
+	}
+	return int32(n * scale), true
+}
+
+func validName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || i > 0 && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isKeyword(s string) bool {
+	switch s {
+	case "var", "on", "end", "do", "wait", "stop", "if", "else", "while", "repeat", "and", "or", "not", "rand", "every":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+package script
+
+import (
+	"errors"
+	"strings"
+)
+
+// Builtin is a value that the host gives to scripts.
+type Builtin uint8
+
+const (
+	// Boop is the boop sensor's proximity, from 0 to 255.
+	Boop Builtin = iota
+	// Talk is how loud the wearer is talking, from 0 to 255.
+	Talk
+	// Battery is the battery's charge in percent, or -1 if it isn't known.
+	Battery
+	// FPS is the frame rate.
+	FPS
+	// AX, AY and AZ are the acceleration in thousandths of gravity.
+	AX
+	AY
+	AZ
+	// Hour and Minute are the time of day, or -1 if it isn't known.
+	Hour
+	Minute
+	numBuiltins
+)
+
+var builtinNames = [numBuiltins]string{"boop", "talk", "battery", "fps", "ax", "ay", "az", "hour", "minute"}
+
+func (b Builtin) String() string {
+	if b < numBuiltins {
+		return builtinNames[b]
+	}
+	return "INVALID"
+}
+
+func findBuiltin(name string) (Builtin, bool) {
+	for i, n := range builtinNames {
+		if n == name {
+			return Builtin(i), true
+		}
+	}
+	return 0, false
+}
+
+// parser compiles an expression by recursive descent, emitting code for each operator after its operands.
+type parser struct {
+	c    *compiler
+	toks []string
+	pos  int
+	// depth is how deeply the parser is nested in parentheses and unary operators
+	depth int
+}
+
+// expr compiles an expression, which leaves its value on the stack.
+func (c *compiler) expr(s string) error {
+	toks, err := tokenize(s)
+	if err != nil {
+		return err
+	}
+	if len(toks) == 0 {
+		return errors.New("missing expression")
+	}
+	p := &parser{c: c, toks: toks}
+	if err = p.or(); err != nil {
+		return err
+	}
+	if p.pos < len(p.toks) {
+		return errors.New("unexpected " + p.toks[p.pos])
+	}
+	return nil
+}
+
+func tokenize(s string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case isWordByte(c):
+			j := i
+			for j < len(s) && isWordByte(s[j]) {
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		case strings.HasPrefix(s[i:], "==") || strings.HasPrefix(s[i:], "!=") ||
+			strings.HasPrefix(s[i:], "<=") || strings.HasPrefix(s[i:], ">="):
+			toks = append(toks, s[i:i+2])
+			i += 2
+		case strings.IndexByte("+-*/%<>()", c) >= 0:
+			toks = append(toks, s[i:i+1])
+			i++
+		default:
+			return nil, errors.New("unexpected " + string(c))
+		}
+	}
+	return toks, nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ""
+}
+
+// binary parses operands joined by any of the operators, from left to right.
+func (p *parser) binary(operand func() error, ops map[string]opcode) error {
+	if err := operand(); err != nil {
+		return err
+	}
+	for {
+		op, ok := ops[p.peek()]
+		if !ok {
+			return nil
+		}
+		p.pos++
+		if err := operand(); err != nil {
+			return err
+		}
+		if err := p.c.emit(op, 0); err != nil {
+			return err
+		}
+	}
+}
+
+var (
+	orOps         = map[string]opcode{"or": opOr}
+	andOps        = map[string]opcode{"and": opAnd}
+	comparisonOps = map[string]opcode{
+		"==": opEqual, "!=": opNotEqual, "<": opLess, "<=": opLessEqual, ">": opGreater, ">=": opGreaterEqual,
+	}
+	sumOps     = map[string]opcode{"+": opAdd, "-": opSub}
+	productOps = map[string]opcode{"*": opMul, "/": opDiv, "%": opMod}
+)
+
+func (p *parser) or() error         { return p.binary(p.and, orOps) }
+func (p *parser) and() error        { return p.binary(p.not, andOps) }
+func (p *parser) comparison() error { return p.binary(p.sum, comparisonOps) }
+func (p *parser) sum() error        { return p.binary(p.product, sumOps) }
+func (p *parser) product() error    { return p.binary(p.unary, productOps) }
+
+func (p *parser) not() error {
+	if p.peek() != "not" {
+		return p.comparison()
+	}
+	p.pos++
+	if err := p.nest(p.not); err != nil {
+		return err
+	}
+	return p.c.emit(opNot, 0)
+}
+
+func (p *parser) unary() error {
+	if p.peek() != "-" {
+		return p.operand()
+	}
+	p.pos++
+	if err := p.nest(p.unary); err != nil {
+		return err
+	}
+	return p.c.emit(opNeg, 0)
+}
+
+func (p *parser) operand() error {
+	tok := p.peek()
+	if tok == "" {
+		return errors.New("missing value")
+	}
+	p.pos++
+	switch {
+	case tok == "(":
+		return p.nest(func() error { return p.closed(p.or) })
+	case tok == "rand":
+		if p.peek() != "(" {
+			return errors.New("rand needs (")
+		}
+		p.pos++
+		if err := p.nest(func() error { return p.closed(p.or) }); err != nil {
+			return err
+		}
+		return p.c.emit(opRand, 0)
+	case '0' <= tok[0] && tok[0] <= '9':
+		n, ok := parseNumber(tok)
+		if !ok {
+			return errors.New("bad number " + tok)
+		}
+		return p.c.emit(opPush, n)
+	}
+	if b, ok := findBuiltin(tok); ok {
+		return p.c.emit(opBuiltin, int32(b))
+	}
+	if v, ok := p.c.names[tok]; ok {
+		return p.c.emit(opLoad, int32(v))
+	}
+	return errors.New("unknown name " + tok)
+}
+
+// nest parses part of an expression that is nested in another. Nesting deeper than MaxStack is refused, since the
+// expression couldn't run anyway, and so that a script full of parentheses can't overflow the stack while compiling.
+func (p *parser) nest(inner func() error) error {
+	if p.depth++; p.depth > MaxStack {
+		return errors.New("expression too deep")
+	}
+	err := inner()
+	p.depth--
+	return err
+}
+
+// closed parses what is inside parentheses, and the closing one.
+func (p *parser) closed(inner func() error) error {
+	if err := inner(); err != nil {
+		return err
+	}
+	if p.peek() != ")" {
+		return errors.New("missing )")
+	}
+	p.pos++
+	return nil
+}
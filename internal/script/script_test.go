@@ -0,0 +1,363 @@
+package script
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// host records the commands a script runs. Commands starting with "fail" return an error.
+type host struct {
+	values   [numBuiltins]int32
+	commands []string
+}
+
+func (h *host) Value(b Builtin) int32 {
+	return h.values[b]
+}
+
+func (h *host) Command(line string) error {
+	h.commands = append(h.commands, line)
+	if strings.HasPrefix(line, "fail") {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+// take returns the commands run since the last call.
+func (h *host) take() string {
+	s := strings.Join(h.commands, ",")
+	h.commands = nil
+	return s
+}
+
+func start(t *testing.T, src string) (*Machine, *host) {
+	t.Helper()
+	p, err := Compile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &host{}
+	return New(p, h), h
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"statement outside a handler", "do expr happy", "expected var or on"},
+		{"var inside a handler", "on boop\nvar x\nend", "var inside a handler"},
+		{"on inside a handler", "on boop\non shake\nend", "on inside a handler"},
+		{"missing end", "on boop\nif 1\nend", "missing end for on"},
+		{"else without if", "on boop\nelse\nend", "else without if"},
+		{"empty do", "on boop\ndo\nend", "do needs a command"},
+		{"undeclared variable", "on boop\nx = 1\nend", "undeclared variable x"},
+		{"unknown statement", "on boop\nfrobnicate\nend", "unknown statement frobnicate"},
+		{"unknown name", "on boop\nwait y\nend", "unknown name y"},
+		{"declared twice", "var x\nvar x", "declared twice"},
+		{"reserved builtin", "var boop", "reserved"},
+		{"reserved keyword", "var while", "reserved"},
+		{"bad variable name", "var 1x", "bad variable name"},
+		{"non-constant start", "var x = boop", "variables start as a number"},
+		{"two events", "on boop shake\nend", "on needs one event"},
+		{"no event", "on\nend", "on needs one event"},
+		{"every too often", "on every 10ms\nend", "every needs a period"},
+		{"missing )", "on boop\nwait (1\nend", "missing )"},
+		{"rand without (", "on boop\nwait rand 3\nend", "rand needs ("},
+		{"trailing tokens", "on boop\nwait 1 2\nend", "unexpected 2"},
+		{"bad character", "on boop\nwait 1 & 2\nend", "unexpected &"},
+		{"missing operand", "on boop\nwait 1 +\nend", "missing value"},
+		{"bad number", "on boop\nwait 99999999999\nend", "bad number"},
+		{"nested too deeply", "on boop\nwait " + strings.Repeat("(", MaxStack+1) + "1" + strings.Repeat(")", MaxStack+1) + "\nend", "too deep"},
+		{"not too deeply", "on boop\nwait " + strings.Repeat("not ", MaxStack+1) + "1\nend", "too deep"},
+		{"negated too deeply", "on boop\nwait " + strings.Repeat("-", MaxStack+1) + "1\nend", "too deep"},
+		{"rand too deeply", "on boop\nwait " + strings.Repeat("rand(", MaxStack+1) + "1" + strings.Repeat(")", MaxStack+1) + "\nend", "too deep"},
+		{"too long", strings.Repeat("#", MaxSource+1), "script too long"},
+		{"too many variables", varList(MaxVars + 1), "too many variables"},
+		{"too many handlers", strings.Repeat("on boop\nend\n", MaxHandlers+1), "too many handlers"},
+		{"too much command text", "on boop\n" + strings.Repeat("do "+strings.Repeat("x", 100)+"\n", MaxText/100+1) + "end", "too much command text"},
+		{"line number", "on boop\n\n\nfrobnicate\nend", "line 4:"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Compile(tc.src)
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("error %q doesn't mention %q", err, tc.want)
+			}
+		})
+	}
+}
+
+// varList declares n distinct variables.
+func varList(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteString("var v")
+		b.WriteString(strings.Repeat("x", i+1))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func TestCompileDeepSource(t *testing.T) {
+	// a script that is nothing but parentheses must be refused rather than recursing once for each of them
+	src := "on boop\nwait " + strings.Repeat("(", MaxSource-20)
+	if _, err := Compile(src); err == nil || !strings.Contains(err.Error(), "too deep") {
+		t.Errorf("got %v, want too deep", err)
+	}
+}
+
+func TestCompileNesting(t *testing.T) {
+	// nesting up to MaxStack deep is fine
+	src := "on boop\nwait " + strings.Repeat("(", MaxStack) + "1" + strings.Repeat(")", MaxStack) + "\nend"
+	if _, err := Compile(src); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		want int32
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"7 / 2", 3},
+		{"-7 / 2", -3},
+		{"7 % 3", 1},
+		{"- -4", 4},
+		{"1 < 2 and 2 < 1", 0},
+		{"1 < 2 or 2 < 1", 1},
+		{"not 0", 1},
+		{"not 1 == 1", 0},
+		{"2 >= 2", 1},
+		{"3 != 3", 0},
+		{"2s", 2000},
+		{"250ms", 250},
+		{"boop + 1", 43},
+		{"rand(1)", 0},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			m, h := start(t, "var x\non start\nx = "+tc.expr+"\nend")
+			h.values[Boop] = 42
+			m.Fire("start")
+			if err := m.Step(time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			if m.vars[0] != tc.want {
+				t.Errorf("got %d, want %d", m.vars[0], tc.want)
+			}
+		})
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	deep := strings.Repeat("1 + (", MaxStack) + "1" + strings.Repeat(")", MaxStack)
+	for _, tc := range []struct {
+		name string
+		expr string
+		want string
+	}{
+		{"divide by zero", "1 / 0", "divide by zero"},
+		{"modulo by zero", "1 % 0", "divide by zero"},
+		{"divide by zero variable", "1 / x", "divide by zero"},
+		{"modulo by zero variable", "x % x", "divide by zero"},
+		{"stack overflow", deep, "expression too deep"},
+		{"rand of 0", "rand(0)", "rand needs a number above 0"},
+		{"rand of negative", "rand(-3)", "rand needs a number above 0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, _ := start(t, "var x\non start\n\nx = "+tc.expr+"\nend")
+			m.Fire("start")
+			err := m.Step(time.Now())
+			if err == nil {
+				t.Fatal("no error")
+			}
+			if !strings.Contains(err.Error(), tc.want) || !strings.HasPrefix(err.Error(), "line 4: ") {
+				t.Errorf("error %q isn't line 4: ...%s", err, tc.want)
+			}
+			// a stopped machine stays stopped
+			if err2 := m.Step(time.Now()); err2 != err || m.Err() != err {
+				t.Errorf("later Step returned %v, Err %v", err2, m.Err())
+			}
+		})
+	}
+}
+
+func TestStepBudget(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		src  string
+		fail bool
+	}{
+		{"endless loop", "var x\non start\nwhile 1\nx = x + 1\nend\nend", true},
+		{"long repeat", "var x\non start\nrepeat 1000\nx = x + 1\nend\nend", true},
+		{"short repeat", "var x\non start\nrepeat 10\nx = x + 1\nend\nend", false},
+		{"loop with a wait", "var x\non start\nwhile 1\nx = x + 1\nwait 10\nend\nend", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, _ := start(t, tc.src)
+			m.Fire("start")
+			err := m.Step(time.Now())
+			if tc.fail && (err == nil || !strings.Contains(err.Error(), "instructions at once")) {
+				t.Errorf("got %v, want the budget to run out", err)
+			}
+			if !tc.fail && err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestStepBudgetShared(t *testing.T) {
+	// two handlers that each fit in the budget alone don't both fit in one Step; each time round the loop is 13
+	// instructions
+	loop := "repeat " + strconv.Itoa(StepBudget*2/3/13) + "\nx = x + 1\nend\n"
+	m, _ := start(t, "var x\non a\n"+loop+"end\non a\n"+loop+"end")
+	m.Fire("a")
+	if err := m.Step(time.Now()); err == nil {
+		t.Error("no error")
+	}
+}
+
+func TestStepCommands(t *testing.T) {
+	m, h := start(t, "on start\n"+strings.Repeat("do a\n", StepCommands+2)+"do b\nend")
+	m.Fire("start")
+	now := time.Now()
+	if err := m.Step(now); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.take(), strings.TrimSuffix(strings.Repeat("a,", StepCommands), ","); got != want {
+		t.Errorf("first Step ran %q, want %q", got, want)
+	}
+	if err := m.Step(now); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.take(); got != "a,a,b" {
+		t.Errorf("second Step ran %q, want a,a,b", got)
+	}
+	if err := m.Step(now); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.take(); got != "" {
+		t.Errorf("third Step ran %q, want nothing", got)
+	}
+}
+
+func TestStepCommandsTakeTurns(t *testing.T) {
+	// a handler that keeps running commands can't keep the others from running
+	busy := "on start\nwhile 1\n" + strings.Repeat("do a\n", StepCommands) + "end\nend\n"
+	m, h := start(t, busy+"on start\ndo b\nend")
+	m.Fire("start")
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := m.Step(now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := h.take(); !strings.Contains(got, "b") {
+		t.Errorf("the second handler never ran: %q", got)
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	m, h := start(t, "on start\ndo fail now\ndo a\nend")
+	m.Fire("start")
+	err := m.Step(time.Now())
+	if err == nil || err.Error() != "line 2: failed" {
+		t.Errorf("got %v, want line 2: failed", err)
+	}
+	if got := h.take(); got != "fail now" {
+		t.Errorf("ran %q, want just fail now", got)
+	}
+}
+
+func TestWait(t *testing.T) {
+	m, h := start(t, "on start\ndo a\nwait 100\ndo b\nwait -5\ndo c\nend")
+	m.Fire("start")
+	t0 := time.Now()
+	for _, step := range []struct {
+		at   time.Duration
+		want string
+	}{
+		{0, "a"},
+		{50 * time.Millisecond, ""},
+		{99 * time.Millisecond, ""},
+		// a negative wait is no wait, but still lets the Step end
+		{100 * time.Millisecond, "b"},
+		{100 * time.Millisecond, "c"},
+		{time.Second, ""},
+	} {
+		if err := m.Step(t0.Add(step.at)); err != nil {
+			t.Fatal(err)
+		}
+		if got := h.take(); got != step.want {
+			t.Errorf("at %v ran %q, want %q", step.at, got, step.want)
+		}
+	}
+}
+
+func TestFireWhileRunning(t *testing.T) {
+	m, h := start(t, "on boop\ndo a\nwait 1s\ndo b\nend")
+	t0 := time.Now()
+	m.Fire("boop")
+	if err := m.Step(t0); err != nil {
+		t.Fatal(err)
+	}
+	// still waiting, so this doesn't start it again
+	m.Fire("boop")
+	if err := m.Step(t0.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	m.Fire("boop")
+	if err := m.Step(t0.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.take(); got != "a,b,a" {
+		t.Errorf("ran %q, want a,b,a", got)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	m, h := start(t, "on every 1s\ndo tick\nend\non every 250ms\ndo tock\nend")
+	t0 := time.Now()
+	for _, step := range []struct {
+		at   time.Duration
+		want string
+	}{
+		// the first Step only schedules them
+		{0, ""},
+		{249 * time.Millisecond, ""},
+		{250 * time.Millisecond, "tock"},
+		{400 * time.Millisecond, ""},
+		{500 * time.Millisecond, "tock"},
+		{999 * time.Millisecond, "tock"},
+		{time.Second, "tick"},
+		// a late Step runs each handler once, rather than catching up
+		{5 * time.Second, "tick,tock"},
+		{5*time.Second + 250*time.Millisecond, "tock"},
+	} {
+		if err := m.Step(t0.Add(step.at)); err != nil {
+			t.Fatal(err)
+		}
+		if got := h.take(); got != step.want {
+			t.Errorf("at %v ran %q, want %q", step.at, got, step.want)
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	p, err := Compile("on boop\nend\non every 1s\nend\non boop=long\nend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(p.Events(), ","); got != "boop,boop=long" {
+		t.Errorf("Events() = %q, want boop,boop=long", got)
+	}
+}
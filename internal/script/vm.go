@@ -0,0 +1,282 @@
+package script
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	// StepBudget is the most instructions a Machine runs in one Step. A script that needs more, like one stuck in a
+	// loop without a wait, is stopped.
+	StepBudget = 1000
+	// StepCommands is the most commands a Machine runs in one Step. Handlers that want to run more carry on in the
+	// next Step.
+	StepCommands = 4
+	// MaxStack is the deepest that an expression can nest.
+	MaxStack = 16
+)
+
+type opcode uint8
+
+const (
+	opPush opcode = iota
+	opLoad
+	opStore
+	opBuiltin
+	opRand
+	opAdd
+	opSub
+	opMul
+	opDiv
+	opMod
+	opEqual
+	opNotEqual
+	opLess
+	opLessEqual
+	opGreater
+	opGreaterEqual
+	opAnd
+	opOr
+	opNot
+	opNeg
+	opJump
+	opJumpIfZero
+	opWait
+	opDo
+	opEnd
+)
+
+type instr struct {
+	op  opcode
+	arg int32
+}
+
+// Host is what a script runs against.
+type Host interface {
+	// Value returns the current value of a builtin.
+	Value(b Builtin) int32
+	// Command runs a command from a do statement.
+	Command(line string) error
+}
+
+// thread is a running handler.
+type thread struct {
+	running bool
+	pc      int
+	// wake is when a waiting thread carries on
+	wake  time.Time
+	stack [MaxStack]int32
+	sp    int
+}
+
+// Machine runs a Program. Each of its handlers runs on its own thread, which runs until it waits or ends; threads
+// share the variables.
+type Machine struct {
+	p       *Program
+	host    Host
+	vars    []int32
+	threads []thread
+	// next is when each every handler next runs
+	next []time.Time
+	// turn is the thread that runs first in the next Step, so that a busy thread can't keep the others from running
+	turn int
+	rng  uint32
+	err  error
+}
+
+// New makes a Machine to run a Program, with its variables at their starting values.
+func New(p *Program, host Host) *Machine {
+	return &Machine{
+		p:       p,
+		host:    host,
+		vars:    append([]int32(nil), p.vars...),
+		threads: make([]thread, len(p.handlers)),
+		next:    make([]time.Time, len(p.handlers)),
+		rng:     uint32(time.Now().UnixNano()) | 1,
+	}
+}
+
+// Err returns the error that stopped the Machine, if it has stopped.
+func (m *Machine) Err() error {
+	return m.err
+}
+
+// Fire starts the handlers for an event, except for any that are still running.
+func (m *Machine) Fire(event string) {
+	for i, h := range m.p.handlers {
+		if h.event == event {
+			m.start(i)
+		}
+	}
+}
+
+func (m *Machine) start(i int) {
+	t := &m.threads[i]
+	if t.running {
+		return
+	}
+	*t = thread{running: true, pc: m.p.handlers[i].pc}
+}
+
+// Step starts the every handlers that are due and runs the threads that aren't waiting, until they wait or end or the
+// Step has run StepCommands commands. An error stops the Machine, and every later Step returns it.
+func (m *Machine) Step(now time.Time) error {
+	if m.err != nil {
+		return m.err
+	}
+	for i, h := range m.p.handlers {
+		if h.every == 0 {
+			continue
+		}
+		if m.next[i].IsZero() {
+			m.next[i] = now.Add(h.every)
+		} else if !now.Before(m.next[i]) {
+			m.next[i] = now.Add(h.every)
+			m.start(i)
+		}
+	}
+
+	budget, commands := StepBudget, 0
+	for n := 0; n < len(m.threads); n++ {
+		i := (m.turn + n) % len(m.threads)
+		t := &m.threads[i]
+		if !t.running || now.Before(t.wake) {
+			continue
+		}
+		if err := m.run(t, now, &budget, &commands); err != nil {
+			m.err = err
+			return err
+		}
+		if commands >= StepCommands {
+			m.turn = (i + 1) % len(m.threads)
+			break
+		}
+	}
+	return nil
+}
+
+// run runs a thread until it waits or ends, or the Step runs out of commands.
+func (m *Machine) run(t *thread, now time.Time, budget, commands *int) error {
+	code := m.p.code
+	for {
+		if *budget == 0 {
+			return m.fail(t.pc, "used more than "+strconv.Itoa(StepBudget)+" instructions at once")
+		}
+		*budget--
+		in := code[t.pc]
+		t.pc++
+
+		switch in.op {
+		case opPush, opLoad, opBuiltin:
+			if t.sp == MaxStack {
+				return m.fail(t.pc-1, "expression too deep")
+			}
+			v := in.arg
+			if in.op == opLoad {
+				v = m.vars[in.arg]
+			} else if in.op == opBuiltin {
+				v = m.host.Value(Builtin(in.arg))
+			}
+			t.stack[t.sp] = v
+			t.sp++
+		case opStore:
+			t.sp--
+			m.vars[in.arg] = t.stack[t.sp]
+		case opRand:
+			n := t.stack[t.sp-1]
+			if n <= 0 {
+				return m.fail(t.pc-1, "rand needs a number above 0")
+			}
+			m.rng ^= m.rng << 13
+			m.rng ^= m.rng >> 17
+			m.rng ^= m.rng << 5
+			t.stack[t.sp-1] = int32(m.rng % uint32(n))
+		case opNot:
+			t.stack[t.sp-1] = truth(t.stack[t.sp-1] == 0)
+		case opNeg:
+			t.stack[t.sp-1] = -t.stack[t.sp-1]
+		case opJump:
+			t.pc = int(in.arg)
+		case opJumpIfZero:
+			t.sp--
+			if t.stack[t.sp] == 0 {
+				t.pc = int(in.arg)
+			}
+		case opWait:
+			t.sp--
+			ms := t.stack[t.sp]
+			if ms < 0 {
+				ms = 0
+			}
+			t.wake = now.Add(time.Duration(ms) * time.Millisecond)
+			return nil
+		case opDo:
+			if err := m.host.Command(m.p.text[in.arg]); err != nil {
+				return m.fail(t.pc-1, err.Error())
+			}
+			if *commands++; *commands >= StepCommands {
+				return nil
+			}
+		case opEnd:
+			t.running = false
+			return nil
+		default:
+			t.sp--
+			a, b := t.stack[t.sp-1], t.stack[t.sp]
+			v, err := binary(in.op, a, b)
+			if err != nil {
+				return m.fail(t.pc-1, err.Error())
+			}
+			t.stack[t.sp-1] = v
+		}
+	}
+}
+
+func binary(op opcode, a, b int32) (int32, error) {
+	switch op {
+	case opAdd:
+		return a + b, nil
+	case opSub:
+		return a - b, nil
+	case opMul:
+		return a * b, nil
+	case opDiv, opMod:
+		if b == 0 {
+			return 0, errors.New("divide by zero")
+		}
+		if op == opDiv {
+			return a / b, nil
+		}
+		return a % b, nil
+	case opEqual:
+		return truth(a == b), nil
+	case opNotEqual:
+		return truth(a != b), nil
+	case opLess:
+		return truth(a < b), nil
+	case opLessEqual:
+		return truth(a <= b), nil
+	case opGreater:
+		return truth(a > b), nil
+	case opGreaterEqual:
+		return truth(a >= b), nil
+	case opAnd:
+		return truth(a != 0 && b != 0), nil
+	case opOr:
+		return truth(a != 0 || b != 0), nil
+	}
+	return 0, errors.New("bad instruction")
+}
+
+func truth(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// fail makes an error for the instruction at pc, with the line of the script it came from.
+func (m *Machine) fail(pc int, msg string) error {
+	return errors.New("line " + strconv.Itoa(m.p.lines[pc]) + ": " + msg)
+}
@@ -0,0 +1,106 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+// maxLipSyncFrames is how many frames of mouth shapes can be queued from a host.
+const maxLipSyncFrames = 512
+
+// lipSync is a queue of mouth shapes sent from a host (e.g. from audio analysis of a recorded skit), one per frame.
+// While there are shapes queued, they are used instead of Talking to animate the mouth.
+type lipSync struct {
+	shapes []int8
+	// the shape for the current frame, or -1 for the closed mouth
+	current int8
+	active  bool
+	// images is how many talking mouth images there are, counted for media generation imagesGen-1
+	images    int
+	imagesGen uint32
+}
+
+// QueueMouthShapes queues mouth shapes to be shown one per frame, after any that are already queued. Each character
+// is the index of a talking mouth image (0-9, e.g. talk_2), which has to be in the media, or '.' for the normal closed
+// mouth. If any of them are bad, none are queued.
+func (g *Gotogen) QueueMouthShapes(shapes string) error {
+	g.owner.check()
+	if len(g.lipSync.shapes)+len(shapes) > maxLipSyncFrames {
+		return errors.New("mouth: too many queued frames")
+	}
+	images := g.talkImages()
+	for i := 0; i < len(shapes); i++ {
+		c := shapes[i]
+		switch {
+		case c == '.':
+		case '0' <= c && c <= '9':
+			if int(c-'0') >= images {
+				return errors.New("mouth: no talk_" + string(c) + " image")
+			}
+		default:
+			return errors.New("mouth: bad shape " + string(c))
+		}
+	}
+	for i := 0; i < len(shapes); i++ {
+		shape := int8(-1)
+		if c := shapes[i]; c != '.' {
+			shape = int8(c - '0')
+		}
+		g.lipSync.shapes = append(g.lipSync.shapes, shape)
+	}
+	return nil
+}
+
+// talkImages returns how many talking mouth images there are, from talk_0 up to the first one that is missing. It is
+// only counted again when the media changes.
+func (g *Gotogen) talkImages() int {
+	ls := &g.lipSync
+	if gen := media.Generation() + 1; gen != ls.imagesGen {
+		ls.images, ls.imagesGen = 0, gen
+		names, err := media.Enumerate(media.TypeMouth)
+		if err != nil {
+			return 0
+		}
+		have := make(map[string]bool, len(names))
+		for _, n := range names {
+			have[n] = true
+		}
+		for ls.images < 10 && have["talk_"+strconv.Itoa(ls.images)] {
+			ls.images++
+		}
+	}
+	return ls.images
+}
+
+// updateLipSync moves on to the next queued mouth shape, once per frame.
+func (g *Gotogen) updateLipSync() {
+	if len(g.lipSync.shapes) == 0 {
+		g.lipSync.active = false
+		return
+	}
+	g.lipSync.current = g.lipSync.shapes[0]
+	g.lipSync.shapes = g.lipSync.shapes[1:]
+	g.lipSync.active = true
+}
+
+// MouthShape returns the mouth shape sent from a host for this frame (see QueueMouthShapes), or else the one for how
+// loud the wearer is talking (see TalkingLevelSensor) or the music playing on their phone (see QueueMusicEnvelope),
+// with -1 being the closed mouth. If there is no shape for this frame, it returns false, and Talking should be used
+// instead.
+func (g *Gotogen) MouthShape() (int8, bool) {
+	if g.lipSync.active {
+		return g.lipSync.current, true
+	}
+	if !g.talkLevel.active {
+		return g.musicShape()
+	}
+	if s := levelShape(g.talkLevel.level); s >= 0 {
+		return s, true
+	}
+	if s, ok := g.musicShape(); ok {
+		return s, true
+	}
+	return -1, true
+}
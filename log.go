@@ -0,0 +1,219 @@
+package gotogen
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+const (
+	// errorBannerTime is how much longer than a normal caption an error banner stays on the status display.
+	errorBannerTime = 3 * time.Second
+	// defaultLogLines is how many log messages are kept for the Boot log page, unless SetLogBuffer says otherwise.
+	defaultLogLines = 32
+)
+
+// Logger receives log messages from Gotogen. Messages normally come from the main loop, but a warning from loading
+// media on another goroutine can come from there if the main loop is too busy to take it.
+type Logger interface {
+	Log(msg string)
+}
+
+// LevelLogger is an optional interface that a Logger may implement to be told how important each message is, e.g. to
+// color them or to send only warnings and errors somewhere.
+type LevelLogger interface {
+	Logger
+	LogAt(level LogLevel, msg string)
+}
+
+// LogLevel is how important a log message is.
+type LogLevel uint8
+
+const (
+	// LogDebug is for the details of what the main loop is doing, like status screen changes and every boop event.
+	LogDebug LogLevel = iota
+	// LogInfo is for things that the wearer or the driver did, and how booting is going.
+	LogInfo
+	// LogWarning is for things that went wrong but were worked around, like a missing image.
+	LogWarning
+	// LogError is for errors that were reported to the wearer, and the one that stopped the main loop.
+	LogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarning:
+		return "warning"
+	case LogError:
+		return "error"
+	default:
+		return "INVALID"
+	}
+}
+
+// Error is an error with a short code that says which subsystem it came from and what went wrong, e.g. E12 MEDIA_SIZE.
+// Errors from the media, the displays, the driver and settings storage have one, which the crash screen, the log and
+// "error: ..." replies to host tools put first. errors.As finds it even after context has been added.
+type Error = errcode.Error
+
+// ErrorCode is the code of an Error. Its String is the compact form, e.g. E12 MEDIA_SIZE.
+type ErrorCode = errcode.Code
+
+// printlnLogger logs to the console with println, which on most boards is the USB serial port.
+type printlnLogger struct{}
+
+func (printlnLogger) Log(msg string) {
+	println(msg)
+}
+
+// logRing keeps the last few log messages, oldest first once it wraps around, to be read on the status display when
+// there's no console attached.
+type logRing struct {
+	lines []string
+	next  int
+	full  bool
+}
+
+// SetLogger sets where log messages go, instead of the console. It should be called before Init, so that booting is
+// logged there too. A nil Logger goes back to the console.
+func (g *Gotogen) SetLogger(l Logger) {
+	g.owner.check()
+	if l == nil {
+		l = printlnLogger{}
+	}
+	g.logger = l
+}
+
+// SetLogLevel sets the least important messages that are logged. It is LogInfo to begin with.
+func (g *Gotogen) SetLogLevel(level LogLevel) {
+	g.owner.check()
+	g.logLevel = level
+}
+
+// SetLogBuffer sets how many log messages are kept in memory for the Boot log page, throwing away the ones kept so far.
+// 0 keeps none, to save the memory.
+func (g *Gotogen) SetLogBuffer(lines int) {
+	g.owner.check()
+	g.logRing = logRing{}
+	if lines > 0 {
+		g.logRing.lines = make([]string, lines)
+	}
+}
+
+// log logs a message, if it is at least as important as the log level.
+func (g *Gotogen) log(level LogLevel, msg string) {
+	if level < g.logLevel {
+		return
+	}
+	g.logTo(level, msg)
+
+	r := &g.logRing
+	if len(r.lines) == 0 {
+		return
+	}
+	line := strconv.Itoa(int(time.Since(g.start)/time.Second)) + "s "
+	if level >= LogWarning {
+		line += "! "
+	}
+	r.lines[r.next] = line + msg
+	r.next++
+	if r.next == len(r.lines) {
+		r.next, r.full = 0, true
+	}
+}
+
+// logTo sends a message to the Logger, with its level if the Logger takes one. Unlike log, it doesn't check the log
+// level or keep the message for the Boot log page.
+func (g *Gotogen) logTo(level LogLevel, msg string) {
+	if ll, ok := g.logger.(LevelLogger); ok {
+		ll.LogAt(level, msg)
+	} else {
+		g.logger.Log(msg)
+	}
+}
+
+// logMedia logs a message from the media package, which may come from another goroutine, so it goes through the main
+// loop. If too much is waiting already, it goes straight to the Logger instead, skipping the Boot log page, which only
+// the main loop may touch.
+func (g *Gotogen) logMedia(warning bool, msg string) {
+	level := LogInfo
+	if warning {
+		level = LogWarning
+	}
+	if g.Do(func() { g.log(level, msg) }) != nil {
+		g.logTo(level, msg)
+	}
+}
+
+// showLog shows the kept log messages as a page, each one starting with how long after booting it was logged.
+// Warnings and errors are marked with a !.
+func (g *Gotogen) showLog() {
+	r := &g.logRing
+	kept := r.lines[:r.next]
+	if r.full {
+		kept = append(append([]string(nil), r.lines[r.next:]...), kept...)
+	}
+	w, _ := g.statusText.Size()
+	var lines []string
+	for _, msg := range kept {
+		lines = append(lines, wordWrap(msg, int(w))...)
+	}
+	if len(lines) == 0 {
+		lines = []string{"(nothing logged)"}
+	}
+	g.showPage("BOOT LOG", lines)
+}
+
+// reportError logs the error (if any), and shows it as a banner and then a warning on the status screen. This is
+// how errors from things the wearer did (menu items, quick actions, commands) are surfaced, instead of stopping the
+// whole unit.
+func (g *Gotogen) reportError(err error) {
+	if err == nil {
+		return
+	}
+	msg := errorText(err)
+	g.log(LogError, msg)
+	if code, ok := errcode.Of(err); ok {
+		// the code fits on the warning line when the whole message wouldn't
+		g.setWarning(code.String())
+	} else {
+		g.setWarning(msg)
+	}
+	if g.init {
+		// during init, the boot log already shows everything
+		g.queueCaption(caption{text: "! " + msg, extra: errorBannerTime})
+	}
+}
+
+// errorText is the error's message, starting with its code if it has one (even if the code is on an error that it
+// wraps) so that it is always in the same place for host tools.
+func errorText(err error) string {
+	msg := err.Error()
+	if code, ok := errcode.Of(err); ok && !strings.HasPrefix(msg, code.String()) {
+		msg = code.String() + ": " + msg
+	}
+	return msg
+}
+
+// showCrash puts the error that stopped the main loop on the status display, with its code on a line of its own so that
+// it can be read off even if the message doesn't fit.
+func (g *Gotogen) showCrash(err error) {
+	if g.statusText == nil {
+		return
+	}
+	g.clearStatusScreen()
+	_ = g.statusText.SetLineInverse(0, "GOTOGEN STOPPED")
+	row := int16(1)
+	if code, ok := errcode.Of(err); ok {
+		_ = g.statusText.SetLine(row, code.String())
+		row++
+	}
+	_ = g.statusText.SetLine(row, err.Error())
+	_ = g.statusText.Display()
+}
@@ -0,0 +1,171 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// macroSlots is how many macros can be saved.
+	macroSlots = 3
+	// maxMacroSteps is the most changes a macro can hold.
+	maxMacroSteps = 32
+)
+
+// macroStep is one change in a macro, as the command that makes it and when it happens after the macro starts.
+type macroStep struct {
+	at  time.Duration
+	cmd string
+}
+
+// macroState tracks recording and playing back macros.
+type macroState struct {
+	// recording is the slot being recorded into, or -1
+	recording int
+	playing   bool
+	start     time.Time
+	steps     []macroStep
+	next      int
+}
+
+// recordedCommand reports whether a command changes the expression, and so is recorded into macros.
+func recordedCommand(cmd string) bool {
+	switch cmd {
+	case "preset", "face", "expr", "anim", "stop", "effect", "tint", "brightness", "caption", "clock":
+		return true
+	}
+	return false
+}
+
+func macroSetting(slot int) string {
+	return "macro" + strconv.Itoa(slot+1)
+}
+
+// encodeMacro stores the steps as "MILLISECONDS COMMAND" lines.
+func encodeMacro(steps []macroStep) []byte {
+	var b []byte
+	for _, s := range steps {
+		b = strconv.AppendInt(b, int64(s.at/time.Millisecond), 10)
+		b = append(b, ' ')
+		b = append(b, s.cmd...)
+		b = append(b, '\n')
+	}
+	return b
+}
+
+func decodeMacro(b []byte) ([]macroStep, error) {
+	var steps []macroStep
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		ms, cmd, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, errors.New("bad macro step")
+		}
+		n, err := strconv.ParseInt(ms, 10, 64)
+		if err != nil {
+			return nil, errors.New("bad macro step: " + err.Error())
+		}
+		steps = append(steps, macroStep{at: time.Duration(n) * time.Millisecond, cmd: cmd})
+	}
+	return steps, nil
+}
+
+// record adds the command to the macro being recorded, if any.
+func (g *Gotogen) record(line string) {
+	if g.macro.recording < 0 || g.macro.playing || len(g.macro.steps) >= maxMacroSteps {
+		return
+	}
+	g.macro.steps = append(g.macro.steps, macroStep{at: time.Since(g.macro.start), cmd: strings.TrimSpace(line)})
+}
+
+// RecordMacro starts recording expression changes into the macro slot (1-3), until StopMacro is called.
+//
+// RecordMacro must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) RecordMacro(n int) error {
+	g.owner.check()
+	if n < 1 || n > macroSlots {
+		return errors.New("macro must be 1-" + strconv.Itoa(macroSlots))
+	}
+	g.macro = macroState{recording: n - 1, start: time.Now()}
+	g.setWarning("REC macro " + strconv.Itoa(n))
+	return nil
+}
+
+// PlayMacro replays the macro saved in the slot (1-3), with the same timing it was recorded with.
+//
+// PlayMacro must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) PlayMacro(n int) error {
+	g.owner.check()
+	if n < 1 || n > macroSlots {
+		return errors.New("macro must be 1-" + strconv.Itoa(macroSlots))
+	}
+	if g.macro.recording >= 0 {
+		return errors.New("recording a macro")
+	}
+	b, ok := g.loadSetting(macroSetting(n - 1))
+	if !ok {
+		return errors.New("macro " + strconv.Itoa(n) + " not saved")
+	}
+	steps, err := decodeMacro(b)
+	if err != nil {
+		return err
+	}
+	g.macro = macroState{recording: -1, playing: true, start: time.Now(), steps: steps}
+	return nil
+}
+
+// StopMacro stops playing back a macro, or finishes recording one and saves it.
+//
+// StopMacro must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) StopMacro() {
+	g.owner.check()
+	if g.macro.recording >= 0 {
+		if len(g.macro.steps) > 0 {
+			g.saveSetting(macroSetting(g.macro.recording), encodeMacro(g.macro.steps))
+		}
+		g.setWarning("")
+	}
+	g.macro = macroState{recording: -1}
+}
+
+// updateMacro runs any macro steps that are due.
+func (g *Gotogen) updateMacro() {
+	if !g.macro.playing {
+		return
+	}
+	elapsed := time.Since(g.macro.start)
+	for g.macro.next < len(g.macro.steps) && g.macro.steps[g.macro.next].at <= elapsed {
+		err := g.Command(g.macro.steps[g.macro.next].cmd)
+		if err != nil {
+			g.log(LogWarning, "macro error: "+err.Error())
+		}
+		g.macro.next++
+	}
+	if g.macro.next >= len(g.macro.steps) {
+		g.macro = macroState{recording: -1}
+	}
+}
+
+func (g *Gotogen) macroMenu() *Menu {
+	m := &Menu{Name: "Macros"}
+	for i := 1; i <= macroSlots; i++ {
+		n := i
+		m.Items = append(m.Items, &ActionItem{
+			Name:   "Play " + strconv.Itoa(n),
+			Invoke: func() { g.reportError(g.PlayMacro(n)) },
+		})
+	}
+	for i := 1; i <= macroSlots; i++ {
+		n := i
+		m.Items = append(m.Items, &ActionItem{
+			Name:   "Record " + strconv.Itoa(n),
+			Invoke: func() { g.reportError(g.RecordMacro(n)) },
+		})
+	}
+	m.Items = append(m.Items, &ActionItem{
+		Name:   "Stop",
+		Invoke: g.StopMacro,
+	})
+	return m
+}
@@ -1,15 +1,12 @@
 package gotogen
 
-import (
-	"github.com/ajanata/textbuf"
-)
-
 type MenuProvider interface {
 	GetMenu() Menu
 }
 
 type Item interface {
 	name() string
+	help() string
 }
 
 type Menuable interface {
@@ -17,7 +14,7 @@ type Menuable interface {
 	SetTop(uint8)
 	Selected() uint8
 	SetSelected(uint8)
-	Render(*textbuf.Buffer)
+	Render(MenuRenderer)
 	Len() uint8
 	Prev() Menuable
 	SetPrev(Menuable)
@@ -25,6 +22,7 @@ type Menuable interface {
 
 type Menu struct {
 	Name     string
+	Help     string // optional, shown by long-pressing Menu on the item
 	Items    []Item
 	selected uint8
 	top      uint8
@@ -33,6 +31,8 @@ type Menu struct {
 
 func (m *Menu) name() string { return m.Name }
 
+func (m *Menu) help() string { return m.Help }
+
 func (m *Menu) Top() uint8 { return m.top }
 
 func (m *Menu) SetTop(t uint8) { m.top = t }
@@ -41,38 +41,63 @@ func (m *Menu) Selected() uint8 { return m.selected }
 
 func (m *Menu) SetSelected(s uint8) { m.selected = s }
 
-func (m *Menu) Len() uint8 { return uint8(len(m.Items)) }
+// maxMenuLen is the most items a menu or setting can show, since positions in them are uint8s.
+const maxMenuLen = 255
+
+func (m *Menu) Len() uint8 { return menuLen(len(m.Items)) }
+
+func menuLen(n int) uint8 {
+	if n > maxMenuLen {
+		return maxMenuLen
+	}
+	return uint8(n)
+}
+
+// clampMenu keeps the selection and scroll position of a menu or setting inside it, since its items can change while
+// it isn't open, e.g. the driver's hardware menu.
+func clampMenu(m Menuable, rows uint8) {
+	if m.Len() == 0 {
+		m.SetSelected(0)
+		m.SetTop(0)
+		return
+	}
+	if m.Selected() >= m.Len() {
+		m.SetSelected(m.Len() - 1)
+	}
+	if m.Top() > m.Selected() {
+		m.SetTop(m.Selected())
+	}
+	if rows > 0 && m.Selected() > m.Top()+rows-1 {
+		m.SetTop(m.Selected() - rows + 1)
+	}
+}
 
 func (m *Menu) Prev() Menuable { return m.prev }
 
 func (m *Menu) SetPrev(p Menuable) { m.prev = p }
 
-func (m *Menu) Render(buf *textbuf.Buffer) {
-	buf.Clear()
-	_, h := buf.Size()
-	// TODO center
-	_ = buf.SetLineInverse(0, m.Name)
-	for i := uint8(0); i+m.top < uint8(len(m.Items)) && i < uint8(h-1); i++ {
+func (m *Menu) Render(r MenuRenderer) {
+	r.Clear()
+	r.Header(m.Name)
+	for i := uint8(0); int(i)+int(m.top) < int(m.Len()) && i < r.Rows(); i++ {
 		item := m.Items[i+m.top]
-		var prefix string
+		var kind ItemKind
 		switch item.(type) {
 		case *Menu:
-			prefix = "+"
+			kind = ItemKindMenu
 		case *ActionItem:
-			prefix = "*"
+			kind = ItemKindAction
 		case *SettingItem:
-			prefix = ">"
-		}
-		if i == m.selected-m.top {
-			_ = buf.SetLineInverse(int16(i+1), prefix+item.name())
-		} else {
-			_ = buf.SetLine(int16(i+1), prefix+item.name())
+			kind = ItemKindSetting
 		}
+		r.Item(i, kind, item.name(), i == m.selected-m.top)
 	}
+	r.Progress(m.selected, m.Len())
 }
 
 type ActionItem struct {
 	Name   string
+	Help   string // optional, shown by long-pressing Menu on the item
 	Invoke func()
 }
 
@@ -80,8 +105,13 @@ func (i *ActionItem) name() string {
 	return i.Name
 }
 
+func (i *ActionItem) help() string {
+	return i.Help
+}
+
 type SettingItem struct {
 	Name     string
+	Help     string // optional, shown by long-pressing Menu on the item
 	Options  []string
 	Default  uint8
 	Active   uint8
@@ -93,6 +123,8 @@ type SettingItem struct {
 
 func (si *SettingItem) name() string { return si.Name }
 
+func (si *SettingItem) help() string { return si.Help }
+
 func (si *SettingItem) Top() uint8 { return si.top }
 
 func (si *SettingItem) SetTop(t uint8) { si.top = t }
@@ -101,27 +133,21 @@ func (si *SettingItem) Selected() uint8 { return si.selected }
 
 func (si *SettingItem) SetSelected(s uint8) { si.selected = s }
 
-func (si *SettingItem) Len() uint8 { return uint8(len(si.Options)) }
+func (si *SettingItem) Len() uint8 { return menuLen(len(si.Options)) }
 
 func (si *SettingItem) Prev() Menuable { return si.prev }
 
 func (si *SettingItem) SetPrev(p Menuable) { si.prev = p }
 
-func (si *SettingItem) Render(buf *textbuf.Buffer) {
-	buf.Clear()
-	_, h := buf.Size()
-	// TODO center
-	_ = buf.SetLineInverse(0, si.Name)
-	for i := uint8(0); i+si.top < uint8(len(si.Options)) && i < uint8(h-1); i++ {
-		item := si.Options[i+si.top]
-		prefix := " "
+func (si *SettingItem) Render(r MenuRenderer) {
+	r.Clear()
+	r.Header(si.Name)
+	for i := uint8(0); int(i)+int(si.top) < int(si.Len()) && i < r.Rows(); i++ {
+		kind := ItemKindOption
 		if i == si.Active-si.top {
-			prefix = "*"
-		}
-		if i == si.selected-si.top {
-			_ = buf.SetLineInverse(int16(i+1), prefix+item)
-		} else {
-			_ = buf.SetLine(int16(i+1), prefix+item)
+			kind = ItemKindActiveOption
 		}
+		r.Item(i, kind, si.Options[i+si.top], i == si.selected-si.top)
 	}
+	r.Progress(si.selected, si.Len())
 }
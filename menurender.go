@@ -0,0 +1,112 @@
+package gotogen
+
+import "strconv"
+
+// ItemKind is what kind of line a MenuRenderer is being asked to draw, so it can decorate it appropriately.
+type ItemKind uint8
+
+const (
+	// ItemKindMenu is a submenu.
+	ItemKindMenu ItemKind = iota
+	// ItemKindAction is an item that does something when selected.
+	ItemKindAction
+	// ItemKindSetting is a setting, which opens a list of options when selected.
+	ItemKindSetting
+	// ItemKindOption is an option of a setting that is not currently active.
+	ItemKindOption
+	// ItemKindActiveOption is the option of a setting that is currently active.
+	ItemKindActiveOption
+)
+
+// MenuRenderer draws menus onto a status display. The default implementation draws into the status text buffer, but
+// alternate implementations may draw graphically (e.g. on a TFT) or mirror the menu somewhere else entirely.
+type MenuRenderer interface {
+	// Clear is called before drawing a menu.
+	Clear()
+	// Rows returns how many items can be shown at once, not including the header.
+	Rows() uint8
+	// Header draws the title of the menu.
+	Header(title string)
+	// Item draws a line of the menu, where row 0 is the first row after the header.
+	Item(row uint8, kind ItemKind, text string, highlighted bool)
+	// Progress indicates the position of the highlighted item in the entire list, for menus longer than Rows.
+	Progress(pos, total uint8)
+}
+
+// SetMenuRenderer replaces the renderer used to draw menus. If r is nil, the default text renderer is used.
+//
+// SetMenuRenderer must be called from the same goroutine as RunTick; other goroutines can use Do.
+func (g *Gotogen) SetMenuRenderer(r MenuRenderer) {
+	g.owner.check()
+	if r == nil && g.statusText != nil {
+		r = &textMenuRenderer{buf: g.statusText, theme: g.theme}
+	}
+	g.menuRenderer = r
+}
+
+// textMenuRenderer is the default MenuRenderer, drawing into a text buffer using a Theme.
+type textMenuRenderer struct {
+	buf   *glyphText
+	theme *Theme
+	title string
+}
+
+func (r *textMenuRenderer) Clear() {
+	r.buf.Clear()
+}
+
+func (r *textMenuRenderer) Rows() uint8 {
+	_, h := r.buf.Size()
+	return uint8(h - 1)
+}
+
+func (r *textMenuRenderer) Header(title string) {
+	r.title = title
+	r.header("")
+}
+
+func (r *textMenuRenderer) header(suffix string) {
+	// TODO center
+	if r.theme.HeaderInverse {
+		_ = r.buf.SetLineInverse(0, r.theme.HeaderPrefix, r.title, r.theme.HeaderSuffix, suffix)
+	} else {
+		_ = r.buf.SetLine(0, r.theme.HeaderPrefix, r.title, r.theme.HeaderSuffix, suffix)
+	}
+}
+
+func (r *textMenuRenderer) Item(row uint8, kind ItemKind, text string, highlighted bool) {
+	var prefix string
+	switch kind {
+	case ItemKindMenu:
+		prefix = r.theme.MenuPrefix
+	case ItemKindAction:
+		prefix = r.theme.ActionPrefix
+	case ItemKindSetting:
+		prefix = r.theme.SettingPrefix
+	case ItemKindOption:
+		prefix = r.theme.InactiveMarker
+	case ItemKindActiveOption:
+		prefix = r.theme.ActiveMarker
+	}
+
+	text = r.theme.line(prefix+text, highlighted)
+	if highlighted && r.theme.SelectInverse {
+		_ = r.buf.SetLineInverse(int16(row+1), text)
+	} else {
+		_ = r.buf.SetLine(int16(row+1), text)
+	}
+}
+
+func (r *textMenuRenderer) Progress(pos, total uint8) {
+	if total <= r.Rows() {
+		return
+	}
+	// squeeze the position in at the end of the header
+	w, _ := r.buf.Size()
+	p := " " + strconv.Itoa(int(pos)+1) + "/" + strconv.Itoa(int(total))
+	used := len(r.theme.HeaderPrefix) + len(r.title) + len(r.theme.HeaderSuffix)
+	for i := used; i < int(w)-len(p); i++ {
+		p = " " + p
+	}
+	r.header(p)
+}
@@ -0,0 +1,83 @@
+package gotogen
+
+import (
+	"time"
+)
+
+// microExpressions are the small random movements that keep the idle face from looking frozen: the eyes shifting a
+// little, and an occasional blush. See also breathing.
+type microExpressions struct {
+	// intensity is 0 for off, 1 for subtle, or 2 for lively.
+	intensity uint8
+	noise     uint32
+
+	eyeX, eyeY int8
+	nextEye    time.Time
+	blush      bool
+	nextBlush  time.Time
+	blushUntil time.Time
+}
+
+// rand returns the next value from the noise source, from 0 to n-1.
+func (m *microExpressions) rand(n uint32) uint32 {
+	m.noise = m.noise*1664525 + 1013904223
+	return (m.noise >> 16) % n
+}
+
+// randDuration returns a random duration from lo to hi.
+func (m *microExpressions) randDuration(lo, hi time.Duration) time.Duration {
+	return lo + time.Duration(m.rand(uint32((hi-lo)/time.Millisecond)+1))*time.Millisecond
+}
+
+// Micro returns the small idle movements the face should make right now.
+func (g *Gotogen) Micro() (eyeX, eyeY int8, blush bool) {
+	return g.micro.eyeX, g.micro.eyeY, g.micro.blush
+}
+
+// microActive reports whether the face should be making micro-expressions.
+func (g *Gotogen) microActive() bool {
+	return g.micro.intensity > 0 && g.idleFaceMoves()
+}
+
+// idleFaceMoves reports whether the face can move on its own, with micro-expressions or by following the head. That
+// only makes sense on the idle face, and do not disturb keeps the face as it is.
+func (g *Gotogen) idleFaceMoves() bool {
+	return g.faceState == faceStateDefault && !g.dnd && !g.photoMode && !g.exprLocked() && !g.asleep()
+}
+
+// updateMicro moves the micro-expressions along. Everything here is driven by timers, so nothing needs to be redrawn
+// on most frames.
+func (g *Gotogen) updateMicro() {
+	m := &g.micro
+	if !g.microActive() {
+		m.eyeX, m.eyeY, m.blush = 0, 0, false
+		return
+	}
+
+	now := time.Now()
+	if now.After(m.nextEye) {
+		m.eyeX, m.eyeY = 0, 0
+		// half the time, look back to the middle
+		if m.rand(2) == 0 {
+			r := uint32(m.intensity)
+			m.eyeX = int8(m.rand(2*r+1)) - int8(r)
+			m.eyeY = int8(m.rand(2))
+		}
+		m.nextEye = now.Add(m.randDuration(1500*time.Millisecond, 5*time.Second))
+	}
+
+	if m.blush && now.After(m.blushUntil) {
+		m.blush = false
+	}
+	if now.After(m.nextBlush) {
+		if !m.nextBlush.IsZero() && m.rand(4-uint32(m.intensity)) == 0 {
+			m.blush = true
+			m.blushUntil = now.Add(m.randDuration(2*time.Second, 4*time.Second))
+		}
+		m.nextBlush = now.Add(m.randDuration(20*time.Second, 60*time.Second))
+	}
+}
+
+func (g *Gotogen) setMicroIntensity(selected uint8) {
+	g.micro.intensity = selected
+}
@@ -0,0 +1,143 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+const (
+	// maxMusicLevels is how many levels of a music envelope can be queued from a phone.
+	maxMusicLevels = 512
+	// musicPulseDepth is how much the Pulse setting dims the face when the music is silent, out of 256.
+	musicPulseDepth = 96
+)
+
+const (
+	musicSyncOff uint8 = iota
+	musicSyncMouth
+	musicSyncPulse
+)
+
+// music is the amplitude envelope of music playing on the wearer's phone, sent a little ahead of time through the
+// command protocol, so that the face can move to it without a microphone. The phone only has to send how loud the
+// music is, a few times a second; it doesn't need to know anything about the face.
+type music struct {
+	mode uint8
+	// levels are the queued levels, from 0 to 255, each lasting step; the first started at start
+	levels []uint8
+	step   time.Duration
+	start  time.Time
+	// level is the level for this frame, and active is whether there is one
+	level  uint8
+	active bool
+	// pulse is how much the brightness is currently reduced by, out of 256
+	pulse uint8
+}
+
+// QueueMusicEnvelope queues the amplitude envelope of music playing on a phone, to follow on from any already queued.
+// Each character of levels is a hex digit from 0 (silent) to f (loudest), and each lasts for step. A different step
+// from what's queued replaces the queue, so that a new song starts right away.
+func (g *Gotogen) QueueMusicEnvelope(step time.Duration, levels string) error {
+	g.owner.check()
+	m := &g.music
+	if step < 10*time.Millisecond || step > time.Second {
+		return errors.New("music: step must be 10 to 1000 ms")
+	}
+	// nothing is queued unless all of it is good
+	for i := 0; i < len(levels); i++ {
+		if _, ok := hexDigit(levels[i]); !ok {
+			return errors.New("music: bad level " + string(levels[i]))
+		}
+	}
+	restart := step != m.step || len(m.levels) == 0
+	queued := len(m.levels)
+	if restart {
+		queued = 0
+	}
+	if queued+len(levels) > maxMusicLevels {
+		return errors.New("music: too many queued levels")
+	}
+	if restart {
+		m.levels = m.levels[:0]
+		m.step = step
+		m.start = time.Now()
+	}
+	for i := 0; i < len(levels); i++ {
+		v, _ := hexDigit(levels[i])
+		m.levels = append(m.levels, v*0x11)
+	}
+	return nil
+}
+
+// hexDigit returns the value of a hex digit.
+func hexDigit(c byte) (uint8, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// updateMusic moves on to the level for the current time, dropping the ones that have passed, and pulses the
+// brightness if that is what the music is for.
+func (g *Gotogen) updateMusic() {
+	m := &g.music
+	if len(m.levels) > 0 {
+		passed := int(time.Since(m.start) / m.step)
+		if passed >= len(m.levels) {
+			m.levels = m.levels[:0]
+		} else if passed > 0 {
+			m.levels = m.levels[:copy(m.levels, m.levels[passed:])]
+			m.start = m.start.Add(time.Duration(passed) * m.step)
+		}
+	}
+	m.active = len(m.levels) > 0 && m.mode != musicSyncOff && g.faceState == faceStateDefault && !g.dnd &&
+		!g.asleep()
+	m.level = 0
+	if m.active {
+		m.level = m.levels[0]
+	}
+
+	var pulse uint8
+	if m.active && m.mode == musicSyncPulse {
+		pulse = uint8(uint16(255-m.level) * musicPulseDepth / 256)
+	}
+	// the face is redrawn for it by updateDimming
+	m.pulse = pulse
+}
+
+// musicShape returns the mouth shape for the music, if the music is moving the mouth.
+func (g *Gotogen) musicShape() (int8, bool) {
+	m := &g.music
+	if !m.active || m.mode != musicSyncMouth {
+		return 0, false
+	}
+	return levelShape(m.level), true
+}
+
+// musicCommand handles "music MS LEVELS" and "music stop".
+func (g *Gotogen) musicCommand(args []string) error {
+	if len(args) == 1 && args[0] == "stop" {
+		g.music.levels = g.music.levels[:0]
+		return nil
+	}
+	if len(args) != 2 {
+		return errors.New("music: need step and levels")
+	}
+	ms, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errcode.Context("music", err)
+	}
+	return g.QueueMusicEnvelope(time.Duration(ms)*time.Millisecond, args[1])
+}
+
+func (g *Gotogen) setMusicSync(selected uint8) {
+	g.music.mode = selected
+}
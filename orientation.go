@@ -0,0 +1,116 @@
+package gotogen
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// setDownTime is how long the head has to stay tilted before it is considered set down.
+const setDownTime = 2 * time.Second
+
+// tiltThresholds are the selectable angles, in degrees, that the head has to be tilted from upright to be considered
+// set down. Zero disables detection.
+var tiltThresholds = []float64{0, 60, 90, 120}
+
+// defaultTiltSetting is 90 degrees, which is what the zero value of orientation.threshold means.
+const defaultTiltSetting = 2
+
+// orientation tracks whether the head has been set down (e.g. upside down or on its side on a table), so the face can
+// be turned off until it is picked up again.
+type orientation struct {
+	// the accelerometer reading when upright
+	upright    [3]int32
+	hasUpright bool
+	// the cosine of the tilt threshold angle, or NaN if disabled
+	threshold float64
+	tilted    time.Time
+}
+
+// updateOrientation is called with every accelerometer reading.
+func (g *Gotogen) updateOrientation(x, y, z int32) {
+	o := &g.orientation
+	if !o.hasUpright {
+		// assume the head is being worn when it boots; it can be recalibrated from the menu
+		o.upright = [3]int32{x, y, z}
+		o.hasUpright = true
+		return
+	}
+	if math.IsNaN(o.threshold) {
+		return
+	}
+
+	tilted := tiltCos(o.upright, [3]int32{x, y, z}) < o.threshold
+	now := time.Now()
+	switch {
+	case !tilted:
+		o.tilted = time.Time{}
+		g.wake(sleepSetDown)
+	case o.tilted.IsZero():
+		o.tilted = now
+	case !g.shaking && now.Sub(o.tilted) > setDownTime:
+		g.sleep(sleepSetDown)
+	}
+}
+
+// tiltCos returns the cosine of the angle between two accelerometer readings.
+func tiltCos(a, b [3]int32) float64 {
+	dot := float64(a[0])*float64(b[0]) + float64(a[1])*float64(b[1]) + float64(a[2])*float64(b[2])
+	la := math.Sqrt(float64(a[0])*float64(a[0]) + float64(a[1])*float64(a[1]) + float64(a[2])*float64(a[2]))
+	lb := math.Sqrt(float64(b[0])*float64(b[0]) + float64(b[1])*float64(b[1]) + float64(b[2])*float64(b[2]))
+	if la == 0 || lb == 0 {
+		// no idea which way is up
+		return 1
+	}
+	return dot / (la * lb)
+}
+
+func (g *Gotogen) setTiltThreshold(selected uint8) {
+	if int(selected) >= len(tiltThresholds) {
+		return
+	}
+	if tiltThresholds[selected] == 0 {
+		g.orientation.threshold = math.NaN()
+		g.wake(sleepSetDown)
+		return
+	}
+	g.orientation.threshold = math.Cos(tiltThresholds[selected] * math.Pi / 180)
+}
+
+// orientationItems are added to the end of the driver's hardware settings menu, along with the other settings for
+// when the face sleeps.
+func (g *Gotogen) orientationItems() []Item {
+	names := make([]string, len(tiltThresholds))
+	for i, t := range tiltThresholds {
+		if t == 0 {
+			names[i] = "off"
+		} else {
+			names[i] = strconv.Itoa(int(t)) + " deg"
+		}
+	}
+	return []Item{
+		&SettingItem{
+			Name:    "Set-down tilt",
+			Options: names,
+			Active:  g.tiltSetting,
+			Apply: func(selected uint8) {
+				g.tiltSetting = selected
+				g.setTiltThreshold(selected)
+			},
+		},
+		&SettingItem{
+			Name:    "Proximity sleep",
+			Help:    "Auto sleeps the face when the proximity sensor says the head isn't being worn.",
+			Options: []string{"off", "auto", "force sleep"},
+			Active:  uint8(g.proximitySleep),
+			Apply:   g.setProximitySleep,
+		},
+		&ActionItem{
+			Name: "Calibrate upright",
+			Invoke: func() {
+				g.orientation.upright = [3]int32{g.aX, g.aY, g.aZ}
+				g.orientation.hasUpright = true
+			},
+		},
+	}
+}
@@ -0,0 +1,196 @@
+//go:build !nonetwork
+
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/ajanata/gotogen/internal/osc"
+)
+
+// oscPrefix is the start of every OSC address gotogen responds to.
+const oscPrefix = "/gotogen/"
+
+// HandleOSC runs the messages in an OSC packet, e.g. received over UDP from TouchOSC or Resolume, by turning them into
+// commands and posting them to the main loop. Like Post, it is safe to call from any goroutine.
+//
+// OSC has no authentication, so only the commands that change the expression or the brightness can be run: each
+// address is /gotogen/ followed by one of expr, preset, face, anim, effect, tint, or brightness (see Command), and
+// messages for anything else are rejected. The arguments become the command's arguments, so /gotogen/effect "glitch"
+// runs "effect glitch". Arguments can also be put in the address, for controls that can only send a number:
+// /gotogen/effect/glitch 1 does the same. Buttons send 0 when released, so a message whose only argument is 0 is
+// ignored, except for /gotogen/brightness. Some addresses are treated specially:
+//
+//	/gotogen/expression NAME   morph the vector face to the expression (expr)
+//	/gotogen/brightness V      a float from 0 to 1 (as from a fader), or an int from 0 to 255
+//	/gotogen/tint R G B        floats from 0 to 1, or ints from 0 to 255
+func (g *Gotogen) HandleOSC(packet []byte) error {
+	msgs, err := osc.Decode(packet)
+	var errs []string
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, m := range msgs {
+		line, ok, err := oscCommand(m)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if err := g.Post(line); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// oscCommand turns an OSC message into a command line. It returns false for messages that should be ignored.
+func oscCommand(m osc.Message) (string, bool, error) {
+	if !strings.HasPrefix(m.Address, oscPrefix) {
+		return "", false, nil
+	}
+	path := strings.Split(strings.TrimPrefix(m.Address, oscPrefix), "/")
+	cmd, args := path[0], path[1:]
+
+	switch cmd {
+	case "brightness":
+		if len(m.Args) != 1 {
+			return "", false, errors.New("osc: " + m.Address + ": need one value")
+		}
+		v, ok := oscLevel(m.Args[0])
+		if !ok {
+			return "", false, errors.New("osc: " + m.Address + ": need a number")
+		}
+		return "brightness " + strconv.Itoa(int(v)), true, nil
+	case "tint":
+		if len(m.Args) != 3 {
+			return "", false, errors.New("osc: " + m.Address + ": need red, green, and blue")
+		}
+		hex := ""
+		for _, a := range m.Args {
+			v, ok := oscLevel(a)
+			if !ok {
+				return "", false, errors.New("osc: " + m.Address + ": need numbers")
+			}
+			if v < 0x10 {
+				hex += "0"
+			}
+			hex += strconv.FormatUint(uint64(v), 16)
+		}
+		return "tint " + hex, true, nil
+	case "expression":
+		cmd = "expr"
+	}
+	if !oscAllowed(cmd) {
+		return "", false, errors.New("osc: " + m.Address + ": not allowed")
+	}
+
+	if len(m.Args) == 1 && oscZero(m.Args[0]) {
+		// a button being released
+		return "", false, nil
+	}
+	if len(args) > 0 && len(m.Args) == 1 && !isOSCString(m.Args[0]) {
+		// the arguments are in the address, and the number only says that a button was pressed
+		m.Args = nil
+	}
+	for _, a := range m.Args {
+		s, ok := oscString(a)
+		if !ok {
+			return "", false, errors.New("osc: " + m.Address + ": unsupported argument")
+		}
+		args = append(args, s)
+	}
+	line := cmd
+	for _, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\r\n") {
+			return "", false, errors.New("osc: " + m.Address + ": bad argument")
+		}
+		line += " " + a
+	}
+	return line, true, nil
+}
+
+// oscAllowed reports whether OSC messages can run the command. Only commands that change the expression are allowed;
+// brightness is handled before this.
+func oscAllowed(cmd string) bool {
+	switch cmd {
+	case "expr", "preset", "face", "anim", "effect", "tint":
+		return true
+	}
+	return false
+}
+
+// oscLevel converts a float from 0 to 1, or an int from 0 to 255, to a level from 0 to 255.
+func oscLevel(a interface{}) (uint8, bool) {
+	var v float64
+	switch a := a.(type) {
+	case float32:
+		v = float64(a) * 0xFF
+	case float64:
+		v = a * 0xFF
+	case int32:
+		v = float64(a)
+	case int64:
+		v = float64(a)
+	default:
+		return 0, false
+	}
+	switch {
+	case v < 0:
+		v = 0
+	case v > 0xFF:
+		v = 0xFF
+	}
+	return uint8(v + 0.5), true
+}
+
+// oscZero reports whether the argument is a zero number or false.
+func oscZero(a interface{}) bool {
+	switch a := a.(type) {
+	case float32:
+		return a == 0
+	case float64:
+		return a == 0
+	case int32:
+		return a == 0
+	case int64:
+		return a == 0
+	case bool:
+		return !a
+	}
+	return false
+}
+
+func isOSCString(a interface{}) bool {
+	_, ok := a.(string)
+	return ok
+}
+
+// oscString formats the argument as a command argument.
+func oscString(a interface{}) (string, bool) {
+	switch a := a.(type) {
+	case string:
+		return a, true
+	case int32:
+		return strconv.Itoa(int(a)), true
+	case int64:
+		return strconv.FormatInt(a, 10), true
+	case float32:
+		return strconv.FormatFloat(float64(a), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(a, 'f', -1, 64), true
+	case bool:
+		if a {
+			return "1", true
+		}
+		return "0", true
+	}
+	return "", false
+}
@@ -0,0 +1,10 @@
+//go:build nonetwork
+
+package gotogen
+
+import "errors"
+
+// HandleOSC is left out of builds with the nonetwork build tag, to save space; see osc.go.
+func (g *Gotogen) HandleOSC(packet []byte) error {
+	return errors.New("OSC: built with nonetwork")
+}
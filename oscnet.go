@@ -0,0 +1,34 @@
+//go:build !tinygo && !nonetwork
+
+package gotogen
+
+import (
+	"net"
+)
+
+// ListenOSC listens for OSC packets over UDP on the address (e.g. ":9000") in the background, and runs them with
+// HandleOSC. Errors in the packets are logged.
+//
+// This is only available on OS-based builds (like a simulator). Drivers with their own networking can pass packets to
+// HandleOSC.
+func (g *Gotogen) ListenOSC(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		buf := make([]byte, 1536)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				// this isn't the main loop, so the message has to go through it to be logged
+				_ = g.Do(func() { g.log(LogWarning, "osc: "+err.Error()) })
+				return
+			}
+			if err := g.HandleOSC(buf[:n]); err != nil {
+				_ = g.Do(func() { g.log(LogWarning, "osc: "+err.Error()) })
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !tinygo && nonetwork
+
+package gotogen
+
+import "errors"
+
+// ListenOSC is left out of builds with the nonetwork build tag, to save space; see oscnet.go.
+func (g *Gotogen) ListenOSC(addr string) error {
+	return errors.New("OSC: built with nonetwork")
+}
@@ -0,0 +1,50 @@
+//go:build gotogendebug && !tinygo
+
+package gotogen
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+// owner is the goroutine that runs the main loop. This is only built with the gotogendebug build tag (and not on
+// tinygo, which can't identify goroutines), to catch drivers using Gotogen from other goroutines.
+type owner struct {
+	id uint64
+}
+
+// goroutineID parses the current goroutine's ID out of its stack trace. This is slow and unsupported, but fine for
+// debugging.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+// enter is called at the start of every tick. The first tick records the current goroutine as the one running the
+// main loop, and every tick after that checks that it still is.
+func (o *owner) enter() {
+	if o.id == 0 {
+		o.id = goroutineID()
+		return
+	}
+	o.check()
+}
+
+// check panics if called from a goroutine other than the one running the main loop.
+func (o *owner) check() {
+	if o.id == 0 {
+		// not running yet
+		return
+	}
+	if id := goroutineID(); id != o.id {
+		panic("gotogen used from goroutine " + strconv.FormatUint(id, 10) + ", but the main loop is on goroutine " +
+			strconv.FormatUint(o.id, 10) + "; use Post or Do instead")
+	}
+}
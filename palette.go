@@ -0,0 +1,95 @@
+package gotogen
+
+import (
+	"image/color"
+)
+
+const paletteSetting = "palette"
+
+// palette remaps every color sent to the face, after tint and brightness are applied.
+type palette struct {
+	name string
+	// remap is nil for the colors as they are
+	remap func(color.RGBA) color.RGBA
+}
+
+// palettes are the selectable palettes. The first one is the default.
+var palettes = []palette{
+	{"normal", nil},
+	{"amber", monochrome(color.RGBA{R: 0xFF, G: 0xB0})},
+	{"green", monochrome(color.RGBA{G: 0xFF, B: 0x40})},
+	{"white", monochrome(color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF})},
+	{"high contrast", highContrast},
+	{"red/green safe", redGreenSafe},
+}
+
+func paletteNames() []string {
+	names := make([]string, len(palettes))
+	for i, p := range palettes {
+		names[i] = p.name
+	}
+	return names
+}
+
+// luma is the approximate perceived brightness of the color, weighted 2:5:1.
+func luma(c color.RGBA) uint16 {
+	return (2*uint16(c.R) + 5*uint16(c.G) + uint16(c.B)) / 8
+}
+
+// monochrome shows everything in shades of one color, by brightness.
+func monochrome(tint color.RGBA) func(color.RGBA) color.RGBA {
+	return func(c color.RGBA) color.RGBA {
+		l := luma(c)
+		return color.RGBA{
+			R: uint8(uint16(tint.R) * l / 0xFF),
+			G: uint8(uint16(tint.G) * l / 0xFF),
+			B: uint8(uint16(tint.B) * l / 0xFF),
+			A: c.A,
+		}
+	}
+}
+
+// highContrast turns each channel fully on or fully off.
+func highContrast(c color.RGBA) color.RGBA {
+	on := func(v uint8) uint8 {
+		if v >= 0x60 {
+			return 0xFF
+		}
+		return 0
+	}
+	return color.RGBA{R: on(c.R), G: on(c.G), B: on(c.B), A: c.A}
+}
+
+// redGreenSafe moves green towards blue, so that red and green art can be told apart by people with red-green color
+// blindness. Red stays red, but picks up a little green to keep yellows from turning into pinks.
+func redGreenSafe(c color.RGBA) color.RGBA {
+	b := c.B
+	if c.G > b {
+		b = c.G
+	}
+	return color.RGBA{
+		R: c.R,
+		G: uint8((uint16(c.R) + uint16(c.G)) / 3),
+		B: b,
+		A: c.A,
+	}
+}
+
+// loadPalette loads the palette for the face display.
+func (g *Gotogen) loadPalette() {
+	b, ok := g.loadSetting(g.profileSetting(paletteSetting))
+	if ok && len(b) == 1 && int(b[0]) < len(palettes) {
+		g.palette = b[0]
+		g.paletteRemap = palettes[b[0]].remap
+	}
+}
+
+func (g *Gotogen) setPalette(selected uint8) {
+	if int(selected) >= len(palettes) {
+		return
+	}
+	g.palette = selected
+	g.paletteRemap = palettes[selected].remap
+	g.saveSetting(g.profileSetting(paletteSetting), []byte{selected})
+	g.redrawFrame()
+}
@@ -0,0 +1,93 @@
+package gotogen
+
+import (
+	"image/color"
+	"sort"
+
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+// LimitedColorDisplay is an optional interface that a face Display may implement if its panels can't show full color,
+// e.g. flexible white matrices that are only on or off, or two-color panels. Everything sent to the face is dithered
+// into the panel's colors, and images are dithered when they are loaded, so full-color art still comes through.
+type LimitedColorDisplay interface {
+	// PanelColors returns every color the panels can show, including black (off).
+	PanelColors() color.Palette
+}
+
+// loadPanelColors limits the face to the panel's colors, if it can't show full color.
+func (g *Gotogen) loadPanelColors() {
+	lcd, ok := g.faceDisplay.(LimitedColorDisplay)
+	if !ok {
+		return
+	}
+	p := lcd.PanelColors()
+	if len(p) < 2 {
+		return
+	}
+	g.panelColors = p
+	// the dither pattern spreads each color over the gap between neighboring panel colors
+	g.panelSpread = 0xFF / uint16(len(p)-1)
+	media.SetPalette(p)
+}
+
+// constrainColor dithers a face pixel into the panel's colors, with an ordered dither so that it stays the same from
+// one frame to the next.
+func (g *Gotogen) constrainColor(x, y int16, c color.RGBA) color.RGBA {
+	// from -1/2 to +1/2 of the spread
+	d := int16(bayer4[y&3][x&3])*int16(g.panelSpread)/16 - int16(g.panelSpread)/2
+	nudge := func(v uint8) uint8 {
+		n := int16(v) + d
+		if n < 0 {
+			return 0
+		}
+		if n > 0xFF {
+			return 0xFF
+		}
+		return uint8(n)
+	}
+	p := g.panelColors[g.panelColors.Index(color.RGBA{R: nudge(c.R), G: nudge(c.G), B: nudge(c.B), A: 0xFF})]
+	r, gr, b, _ := p.RGBA()
+	return color.RGBA{R: uint8(r >> 8), G: uint8(gr >> 8), B: uint8(b >> 8), A: c.A}
+}
+
+// checkMedia looks for images that can't be seen at all in the panel's colors, and lists them.
+func (g *Gotogen) checkMedia() {
+	var bad []string
+	for _, typ := range media.Types {
+		names, err := media.Enumerate(typ)
+		if err != nil {
+			g.reportError(err)
+			return
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := media.Check(typ, name); err != nil {
+				bad = append(bad, string(typ)+"/"+name+": "+err.Error())
+			}
+		}
+	}
+	if len(bad) == 0 {
+		bad = []string{"All media can be seen", "in the panel's colors."}
+	}
+	w, _ := g.statusText.Size()
+	var lines []string
+	for _, l := range bad {
+		lines = append(lines, wordWrap(l, int(w))...)
+	}
+	g.showPage("CHECK MEDIA", lines)
+}
+
+// panelColorsItems are the panel menu items for panels that can't show full color.
+func (g *Gotogen) panelColorsItems() []Item {
+	if g.panelColors == nil {
+		return nil
+	}
+	return []Item{
+		&ActionItem{
+			Name:   "Check media",
+			Help:   "Lists images that come out blank in the panel's colors.",
+			Invoke: g.checkMedia,
+		},
+	}
+}
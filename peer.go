@@ -0,0 +1,212 @@
+//go:build !nonetwork
+
+package gotogen
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// peerBeaconInterval is how often the leader sends its clock to followers.
+	peerBeaconInterval = time.Second
+	// peerLeadTime is how far in the future synchronized commands are scheduled, to give them time to reach peers.
+	peerLeadTime = 200 * time.Millisecond
+	// peerOffsetWindow is how many clock beacons the follower considers when estimating the clock offset.
+	peerOffsetWindow = 8
+	// syncBlinkPeriod is how often the eyes blink while synchronized with peers.
+	syncBlinkPeriod = 4 * time.Second
+	// syncBlinkLength is how long the eyes stay closed for a blink.
+	syncBlinkLength = 150 * time.Millisecond
+	// maxScheduled is how many synchronized commands can be waiting to run.
+	maxScheduled = 8
+)
+
+type peerRole uint8
+
+const (
+	peerRoleOff peerRole = iota
+	peerRoleLeader
+	peerRoleFollower
+)
+
+// peerSync is the state of synchronization with peers.
+type peerSync struct {
+	role peerRole
+	// phase is added to the synchronized clock, so that followers can deliberately lag (or lead) the leader
+	phase time.Duration
+	// offset is added to the local clock to get the leader's clock
+	offset     time.Duration
+	synced     bool
+	offsets    [peerOffsetWindow]time.Duration
+	numOffsets int
+	nextOffset int
+	nextBeacon time.Time
+	scheduled  []scheduledCommand
+}
+
+type scheduledCommand struct {
+	at  time.Duration
+	cmd string
+}
+
+// clock returns the time since boot, on the leader's clock if synchronized with one.
+func (g *Gotogen) clock() time.Duration {
+	c := time.Since(g.start)
+	if g.peer.synced {
+		c += g.peer.offset
+	}
+	return c + g.peer.phase
+}
+
+// syncBlinking returns whether the eyes should be closed right now. Blinks happen on a fixed schedule of the shared
+// clock, so synchronized units blink together.
+func (g *Gotogen) syncBlinking() bool {
+	return g.clock()%syncBlinkPeriod < syncBlinkLength
+}
+
+// updatePeers exchanges messages with peers and runs any synchronized commands that are due.
+func (g *Gotogen) updatePeers() {
+	pl, ok := g.driver.(PeerLink)
+	if !ok || g.peer.role == peerRoleOff {
+		return
+	}
+
+	for {
+		msg, ok := pl.PollPeer()
+		if !ok {
+			break
+		}
+		g.peerMessage(msg)
+	}
+
+	if g.peer.role == peerRoleLeader && time.Now().After(g.peer.nextBeacon) {
+		g.peer.nextBeacon = time.Now().Add(peerBeaconInterval)
+		pl.SendPeer("sync " + strconv.FormatInt(int64(time.Since(g.start)/time.Millisecond), 10))
+	}
+
+	now := g.clock()
+	for i := 0; i < len(g.peer.scheduled); i++ {
+		sc := g.peer.scheduled[i]
+		if sc.at > now {
+			continue
+		}
+		g.peer.scheduled = append(g.peer.scheduled[:i], g.peer.scheduled[i+1:]...)
+		i--
+		err := g.runCommand(sc.cmd)
+		if err != nil {
+			g.log(LogWarning, "synchronized command error: "+err.Error())
+		}
+	}
+}
+
+// peerMessage handles a message from a peer.
+func (g *Gotogen) peerMessage(msg string) {
+	verb, rest, _ := strings.Cut(msg, " ")
+	switch verb {
+	case "sync":
+		if g.peer.role != peerRoleFollower {
+			return
+		}
+		ms, err := strconv.ParseInt(rest, 10, 64)
+		if err != nil {
+			return
+		}
+		g.peerBeacon(time.Duration(ms) * time.Millisecond)
+	case "at":
+		if g.peer.role != peerRoleFollower {
+			return
+		}
+		ts, cmd, ok := strings.Cut(rest, " ")
+		ms, err := strconv.ParseInt(ts, 10, 64)
+		if !ok || err != nil {
+			return
+		}
+		g.schedule(time.Duration(ms)*time.Millisecond, cmd)
+	}
+}
+
+// peerBeacon updates the clock offset from a leader's clock beacon. Messages are only ever delayed, never early, so
+// the smallest offset seen recently is the best estimate.
+func (g *Gotogen) peerBeacon(leader time.Duration) {
+	p := &g.peer
+	p.offsets[p.nextOffset] = leader - time.Since(g.start)
+	p.nextOffset = (p.nextOffset + 1) % peerOffsetWindow
+	if p.numOffsets < peerOffsetWindow {
+		p.numOffsets++
+	}
+	best := p.offsets[0]
+	for _, o := range p.offsets[1:p.numOffsets] {
+		if o < best {
+			best = o
+		}
+	}
+	if !p.synced {
+		g.log(LogInfo, "synchronized with peer")
+	}
+	p.offset = best
+	p.synced = true
+}
+
+func (g *Gotogen) schedule(at time.Duration, cmd string) {
+	if len(g.peer.scheduled) >= maxScheduled {
+		g.peer.scheduled = g.peer.scheduled[1:]
+	}
+	g.peer.scheduled = append(g.peer.scheduled, scheduledCommand{at: at, cmd: cmd})
+}
+
+// syncCommand is called for commands that change the expression. If this unit is leading peers, the command is sent to
+// them and scheduled to run a little later on every unit at the same time, and true is returned.
+func (g *Gotogen) syncCommand(cmd string) bool {
+	pl, ok := g.driver.(PeerLink)
+	if !ok || g.peer.role != peerRoleLeader {
+		return false
+	}
+	at := g.clock() + peerLeadTime
+	pl.SendPeer("at " + strconv.FormatInt(int64(at/time.Millisecond), 10) + " " + cmd)
+	g.schedule(at, cmd)
+	return true
+}
+
+func (g *Gotogen) setPeerRole(selected uint8) {
+	g.peer.role = peerRole(selected)
+	g.peer.synced = false
+	g.peer.numOffsets = 0
+	g.peer.scheduled = g.peer.scheduled[:0]
+}
+
+func (g *Gotogen) setPeerPhase(selected uint8) {
+	switch selected {
+	case 0:
+		g.peer.phase = 0
+	case 1:
+		g.peer.phase = 100 * time.Millisecond
+	case 2:
+		g.peer.phase = 250 * time.Millisecond
+	case 3:
+		g.peer.phase = 500 * time.Millisecond
+	}
+}
+
+// peerMenu returns the peer sync settings menu, or nil if the driver can't talk to peers.
+func (g *Gotogen) peerMenu() *Menu {
+	if _, ok := g.driver.(PeerLink); !ok {
+		return nil
+	}
+	return &Menu{
+		Name: "Peer sync",
+		Items: []Item{
+			&SettingItem{
+				Name:    "Role",
+				Options: []string{"off", "leader", "follower"},
+				Apply:   g.setPeerRole,
+			},
+			&SettingItem{
+				Name:    "Phase offset",
+				Options: []string{"0ms", "100ms", "250ms", "500ms"},
+				Apply:   g.setPeerPhase,
+			},
+		},
+	}
+}
@@ -0,0 +1,21 @@
+//go:build nonetwork
+
+package gotogen
+
+// Peer sync is left out of builds with the nonetwork build tag, to save space; see peer.go.
+
+type peerRole uint8
+
+const peerRoleOff peerRole = 0
+
+type peerSync struct {
+	role peerRole
+}
+
+func (g *Gotogen) syncBlinking() bool { return false }
+
+func (g *Gotogen) updatePeers() {}
+
+func (g *Gotogen) syncCommand(cmd string) bool { return false }
+
+func (g *Gotogen) peerMenu() *Menu { return nil }
@@ -0,0 +1,231 @@
+// Package pixel has conversions between color.RGBA and the packed pixel formats used by small displays, plus helpers
+// for reducing colors to what a display can actually show.
+package pixel
+
+import (
+	"image/color"
+)
+
+// RGB565 is a color packed into 16 bits: 5 bits of red, 6 of green, and 5 of blue, from most to least significant.
+type RGB565 uint16
+
+// RGB332 is a color packed into 8 bits: 3 bits of red, 3 of green, and 2 of blue, from most to least significant.
+type RGB332 uint8
+
+// ToRGB565 packs the color, discarding alpha.
+func ToRGB565(c color.RGBA) RGB565 {
+	return RGB565(uint16(c.R>>3)<<11 | uint16(c.G>>2)<<5 | uint16(c.B>>3))
+}
+
+// RGBA unpacks the color. The low bits of each channel are filled in from the high bits, so that full intensity stays
+// full intensity.
+func (p RGB565) RGBA() color.RGBA {
+	r := uint8(p>>11) & 0x1F
+	g := uint8(p>>5) & 0x3F
+	b := uint8(p) & 0x1F
+	return color.RGBA{
+		R: r<<3 | r>>2,
+		G: g<<2 | g>>4,
+		B: b<<3 | b>>2,
+		A: 0xFF,
+	}
+}
+
+// ToRGB332 packs the color, discarding alpha.
+func ToRGB332(c color.RGBA) RGB332 {
+	return RGB332(c.R&0xE0 | (c.G&0xE0)>>3 | c.B>>6)
+}
+
+// RGBA unpacks the color. The low bits of each channel are filled in from the high bits, so that full intensity stays
+// full intensity.
+func (p RGB332) RGBA() color.RGBA {
+	r := uint8(p>>5) & 0x7
+	g := uint8(p>>2) & 0x7
+	b := uint8(p) & 0x3
+	return color.RGBA{
+		R: r<<5 | r<<2 | r>>1,
+		G: g<<5 | g<<2 | g>>1,
+		B: b<<6 | b<<4 | b<<2 | b,
+		A: 0xFF,
+	}
+}
+
+// bayer is a 4x4 ordered dithering matrix, with thresholds from 0 to 15.
+var bayer = [4][4]uint8{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// Dither nudges the color for the pixel at x, y so that, once the low bits of each channel are truncated away, areas
+// of the color average out to about the original color. bits is how many bits of each channel are kept, e.g. 5 for the
+// red channel of RGB565. Alpha is left alone.
+func Dither(c color.RGBA, x, y int16, rBits, gBits, bBits uint8) color.RGBA {
+	t := bayer[y&3][x&3]
+	return color.RGBA{
+		R: ditherChannel(c.R, t, rBits),
+		G: ditherChannel(c.G, t, gBits),
+		B: ditherChannel(c.B, t, bBits),
+		A: c.A,
+	}
+}
+
+// Dither565 is Dither for the RGB565 format.
+func Dither565(c color.RGBA, x, y int16) RGB565 {
+	return ToRGB565(Dither(c, x, y, 5, 6, 5))
+}
+
+// Dither332 is Dither for the RGB332 format.
+func Dither332(c color.RGBA, x, y int16) RGB332 {
+	return ToRGB332(Dither(c, x, y, 3, 3, 2))
+}
+
+func ditherChannel(v, threshold, bits uint8) uint8 {
+	if bits >= 8 {
+		return v
+	}
+	// the size of one step of the reduced channel, spread across the 16 thresholds
+	step := uint16(1) << (8 - bits)
+	n := uint16(v) + uint16(threshold)*step/16
+	if n > 0xFF {
+		return 0xFF
+	}
+	return uint8(n)
+}
+
+// Palette is a fixed set of colors.
+type Palette []color.RGBA
+
+// Index returns the index of the palette color closest to c, ignoring alpha. It panics if the palette is empty.
+func (p Palette) Index(c color.RGBA) int {
+	best, bestDist := 0, uint32(1<<32-1)
+	for i, pc := range p {
+		d := Distance(c, pc)
+		if d < bestDist {
+			best, bestDist = i, d
+			if d == 0 {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// Convert returns the palette color closest to c. It panics if the palette is empty.
+func (p Palette) Convert(c color.RGBA) color.RGBA {
+	return p[p.Index(c)]
+}
+
+// Distance is the squared distance between two colors, ignoring alpha, with the channels weighted roughly by how
+// sensitive eyes are to them.
+func Distance(a, b color.RGBA) uint32 {
+	dr := int32(a.R) - int32(b.R)
+	dg := int32(a.G) - int32(b.G)
+	db := int32(a.B) - int32(b.B)
+	return uint32(2*dr*dr + 4*dg*dg + 3*db*db)
+}
+
+// Quantize builds a palette of at most n colors that represents the colors well, using median cut. The colors are not
+// modified. Fully-transparent colors are ignored.
+func Quantize(colors []color.RGBA, n int) Palette {
+	if n <= 0 {
+		return nil
+	}
+	var opaque []color.RGBA
+	for _, c := range colors {
+		if c.A != 0 {
+			opaque = append(opaque, c)
+		}
+	}
+	if len(opaque) == 0 {
+		return nil
+	}
+
+	boxes := [][]color.RGBA{opaque}
+	for len(boxes) < n {
+		// split the box with the widest channel range
+		widest, widestRange, widestChan := -1, 0, 0
+		for i, b := range boxes {
+			if len(b) < 2 {
+				continue
+			}
+			ch, r := widestChannel(b)
+			if r > widestRange {
+				widest, widestRange, widestChan = i, r, ch
+			}
+		}
+		if widest < 0 {
+			// every box is a single color
+			break
+		}
+		b := boxes[widest]
+		sortByChannel(b, widestChan)
+		mid := len(b) / 2
+		boxes[widest] = b[:mid]
+		boxes = append(boxes, b[mid:])
+	}
+
+	p := make(Palette, len(boxes))
+	for i, b := range boxes {
+		p[i] = average(b)
+	}
+	return p
+}
+
+func channel(c color.RGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
+
+// widestChannel returns the channel with the biggest range of values in the colors, and that range.
+func widestChannel(colors []color.RGBA) (int, int) {
+	best, bestRange := 0, -1
+	for ch := 0; ch < 3; ch++ {
+		lo, hi := uint8(0xFF), uint8(0)
+		for _, c := range colors {
+			v := channel(c, ch)
+			if v < lo {
+				lo = v
+			}
+			if v > hi {
+				hi = v
+			}
+		}
+		if int(hi)-int(lo) > bestRange {
+			best, bestRange = ch, int(hi)-int(lo)
+		}
+	}
+	return best, bestRange
+}
+
+// sortByChannel sorts the colors by one channel. It's an insertion sort to avoid pulling in package sort; the inputs
+// are small images.
+func sortByChannel(colors []color.RGBA, ch int) {
+	for i := 1; i < len(colors); i++ {
+		c := colors[i]
+		v := channel(c, ch)
+		j := i
+		for ; j > 0 && channel(colors[j-1], ch) > v; j-- {
+			colors[j] = colors[j-1]
+		}
+		colors[j] = c
+	}
+}
+
+func average(colors []color.RGBA) color.RGBA {
+	var r, g, b uint32
+	for _, c := range colors {
+		r += uint32(c.R)
+		g += uint32(c.G)
+		b += uint32(c.B)
+	}
+	n := uint32(len(colors))
+	return color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 0xFF}
+}
@@ -0,0 +1,241 @@
+package pixel
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestToRGB565(t *testing.T) {
+	tests := []struct {
+		name string
+		c    color.RGBA
+		want RGB565
+	}{
+		{"black", color.RGBA{A: 0xFF}, 0x0000},
+		{"white", color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, 0xFFFF},
+		{"red", color.RGBA{R: 0xFF, A: 0xFF}, 0xF800},
+		{"green", color.RGBA{G: 0xFF, A: 0xFF}, 0x07E0},
+		{"blue", color.RGBA{B: 0xFF, A: 0xFF}, 0x001F},
+		{"lowest step", color.RGBA{R: 0x08, G: 0x04, B: 0x08, A: 0xFF}, 0x0821},
+		{"just under lowest step", color.RGBA{R: 0x07, G: 0x03, B: 0x07, A: 0xFF}, 0x0000},
+		{"alpha discarded", color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF}, 0xFFFF},
+	}
+	for _, tt := range tests {
+		if got := ToRGB565(tt.c); got != tt.want {
+			t.Errorf("%s: ToRGB565(%v) = %#04x, want %#04x", tt.name, tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestRGB565RGBA(t *testing.T) {
+	tests := []struct {
+		p    RGB565
+		want color.RGBA
+	}{
+		{0x0000, color.RGBA{A: 0xFF}},
+		{0xFFFF, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}},
+		{0xF800, color.RGBA{R: 0xFF, A: 0xFF}},
+		{0x07E0, color.RGBA{G: 0xFF, A: 0xFF}},
+		{0x001F, color.RGBA{B: 0xFF, A: 0xFF}},
+		{0x0821, color.RGBA{R: 0x08, G: 0x04, B: 0x08, A: 0xFF}},
+		{0x8410, color.RGBA{R: 0x84, G: 0x82, B: 0x84, A: 0xFF}},
+	}
+	for _, tt := range tests {
+		if got := tt.p.RGBA(); got != tt.want {
+			t.Errorf("RGB565(%#04x).RGBA() = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRGB565RoundTrip(t *testing.T) {
+	for i := 0; i <= 0xFFFF; i++ {
+		p := RGB565(i)
+		if got := ToRGB565(p.RGBA()); got != p {
+			t.Fatalf("ToRGB565(RGB565(%#04x).RGBA()) = %#04x", p, got)
+		}
+	}
+}
+
+func TestToRGB332(t *testing.T) {
+	tests := []struct {
+		name string
+		c    color.RGBA
+		want RGB332
+	}{
+		{"black", color.RGBA{A: 0xFF}, 0x00},
+		{"white", color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, 0xFF},
+		{"red", color.RGBA{R: 0xFF, A: 0xFF}, 0xE0},
+		{"green", color.RGBA{G: 0xFF, A: 0xFF}, 0x1C},
+		{"blue", color.RGBA{B: 0xFF, A: 0xFF}, 0x03},
+		{"lowest step", color.RGBA{R: 0x20, G: 0x20, B: 0x40, A: 0xFF}, 0x25},
+		{"just under lowest step", color.RGBA{R: 0x1F, G: 0x1F, B: 0x3F, A: 0xFF}, 0x00},
+		{"alpha discarded", color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF}, 0xFF},
+	}
+	for _, tt := range tests {
+		if got := ToRGB332(tt.c); got != tt.want {
+			t.Errorf("%s: ToRGB332(%v) = %#02x, want %#02x", tt.name, tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestRGB332RGBA(t *testing.T) {
+	tests := []struct {
+		p    RGB332
+		want color.RGBA
+	}{
+		{0x00, color.RGBA{A: 0xFF}},
+		{0xFF, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}},
+		{0xE0, color.RGBA{R: 0xFF, A: 0xFF}},
+		{0x1C, color.RGBA{G: 0xFF, A: 0xFF}},
+		{0x03, color.RGBA{B: 0xFF, A: 0xFF}},
+		{0x25, color.RGBA{R: 0x24, G: 0x24, B: 0x55, A: 0xFF}},
+	}
+	for _, tt := range tests {
+		if got := tt.p.RGBA(); got != tt.want {
+			t.Errorf("RGB332(%#02x).RGBA() = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRGB332RoundTrip(t *testing.T) {
+	for i := 0; i <= 0xFF; i++ {
+		p := RGB332(i)
+		if got := ToRGB332(p.RGBA()); got != p {
+			t.Fatalf("ToRGB332(RGB332(%#02x).RGBA()) = %#02x", p, got)
+		}
+	}
+}
+
+func TestDither(t *testing.T) {
+	black := color.RGBA{A: 0xFF}
+	white := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	for y := int16(0); y < 4; y++ {
+		for x := int16(0); x < 4; x++ {
+			if got := Dither565(black, x, y); got != 0x0000 {
+				t.Errorf("Dither565(black, %d, %d) = %#04x, want 0", x, y, got)
+			}
+			if got := Dither565(white, x, y); got != 0xFFFF {
+				t.Errorf("Dither565(white, %d, %d) = %#04x, want 0xffff", x, y, got)
+			}
+			if got := Dither332(black, x, y); got != 0x00 {
+				t.Errorf("Dither332(black, %d, %d) = %#02x, want 0", x, y, got)
+			}
+			if got := Dither332(white, x, y); got != 0xFF {
+				t.Errorf("Dither332(white, %d, %d) = %#02x, want 0xff", x, y, got)
+			}
+			c := color.RGBA{R: 0x12, G: 0x34, B: 0x56, A: 0x78}
+			if got := Dither(c, x, y, 8, 8, 8); got != c {
+				t.Errorf("Dither(%v, %d, %d) with 8 bits = %v, want it unchanged", c, x, y, got)
+			}
+		}
+	}
+}
+
+func TestDitherAverages(t *testing.T) {
+	// half of a 5-bit step should come out as a full step in half of the pixels
+	c := color.RGBA{R: 0x04, A: 0xFF}
+	n := 0
+	for y := int16(0); y < 4; y++ {
+		for x := int16(0); x < 4; x++ {
+			n += int(Dither565(c, x, y) >> 11)
+		}
+	}
+	if n != 8 {
+		t.Errorf("%d of 16 pixels stepped up, want 8", n)
+	}
+}
+
+func TestDitherNegativeCoordinates(t *testing.T) {
+	c := color.RGBA{R: 0x04, G: 0x04, B: 0x04, A: 0xFF}
+	if got, want := Dither(c, -1, -1, 5, 6, 5), Dither(c, 3, 3, 5, 6, 5); got != want {
+		t.Errorf("Dither at -1, -1 = %v, want %v as at 3, 3", got, want)
+	}
+}
+
+func TestPaletteIndex(t *testing.T) {
+	p := Palette{
+		{A: 0xFF},
+		{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF},
+		{R: 0xFF, A: 0xFF},
+		{R: 0xFF, A: 0xFF},
+	}
+	tests := []struct {
+		c    color.RGBA
+		want int
+	}{
+		{color.RGBA{A: 0xFF}, 0},
+		{color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, 1},
+		{color.RGBA{R: 100, G: 100, B: 100, A: 0xFF}, 0},
+		{color.RGBA{R: 200, G: 200, B: 200, A: 0xFF}, 1},
+		// the first of two equally close colors
+		{color.RGBA{R: 0xF0, A: 0xFF}, 2},
+		{color.RGBA{R: 0xFF, A: 0}, 2},
+	}
+	for _, tt := range tests {
+		if got := p.Index(tt.c); got != tt.want {
+			t.Errorf("Index(%v) = %d, want %d", tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	a := color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xFF}
+	b := color.RGBA{R: 0x30, G: 0x20, B: 0x10}
+	if d := Distance(a, a); d != 0 {
+		t.Errorf("Distance(a, a) = %d, want 0", d)
+	}
+	if Distance(a, b) != Distance(b, a) {
+		t.Errorf("Distance isn't symmetric")
+	}
+	black, white := color.RGBA{}, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF}
+	if d, want := Distance(black, white), uint32(9*0xFF*0xFF); d != want {
+		t.Errorf("Distance(black, white) = %d, want %d", d, want)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	blue := color.RGBA{B: 0xFF, A: 0xFF}
+	tests := []struct {
+		name   string
+		colors []color.RGBA
+		n      int
+		want   []color.RGBA
+	}{
+		{"no colors", nil, 4, nil},
+		{"zero size", []color.RGBA{red}, 0, nil},
+		{"negative size", []color.RGBA{red}, -1, nil},
+		{"only transparent", []color.RGBA{{R: 0xFF}}, 4, nil},
+		{"one color", []color.RGBA{red, red, red}, 4, []color.RGBA{red}},
+		{"transparent ignored", []color.RGBA{red, {B: 0xFF}}, 4, []color.RGBA{red}},
+		{"two colors", []color.RGBA{red, blue}, 2, []color.RGBA{red, blue}},
+		{"averaged", []color.RGBA{red, blue}, 1, []color.RGBA{{R: 0x7F, B: 0x7F, A: 0xFF}}},
+	}
+	for _, tt := range tests {
+		got := Quantize(tt.colors, tt.n)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: Quantize = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for _, w := range tt.want {
+			found := false
+			for _, c := range got {
+				found = found || c == w
+			}
+			if !found {
+				t.Errorf("%s: Quantize = %v, missing %v", tt.name, got, w)
+			}
+		}
+	}
+}
+
+func TestQuantizeDoesNotModify(t *testing.T) {
+	colors := []color.RGBA{{R: 0xFF, A: 0xFF}, {B: 0xFF, A: 0xFF}, {G: 0xFF, A: 0xFF}, {R: 0x10, A: 0xFF}}
+	orig := append([]color.RGBA(nil), colors...)
+	Quantize(colors, 2)
+	for i := range colors {
+		if colors[i] != orig[i] {
+			t.Fatalf("Quantize changed its input to %v", colors)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package gotogen
+
+import (
+	"strconv"
+	"time"
+)
+
+// BatteryDriver is an optional interface that a Driver may implement if it can measure the battery level.
+type BatteryDriver interface {
+	// Battery returns the remaining battery charge, from 0 to 100 percent.
+	Battery() (percent uint8, status SensorStatus)
+}
+
+// powerState is how aggressively the power policy is currently saving power.
+type powerState uint8
+
+const (
+	powerStateNormal powerState = iota
+	// powerStateReduced lowers the framerate a bit.
+	powerStateReduced
+	// powerStateLow lowers the framerate further and disables effects.
+	powerStateLow
+	// powerStateCritical runs the face as slowly as is still tolerable.
+	powerStateCritical
+)
+
+func (s powerState) String() string {
+	switch s {
+	case powerStateNormal:
+		return "normal"
+	case powerStateReduced:
+		return "reduced"
+	case powerStateLow:
+		return "low"
+	case powerStateCritical:
+		return "critical"
+	default:
+		return "INVALID"
+	}
+}
+
+// powerPolicies are the battery percentages at which each power state after normal is entered.
+var powerPolicies = [][3]uint8{
+	// off
+	{0, 0, 0},
+	// gentle
+	{40, 20, 8},
+	// aggressive
+	{70, 40, 15},
+}
+
+// powerHysteresis is how far the battery must rise above a threshold before leaving that power state, so that a
+// battery voltage that sags under load doesn't bounce between states.
+const powerHysteresis = 3
+
+// framerateQuarters is how many quarters of the target framerate to run at in each power state.
+var framerateQuarters = [...]uint{4, 3, 2, 1}
+
+// updatePowerPolicy checks the battery and adjusts the power state.
+func (g *Gotogen) updatePowerPolicy() {
+	bd, ok := g.driver.(BatteryDriver)
+	if !ok {
+		return
+	}
+	pct, st := bd.Battery()
+	if st != SensorStatusAvailable {
+		return
+	}
+	g.battery = pct
+	g.hasBattery = true
+
+	thresholds := powerPolicies[g.powerPolicy]
+	state := powerStateNormal
+	for i, t := range thresholds {
+		limit := t
+		// it takes a bit more charge to get out of a state than it took to get in
+		if powerState(i+1) <= g.powerState {
+			limit += powerHysteresis
+		}
+		if pct <= limit {
+			state = powerState(i + 1)
+		}
+	}
+	if state == g.powerState {
+		return
+	}
+
+	g.log(LogInfo, "power state "+g.powerState.String()+" -> "+state.String()+" at "+strconv.Itoa(int(pct))+"%")
+	g.powerState = state
+	g.applyFramerate()
+	if state >= powerStateLow {
+		g.effect = nil
+		g.redrawFrame()
+	}
+	if state == powerStateCritical {
+		// let the wearer know even if the status display isn't visible
+		g.Blink(MorsePattern("LB"), true)
+	} else if g.blinkPattern.repeat {
+		g.Blink(nil, false)
+	}
+}
+
+// applyFramerate sets the frame time from the requested framerate and the power state.
+func (g *Gotogen) applyFramerate() {
+	fps := g.framerate * framerateQuarters[g.powerState] / 4
+	if fps == 0 {
+		fps = 1
+	}
+	g.frameTime = time.Second / time.Duration(fps)
+}
+
+// framerates are the framerates that can be chosen in the menu, after the default one that was passed to New.
+var framerates = []uint{30, 45, 60}
+
+func framerateNames(base uint) []string {
+	names := []string{"default " + strconv.Itoa(int(base))}
+	for _, f := range framerates {
+		names = append(names, strconv.Itoa(int(f)))
+	}
+	return names
+}
+
+// setFramerate changes the target framerate. Run picks up the new frame time on the next frame.
+func (g *Gotogen) setFramerate(selected uint8) {
+	switch {
+	case selected == 0:
+		g.framerate = g.baseFramerate
+	case int(selected) <= len(framerates):
+		g.framerate = framerates[selected-1]
+	}
+	g.applyFramerate()
+}
+
+// effectsAllowed reports whether the power state allows optional visual effects.
+func (g *Gotogen) effectsAllowed() bool {
+	return g.powerState < powerStateLow
+}
+
+func (g *Gotogen) setPowerPolicy(selected uint8) {
+	if int(selected) < len(powerPolicies) {
+		g.powerPolicy = selected
+		// the next check will move to the right state
+		g.powerState = powerStateNormal
+		g.applyFramerate()
+	}
+}
@@ -0,0 +1,135 @@
+package gotogen
+
+import (
+	"errors"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+)
+
+// presetCount is how many expression presets there are. They are numbered from 1 so they can be recalled with a
+// numeric remote.
+const presetCount = 9
+
+// preset is a saved expression: the parts of the face, any full-screen animation, and the color adjustments.
+type preset struct {
+	eye, nose, mouth   string
+	animKind, animFile string
+	tint               color.RGBA
+	brightness         uint8
+}
+
+func presetSetting(n int) string {
+	return "preset" + strconv.Itoa(n)
+}
+
+func (p *preset) encode() []byte {
+	b := []byte{p.tint.R, p.tint.G, p.tint.B, p.brightness}
+	return append(b, strings.Join([]string{p.eye, p.nose, p.mouth, p.animKind, p.animFile}, "\x00")...)
+}
+
+func decodePreset(b []byte) (preset, error) {
+	if len(b) < 4 {
+		return preset{}, errors.New("preset too short")
+	}
+	fields := strings.Split(string(b[4:]), "\x00")
+	if len(fields) != 5 {
+		return preset{}, errors.New("corrupt preset")
+	}
+	return preset{
+		tint:       color.RGBA{R: b[0], G: b[1], B: b[2], A: 0xFF},
+		brightness: b[3],
+		eye:        fields[0],
+		nose:       fields[1],
+		mouth:      fields[2],
+		animKind:   fields[3],
+		animFile:   fields[4],
+	}, nil
+}
+
+func checkPreset(n int) error {
+	if n < 1 || n > presetCount {
+		return errors.New("no preset " + strconv.Itoa(n))
+	}
+	return nil
+}
+
+// SavePreset saves the current expression as preset n (1-9).
+//
+// SavePreset must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) SavePreset(n int) error {
+	g.owner.check()
+	err := checkPreset(n)
+	if err != nil {
+		return err
+	}
+	p := preset{
+		tint:       g.tint,
+		brightness: g.brightness,
+	}
+	p.eye, p.nose, p.mouth = f.Parts()
+	if g.faceState == faceStateAnimation {
+		p.animKind, p.animFile = g.animKind, g.animFile
+	}
+	g.saveSetting(presetSetting(n), p.encode())
+	return nil
+}
+
+// RecallPreset changes to the expression saved as preset n (1-9).
+//
+// RecallPreset must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) RecallPreset(n int) error {
+	g.owner.check()
+	err := checkPreset(n)
+	if err != nil {
+		return err
+	}
+	b, ok := g.loadSetting(presetSetting(n))
+	if !ok {
+		return errors.New("preset " + strconv.Itoa(n) + " not saved")
+	}
+	p, err := decodePreset(b)
+	if err != nil {
+		return err
+	}
+
+	err = g.setFaceParts(p.eye, p.nose, p.mouth)
+	if err != nil {
+		return errcode.Context("preset face", err)
+	}
+	if p.animKind != "" {
+		k, ok := findAnimationKind(p.animKind)
+		if !ok {
+			return errors.New("preset: unknown animation " + p.animKind)
+		}
+		err = g.newAnimation(p.animFile, k)
+		if err != nil {
+			return errcode.Context("preset", err)
+		}
+	} else {
+		g.returnToFace()
+	}
+	g.SetTint(p.tint)
+	return g.SetBrightness(p.brightness)
+}
+
+func (g *Gotogen) presetsMenu() *Menu {
+	m := &Menu{Name: "Presets"}
+	for i := 1; i <= presetCount; i++ {
+		n := i
+		m.Items = append(m.Items, &ActionItem{
+			Name:   "Recall " + strconv.Itoa(n),
+			Invoke: func() { g.reportError(g.Command("preset " + strconv.Itoa(n))) },
+		})
+	}
+	for i := 1; i <= presetCount; i++ {
+		n := i
+		m.Items = append(m.Items, &ActionItem{
+			Name:   "Save " + strconv.Itoa(n),
+			Invoke: func() { g.reportError(g.SavePreset(n)) },
+		})
+	}
+	return m
+}
@@ -0,0 +1,54 @@
+package gotogen
+
+import (
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation"
+)
+
+// previewInfoRow is the first line of the status screen used for the animation's name and running time, in place of
+// the preview of the face.
+const previewInfoRow = 5
+
+// previewHinted reports whether the animation asks for the status preview to be skipped.
+func previewHinted(a animation.Animation) bool {
+	np, ok := a.(animation.NoStatusPreview)
+	return ok && np.NoStatusPreview()
+}
+
+// setNoPreview turns the preview of the face on the status screen off or back on. The idle screen is cleared either
+// way, to get rid of the old preview or the text that replaced it.
+func (g *Gotogen) setNoPreview(off bool) {
+	if off == g.noPreview {
+		return
+	}
+	g.noPreview = off
+	if g.statusState == statusStateIdle {
+		g.changeStatusState(statusStateIdle)
+	}
+}
+
+// drawPreviewInfo shows the name of the animation and how long it has been running where the preview of the face
+// would be, while the preview is off.
+func (g *Gotogen) drawPreviewInfo() {
+	if !g.noPreview || g.compactStatus {
+		return
+	}
+	l := &g.previewLines[0]
+	l.reset()
+	if g.animFile != "" {
+		l.buf = append(l.buf, g.animFile...)
+	} else {
+		l.buf = append(l.buf, "animation"...)
+	}
+	changed := l.flush(g.statusText, previewInfoRow)
+
+	// the same format as alarm times, but in minutes and seconds
+	l = &g.previewLines[1]
+	l.reset()
+	l.buf = appendAlarmTime(l.buf, int(time.Since(g.animStart)/time.Second))
+	changed = l.flush(g.statusText, previewInfoRow+1) || changed
+	if changed {
+		g.statusDirty = true
+	}
+}
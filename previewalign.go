@@ -0,0 +1,127 @@
+package gotogen
+
+import (
+	"image/color"
+	"strconv"
+)
+
+const previewAlignSetting = "preview"
+
+// previewAlign is where the preview of the face goes on the status screen, and how big it is.
+type previewAlign struct {
+	x, y int8
+	// half shows every other pixel, for faces too big for the status screen
+	half bool
+}
+
+// defaultPreviewAlign puts the preview under the four lines of the idle status screen.
+var defaultPreviewAlign = previewAlign{y: 32}
+
+// previewAlignEditor is the state of the preview alignment calibration.
+type previewAlignEditor struct {
+	// selected is the field being adjusted: x, y, or scale
+	selected int
+	// the alignment before editing started, to go back to if the calibration is cancelled
+	saved previewAlign
+}
+
+// previewPixel draws a pixel of the face on the preview, where it has been aligned to.
+func (g *Gotogen) previewPixel(x, y int16, c color.RGBA) {
+	if g.largePrint {
+		// the text takes up the whole screen
+		return
+	}
+	a := &g.preview
+	if a.half {
+		if x&1 != 0 || y&1 != 0 {
+			return
+		}
+		x, y = x/2, y/2
+	}
+	x, y = x+int16(a.x), y+int16(a.y)
+	w, h := g.statusMirror.Size()
+	if x < 0 || y < 0 || x >= w || y >= h {
+		return
+	}
+	g.statusMirror.SetPixel(x, y, c)
+}
+
+func (g *Gotogen) loadPreviewAlign() {
+	g.preview = defaultPreviewAlign
+	b, ok := g.loadSetting(g.profileSetting(previewAlignSetting))
+	if ok && len(b) == 3 {
+		g.preview = previewAlign{x: int8(b[0]), y: int8(b[1]), half: b[2] == 1}
+	}
+}
+
+func (g *Gotogen) savePreviewAlign() {
+	half := uint8(0)
+	if g.preview.half {
+		half = 1
+	}
+	g.saveSetting(g.profileSetting(previewAlignSetting), []byte{uint8(g.preview.x), uint8(g.preview.y), half})
+}
+
+// alignPreview starts calibrating where the preview of the face goes on the status screen.
+func (g *Gotogen) alignPreview() {
+	g.previewEdit = previewAlignEditor{saved: g.preview}
+	g.changeStatusState(statusStatePreview)
+	g.redrawPreviewAlign()
+}
+
+// redrawPreviewAlign redraws the instructions and the whole preview where it is now.
+func (g *Gotogen) redrawPreviewAlign() {
+	g.clearStatusScreen()
+	a, e := &g.preview, &g.previewEdit
+	fields := [...]string{"x " + strconv.Itoa(int(a.x)), "y " + strconv.Itoa(int(a.y)), "1:1"}
+	if a.half {
+		fields[2] = "1:2"
+	}
+	fields[e.selected] = "[" + fields[e.selected] + "]"
+	_ = g.statusText.SetLineInverse(0, "ALIGN PREVIEW")
+	_ = g.statusText.SetLine(1, fields[0], " ", fields[1], " ", fields[2])
+	_ = g.statusText.SetLine(2, "Up/Dn move Dflt next")
+	_ = g.statusText.SetLine(3, "Menu save Back cancel")
+	g.statusDirty = true
+	g.statusForceUpdate = true
+	g.redrawFrame()
+}
+
+// updatePreviewAlign is called every frame while the preview is being aligned.
+func (g *Gotogen) updatePreviewAlign() {
+	a, e := &g.preview, &g.previewEdit
+	but := g.repeatedButton(g.pressedButton())
+	switch but {
+	case MenuButtonNone:
+		return
+	case MenuButtonUp, MenuButtonDown:
+		d := int8(1)
+		if but == MenuButtonDown {
+			d = -1
+		}
+		switch e.selected {
+		case 0:
+			if a.x+d >= -64 && a.x+d <= 127 {
+				a.x += d
+			}
+		case 1:
+			// down moves the preview down the screen
+			if a.y-d >= -64 && a.y-d <= 127 {
+				a.y -= d
+			}
+		case 2:
+			a.half = !a.half
+		}
+	case MenuButtonDefault:
+		e.selected = (e.selected + 1) % 3
+	case MenuButtonMenu:
+		g.savePreviewAlign()
+		g.changeStatusState(statusStateMenu)
+		return
+	case MenuButtonBack:
+		*a = e.saved
+		g.changeStatusState(statusStateMenu)
+		return
+	}
+	g.redrawPreviewAlign()
+}
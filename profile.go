@@ -0,0 +1,174 @@
+package gotogen
+
+import (
+	"time"
+
+	"github.com/ajanata/gotogen/internal/fmtlite"
+)
+
+// FrameStats are timing statistics for the main loop, over the last second.
+type FrameStats struct {
+	FPS uint32
+	// how long ticks took to run
+	MinFrame, AvgFrame, MaxFrame time.Duration
+	// how far the time between the starts of ticks strayed from the target frame time, on average and at worst
+	AvgJitter, MaxJitter time.Duration
+	// free heap memory, in bytes
+	HeapFree uint64
+	// errors from the face and status displays since boot
+	FaceErrors, StatusErrors uint32
+	// how many times driver callbacks took longer than they should have, since boot
+	SlowDriverCalls uint32
+}
+
+// frameWindow accumulates frame timing until it is published once a second.
+type frameWindow struct {
+	lastStart time.Time
+	frames    int
+	intervals int
+	min, max  time.Duration
+	sum       time.Duration
+	jitterSum time.Duration
+	jitterMax time.Duration
+}
+
+// recordFrameStart is called at the start of every tick, to measure how regularly ticks happen.
+func (g *Gotogen) recordFrameStart(now time.Time) {
+	w := &g.frameWindow
+	if !w.lastStart.IsZero() {
+		j := now.Sub(w.lastStart) - g.frameTime
+		if j < 0 {
+			j = -j
+		}
+		w.jitterSum += j
+		if j > w.jitterMax {
+			w.jitterMax = j
+		}
+		w.intervals++
+	}
+	w.lastStart = now
+}
+
+// recordFrameTime is called at the end of every tick with how long it took.
+func (g *Gotogen) recordFrameTime(d time.Duration) {
+	w := &g.frameWindow
+	if w.frames == 0 || d < w.min {
+		w.min = d
+	}
+	if d > w.max {
+		w.max = d
+	}
+	w.sum += d
+	w.frames++
+}
+
+// publishFrameStats makes the last second of timing available from Stats, and starts a new second.
+func (g *Gotogen) publishFrameStats() {
+	w := &g.frameWindow
+	s := FrameStats{
+		FPS:       g.lastFPS,
+		MinFrame:  w.min,
+		MaxFrame:  w.max,
+		MaxJitter: w.jitterMax,
+		HeapFree:  g.heapIdle,
+
+		FaceErrors:   g.faceHealth.total,
+		StatusErrors: g.statusHealth.total,
+
+		SlowDriverCalls: g.slowDriverCalls(),
+	}
+	if w.frames > 0 {
+		s.AvgFrame = w.sum / time.Duration(w.frames)
+	}
+	if w.intervals > 0 {
+		s.AvgJitter = w.jitterSum / time.Duration(w.intervals)
+	}
+	g.stats = s
+	*w = frameWindow{lastStart: w.lastStart}
+
+	if g.statusState == statusStateProfile {
+		g.drawProfile()
+	}
+}
+
+// Stats returns timing statistics for the main loop, over the last second.
+func (g *Gotogen) Stats() FrameStats {
+	return g.stats
+}
+
+// drawProfile draws the profiling page on the status screen. The lines are built in one buffer that is kept between
+// draws, though each one is still copied to a string for the text buffer.
+func (g *Gotogen) drawProfile() {
+	s := &g.stats
+	b := g.profileBuf[:0]
+	line := func(row int16) {
+		_ = g.statusText.SetLine(row, string(b))
+		b = b[:0]
+	}
+	if g.compactStatus {
+		b = append(b, "PROFILE "...)
+		b = fmtlite.AppendUint(b, uint64(s.FPS))
+		b = append(b, "Hz/"...)
+		b = appendMillis(b, g.frameTime)
+		line(0)
+		b = append(b, "frame "...)
+		b = appendMillis(appendMillisPart(b, s.AvgFrame), s.MaxFrame)
+		line(1)
+		b = append(b, "jitter "...)
+		b = appendMillis(appendMillisPart(b, s.AvgJitter), s.MaxJitter)
+		line(2)
+		b = g.appendProfileHeap(b)
+		line(3)
+		g.profileBuf = b
+		return
+	}
+	b = append(b, "PROFILE "...)
+	b = fmtlite.AppendUint(b, uint64(s.FPS))
+	b = append(b, "Hz"...)
+	line(0)
+	for i, t := range [...]struct {
+		name string
+		d    time.Duration
+	}{
+		{"frame min ", s.MinFrame},
+		{"frame avg ", s.AvgFrame},
+		{"frame max ", s.MaxFrame},
+		{"jitter avg ", s.AvgJitter},
+		{"jitter max ", s.MaxJitter},
+		{"target ", g.frameTime},
+	} {
+		b = appendMillis(append(b, t.name...), t.d)
+		line(int16(i) + 1)
+	}
+	b = g.appendProfileHeap(b)
+	line(7)
+	g.profileBuf = b
+}
+
+// appendProfileHeap appends the free heap and error counts for the profiling page.
+func (g *Gotogen) appendProfileHeap(b []byte) []byte {
+	s := &g.stats
+	b = append(b, "heap "...)
+	b = fmtlite.AppendUint(b, s.HeapFree/1024)
+	b = append(b, "k err "...)
+	b = fmtlite.AppendUint(b, uint64(s.FaceErrors))
+	b = append(b, '/')
+	return fmtlite.AppendUint(b, uint64(s.StatusErrors))
+}
+
+// appendMillis appends a duration in milliseconds to one decimal place, e.g. "16.7ms".
+func appendMillis(b []byte, d time.Duration) []byte {
+	return append(fmtlite.AppendFixed(b, int64(d/(100*time.Microsecond)), 1), "ms"...)
+}
+
+// appendMillisPart is appendMillis without the unit, followed by a /, for the first of a pair of durations.
+func appendMillisPart(b []byte, d time.Duration) []byte {
+	return append(fmtlite.AppendFixed(b, int64(d/(100*time.Microsecond)), 1), '/')
+}
+
+// updateProfile is called every frame while the profiling page is shown.
+func (g *Gotogen) updateProfile() {
+	if g.pressedButton() != MenuButtonNone {
+		g.changeStatusState(statusStateIdle)
+	}
+}
@@ -0,0 +1,298 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/errcode"
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+// promptLineGap is how long after the previous line a script line without a time is shown.
+const promptLineGap = 3 * time.Second
+
+// promptMaxLines limits how long a script can be, since it is all kept in memory.
+const promptMaxLines = 500
+
+// promptCue is a line of a teleprompter script, and when to show it.
+type promptCue struct {
+	at   time.Duration
+	text string
+}
+
+// prompter is a teleprompter on the status display, for performers with lines or lyrics to deliver in suit. The
+// script is pushed with the prompt command or loaded from media storage, and scrolls along on its own once started.
+type prompter struct {
+	script []promptCue
+	name   string
+	// elapsed is how far into the script playback was when it was last paused
+	elapsed time.Duration
+	playing bool
+	started time.Time
+	// cue is the line being shown at the top
+	cue int
+	// shown is the elapsed second shown in the header
+	shown int
+}
+
+// parsePromptLine parses a script line, which may start with a time in the same format as LRC lyrics files, e.g.
+// "[1:23] text" or "[1:23.45] text". LRC tags that aren't times, like "[ar:Artist]", are skipped.
+func parsePromptLine(line string) (at time.Duration, text string, timed, ok bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") {
+		return 0, line, false, line != ""
+	}
+	tag, rest, found := strings.Cut(line[1:], "]")
+	if !found {
+		return 0, line, false, true
+	}
+	m, s, found := strings.Cut(tag, ":")
+	mins, err1 := strconv.Atoi(m)
+	secs, err2 := strconv.ParseFloat(s, 32)
+	if !found || err1 != nil || err2 != nil || mins < 0 || secs < 0 {
+		return 0, "", false, false
+	}
+	at = time.Duration(mins)*time.Minute + time.Duration(secs*float64(time.Second))
+	return at, strings.TrimSpace(rest), true, true
+}
+
+// addPromptLine adds a line to the end of the script.
+func (g *Gotogen) addPromptLine(line string) error {
+	p := &g.prompt
+	if len(p.script) >= promptMaxLines {
+		return errors.New("prompt: script too long")
+	}
+	at, text, timed, ok := parsePromptLine(line)
+	if !ok {
+		return nil
+	}
+	if !timed && len(p.script) > 0 {
+		at = p.script[len(p.script)-1].at + promptLineGap
+	}
+	p.script = append(p.script, promptCue{at: at, text: text})
+	return nil
+}
+
+// LoadScript loads a teleprompter script from media storage (media/script/NAME.txt), one line per line of the file,
+// replacing the current script.
+func (g *Gotogen) LoadScript(name string) error {
+	g.owner.check()
+	b, err := media.ReadFile(media.TypeScript, name+".txt")
+	if err != nil {
+		return errcode.Context("load script "+name, err)
+	}
+	g.clearPrompter()
+	g.prompt.name = name
+	for _, line := range strings.Split(string(b), "\n") {
+		if err := g.addPromptLine(line); err != nil {
+			return err
+		}
+	}
+	g.drawPrompter()
+	return nil
+}
+
+// clearPrompter removes the script and rewinds.
+func (g *Gotogen) clearPrompter() {
+	g.prompt = prompter{}
+	g.drawPrompter()
+}
+
+// promptElapsed is how far into the script playback is.
+func (g *Gotogen) promptElapsed() time.Duration {
+	p := &g.prompt
+	if p.playing {
+		return p.elapsed + time.Since(p.started)
+	}
+	return p.elapsed
+}
+
+// setPromptPlaying starts or pauses the script.
+func (g *Gotogen) setPromptPlaying(playing bool) {
+	p := &g.prompt
+	if playing == p.playing {
+		return
+	}
+	if playing {
+		p.started = time.Now()
+	} else {
+		p.elapsed = g.promptElapsed()
+	}
+	p.playing = playing
+	g.drawPrompter()
+}
+
+// seekPrompter jumps to the start of the cue.
+func (g *Gotogen) seekPrompter(cue int) {
+	p := &g.prompt
+	if cue < 0 || cue >= len(p.script) {
+		return
+	}
+	p.cue = cue
+	p.elapsed = p.script[cue].at
+	p.started = time.Now()
+	g.drawPrompter()
+}
+
+// showPrompter switches the status display to the teleprompter.
+func (g *Gotogen) showPrompter() {
+	g.changeStatusState(statusStatePrompter)
+	g.drawPrompter()
+}
+
+// drawPrompter draws the current line highlighted at the top, and as many of the following lines as fit below it.
+func (g *Gotogen) drawPrompter() {
+	if g.statusState != statusStatePrompter {
+		return
+	}
+	p := &g.prompt
+	w, h := g.statusText.Size()
+	g.statusText.Clear()
+
+	// the same format as alarm times, but in minutes and seconds
+	header := "|| "
+	if p.playing {
+		header = "> "
+	}
+	p.shown = int(g.promptElapsed() / time.Second)
+	header += alarmTime(p.shown) + " " + p.name
+	if len(header) > int(w) {
+		header = header[:w]
+	}
+	if g.theme.HeaderInverse {
+		_ = g.statusText.SetLineInverse(0, header)
+	} else {
+		_ = g.statusText.SetLine(0, header)
+	}
+
+	row := int16(1)
+	if len(p.script) == 0 {
+		_ = g.statusText.SetLine(row, "(no script)")
+	}
+	for i := p.cue; i < len(p.script) && row < h; i++ {
+		for _, line := range wordWrap(p.script[i].text, int(w)) {
+			if row >= h {
+				break
+			}
+			if i == p.cue {
+				_ = g.statusText.SetLineInverse(row, line)
+			} else {
+				_ = g.statusText.SetLine(row, line)
+			}
+			row++
+		}
+	}
+	g.statusDirty = true
+}
+
+// updatePrompter is called every frame while the teleprompter is shown. Menu plays and pauses, Up and Down go back and
+// forward a line, and Back leaves it, keeping its place. It never times out, since the performer may not have a hand
+// free to bring it back.
+func (g *Gotogen) updatePrompter() {
+	p := &g.prompt
+	switch g.pressedButton() {
+	case MenuButtonMenu:
+		g.setPromptPlaying(!p.playing)
+	case MenuButtonUp:
+		g.seekPrompter(p.cue - 1)
+	case MenuButtonDown:
+		g.seekPrompter(p.cue + 1)
+	case MenuButtonBack:
+		g.setPromptPlaying(false)
+		g.changeStatusState(statusStateIdle)
+		return
+	}
+
+	if !p.playing {
+		return
+	}
+	elapsed := g.promptElapsed()
+	cue := p.cue
+	for cue+1 < len(p.script) && p.script[cue+1].at <= elapsed {
+		cue++
+	}
+	// redraw when the line changes, and every second for the clock
+	if cue != p.cue || int(elapsed/time.Second) != p.shown {
+		p.cue = cue
+		g.drawPrompter()
+	}
+}
+
+// promptCommand handles the prompt command.
+func (g *Gotogen) promptCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("prompt: need load, add, clear, play, pause, or stop")
+	}
+	switch args[0] {
+	case "load":
+		if len(args) != 2 {
+			return errors.New("prompt: need script name")
+		}
+		return g.LoadScript(args[1])
+	case "add":
+		if err := g.addPromptLine(strings.Join(args[1:], " ")); err != nil {
+			return err
+		}
+		g.drawPrompter()
+	case "clear":
+		g.clearPrompter()
+	case "play":
+		if g.statusState != statusStatePrompter {
+			g.showPrompter()
+		}
+		g.setPromptPlaying(true)
+	case "pause":
+		g.setPromptPlaying(false)
+	case "stop":
+		g.setPromptPlaying(false)
+		g.prompt.elapsed, g.prompt.cue = 0, 0
+		if g.statusState == statusStatePrompter {
+			g.changeStatusState(statusStateIdle)
+		}
+	default:
+		return errors.New("prompt: unknown subcommand " + args[0])
+	}
+	return nil
+}
+
+// scriptItems are the Load items for every script in media storage. They are refreshed every time the menu is opened,
+// since scripts can be uploaded at any time.
+func (g *Gotogen) scriptItems() []Item {
+	var items []Item
+	for _, n := range media.EnumerateFiles(media.TypeScript, ".txt") {
+		name := n
+		items = append(items, &ActionItem{
+			Name: "Load " + name,
+			Invoke: func() {
+				if err := g.LoadScript(name); err != nil {
+					g.reportError(err)
+					return
+				}
+				g.showPrompter()
+			},
+		})
+	}
+	return items
+}
+
+func (g *Gotogen) prompterMenu() *Menu {
+	g.promptMenu = &Menu{Name: "Teleprompter"}
+	g.refreshPrompterMenu()
+	return g.promptMenu
+}
+
+func (g *Gotogen) refreshPrompterMenu() {
+	g.promptMenu.selected, g.promptMenu.top = 0, 0
+	g.promptMenu.Items = append([]Item{
+		&ActionItem{
+			Name:   "Show",
+			Invoke: g.showPrompter,
+		},
+		&ActionItem{
+			Name:   "Rewind",
+			Invoke: func() { g.seekPrompter(0) },
+		},
+	}, g.scriptItems()...)
+}
@@ -0,0 +1,222 @@
+package gotogen
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/animation/qrcode"
+	"github.com/ajanata/gotogen/internal/qr"
+)
+
+const (
+	qrCodesSetting = "qrcodes"
+	// maxQRCodes is how many QR codes can be saved, not counting the driver's.
+	maxQRCodes = 8
+	// qrFaceTime is how long a QR code is shown on the face.
+	qrFaceTime = 30 * time.Second
+)
+
+// QRCode is text to offer as a QR code from the menu, e.g. a social handle, the URL of a web control panel, or a
+// pairing token. QR codes are small enough to scan off the status screen or the face, so the text has to be short:
+// about 50 bytes fit on a 32 pixel high display, and 130 on a 64 pixel one.
+type QRCode struct {
+	Name string
+	Text string
+}
+
+// QRCodeProvider is an optional interface that a Driver may implement to offer QR codes in the menu, as well as the
+// ones saved with the qr command. The driver's codes can change, e.g. to include a fresh pairing token; they are asked
+// for every time the menu is opened.
+type QRCodeProvider interface {
+	QRCodes() []QRCode
+}
+
+// qrCodes returns the driver's QR codes and then the saved ones.
+func (g *Gotogen) qrCodes() []QRCode {
+	var codes []QRCode
+	if qp, ok := g.driver.(QRCodeProvider); ok {
+		codes = append(codes, qp.QRCodes()...)
+	}
+	return append(codes, g.savedQRCodes...)
+}
+
+// findQRCode returns the QR code with the name, ignoring case.
+func (g *Gotogen) findQRCode(name string) (QRCode, bool) {
+	for _, c := range g.qrCodes() {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return QRCode{}, false
+}
+
+// ShowQRCode shows the text as a QR code on the status screen until a button is pressed, and also on the face for a
+// while if that is turned on in the menu. A code that doesn't fit where it would be shown isn't shown anywhere.
+//
+// ShowQRCode must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) ShowQRCode(name, text string) error {
+	g.owner.check()
+	code, err := qr.Encode([]byte(text))
+	if err != nil {
+		return err
+	}
+	_, h := g.statusDisplay.Size()
+	if code.Size+2 > int(h) {
+		return errors.New("qr: too long for the status display")
+	}
+	onFace := g.qr.face && !g.dnd && g.faceState != faceStateBusy
+	if _, fh := g.Size(); onFace && code.Size+2 > int(fh) {
+		return errors.New("qr: too long for the face")
+	}
+	g.qr.name, g.qr.code = name, code
+	g.changeStatusState(statusStateQR)
+	g.drawQRCode()
+
+	if onFace {
+		g.startAnimation(qrcode.New(code, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}, qrFaceTime))
+	}
+	return nil
+}
+
+// qrState is the QR code being shown on the status screen.
+type qrState struct {
+	name string
+	code *qr.Code
+	// face is whether to show QR codes on the face as well
+	face bool
+}
+
+// drawQRCode draws the QR code in a square at the right of the status screen, with its name to the left.
+func (g *Gotogen) drawQRCode() {
+	w, h := g.statusDisplay.Size()
+	g.qr.code.Draw(g.statusDisplay, image.Rect(int(w-h), 0, int(w), int(h)), color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+
+	// the name wraps in the columns to the left of the code
+	cols, rows := g.statusText.Size()
+	for i, line := range wordWrap(g.qr.name, int(cols)*int(w-h)/int(w)) {
+		if i >= int(rows) {
+			break
+		}
+		_ = g.statusText.SetLine(int16(i), line)
+	}
+	g.statusDirty = true
+}
+
+// updateQRCode is called every frame while a QR code is shown. Any button goes back to the idle screen.
+func (g *Gotogen) updateQRCode() {
+	if g.pressedButton() != MenuButtonNone {
+		g.qr.code = nil
+		g.changeStatusState(statusStateIdle)
+	}
+}
+
+func (g *Gotogen) loadQRCodes() {
+	b, ok := g.loadSetting(qrCodesSetting)
+	if !ok {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		name, text, ok := strings.Cut(line, " ")
+		if !ok || len(g.savedQRCodes) >= maxQRCodes {
+			continue
+		}
+		g.savedQRCodes = append(g.savedQRCodes, QRCode{Name: name, Text: text})
+	}
+}
+
+// saveQRCodes saves the QR codes as "NAME TEXT" lines.
+func (g *Gotogen) saveQRCodes() {
+	var b []byte
+	for _, c := range g.savedQRCodes {
+		b = append(b, c.Name...)
+		b = append(b, ' ')
+		b = append(b, c.Text...)
+		b = append(b, '\n')
+	}
+	g.saveSetting(qrCodesSetting, b)
+}
+
+// qrCommand handles "qr [NAME]", "qr add NAME TEXT..." and "qr del NAME". Without a name, it replies with the QR codes.
+func (g *Gotogen) qrCommand(args []string) error {
+	switch {
+	case len(args) == 0:
+		for _, c := range g.qrCodes() {
+			g.reply("qr " + c.Name + " " + c.Text)
+		}
+		return nil
+	case args[0] == "add":
+		if len(args) < 3 {
+			return errors.New("qr add: need name and text")
+		}
+		text := strings.Join(args[2:], " ")
+		if _, err := qr.Encode([]byte(text)); err != nil {
+			return err
+		}
+		g.deleteQRCode(args[1])
+		if len(g.savedQRCodes) >= maxQRCodes {
+			return errors.New("qr add: too many QR codes")
+		}
+		g.savedQRCodes = append(g.savedQRCodes, QRCode{Name: args[1], Text: text})
+		g.saveQRCodes()
+		return nil
+	case args[0] == "del":
+		if len(args) != 2 {
+			return errors.New("qr del: need name")
+		}
+		if !g.deleteQRCode(args[1]) {
+			return errors.New("qr del: no QR code " + args[1])
+		}
+		g.saveQRCodes()
+		return nil
+	}
+	c, ok := g.findQRCode(args[0])
+	if !ok {
+		return errors.New("qr: no QR code " + args[0])
+	}
+	return g.ShowQRCode(c.Name, c.Text)
+}
+
+// deleteQRCode removes the saved QR code with the name, if there is one.
+func (g *Gotogen) deleteQRCode(name string) bool {
+	for i, c := range g.savedQRCodes {
+		if strings.EqualFold(c.Name, name) {
+			g.savedQRCodes = append(g.savedQRCodes[:i], g.savedQRCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Gotogen) qrMenu() *Menu {
+	g.qrMenuItem = &Menu{Name: "QR codes"}
+	g.refreshQRMenu()
+	return g.qrMenuItem
+}
+
+// refreshQRMenu rebuilds the QR codes menu, since the codes can change while running.
+func (g *Gotogen) refreshQRMenu() {
+	m := g.qrMenuItem
+	m.selected, m.top = 0, 0
+	m.Items = m.Items[:0]
+	for _, c := range g.qrCodes() {
+		c := c
+		m.Items = append(m.Items, &ActionItem{
+			Name:   c.Name,
+			Invoke: func() { g.reportError(g.ShowQRCode(c.Name, c.Text)) },
+		})
+	}
+	face := uint8(0)
+	if g.qr.face {
+		face = 1
+	}
+	m.Items = append(m.Items, &SettingItem{
+		Name:    "Show on face",
+		Help:    "Also show QR codes on the face for a while, for scanning from further away.",
+		Options: []string{"off", "on"},
+		Active:  face,
+		Apply:   func(selected uint8) { g.qr.face = selected == 1 },
+	})
+}
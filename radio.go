@@ -0,0 +1,31 @@
+package gotogen
+
+// RadioDriver is an optional interface that a Driver may implement if it has WiFi or Bluetooth, so that the idle status
+// screen can show which of them are connected, with the theme's icons, after the battery.
+type RadioDriver interface {
+	// Radios returns whether WiFi and Bluetooth are connected. A radio that the hardware doesn't have is never
+	// connected.
+	Radios() (wifi, bluetooth bool)
+}
+
+// appendRadios appends the icons for the driver's connected radios, if it has any.
+func (g *Gotogen) appendRadios(buf []byte, sep string) []byte {
+	rd, ok := g.driver.(RadioDriver)
+	if !ok {
+		return buf
+	}
+	wifi, bt := rd.Radios()
+	for _, r := range [...]struct {
+		on   bool
+		icon Icon
+	}{{wifi, IconWiFi}, {bt, IconBluetooth}} {
+		if !r.on {
+			continue
+		}
+		if len(buf) > 0 {
+			buf = append(buf, sep...)
+		}
+		buf = append(buf, g.theme.Icon(r.icon)...)
+	}
+	return buf
+}
@@ -0,0 +1,174 @@
+package gotogen
+
+import (
+	"time"
+)
+
+const (
+	reactionsSetting = "reactions"
+	// maxReactionsPerMinute is the most that the rate limit can be set to, and how many start times are kept.
+	maxReactionsPerMinute = 30
+	// reactionQueueTime is how long a queued reaction waits before it is too late to be worth playing.
+	reactionQueueTime = 5 * time.Second
+)
+
+// reactionCooldowns and reactionRates are the options for the Cooldown and Rate limit settings. The first rate is off.
+var (
+	reactionCooldowns = [...]time.Duration{0, time.Second, 3 * time.Second, 10 * time.Second}
+	reactionRates     = [...]int{0, 6, 12, maxReactionsPerMinute}
+)
+
+// reactionLimits limits how often sensor-triggered reactions (boop and shake rules, and glitch on shake) can start, so
+// that a crowd taking turns booping the nose doesn't make the face flash from one animation to the next. Reactions over
+// the limit are dropped, or queued to play once the limits allow.
+type reactionLimits struct {
+	cooldown  time.Duration
+	perMinute int
+	queue     bool
+
+	last time.Time
+	// recent is when the last reactions started, as a ring
+	recent [maxReactionsPerMinute]time.Time
+	next   int
+	// pending is the queued reaction, if there is one; only one is kept, and any others are dropped
+	pending      func()
+	pendingSince time.Time
+}
+
+// reactionAllowed returns whether a reaction can start now.
+func (g *Gotogen) reactionAllowed(now time.Time) bool {
+	l := &g.reactions
+	if l.cooldown > 0 && !l.last.IsZero() && now.Sub(l.last) < l.cooldown {
+		return false
+	}
+	if l.perMinute > 0 {
+		n := 0
+		for _, t := range l.recent {
+			if !t.IsZero() && now.Sub(t) < time.Minute {
+				n++
+			}
+		}
+		if n >= l.perMinute {
+			return false
+		}
+	}
+	return true
+}
+
+// react starts a sensor-triggered reaction if the limits allow it, and otherwise drops or queues it.
+func (g *Gotogen) react(f func()) {
+	l := &g.reactions
+	now := time.Now()
+	if !g.reactionAllowed(now) {
+		if l.queue && l.pending == nil {
+			l.pending, l.pendingSince = f, now
+		}
+		return
+	}
+	l.last = now
+	l.recent[l.next] = now
+	l.next = (l.next + 1) % len(l.recent)
+	f()
+}
+
+// updateReactions starts the queued reaction once the limits allow it, or drops it once it has waited too long.
+func (g *Gotogen) updateReactions() {
+	l := &g.reactions
+	if l.pending == nil {
+		return
+	}
+	now := time.Now()
+	if now.Sub(l.pendingSince) > reactionQueueTime {
+		l.pending = nil
+		return
+	}
+	if !g.reactionAllowed(now) {
+		return
+	}
+	f := l.pending
+	l.pending = nil
+	g.react(f)
+}
+
+func (g *Gotogen) loadReactions() {
+	l := &g.reactions
+	// a short cooldown by default
+	l.cooldown = reactionCooldowns[1]
+	b, ok := g.loadSetting(reactionsSetting)
+	if !ok || len(b) != 3 || int(b[0]) >= len(reactionCooldowns) || int(b[1]) >= len(reactionRates) {
+		return
+	}
+	l.cooldown = reactionCooldowns[b[0]]
+	l.perMinute = reactionRates[b[1]]
+	l.queue = b[2] == 1
+}
+
+func (g *Gotogen) saveReactions() {
+	q := uint8(0)
+	if g.reactions.queue {
+		q = 1
+	}
+	g.saveSetting(reactionsSetting, []byte{g.reactionCooldownActive(), g.reactionRateActive(), q})
+}
+
+func (g *Gotogen) reactionCooldownActive() uint8 {
+	for i, d := range reactionCooldowns {
+		if d == g.reactions.cooldown {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+func (g *Gotogen) reactionRateActive() uint8 {
+	for i, n := range reactionRates {
+		if n == g.reactions.perMinute {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+func (g *Gotogen) reactionsMenu() *Menu {
+	queue := uint8(0)
+	if g.reactions.queue {
+		queue = 1
+	}
+	return &Menu{
+		Name: "Reaction limits",
+		Help: "Limits how often boops and shakes can set off reactions, so a crowd mashing the nose doesn't make the face flash.",
+		Items: []Item{
+			&SettingItem{
+				Name:    "Cooldown",
+				Help:    "The least time between one reaction starting and the next.",
+				Options: []string{"off", "1s", "3s", "10s"},
+				Active:  g.reactionCooldownActive(),
+				Apply: func(selected uint8) {
+					g.reactions.cooldown = reactionCooldowns[selected]
+					g.saveReactions()
+				},
+			},
+			&SettingItem{
+				Name:    "Rate limit",
+				Help:    "The most reactions that can start in a minute.",
+				Options: []string{"off", "6/min", "12/min", "30/min"},
+				Active:  g.reactionRateActive(),
+				Apply: func(selected uint8) {
+					g.reactions.perMinute = reactionRates[selected]
+					g.saveReactions()
+				},
+			},
+			&SettingItem{
+				Name:    "Over limit",
+				Help:    "Whether a reaction over the limits is dropped, or waits a few seconds to play.",
+				Options: []string{"drop", "queue"},
+				Active:  queue,
+				Apply: func(selected uint8) {
+					g.reactions.queue = selected == 1
+					g.reactions.pending = nil
+					g.saveReactions()
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package gotogen
+
+import (
+	"time"
+)
+
+const (
+	buttonMapSetting = "btnmap"
+	// remapTimeout is how long the remap wizard waits for a button before skipping that one.
+	remapTimeout = 5 * time.Second
+)
+
+// buttonMap maps the buttons the driver reports (physical buttons) to the buttons Gotogen acts on (logical buttons).
+type buttonMap [menuButtonCount]MenuButton
+
+// remapTargets are the logical buttons the remap wizard asks for, in order.
+var remapTargets = []MenuButton{
+	MenuButtonMenu, MenuButtonBack, MenuButtonUp, MenuButtonDown, MenuButtonDefault, MenuButtonNextFace,
+}
+
+func identityButtonMap() buttonMap {
+	var m buttonMap
+	for i := range m {
+		m[i] = MenuButton(i)
+	}
+	return m
+}
+
+func (m *buttonMap) apply(b MenuButton) MenuButton {
+	if int(b) >= len(m) {
+		return MenuButtonNone
+	}
+	return m[b]
+}
+
+func (m *buttonMap) applySet(s ButtonSet) ButtonSet {
+	var out ButtonSet
+	for b := MenuButton(1); b < menuButtonCount; b++ {
+		if s.Has(b) {
+			out = out.With(m[b])
+		}
+	}
+	return out
+}
+
+// valid checks that the map can still get into the menu, so that a botched remap can't lock the user out.
+func (m *buttonMap) valid() bool {
+	for _, b := range m {
+		if b == MenuButtonMenu {
+			return true
+		}
+	}
+	return false
+}
+
+// pressedButton returns the button pressed this frame, after remapping.
+func (g *Gotogen) pressedButton() MenuButton {
+	start := time.Now()
+	b := g.driver.PressedButton()
+	g.timeDriverCall(driverCallPressedButton, start)
+	return g.buttonMap.apply(b)
+}
+
+// loadButtonMap loads the button map from settings storage, if there is one.
+func (g *Gotogen) loadButtonMap() {
+	g.buttonMap = identityButtonMap()
+	b, ok := g.loadSetting(buttonMapSetting)
+	if !ok || len(b) != len(g.buttonMap) {
+		return
+	}
+	var m buttonMap
+	for i, v := range b {
+		if v >= uint8(menuButtonCount) {
+			return
+		}
+		m[i] = MenuButton(v)
+	}
+	if m.valid() {
+		g.buttonMap = m
+	}
+}
+
+func (g *Gotogen) saveButtonMap() {
+	b := make([]byte, len(g.buttonMap))
+	for i, v := range g.buttonMap {
+		b[i] = uint8(v)
+	}
+	g.saveSetting(buttonMapSetting, b)
+}
+
+// remapWizard tracks the progress of the button remap wizard.
+type remapWizard struct {
+	step     int
+	deadline time.Time
+	m        buttonMap
+}
+
+func (g *Gotogen) startRemap() {
+	g.changeStatusState(statusStateRemap)
+	g.remap = remapWizard{deadline: time.Now().Add(remapTimeout)}
+	g.renderRemap()
+}
+
+func (g *Gotogen) renderRemap() {
+	g.statusText.Clear()
+	_ = g.statusText.SetLineInverse(0, "BUTTON REMAP")
+	if g.compactStatus {
+		_ = g.statusText.SetLine(1, "Press button for")
+		_ = g.statusText.SetLine(2, "  "+remapTargets[g.remap.step].String())
+		_ = g.statusText.SetLine(3, "Wait to skip")
+		return
+	}
+	_ = g.statusText.SetLine(2, "Press button for")
+	_ = g.statusText.SetLine(3, "  "+remapTargets[g.remap.step].String())
+	_ = g.statusText.SetLine(5, "Wait to skip")
+}
+
+// updateRemap is called every frame while the remap wizard is running.
+func (g *Gotogen) updateRemap() {
+	now := time.Now()
+	raw := g.driver.PressedButton()
+	switch {
+	case raw != MenuButtonNone && int(raw) < len(g.remap.m):
+		if g.remap.m[raw] != MenuButtonNone {
+			// already used for something else
+			return
+		}
+		g.remap.m[raw] = remapTargets[g.remap.step]
+	case now.After(g.remap.deadline):
+		// skipped
+	default:
+		return
+	}
+
+	g.remap.step++
+	g.remap.deadline = now.Add(remapTimeout)
+	if g.remap.step < len(remapTargets) {
+		g.renderRemap()
+		return
+	}
+
+	if g.remap.m.valid() {
+		g.buttonMap = g.remap.m
+		g.saveButtonMap()
+		g.log(LogInfo, "buttons remapped")
+	} else {
+		g.log(LogWarning, "remap did not include a menu button, ignoring")
+		g.setWarning("Remap needs Menu")
+	}
+	g.changeStatusState(statusStateIdle)
+}
+
+func (g *Gotogen) resetButtonMap() {
+	g.buttonMap = identityButtonMap()
+	g.saveButtonMap()
+}
+
+// nextFace switches to the next full-screen image, or back to the face after the last one.
+func (g *Gotogen) nextFace() {
+	g.nextFaceIndex++
+	if g.nextFaceIndex > len(g.faceImages) {
+		g.nextFaceIndex = 0
+	}
+	// through Command, so that it can be recorded into macros and synchronized with peers
+	var err error
+	if g.nextFaceIndex == 0 {
+		err = g.Command("stop")
+	} else {
+		err = g.StartAnimationByName(animationKinds[0].name, g.faceImages[g.nextFaceIndex-1])
+	}
+	g.reportError(err)
+}
+
+func (g *Gotogen) buttonsMenu() *Menu {
+	return &Menu{
+		Name: "Buttons",
+		Items: []Item{
+			&ActionItem{
+				Name:   "Remap buttons",
+				Invoke: g.startRemap,
+			},
+			&ActionItem{
+				Name:   "Reset mapping",
+				Invoke: g.resetButtonMap,
+			},
+			g.bindingsMenu(),
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package gotogen
+
+import (
+	"time"
+)
+
+// minRepeatInterval is the fastest that a held button will repeat, regardless of acceleration.
+const minRepeatInterval = 15 * time.Millisecond
+
+// buttonRepeat tracks a held up or down button for accelerated repeat inside setting lists.
+type buttonRepeat struct {
+	button MenuButton
+	since  time.Time
+	next   time.Time
+}
+
+// repeatedButton returns a synthesized button press if the driver reports up or down being held long enough to start
+// repeating. Drivers that do not implement ButtonStateDriver never repeat here.
+//
+// pressed is the button that the driver reported via PressedButton this tick, if any. A real press resets the repeat
+// timing, so that the driver's own repeat (if it has one) and ours don't stack up.
+func (g *Gotogen) repeatedButton(pressed MenuButton) MenuButton {
+	bs, ok := g.driver.(ButtonStateDriver)
+	if !ok || g.repeatDelay == 0 {
+		return pressed
+	}
+
+	held := g.buttonMap.applySet(bs.ButtonState())
+	var but MenuButton
+	switch {
+	case held.Has(MenuButtonUp) && !held.Has(MenuButtonDown):
+		but = MenuButtonUp
+	case held.Has(MenuButtonDown) && !held.Has(MenuButtonUp):
+		but = MenuButtonDown
+	}
+
+	now := time.Now()
+	if but == MenuButtonNone || but != g.repeat.button {
+		g.repeat = buttonRepeat{
+			button: but,
+			since:  now,
+			next:   now.Add(g.repeatDelay),
+		}
+		return pressed
+	}
+	if pressed != MenuButtonNone {
+		g.repeat.next = now.Add(g.repeatInterval(now))
+		return pressed
+	}
+	if now.Before(g.repeat.next) {
+		return MenuButtonNone
+	}
+
+	g.repeat.next = now.Add(g.repeatInterval(now))
+	return but
+}
+
+// repeatInterval determines how long until the next repeat, getting shorter the longer the button has been held.
+func (g *Gotogen) repeatInterval(now time.Time) time.Duration {
+	interval := g.repeatDelay / 2
+	if g.repeatAccel > 0 {
+		// every second the button is held after the initial delay speeds it up some more
+		held := now.Sub(g.repeat.since) - g.repeatDelay
+		if held > 0 {
+			interval /= 1 + time.Duration(g.repeatAccel)*held/time.Second
+		}
+	}
+	if interval < minRepeatInterval {
+		interval = minRepeatInterval
+	}
+	return interval
+}
+
+func (g *Gotogen) setRepeatDelay(selected uint8) {
+	switch selected {
+	case 0:
+		g.repeatDelay = 0
+	case 1:
+		g.repeatDelay = 250 * time.Millisecond
+	case 2:
+		g.repeatDelay = 400 * time.Millisecond
+	case 3:
+		g.repeatDelay = 600 * time.Millisecond
+	}
+}
+
+func (g *Gotogen) setRepeatAccel(selected uint8) {
+	g.repeatAccel = selected * 2
+}
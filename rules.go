@@ -0,0 +1,274 @@
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rulesSetting = "rules"
+	// maxRules is how many rules can be configured.
+	maxRules = 16
+)
+
+// ruleTrigger is a condition that can set off a rule.
+type ruleTrigger uint8
+
+const (
+	ruleTriggerBoop ruleTrigger = iota
+	ruleTriggerShake
+	ruleTriggerTalking
+	// ruleTriggerBattery is when the battery is below a percentage.
+	ruleTriggerBattery
+	// ruleTriggerTime is a minute of the day, on the wall clock.
+	ruleTriggerTime
+	// ruleTriggerBoopEvent is a kind of boop, like a double tap or a long boop.
+	ruleTriggerBoopEvent
+)
+
+// rule runs an action when its trigger starts. Rules let reactions be configured instead of hardcoded.
+type rule struct {
+	text     string
+	trigger  ruleTrigger
+	arg      int
+	cooldown time.Duration
+	duration time.Duration
+	action   string
+
+	// whether the trigger was true last tick, so the rule only fires when it starts
+	was   bool
+	fired time.Time
+	until time.Time
+}
+
+// parseRule parses a rule in the form "TRIGGER COOLDOWN DURATION COMMAND...", where TRIGGER is one of boop, shake,
+// talking, battery<PERCENT, time=HH:MM, or boop=KIND (KIND is tap, double, triple, or long); COOLDOWN and DURATION
+// are durations like 10s; and COMMAND is any command (see Command). Commands that show something on the face (anim,
+// clock) are stopped after the duration, unless it is 0.
+//
+// For example, "boop 30s 3s anim slide wait", "boop=long 0 0 expr happy", or "battery<15 5m 0 caption charge me".
+func parseRule(text string) (*rule, error) {
+	f := strings.Fields(text)
+	if len(f) < 4 {
+		return nil, errors.New("rule: need trigger, cooldown, duration, and command")
+	}
+	r := &rule{text: strings.Join(f, " ")}
+
+	switch {
+	case f[0] == "boop":
+		r.trigger = ruleTriggerBoop
+	case f[0] == "shake":
+		r.trigger = ruleTriggerShake
+	case f[0] == "talking":
+		r.trigger = ruleTriggerTalking
+	case strings.HasPrefix(f[0], "battery<"):
+		r.trigger = ruleTriggerBattery
+		n, err := strconv.Atoi(f[0][len("battery<"):])
+		if err != nil || n < 0 || n > 100 {
+			return nil, errors.New("rule: bad battery percent")
+		}
+		r.arg = n
+	case strings.HasPrefix(f[0], "time="):
+		r.trigger = ruleTriggerTime
+		h, m, ok := strings.Cut(f[0][len("time="):], ":")
+		hour, err1 := strconv.Atoi(h)
+		minute, err2 := strconv.Atoi(m)
+		if !ok || err1 != nil || err2 != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+			return nil, errors.New("rule: bad time")
+		}
+		r.arg = hour*60 + minute
+	case strings.HasPrefix(f[0], "boop="):
+		r.trigger = ruleTriggerBoopEvent
+		e, ok := parseBoopEvent(f[0][len("boop="):])
+		if !ok {
+			return nil, errors.New("rule: bad boop kind")
+		}
+		r.arg = int(e)
+	default:
+		return nil, errors.New("rule: unknown trigger " + f[0])
+	}
+
+	var err error
+	r.cooldown, err = parseRuleDuration(f[1])
+	if err != nil {
+		return nil, err
+	}
+	r.duration, err = parseRuleDuration(f[2])
+	if err != nil {
+		return nil, err
+	}
+	r.action = strings.Join(f[3:], " ")
+	return r, nil
+}
+
+func parseRuleDuration(s string) (time.Duration, error) {
+	if s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, errors.New("rule: bad duration " + s)
+	}
+	return d, nil
+}
+
+// triggered returns whether the rule's trigger condition is currently true.
+func (g *Gotogen) triggered(r *rule) bool {
+	switch r.trigger {
+	case ruleTriggerBoop:
+		return g.booped()
+	case ruleTriggerShake:
+		return g.shaking
+	case ruleTriggerTalking:
+		return g.Talking()
+	case ruleTriggerBattery:
+		return g.hasBattery && int(g.battery) < r.arg
+	case ruleTriggerTime:
+		t, ok := g.wallClock()
+		return ok && t.Hour()*60+t.Minute() == r.arg
+	case ruleTriggerBoopEvent:
+		return int(g.boopClass.event) == r.arg
+	default:
+		return false
+	}
+}
+
+// updateRules runs the actions of any rules that were just triggered, and stops any that have run their duration.
+func (g *Gotogen) updateRules() {
+	if !g.rulesEnabled || g.dnd {
+		return
+	}
+	now := time.Now()
+	// the current character's rules apply as well as the saved ones
+	for _, rules := range [...][]*rule{g.rules, g.characters.rules} {
+		for _, r := range rules {
+			if !r.until.IsZero() && now.After(r.until) {
+				r.until = time.Time{}
+				g.reportError(g.Command("stop"))
+			}
+
+			t := g.triggered(r)
+			start := t && !r.was
+			r.was = t
+			if !start || (!r.fired.IsZero() && now.Sub(r.fired) < r.cooldown) {
+				continue
+			}
+			r.fired = now
+			if r.fromSensor() {
+				rr := r
+				g.react(func() { g.fireRule(rr) })
+			} else {
+				g.fireRule(r)
+			}
+		}
+	}
+}
+
+// fromSensor returns whether the rule is triggered by the boop sensor or the accelerometer, and so subject to the
+// reaction limits.
+func (r *rule) fromSensor() bool {
+	return r.trigger == ruleTriggerBoop || r.trigger == ruleTriggerShake || r.trigger == ruleTriggerBoopEvent
+}
+
+// fireRule runs the rule's action.
+func (g *Gotogen) fireRule(r *rule) {
+	g.log(LogInfo, "rule: "+r.text)
+	if f := strings.Fields(r.action); g.exprLocked() && expressionCommand(strings.ToLower(f[0])) {
+		g.log(LogInfo, "rule: "+errExprLocked.Error())
+		return
+	}
+	err := g.Command(r.action)
+	if err != nil {
+		g.reportError(errors.New("rule: " + err.Error()))
+		return
+	}
+	if r.duration > 0 && g.faceState == faceStateAnimation {
+		r.until = time.Now().Add(r.duration)
+	}
+}
+
+// AddRule parses and adds a rule (see parseRule for the format), and saves the rules.
+//
+// AddRule must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) AddRule(text string) error {
+	g.owner.check()
+	if len(g.rules) >= maxRules {
+		return errors.New("rule: too many rules")
+	}
+	r, err := parseRule(text)
+	if err != nil {
+		return err
+	}
+	g.rules = append(g.rules, r)
+	g.saveRules()
+	return nil
+}
+
+// ClearRules removes every rule, and drops a reaction that is waiting for the reaction limits, which may be one of
+// theirs.
+//
+// ClearRules must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) ClearRules() {
+	g.owner.check()
+	g.rules = g.rules[:0]
+	g.reactions.pending = nil
+	g.saveRules()
+}
+
+// setRulesEnabled turns the rules on or off. Turning them off drops a reaction that is waiting for the reaction
+// limits, like ClearRules.
+func (g *Gotogen) setRulesEnabled(selected uint8) {
+	g.rulesEnabled = selected == 1
+	if !g.rulesEnabled {
+		g.reactions.pending = nil
+	}
+}
+
+func (g *Gotogen) loadRules() {
+	b, ok := g.loadSetting(rulesSetting)
+	if !ok {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.TrimSpace(line) == "" || len(g.rules) >= maxRules {
+			continue
+		}
+		r, err := parseRule(line)
+		if err != nil {
+			g.log(LogWarning, "loading rules: "+err.Error())
+			continue
+		}
+		g.rules = append(g.rules, r)
+	}
+}
+
+func (g *Gotogen) saveRules() {
+	var b []byte
+	for _, r := range g.rules {
+		b = append(b, r.text...)
+		b = append(b, '\n')
+	}
+	g.saveSetting(rulesSetting, b)
+}
+
+func (g *Gotogen) rulesMenu() *Menu {
+	return &Menu{
+		Name: "Rules",
+		Items: []Item{
+			&SettingItem{
+				Name:    "Rules",
+				Options: []string{"off", "on"},
+				Active:  1,
+				Apply:   g.setRulesEnabled,
+			},
+			&ActionItem{
+				Name:   "Clear rules",
+				Invoke: g.ClearRules,
+			},
+			g.reactionsMenu(),
+			g.behaviorMenu(),
+		},
+	}
+}
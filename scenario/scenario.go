@@ -0,0 +1,294 @@
+// Package scenario runs scripted scenarios against a Gotogen, with in-memory displays and a driver whose buttons and
+// sensors are driven by the script, and checks that it ends up in the expected states. It is for regression testing the
+// menu and animation state machines on a computer, without any hardware.
+//
+// A scenario is a JSON file:
+//
+//	{
+//	  "name": "open the menu and start an animation",
+//	  "framerate": 30,
+//	  "steps": [
+//	    {"at": "200ms", "button": "back"},
+//	    {"at": "500ms", "button": "menu"},
+//	    {"at": "1s", "expect": {"status": "menu", "menu": "GOTOGEN MENU"}},
+//	    {"at": "1s", "boop": 200},
+//	    {"at": "2s", "command": "anim slide wait"},
+//	    {"at": "2500ms", "expect": {"face": "animation"}}
+//	  ]
+//	}
+//
+// Each step happens once its time (since the end of Init) has passed. A step can press a button (menu, back, up, down,
+// default, or next face) for one frame; press random buttons, one per frame, for a number of frames ("random": 500),
+// checking that the menu's selection stays inside it and nothing panics; set the boop distance, the accelerometer
+// reading ("accel": [x, y, z]), or whether the driver hears talking, which all stay set until changed; run a command
+// (see gotogen.Command); or check the state (see gotogen.Snapshot) after the next frame. Expectations that are left
+// out aren't checked.
+//
+// The status screen starts on the boot log, which the first button press clears. Scenarios run in real time, since
+// Gotogen keeps time with the wall clock.
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ajanata/gotogen"
+	"github.com/ajanata/gotogen/internal/framebuf"
+)
+
+// Scenario is a script of inputs and expected states.
+type Scenario struct {
+	Name string `json:"name"`
+	// Framerate is the framerate to run at; 30 if it is left out.
+	Framerate uint `json:"framerate"`
+	// Seed seeds the random button presses, so that a failing run can be repeated.
+	Seed  uint32 `json:"seed"`
+	Steps []Step `json:"steps"`
+}
+
+// Step is something that happens at a point in the scenario.
+type Step struct {
+	// At is when the step happens, as a duration like "1.5s".
+	At      string    `json:"at"`
+	Button  string    `json:"button,omitempty"`
+	Random  int       `json:"random,omitempty"`
+	Boop    *uint8    `json:"boop,omitempty"`
+	Accel   *[3]int32 `json:"accel,omitempty"`
+	Talking *bool     `json:"talking,omitempty"`
+	Command string    `json:"command,omitempty"`
+	Expect  *Expect   `json:"expect,omitempty"`
+
+	at     time.Duration
+	button gotogen.MenuButton
+}
+
+// Expect is the state expected after a step. Empty fields aren't checked.
+type Expect struct {
+	Status       string `json:"status,omitempty"`
+	Face         string `json:"face,omitempty"`
+	Menu         string `json:"menu,omitempty"`
+	Selected     string `json:"selected,omitempty"`
+	Warning      string `json:"warning,omitempty"`
+	DoNotDisturb *bool  `json:"dnd,omitempty"`
+	ExprLocked   *bool  `json:"locked,omitempty"`
+}
+
+// Result is how a scenario went.
+type Result struct {
+	Name string
+	// Failures are the expectations that weren't met, and anything else that went wrong.
+	Failures []string
+}
+
+// Passed returns whether every expectation was met.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Parse reads a scenario, and checks that its steps make sense.
+func Parse(r io.Reader) (*Scenario, error) {
+	var s Scenario
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, errors.New("scenario: " + err.Error())
+	}
+	if s.Framerate == 0 {
+		s.Framerate = 30
+	}
+	var last time.Duration
+	for i := range s.Steps {
+		st := &s.Steps[i]
+		d, err := time.ParseDuration(st.At)
+		if err != nil {
+			return nil, fmt.Errorf("scenario: step %d: %v", i+1, err)
+		}
+		if d < last {
+			return nil, fmt.Errorf("scenario: step %d: steps must be in order", i+1)
+		}
+		st.at, last = d, d
+		if st.Button != "" {
+			b, ok := parseButton(st.Button)
+			if !ok {
+				return nil, fmt.Errorf("scenario: step %d: unknown button %q", i+1, st.Button)
+			}
+			st.button = b
+		}
+	}
+	return &s, nil
+}
+
+func parseButton(name string) (gotogen.MenuButton, bool) {
+	for b := gotogen.MenuButtonMenu; b <= gotogen.MenuButtonNextFace; b++ {
+		if b.String() == name {
+			return b, true
+		}
+	}
+	return gotogen.MenuButtonNone, false
+}
+
+// randomButtons are the buttons pressed at random.
+var randomButtons = [...]gotogen.MenuButton{gotogen.MenuButtonMenu, gotogen.MenuButtonBack, gotogen.MenuButtonUp,
+	gotogen.MenuButtonDown, gotogen.MenuButtonDefault}
+
+// Run runs the scenario against a new Gotogen. Anything Gotogen prints still goes to standard error.
+func Run(s *Scenario) Result {
+	return run(s, nil)
+}
+
+// run runs the scenario with a driver that adds the items to the hardware menu.
+func run(s *Scenario, items []gotogen.Item) (res Result) {
+	res.Name = s.Name
+	fail := func(at time.Duration, msg string) {
+		res.Failures = append(res.Failures, at.String()+": "+msg)
+	}
+	start := time.Now()
+	defer func() {
+		if v := recover(); v != nil {
+			fail(time.Since(start), fmt.Sprint("panic: ", v))
+		}
+	}()
+
+	d := &driver{face: newDisplay(64, 32), items: items}
+	g, err := gotogen.New(s.Framerate, newDisplay(128, 64), blinker{}, d)
+	if err != nil {
+		fail(0, "new: "+err.Error())
+		return res
+	}
+	if err = g.Init(); err != nil {
+		fail(0, "init: "+err.Error())
+		return res
+	}
+
+	frame := time.Second / time.Duration(s.Framerate)
+	start = time.Now()
+	next := 0
+	var expects []Step
+	random, rng := 0, s.Seed|1
+	for next < len(s.Steps) || len(expects) > 0 || random > 0 {
+		now := time.Since(start)
+		for ; next < len(s.Steps) && s.Steps[next].at <= now; next++ {
+			st := s.Steps[next]
+			if st.button != gotogen.MenuButtonNone {
+				d.button = st.button
+			}
+			if st.Boop != nil {
+				d.boop = *st.Boop
+			}
+			if st.Accel != nil {
+				d.accel = *st.Accel
+			}
+			if st.Talking != nil {
+				d.talking = *st.Talking
+			}
+			if st.Command != "" {
+				if err := g.Command(st.Command); err != nil {
+					fail(st.at, "command "+st.Command+": "+err.Error())
+				}
+			}
+			if st.Expect != nil {
+				expects = append(expects, st)
+			}
+			random += st.Random
+		}
+		if random > 0 {
+			random--
+			rng = rng*1664525 + 1013904223
+			d.button = randomButtons[(rng>>16)%uint32(len(randomButtons))]
+		}
+
+		if err := g.RunTick(); err != nil {
+			fail(now, "tick: "+err.Error())
+			return res
+		}
+		for _, st := range expects {
+			for _, msg := range check(g.Snapshot(), st.Expect) {
+				fail(st.at, msg)
+			}
+		}
+		expects = expects[:0]
+		if snap := g.Snapshot(); snap.MenuIndex > 0 && snap.MenuIndex >= snap.MenuLen {
+			fail(now, fmt.Sprintf("menu %q has item %d of %d selected", snap.Menu, snap.MenuIndex+1, snap.MenuLen))
+		}
+		time.Sleep(frame)
+	}
+	return res
+}
+
+// check compares the state to the expectations.
+func check(s gotogen.Snapshot, e *Expect) []string {
+	var fails []string
+	str := func(what, got, want string) {
+		if want != "" && got != want {
+			fails = append(fails, fmt.Sprintf("%s is %q, expected %q", what, got, want))
+		}
+	}
+	boolean := func(what string, got bool, want *bool) {
+		if want != nil && got != *want {
+			fails = append(fails, fmt.Sprintf("%s is %v, expected %v", what, got, *want))
+		}
+	}
+	str("status", s.Status, e.Status)
+	str("face", s.Face, e.Face)
+	str("menu", s.Menu, e.Menu)
+	str("selected", s.Selected, e.Selected)
+	str("warning", s.Warning, e.Warning)
+	boolean("dnd", s.DoNotDisturb, e.DoNotDisturb)
+	boolean("locked", s.ExprLocked, e.ExprLocked)
+	return fails
+}
+
+// driver is a Driver whose buttons and sensors are set by the scenario.
+type driver struct {
+	face    *display
+	button  gotogen.MenuButton
+	boop    uint8
+	accel   [3]int32
+	talking bool
+	items   []gotogen.Item
+}
+
+func (d *driver) EarlyInit() (gotogen.Display, error) { return d.face, nil }
+
+func (d *driver) LateInit(gotogen.BootReporter) {}
+
+// PressedButton returns the scenario's button press once.
+func (d *driver) PressedButton() gotogen.MenuButton {
+	b := d.button
+	d.button = gotogen.MenuButtonNone
+	return b
+}
+
+func (d *driver) MenuItems() []gotogen.Item { return d.items }
+
+func (d *driver) BoopDistance() (uint8, gotogen.SensorStatus) {
+	return d.boop, gotogen.SensorStatusAvailable
+}
+
+func (d *driver) Accelerometer() (x, y, z int32, status gotogen.SensorStatus) {
+	return d.accel[0], d.accel[1], d.accel[2], gotogen.SensorStatusAvailable
+}
+
+func (d *driver) Talking() bool { return d.talking }
+
+func (d *driver) StatusLine() string { return "scenario" }
+
+// display is an in-memory display.
+type display struct {
+	*framebuf.Buffer
+}
+
+func newDisplay(w, h int16) *display {
+	return &display{framebuf.New(w, h)}
+}
+
+func (*display) CanUpdateNow() bool { return true }
+
+type blinker struct{}
+
+func (blinker) Low() {}
+
+func (blinker) High() {}
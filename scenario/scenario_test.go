@@ -0,0 +1,117 @@
+package scenario
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ajanata/gotogen"
+)
+
+// framerate is fast, so that the tests don't take long to press a lot of buttons.
+const framerate = 500
+
+// presses makes a scenario that presses the buttons one after another, and then presses random ones.
+func presses(t testing.TB, seed uint32, random int, buttons ...string) *Scenario {
+	s := &Scenario{Name: t.Name(), Framerate: framerate, Seed: seed}
+	at := 10 * time.Millisecond
+	for _, name := range buttons {
+		b, ok := parseButton(name)
+		if !ok {
+			t.Fatalf("unknown button %q", name)
+		}
+		s.Steps = append(s.Steps, Step{at: at, button: b})
+		at += 10 * time.Millisecond
+	}
+	s.Steps = append(s.Steps, Step{at: at, Random: random})
+	return s
+}
+
+// oddItems are menu items that drivers can give that have nothing in them.
+func oddItems() []gotogen.Item {
+	return []gotogen.Item{
+		&gotogen.Menu{Name: "Empty"},
+		&gotogen.SettingItem{Name: "No options"},
+		&gotogen.SettingItem{Name: "No options, active", Active: 3, Default: 2},
+		&gotogen.Menu{Name: "Nested", Items: []gotogen.Item{
+			&gotogen.Menu{Name: "Empty too"},
+			&gotogen.SettingItem{Name: "No options either"},
+		}},
+		&gotogen.ActionItem{Name: "Nothing", Invoke: func() {}},
+	}
+}
+
+func checkPassed(t *testing.T, r Result) {
+	t.Helper()
+	for _, f := range r.Failures {
+		t.Error(f)
+	}
+}
+
+func TestEmptyMenus(t *testing.T) {
+	// open the menu and the hardware menu, then go into each of the odd items and press everything inside it
+	inside := []string{"up", "down", "default", "menu", "up", "down", "back"}
+	buttons := []string{"back", "menu", "menu"}
+	for range oddItems() {
+		buttons = append(buttons, "menu")
+		buttons = append(buttons, inside...)
+		buttons = append(buttons, "down")
+	}
+	s := presses(t, 1, 0, buttons...)
+	// make sure that the first of them was really opened
+	s.Steps = append(s.Steps[:4:4], append([]Step{{at: s.Steps[3].at, Expect: &Expect{Menu: "Empty"}}},
+		s.Steps[4:]...)...)
+	checkPassed(t, run(s, oddItems()))
+}
+
+func TestNoDriverItems(t *testing.T) {
+	for _, items := range [][]gotogen.Item{nil, {}} {
+		checkPassed(t, run(presses(t, 1, 100, "back", "menu", "menu", "up", "down", "menu"), items))
+	}
+}
+
+// TestMashOddMenus checks that however the buttons are pressed, the menus with nothing in them don't panic or select
+// something that isn't there.
+func TestMashOddMenus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("presses a lot of buttons")
+	}
+	for seed := uint32(1); seed <= 8; seed++ {
+		checkPassed(t, run(presses(t, seed, 300, "back", "menu", "menu"), oddItems()))
+	}
+}
+
+// FuzzMenus builds menus of odd items from the input, and presses buttons at random in them.
+func FuzzMenus(f *testing.F) {
+	f.Add(uint32(1), []byte{0, 1, 2, 3})
+	f.Add(uint32(2), []byte{3, 3, 3, 0})
+	f.Add(uint32(3), []byte{})
+	f.Fuzz(func(t *testing.T, seed uint32, shape []byte) {
+		if len(shape) > 32 {
+			shape = shape[:32]
+		}
+		checkPassed(t, run(presses(t, seed, 100, "back", "menu", "menu"), buildItems(&shape, 0)))
+	})
+}
+
+// buildItems makes a list of items from the shape, which is used up as it goes: each byte is an empty menu, a setting
+// without options, a setting with one option, or a menu of the items that follow, up to a 0xFF.
+func buildItems(shape *[]byte, depth int) []gotogen.Item {
+	var items []gotogen.Item
+	for len(*shape) > 0 {
+		b := (*shape)[0]
+		*shape = (*shape)[1:]
+		switch {
+		case b == 0xFF:
+			return items
+		case b%4 == 0:
+			items = append(items, &gotogen.Menu{Name: "Empty"})
+		case b%4 == 1:
+			items = append(items, &gotogen.SettingItem{Name: "No options", Active: b >> 2})
+		case b%4 == 2:
+			items = append(items, &gotogen.SettingItem{Name: "One option", Options: []string{"only"}, Active: b >> 2})
+		case depth < 4:
+			items = append(items, &gotogen.Menu{Name: "Menu", Items: buildItems(shape, depth+1)})
+		}
+	}
+	return items
+}
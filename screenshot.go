@@ -0,0 +1,143 @@
+package gotogen
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+
+	"golang.org/x/image/bmp"
+)
+
+const (
+	screenshotSetting = "shots"
+	// screenshotChunk is how many bytes of a screenshot are sent in each line when sending it over serial.
+	screenshotChunk = 192
+)
+
+// ScreenshotStorage is an optional interface that a Driver may implement if it can store screenshots, e.g. on an SD
+// card. Without it, screenshots are sent as base64 "screenshot NAME DATA" lines back to where commands come from (see
+// CommandReplier), ending with a "screenshot NAME end" line; gotogenctl can receive them.
+type ScreenshotStorage interface {
+	SaveScreenshot(name string, data []byte) error
+}
+
+// PixelReader is an optional interface that a monochrome status display may implement if it can report what is
+// currently drawn on it, so that it can be included in screenshots. ssd1306.Device implements it.
+type PixelReader interface {
+	GetPixel(x, y int16) bool
+}
+
+// composedPixel returns the pixel as it is being sent to the face, with any effect, tint, and brightness applied.
+func (g *Gotogen) composedPixel(x, y int16) color.RGBA {
+	var c color.RGBA
+	if g.effect != nil {
+		c = g.effect.Pixel(g.frame, x, y)
+	} else {
+		c = g.frame.At(x, y)
+	}
+	if g.colorScaled {
+		c = g.scaleColor(c)
+	}
+	return c
+}
+
+// Screenshot saves the face as it is currently being shown, and the status display if enabled and possible.
+//
+// Screenshot must be called from the same goroutine as RunTick; other goroutines can use Post.
+func (g *Gotogen) Screenshot() error {
+	g.owner.check()
+	n := 1
+	if b, ok := g.loadSetting(screenshotSetting); ok && len(b) == 2 {
+		n = int(b[0])<<8 | int(b[1]) + 1
+	}
+	g.saveSetting(screenshotSetting, []byte{uint8(n >> 8), uint8(n)})
+	name := "shot" + strconv.Itoa(n)
+
+	w, h := g.frame.Size()
+	face := image.NewRGBA(image.Rect(0, 0, int(w), int(h)))
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			c := g.composedPixel(x, y)
+			c.A = 0xFF
+			face.SetRGBA(int(x), int(y), c)
+		}
+	}
+	err := g.saveScreenshot(name, face)
+	if err != nil {
+		return err
+	}
+
+	pr, ok := g.statusDisplay.(PixelReader)
+	if !g.screenshotStatus || !ok {
+		return nil
+	}
+	w, h = g.statusDisplay.Size()
+	status := image.NewRGBA(image.Rect(0, 0, int(w), int(h)))
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			c := color.RGBA{A: 0xFF}
+			if pr.GetPixel(x, y) {
+				c = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+			}
+			status.SetRGBA(int(x), int(y), c)
+		}
+	}
+	return g.saveScreenshot(name+"_status", status)
+}
+
+// saveScreenshot encodes the image and stores or sends it.
+func (g *Gotogen) saveScreenshot(name string, img image.Image) error {
+	var buf bytes.Buffer
+	var err error
+	if g.screenshotPNG {
+		name += ".png"
+		err = png.Encode(&buf, img)
+	} else {
+		name += ".bmp"
+		err = bmp.Encode(&buf, img)
+	}
+	if err != nil {
+		return err
+	}
+
+	if ss, ok := g.driver.(ScreenshotStorage); ok {
+		g.log(LogInfo, "saving screenshot "+name)
+		return ss.SaveScreenshot(name, buf.Bytes())
+	}
+	data := buf.Bytes()
+	for len(data) > 0 {
+		n := screenshotChunk
+		if n > len(data) {
+			n = len(data)
+		}
+		g.reply("screenshot " + name + " " + base64.StdEncoding.EncodeToString(data[:n]))
+		data = data[n:]
+	}
+	g.reply("screenshot " + name + " end")
+	return nil
+}
+
+func (g *Gotogen) screenshotMenu() *Menu {
+	return &Menu{
+		Name: "Screenshot",
+		Items: []Item{
+			&ActionItem{
+				Name:   "Take screenshot",
+				Invoke: func() { g.reportError(g.Screenshot()) },
+			},
+			&SettingItem{
+				Name:    "Format",
+				Options: []string{"BMP", "PNG"},
+				Apply:   func(selected uint8) { g.screenshotPNG = selected == 1 },
+			},
+			&SettingItem{
+				Name:    "Include status",
+				Options: []string{"off", "on"},
+				Apply:   func(selected uint8) { g.screenshotStatus = selected == 1 },
+			},
+		},
+	}
+}
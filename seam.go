@@ -0,0 +1,83 @@
+package gotogen
+
+import (
+	"image/color"
+
+	"github.com/ajanata/gotogen/internal/framebuf"
+	"github.com/ajanata/gotogen/internal/mirror"
+)
+
+const seamSetting = "seam"
+
+// maxBezelGap is the widest bezel gap that can be set, in pixels on each side of the middle.
+const maxBezelGap = 4
+
+// loadSeam loads how the middle of the face display is handled, kept separately for each face display profile.
+func (g *Gotogen) loadSeam() {
+	b, ok := g.loadSetting(g.profileSetting(seamSetting))
+	if ok && len(b) == 2 && b[0] <= uint8(mirror.SeamDuplicate) && b[1] <= maxBezelGap {
+		g.seam, g.bezelGap = mirror.Seam(b[0]), b[1]
+	}
+	g.applySeam()
+}
+
+// applySeam sets up the mirror for the seam settings. The face is a different size afterwards, so the framebuffer is
+// made again and the animation restarted on it.
+func (g *Gotogen) applySeam() {
+	m, ok := g.faceMirror.(*mirror.Mirror)
+	if !ok {
+		return
+	}
+	m.SetSeam(g.seam, int16(g.bezelGap))
+	w, h := m.Size()
+	if fw, fh := g.frame.Size(); fw == w && fh == h {
+		return
+	}
+	g.frame = framebuf.New(w, h)
+
+	// clear the whole display, since columns that used to be drawn may not be any more
+	dw, dh := g.faceDisplay.Size()
+	for y := int16(0); y < dh; y++ {
+		for x := int16(0); x < dw; x++ {
+			g.faceDisplay.SetPixel(x, y, color.RGBA{})
+		}
+	}
+	g.fullFlush = true
+	if g.activeAnim != nil {
+		g.activeAnim.Activate(g)
+	}
+}
+
+func (g *Gotogen) setSeam(selected uint8) {
+	g.seam = mirror.Seam(selected)
+	g.saveSeam()
+}
+
+func (g *Gotogen) setBezelGap(selected uint8) {
+	g.bezelGap = selected
+	g.saveSeam()
+}
+
+func (g *Gotogen) saveSeam() {
+	g.saveSetting(g.profileSetting(seamSetting), []byte{uint8(g.seam), g.bezelGap})
+	g.applySeam()
+}
+
+func (g *Gotogen) seamItems() []Item {
+	return []Item{
+		&SettingItem{
+			Name:    "Center seam",
+			Help:    "For panels with an odd total width: leave the middle column dark, or let both halves of the face share it.",
+			Options: []string{"skip", "duplicate"},
+			Active:  uint8(g.seam),
+			Apply:   g.setSeam,
+		},
+		&SettingItem{
+			Name:    "Bezel gap",
+			Help:    "How many pixels wide the gap between the left and right panels is, on each side, so art lines up across it.",
+			Options: []string{"0", "1", "2", "3", "4"},
+			Active:  g.bezelGap,
+			Apply:   g.setBezelGap,
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package gotogen
+
+import "github.com/ajanata/gotogen/internal/errcode"
+
+// SettingsStorage is an optional interface that a Driver may implement to persist settings across reboots, e.g. in a
+// flash partition or on an SD card. Keys are short ASCII strings; values are opaque to the driver.
+type SettingsStorage interface {
+	// LoadSetting returns the stored value for the key, or false if there is no stored value.
+	LoadSetting(key string) ([]byte, bool)
+	// SaveSetting stores the value for the key.
+	SaveSetting(key string, value []byte) error
+}
+
+// loadSetting loads a setting from the driver's storage, if it has any.
+func (g *Gotogen) loadSetting(key string) ([]byte, bool) {
+	ss, ok := g.driver.(SettingsStorage)
+	if !ok {
+		return nil, false
+	}
+	return ss.LoadSetting(key)
+}
+
+// saveSetting saves a setting to the driver's storage, if it has any. Failures are logged (as E40 SETTINGS_SAVE) but
+// otherwise ignored, since there isn't much that can be done about them.
+func (g *Gotogen) saveSetting(key string, value []byte) {
+	ss, ok := g.driver.(SettingsStorage)
+	if !ok {
+		return
+	}
+	err := ss.SaveSetting(key, value)
+	if err != nil {
+		g.log(LogError, errcode.Context("saving setting "+key, errcode.Wrap(errcode.SettingsSave, err)).Error())
+	}
+}
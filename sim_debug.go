@@ -0,0 +1,86 @@
+//go:build gotogendebug && !nosim
+
+package gotogen
+
+// simState holds fake sensor values injected from the Simulate menu. This is only built with the gotogendebug build
+// tag (and without nosim), for checking how animations react to sensors while on the workbench without the sensors
+// attached.
+type simState struct {
+	boop    uint8
+	boopSet bool
+	// 0 for not simulated, 1 for forced on, 2 for forced off
+	talking    uint8
+	accel      uint8
+	shakePhase bool
+}
+
+var simBoopValues = []uint8{0, 0, 64, 128, 192, 255}
+
+// simAccelValues are the simulated accelerometer readings, with the first one meaning no simulation and the last one
+// meaning shaking.
+var simAccelValues = [][3]int32{
+	{},
+	{-500, 0, 0},
+	{500, 0, 0},
+	{0, 500, 0},
+	{0, -500, 0},
+	{},
+}
+
+// simulate replaces sensor readings with simulated values, if any are set.
+func (g *Gotogen) simulate() {
+	if g.sim.boopSet {
+		g.boopDist = g.sim.boop
+	}
+	switch {
+	case g.sim.accel == uint8(len(simAccelValues)-1):
+		g.sim.shakePhase = !g.sim.shakePhase
+		v := int32(shakeThreshold)
+		if g.sim.shakePhase {
+			v = -v
+		}
+		g.checkEffectTriggers(v-g.aX, 0, 0)
+		g.aX, g.aY, g.aZ = v, 0, 0
+	case g.sim.accel > 0:
+		v := simAccelValues[g.sim.accel]
+		g.aX, g.aY, g.aZ = v[0], v[1], v[2]
+	}
+}
+
+// simTalking overrides whether the driver reports talking, returning the value and whether it is overridden.
+func (g *Gotogen) simTalking() (talking, ok bool) {
+	switch g.sim.talking {
+	case 1:
+		return true, true
+	case 2:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+func (g *Gotogen) simMenu() Item {
+	return &Menu{
+		Name: "Simulate",
+		Items: []Item{
+			&SettingItem{
+				Name:    "Boop",
+				Options: []string{"real", "0", "64", "128", "192", "255"},
+				Apply: func(selected uint8) {
+					g.sim.boopSet = selected > 0
+					g.sim.boop = simBoopValues[selected]
+				},
+			},
+			&SettingItem{
+				Name:    "Talking",
+				Options: []string{"real", "on", "off"},
+				Apply:   func(selected uint8) { g.sim.talking = selected },
+			},
+			&SettingItem{
+				Name:    "Accelerometer",
+				Options: []string{"real", "tilt left", "tilt right", "tilt up", "tilt down", "shake"},
+				Apply:   func(selected uint8) { g.sim.accel = selected },
+			},
+		},
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !gotogendebug || nosim
+
+package gotogen
+
+// simState is empty in normal builds and with the nosim build tag; see sim_debug.go.
+type simState struct{}
+
+func (g *Gotogen) simulate() {}
+
+func (g *Gotogen) simTalking() (talking, ok bool) { return false, false }
+
+func (g *Gotogen) simMenu() Item { return nil }
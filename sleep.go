@@ -0,0 +1,125 @@
+package gotogen
+
+import (
+	"image/color"
+	"time"
+)
+
+const (
+	// wornTime is how long the proximity sensor has to read close before the head is considered to be worn.
+	wornTime = 3 * time.Second
+	// notWornTime is how long the proximity sensor has to read far before the head is considered to be taken off.
+	notWornTime = 30 * time.Second
+	// wornThreshold is the boop distance at or above which the wearer's face is considered to be there.
+	wornThreshold = 64
+)
+
+// sleepReason is why the face is asleep (blank, with animations paused). The face sleeps while there is any reason.
+type sleepReason uint8
+
+const (
+	// sleepSetDown is when the head has been set down; see orientation.
+	sleepSetDown sleepReason = 1 << iota
+	// sleepNotWorn is when the proximity sensor says nobody is wearing the head.
+	sleepNotWorn
+	// sleepForced is when sleep was forced from the menu.
+	sleepForced
+)
+
+func (r sleepReason) String() string {
+	switch {
+	case r&sleepForced != 0:
+		return "asleep"
+	case r&sleepSetDown != 0:
+		return "set down"
+	case r&sleepNotWorn != 0:
+		return "not worn"
+	default:
+		return ""
+	}
+}
+
+// proximitySleep is how the proximity sensor controls sleep.
+type proximitySleep uint8
+
+const (
+	proximitySleepOff proximitySleep = iota
+	proximitySleepAuto
+	// proximitySleepForce keeps the face asleep regardless, e.g. on a stand at a booth.
+	proximitySleepForce
+)
+
+// asleep reports whether the face is asleep.
+func (g *Gotogen) asleep() bool {
+	return g.sleepReasons != 0
+}
+
+// sleep adds a reason for the face to sleep, blanking it if it was awake.
+func (g *Gotogen) sleep(r sleepReason) {
+	was := g.sleepReasons
+	g.sleepReasons |= r
+	if was == g.sleepReasons {
+		return
+	}
+	g.setWarning(g.sleepReasons.String())
+	if was != 0 {
+		return
+	}
+	g.log(LogInfo, "face going to sleep: "+r.String())
+	w, h := g.faceMirror.Size()
+	for x := int16(0); x < w; x++ {
+		for y := int16(0); y < h; y++ {
+			g.faceMirror.SetPixel(x, y, color.RGBA{})
+		}
+	}
+	g.fullFlush = true
+	g.faceDisplayed(g.flushFace())
+}
+
+// wake removes a reason for the face to sleep, resuming it where it left off if there are no reasons left.
+func (g *Gotogen) wake(r sleepReason) {
+	if g.sleepReasons&r == 0 {
+		return
+	}
+	if g.warning == g.sleepReasons.String() {
+		// only replace the warning if it is still ours
+		g.setWarning((g.sleepReasons &^ r).String())
+	}
+	g.sleepReasons &^= r
+	if g.sleepReasons != 0 {
+		return
+	}
+	g.log(LogInfo, "face waking up")
+	g.redrawFrame()
+}
+
+// updateProximity checks whether the head is being worn, from the proximity (boop) sensor.
+func (g *Gotogen) updateProximity() {
+	if g.proximitySleep != proximitySleepAuto {
+		return
+	}
+	now := time.Now()
+	worn := g.boopDist >= wornThreshold
+	if worn != g.wornReading {
+		g.wornReading = worn
+		g.wornSince = now
+		return
+	}
+	switch {
+	case worn && now.Sub(g.wornSince) > wornTime:
+		g.wake(sleepNotWorn)
+	case !worn && now.Sub(g.wornSince) > notWornTime:
+		g.sleep(sleepNotWorn)
+	}
+}
+
+func (g *Gotogen) setProximitySleep(selected uint8) {
+	g.proximitySleep = proximitySleep(selected)
+	g.wornSince = time.Now()
+	g.wake(sleepNotWorn)
+	if g.proximitySleep == proximitySleepForce {
+		g.sleep(sleepForced)
+	} else {
+		g.wake(sleepForced)
+	}
+}
@@ -0,0 +1,52 @@
+package gotogen
+
+// Snapshot is a summary of what Gotogen is doing, for tools that check it from outside, like the scenario runner.
+type Snapshot struct {
+	Tick uint32
+	// Status is the state of the status screen, e.g. "idle" or "menu".
+	Status string
+	// Face is the state of the face: "default", "animation", or "busy".
+	Face string
+	// Menu is the name of the open menu or setting, and Selected is its selected item or option. Both are empty when
+	// the menu isn't open.
+	Menu, Selected string
+	// MenuIndex is the position of the selected item or option, and MenuLen how many there are.
+	MenuIndex, MenuLen int
+	Warning            string
+	DoNotDisturb       bool
+	ExprLocked         bool
+}
+
+// Snapshot returns a summary of what Gotogen is doing right now.
+//
+// Snapshot must be called from the same goroutine as RunTick.
+func (g *Gotogen) Snapshot() Snapshot {
+	g.owner.check()
+	s := Snapshot{
+		Tick:         g.tick,
+		Status:       g.statusState.String(),
+		Face:         g.faceState.String(),
+		Warning:      g.warning,
+		DoNotDisturb: g.dnd,
+		ExprLocked:   g.exprLocked(),
+	}
+	if g.statusState != statusStateMenu {
+		return s
+	}
+	if g.activeMenu != nil {
+		s.MenuIndex, s.MenuLen = int(g.activeMenu.Selected()), int(g.activeMenu.Len())
+	}
+	switch m := g.activeMenu.(type) {
+	case *Menu:
+		s.Menu = m.Name
+		if int(m.selected) < len(m.Items) {
+			s.Selected = m.Items[m.selected].name()
+		}
+	case *SettingItem:
+		s.Menu = m.Name
+		if int(m.selected) < len(m.Options) {
+			s.Selected = m.Options[m.selected]
+		}
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package gotogen
+
+import (
+	"errors"
+	"image/color"
+
+	"github.com/ajanata/textbuf"
+)
+
+const (
+	largePrintSetting = "largeprint"
+	// largePrintScale is how much bigger everything on the status display is in large print.
+	largePrintScale = 2
+	// minStatusCols and minStatusRows are the least text the status screens can be laid out in. Large print is refused
+	// on displays that would have less than this.
+	minStatusCols = 20
+	minStatusRows = 4
+)
+
+// StatusFontProvider is an optional interface that a Driver may implement to use another font size on the status
+// display than the default 6x8, e.g. a bigger one on a high resolution screen. It has to leave room for at least 20
+// characters across, or Init fails. Large print doubles whatever it is.
+type StatusFontProvider interface {
+	StatusFont() textbuf.FontSize
+}
+
+// scaledDisplay draws every pixel as a square block of pixels on the display underneath, so that text drawn on it is
+// that much bigger.
+type scaledDisplay struct {
+	display Display
+	scale   int16
+}
+
+func (d scaledDisplay) Size() (x, y int16) {
+	w, h := d.display.Size()
+	return w / d.scale, h / d.scale
+}
+
+func (d scaledDisplay) Display() error {
+	return d.display.Display()
+}
+
+func (d scaledDisplay) CanUpdateNow() bool {
+	return d.display.CanUpdateNow()
+}
+
+func (d scaledDisplay) SetPixel(x, y int16, c color.RGBA) {
+	for dy := int16(0); dy < d.scale; dy++ {
+		for dx := int16(0); dx < d.scale; dx++ {
+			d.display.SetPixel(x*d.scale+dx, y*d.scale+dy, c)
+		}
+	}
+}
+
+func (g *Gotogen) statusFont() textbuf.FontSize {
+	if sfp, ok := g.driver.(StatusFontProvider); ok {
+		return sfp.StatusFont()
+	}
+	return textbuf.FontSize6x8
+}
+
+// newStatusText makes the text buffer for the status display, at the driver's font size and doubled in large print.
+// The default menu renderer is moved over to it, and the layout is picked for how many lines there are.
+func (g *Gotogen) newStatusText() error {
+	var canvas Display = g.statusDisplay
+	if g.largePrint {
+		canvas = scaledDisplay{display: g.statusDisplay, scale: largePrintScale}
+	}
+	buf, err := textbuf.New(canvas, g.statusFont())
+	if err != nil {
+		return err
+	}
+	tw, th := buf.Size()
+	if tw < minStatusCols || th < minStatusRows {
+		return errors.New("unusably small status display")
+	}
+	buf.AutoFlush = true
+	g.statusText, g.statusCanvas = newGlyphText(buf, canvas), canvas
+	// displays with only room for the four idle lines, like 128x32 ones, have the status screen laid out more tightly
+	g.compactStatus = th < 8
+	if _, ok := g.menuRenderer.(*textMenuRenderer); ok || g.menuRenderer == nil {
+		g.SetMenuRenderer(nil)
+	}
+	return nil
+}
+
+func (g *Gotogen) loadLargePrint() {
+	b, ok := g.loadSetting(largePrintSetting)
+	if ok && len(b) == 1 && b[0] == 1 {
+		g.setLargePrint(1)
+	}
+}
+
+func (g *Gotogen) largePrintActive() uint8 {
+	if g.largePrint {
+		return 1
+	}
+	return 0
+}
+
+// setLargePrint turns large print on or off. If the status display is too small for it, it stays off.
+func (g *Gotogen) setLargePrint(selected uint8) {
+	on := selected == 1
+	if on == g.largePrint {
+		return
+	}
+	g.largePrint = on
+	if err := g.newStatusText(); err != nil {
+		g.largePrint = !on
+		g.log(LogWarning, "large print: "+err.Error())
+		if g.largePrintItem != nil {
+			g.largePrintItem.Active = g.largePrintActive()
+		}
+		return
+	}
+	g.saveSetting(largePrintSetting, []byte{selected})
+	g.clearStatusScreen()
+}
+
+func (g *Gotogen) largePrintMenuSetting() *SettingItem {
+	g.largePrintItem = &SettingItem{
+		Name:    "Large print",
+		Help:    "Doubles the size of the text on the status screen, with fewer things on each screen. It needs room for 20 characters across, e.g. a display 240 pixels wide with the default font. There is no room for the copy of the face.",
+		Options: []string{"off", "on"},
+		Active:  g.largePrintActive(),
+		Apply:   g.setLargePrint,
+	}
+	return g.largePrintItem
+}
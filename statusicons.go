@@ -0,0 +1,97 @@
+package gotogen
+
+import (
+	"image/color"
+	"strconv"
+)
+
+const (
+	// statusIconSize is the width and height of a status icon.
+	statusIconSize = 8
+	// statusIconSpacing is the space left of each status icon.
+	statusIconSpacing = 1
+	// maxStatusIcons is how many status icons can be shown.
+	maxStatusIcons = 4
+)
+
+// StatusIcon is a small icon that a driver can show in the header line of the idle status screen, e.g. WiFi strength
+// or whether an SD card is present.
+type StatusIcon struct {
+	// Images are the 8x8 images for each state of the icon, as one byte per row from top to bottom, with the leftmost
+	// pixel in the high bit.
+	Images [][statusIconSize]uint8
+	// State returns the index of the image to show, or -1 to hide the icon. It is called every time the idle status
+	// screen is updated, so it must be fast.
+	State func() int
+}
+
+// StatusIconProvider is an optional interface that a Driver may implement to show icons on the status screen. The
+// icons are drawn right-aligned in the header line, in order from right to left.
+type StatusIconProvider interface {
+	StatusIcons() []StatusIcon
+}
+
+// loadStatusIcons gets the driver's status icons, if it has any.
+func (g *Gotogen) loadStatusIcons() {
+	sip, ok := g.driver.(StatusIconProvider)
+	if !ok {
+		return
+	}
+	g.statusIcons = sip.StatusIcons()
+	if len(g.statusIcons) > maxStatusIcons {
+		g.log(LogWarning, "too many status icons, only showing "+strconv.Itoa(maxStatusIcons))
+		g.statusIcons = g.statusIcons[:maxStatusIcons]
+	}
+	for i := range g.iconStates {
+		g.iconStates[i] = -1
+	}
+}
+
+// updateIconStates checks the state of every icon, and returns whether any of them changed and how many are visible.
+func (g *Gotogen) updateIconStates() (changed bool, visible int) {
+	for i, icon := range g.statusIcons {
+		s := icon.State()
+		if s >= len(icon.Images) {
+			s = -1
+		}
+		if s != g.iconStates[i] {
+			g.iconStates[i] = s
+			changed = true
+		}
+		if s >= 0 {
+			visible++
+		}
+	}
+	return changed, visible
+}
+
+// headerChars returns how many characters of the header line are not covered by visible icons.
+func (g *Gotogen) headerChars(visible int) int {
+	w, _ := g.statusCanvas.Size()
+	tw, _ := g.statusText.Size()
+	return (int(w) - visible*(statusIconSize+statusIconSpacing)) * int(tw) / int(w)
+}
+
+// drawStatusIcons draws the visible icons, right-aligned on the header line.
+func (g *Gotogen) drawStatusIcons() {
+	w, _ := g.statusCanvas.Size()
+	x := w
+	on := color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	for i, icon := range g.statusIcons {
+		s := g.iconStates[i]
+		if s < 0 {
+			continue
+		}
+		x -= statusIconSize + statusIconSpacing
+		img := icon.Images[s]
+		for row := int16(0); row < statusIconSize; row++ {
+			for col := int16(-statusIconSpacing); col < statusIconSize; col++ {
+				c := color.RGBA{}
+				if col >= 0 && img[row]&(0x80>>col) != 0 {
+					c = on
+				}
+				g.statusCanvas.SetPixel(x+col+statusIconSpacing, row, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package gotogen
+
+import (
+	"image/color"
+)
+
+// boopThreshold is the boop distance above which the face is considered booped, until the boop sensor is calibrated.
+// TODO define the normalization of the boop distance
+const boopThreshold = 128
+
+// StatusLED is an optional interface that a Blinker may implement if it is an RGB LED. The color is used to indicate
+// what state Gotogen is in; High and Low still turn the LED on and off.
+type StatusLED interface {
+	Blinker
+	// SetColor sets the color of the LED for when it is on. The color has already been scaled for brightness.
+	SetColor(c color.RGBA)
+}
+
+// ledState is what the status LED is indicating.
+type ledState uint8
+
+const (
+	ledStateIdle ledState = iota
+	ledStateMenu
+	ledStateBoop
+	ledStateError
+	ledStateCount
+)
+
+// ledColors are the colors available for each LED state in the settings menu.
+var ledColors = []struct {
+	name string
+	c    color.RGBA
+}{
+	{"off", color.RGBA{}},
+	{"red", color.RGBA{R: 0xFF}},
+	{"green", color.RGBA{G: 0xFF}},
+	{"blue", color.RGBA{B: 0xFF}},
+	{"purple", color.RGBA{R: 0xFF, B: 0xFF}},
+	{"yellow", color.RGBA{R: 0xFF, G: 0xFF}},
+	{"cyan", color.RGBA{G: 0xFF, B: 0xFF}},
+	{"white", color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF}},
+}
+
+// defaultLEDColors are the indexes into ledColors for each ledState.
+var defaultLEDColors = [ledStateCount]uint8{2, 3, 4, 1}
+
+// currentLEDState determines what the status LED should be indicating right now.
+func (g *Gotogen) currentLEDState() ledState {
+	switch {
+	case g.warning != "":
+		return ledStateError
+	case g.statusState == statusStateMenu:
+		return ledStateMenu
+	case g.booped():
+		return ledStateBoop
+	default:
+		return ledStateIdle
+	}
+}
+
+// setLEDColor sets the status LED color for the state, if the Blinker is an RGB LED.
+func (g *Gotogen) setLEDColor(state ledState) {
+	g.setLEDColorIndex(g.ledColors[state])
+}
+
+// setLEDColorIndex sets the status LED to one of ledColors, if the Blinker is an RGB LED.
+func (g *Gotogen) setLEDColorIndex(i uint8) {
+	led, ok := g.blinker.(StatusLED)
+	if !ok {
+		return
+	}
+	c := ledColors[i].c
+	c.R = uint8(uint16(c.R) * uint16(g.ledBrightness) / 0xFF)
+	c.G = uint8(uint16(c.G) * uint16(g.ledBrightness) / 0xFF)
+	c.B = uint8(uint16(c.B) * uint16(g.ledBrightness) / 0xFF)
+	c.A = 0xFF
+	led.SetColor(c)
+}
+
+func (g *Gotogen) ledMenu() *Menu {
+	names := make([]string, len(ledColors))
+	for i, c := range ledColors {
+		names[i] = c.name
+	}
+	setting := func(name string, state ledState) Item {
+		return &SettingItem{
+			Name:    name,
+			Options: names,
+			Active:  g.ledColors[state],
+			Apply:   func(selected uint8) { g.ledColors[state] = selected },
+		}
+	}
+	return &Menu{
+		Name: "Status LED",
+		Items: []Item{
+			&SettingItem{
+				Name:    "Brightness",
+				Options: []string{"10%", "25%", "50%", "100%"},
+				Active:  2,
+				Apply:   g.setLEDBrightness,
+			},
+			setting("Idle color", ledStateIdle),
+			setting("Menu color", ledStateMenu),
+			setting("Boop color", ledStateBoop),
+			setting("Error color", ledStateError),
+		},
+	}
+}
+
+func (g *Gotogen) setLEDBrightness(selected uint8) {
+	switch selected {
+	case 0:
+		g.ledBrightness = 0x1A
+	case 1:
+		g.ledBrightness = 0x40
+	case 2:
+		g.ledBrightness = 0x80
+	case 3:
+		g.ledBrightness = 0xFF
+	}
+}
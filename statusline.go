@@ -0,0 +1,33 @@
+package gotogen
+
+import "bytes"
+
+// statusLine caches the formatted contents of one line of the idle status screen, so that it is only sent to the
+// text buffer (and from there, to the display) when the contents actually change. The buffers are reused every frame
+// to avoid allocating in the main loop.
+type statusLine struct {
+	buf   []byte
+	last  []byte
+	valid bool
+}
+
+// reset empties the line to be built again. The previous contents are retained for comparison.
+func (l *statusLine) reset() {
+	l.buf = l.buf[:0]
+}
+
+// invalidate forces the next flush to write out the line, e.g. after the screen has been cleared.
+func (l *statusLine) invalidate() {
+	l.valid = false
+}
+
+// flush writes the line to the text buffer if it changed since the last flush, and reports whether it did so.
+func (l *statusLine) flush(buf *glyphText, y int16) bool {
+	if l.valid && bytes.Equal(l.buf, l.last) {
+		return false
+	}
+	l.last = append(l.last[:0], l.buf...)
+	l.valid = true
+	_ = buf.SetLine(y, string(l.buf))
+	return true
+}
@@ -0,0 +1,133 @@
+package gotogen
+
+import (
+	"time"
+
+	"github.com/ajanata/gotogen/internal/fmtlite"
+)
+
+// statusPageRotations are how long each page is shown for each Page rotation setting. The first is off.
+var statusPageRotations = [...]time.Duration{0, 5 * time.Second, 10 * time.Second, 30 * time.Second}
+
+// StatusRenderer is what a StatusPage draws with: the lines of text above the preview of the face on the idle status
+// screen, below the page's name.
+type StatusRenderer interface {
+	// Size returns how many characters fit on a line, and how many lines there are.
+	Size() (cols, rows int16)
+	// SetLine sets the text of a line, counting from 0. Lines that aren't set are blank.
+	SetLine(row int16, text string)
+}
+
+// StatusPage is a page of the idle status screen provided by a driver, e.g. for WiFi diagnostics or tuning a sensor.
+type StatusPage struct {
+	Name string
+	// Render draws the page. It is called every time the idle status screen is updated, so it must be fast; lines are
+	// only sent to the display when they change.
+	Render func(r StatusRenderer)
+}
+
+// StatusPageProvider is an optional interface that a Driver may implement to add pages to the idle status screen.
+// Up and Down move between the built-in page and the driver's pages, and they can also rotate on their own.
+type StatusPageProvider interface {
+	StatusPages() []StatusPage
+}
+
+// statusPager is which page of the idle status screen is shown. Page 0 is the built-in one, and the rest are the
+// driver's pages.
+type statusPager struct {
+	pages   []StatusPage
+	current int
+	rotate  time.Duration
+	next    time.Time
+}
+
+// pageRenderer draws a driver's page into the idle lines after the header.
+type pageRenderer struct {
+	g *Gotogen
+}
+
+func (r pageRenderer) Size() (cols, rows int16) {
+	w, _ := r.g.statusText.Size()
+	return w, int16(len(r.g.idleLines) - 1)
+}
+
+func (r pageRenderer) SetLine(row int16, text string) {
+	if row < 0 || int(row) >= len(r.g.idleLines)-1 {
+		return
+	}
+	l := &r.g.idleLines[row+1]
+	l.reset()
+	l.buf = append(l.buf, text...)
+}
+
+// loadStatusPages gets the driver's status pages, if it has any.
+func (g *Gotogen) loadStatusPages() {
+	spp, ok := g.driver.(StatusPageProvider)
+	if !ok {
+		return
+	}
+	g.pager.pages = spp.StatusPages()
+}
+
+// changeStatusPage moves to another page of the idle status screen, wrapping around.
+func (g *Gotogen) changeStatusPage(delta int) {
+	p := &g.pager
+	if len(p.pages) == 0 {
+		return
+	}
+	n := len(p.pages) + 1
+	p.current = ((p.current+delta)%n + n) % n
+	p.next = time.Now().Add(p.rotate)
+	// start over with a clean screen for the new page
+	g.changeStatusState(statusStateIdle)
+}
+
+// rotateStatusPage moves to the next page of the idle status screen when it is time to.
+func (g *Gotogen) rotateStatusPage() {
+	p := &g.pager
+	if p.rotate == 0 || len(p.pages) == 0 || time.Now().Before(p.next) {
+		return
+	}
+	g.changeStatusPage(1)
+}
+
+// drawStatusPage draws the current driver page, with its name and number as the header.
+func (g *Gotogen) drawStatusPage() {
+	page := g.pager.pages[g.pager.current-1]
+	l := &g.idleLines[0]
+	l.reset()
+	l.buf = append(l.buf, page.Name...)
+	l.buf = append(l.buf, g.theme.Separator...)
+	l.buf = fmtlite.AppendInt(l.buf, int64(g.pager.current+1))
+	l.buf = append(l.buf, '/')
+	l.buf = fmtlite.AppendInt(l.buf, int64(len(g.pager.pages)+1))
+	changed := l.flush(g.statusText, 0)
+
+	for i := 1; i < len(g.idleLines); i++ {
+		g.idleLines[i].reset()
+	}
+	page.Render(pageRenderer{g: g})
+	for i := 1; i < len(g.idleLines); i++ {
+		changed = g.idleLines[i].flush(g.statusText, int16(i)) || changed
+	}
+	if changed {
+		g.statusDirty = true
+	}
+}
+
+func (g *Gotogen) statusPageRotateActive() uint8 {
+	for i, d := range statusPageRotations {
+		if d == g.pager.rotate {
+			return uint8(i)
+		}
+	}
+	return 0
+}
+
+func (g *Gotogen) setStatusPageRotate(selected uint8) {
+	if int(selected) >= len(statusPageRotations) {
+		return
+	}
+	g.pager.rotate = statusPageRotations[selected]
+	g.pager.next = time.Now().Add(g.pager.rotate)
+}
@@ -0,0 +1,105 @@
+//go:build gotogendebug
+
+package gotogen
+
+import (
+	"errors"
+	"strconv"
+)
+
+// stepper pauses the main loop so that it can be run a tick at a time, for debugging the state machines, which move
+// too fast to follow at full framerate. Time still passes while paused, so anything on a timer catches up all at once
+// on the next step. This is only built with the gotogendebug build tag, so that a normal build can't be stopped by a
+// command.
+type stepper struct {
+	paused bool
+	// steps is how many more ticks to run before pausing again
+	steps uint32
+}
+
+// maxSteps is the most ticks that can be stepped at once, since every one of them is replied to.
+const maxSteps = 600
+
+// pausedTick is called at the start of every tick. It returns whether the tick should be skipped; if it is, only
+// commands are taken, since they're how the loop is stepped or resumed. Ticks that are stepped reply with the state
+// when they are done.
+func (g *Gotogen) pausedTick() bool {
+	st := &g.stepper
+	if !st.paused {
+		return false
+	}
+	if st.steps == 0 {
+		g.pollCommands()
+		g.drainBus()
+		return true
+	}
+	st.steps--
+	return false
+}
+
+// stepping reports whether the main loop is paused, and so this tick is being stepped.
+func (g *Gotogen) stepping() bool {
+	return g.stepper.paused
+}
+
+// replyDebugState replies with what the state machines are up to, on one line.
+func (g *Gotogen) replyDebugState() {
+	s := g.Snapshot()
+	line := "debug tick=" + strconv.FormatUint(uint64(s.Tick), 10) + " face=" + s.Face + " anim=" + g.animDescription() +
+		" status=" + s.Status
+	if s.Menu != "" {
+		line += " menu=\"" + s.Menu + "\" selected=\"" + s.Selected + "\""
+	}
+	if e := g.currentExpression(); e != "" {
+		line += " expr=" + e
+	}
+	if g.stepper.paused {
+		line += " paused"
+	}
+	g.reply(line)
+}
+
+// animDescription says what is on the face: the face style for the default face, or the kind and file of the
+// full-screen animation.
+func (g *Gotogen) animDescription() string {
+	switch {
+	case g.faceState == faceStateDefault && g.faceStyle == faceStyleVector:
+		return "vector"
+	case g.faceState == faceStateDefault:
+		return "bitmap"
+	case g.animKind != "":
+		return g.animKind + ":" + g.animFile
+	default:
+		return "other"
+	}
+}
+
+// debugCommand handles "debug pause|resume|state" and "debug step [N]".
+func (g *Gotogen) debugCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("debug: need pause, step, resume or state")
+	}
+	st := &g.stepper
+	switch args[0] {
+	case "pause":
+		st.paused, st.steps = true, 0
+		g.replyDebugState()
+	case "resume":
+		st.paused, st.steps = false, 0
+	case "state":
+		g.replyDebugState()
+	case "step":
+		n := 1
+		if len(args) > 1 {
+			var err error
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 || n > maxSteps {
+				return errors.New("debug: step count must be 1 to " + strconv.Itoa(maxSteps))
+			}
+		}
+		st.paused, st.steps = true, uint32(n)
+	default:
+		return errors.New("debug: unknown subcommand " + args[0])
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build !gotogendebug
+
+package gotogen
+
+import "errors"
+
+// stepper is empty in normal builds; see stepper.go.
+type stepper struct{}
+
+func (g *Gotogen) pausedTick() bool { return false }
+
+func (g *Gotogen) stepping() bool { return false }
+
+func (g *Gotogen) replyDebugState() {}
+
+func (g *Gotogen) debugCommand([]string) error {
+	return errors.New("debug: only in debug builds")
+}
@@ -0,0 +1,66 @@
+package gotogen
+
+import (
+	"time"
+)
+
+const (
+	// talkShapes is how many talking mouth shapes loudness picks between, talk_0 for quiet to talk_3 for loud, as
+	// opposed to lip-sync from a host, which can use all of them.
+	talkShapes = 4
+	// talkQuiet is the level below which the mouth stays closed.
+	talkQuiet = 24
+	// talkRelease is how much of the way the smoothed level falls towards a quieter level each frame, out of 256. It
+	// rises right away, so the mouth opens on time but doesn't snap shut between syllables.
+	talkRelease = 64
+)
+
+// TalkingLevelSensor is an optional interface that a Driver may implement if it can tell how loud the wearer is
+// talking, not just whether they are. The mouth then opens wider the louder it is, instead of cycling through the
+// talking mouth shapes.
+type TalkingLevelSensor interface {
+	// TalkingLevel returns how loud the microphone is, from 0 for silence to 255 for as loud as the wearer gets. The
+	// second return value indicates the status of the microphone.
+	TalkingLevel() (uint8, SensorStatus)
+}
+
+// talkLevel is the smoothed loudness from a TalkingLevelSensor.
+type talkLevel struct {
+	level uint8
+	// active is whether the driver has a level for this frame
+	active bool
+}
+
+// updateTalkLevel reads the loudness for this frame.
+func (g *Gotogen) updateTalkLevel() {
+	t := &g.talkLevel
+	ls, ok := g.driver.(TalkingLevelSensor)
+	if _, sim := g.simTalking(); !ok || sim {
+		t.level, t.active = 0, false
+		return
+	}
+	start := time.Now()
+	level, st := ls.TalkingLevel()
+	g.timeDriverCall(driverCallTalkingLevel, start)
+	if st != SensorStatusAvailable {
+		t.level, t.active = 0, false
+		return
+	}
+	if g.micMuted {
+		level = 0
+	}
+	t.active = true
+	if level >= t.level {
+		t.level = level
+		return
+	}
+	t.level -= uint8((uint16(t.level-level)*talkRelease + 255) / 256)
+}
+
+// levelShape returns the talking mouth shape for how loud something is, or -1 for the closed mouth if it is quiet.
+func levelShape(level uint8) int8 {
+	if level < talkQuiet {
+		return -1
+	}
+	return int8(int(level-talkQuiet) * talkShapes / (256 - talkQuiet))
+}
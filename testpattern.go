@@ -0,0 +1,30 @@
+package gotogen
+
+import (
+	"github.com/ajanata/gotogen/internal/animation"
+	"github.com/ajanata/gotogen/internal/animation/testpattern"
+)
+
+// testPatternMenu puts test patterns on the face, for checking the panels after assembly. Back on the idle screen goes
+// back to the face. The patterns go to the panels exactly as they are drawn, without the tint, software brightness,
+// palette, panel colors or brightness curve.
+func (g *Gotogen) testPatternMenu() *Menu {
+	m := &Menu{
+		Name: "Test patterns",
+		Help: "Check alignment, mirroring, dead pixels and color order. The border's top left corner is red, top right green, and bottom left blue.",
+	}
+	for p := testpattern.Pattern(0); p < testpattern.PatternCount; p++ {
+		pattern := p
+		m.Items = append(m.Items, &ActionItem{
+			Name:   pattern.String(),
+			Invoke: func() { g.startAnimation(testpattern.New(pattern)) },
+		})
+	}
+	return m
+}
+
+// rawHinted reports whether the animation asks to be sent to the panels exactly as it is drawn.
+func rawHinted(a animation.Animation) bool {
+	ro, ok := a.(animation.RawOutput)
+	return ok && ro.RawOutput()
+}
@@ -0,0 +1,146 @@
+package gotogen
+
+import "unicode/utf8"
+
+// Icon identifies a status glyph that a Theme knows how to draw.
+type Icon uint8
+
+const (
+	IconBattery Icon = iota
+	IconBatteryLow
+	IconWiFi
+	IconBluetooth
+)
+
+// Theme controls how the status UI is drawn into the text buffer.
+type Theme struct {
+	Name string
+
+	// HeaderInverse draws menu headers in inverse video. Otherwise, HeaderPrefix and HeaderSuffix should be used to
+	// make the header stand out.
+	HeaderInverse bool
+	HeaderPrefix  string
+	HeaderSuffix  string
+
+	// SelectInverse draws the selected menu line in inverse video. SelectMarker is drawn in front of the selected line,
+	// and an equal number of spaces is drawn in front of every other line.
+	SelectInverse bool
+	SelectMarker  string
+
+	// Prefixes for the different types of menu items.
+	MenuPrefix    string
+	ActionPrefix  string
+	SettingPrefix string
+
+	// Markers in front of the options of a setting, for the currently-active option and every other option.
+	ActiveMarker   string
+	InactiveMarker string
+
+	// Separator goes between fields on the idle status screen.
+	Separator string
+
+	// Icons are single glyphs for status indicators, indexed by Icon.
+	Icons [4]string
+}
+
+// Icon returns the glyph for the icon in this theme.
+func (t *Theme) Icon(i Icon) string {
+	if int(i) >= len(t.Icons) {
+		return "?"
+	}
+	return t.Icons[i]
+}
+
+// line pads out the text with the selection marker (or the space for it) in front.
+func (t *Theme) line(text string, selected bool) string {
+	if t.SelectMarker == "" {
+		return text
+	}
+	if selected {
+		return t.SelectMarker + text
+	}
+	pad := make([]byte, utf8.RuneCountInString(t.SelectMarker))
+	for i := range pad {
+		pad[i] = ' '
+	}
+	return string(pad) + text
+}
+
+// Themes are the built-in themes that are selectable in the settings menu. The first one is the default.
+var Themes = []*Theme{
+	{
+		Name:           "classic",
+		HeaderInverse:  true,
+		SelectInverse:  true,
+		MenuPrefix:     "+",
+		ActionPrefix:   "*",
+		SettingPrefix:  ">",
+		ActiveMarker:   "*",
+		InactiveMarker: " ",
+		Separator:      " ",
+		Icons:          [4]string{"B", "b", "W", "*"},
+	},
+	{
+		Name:           "minimal",
+		HeaderPrefix:   "- ",
+		HeaderSuffix:   " -",
+		SelectMarker:   ">",
+		MenuPrefix:     "",
+		ActionPrefix:   "",
+		SettingPrefix:  "",
+		ActiveMarker:   "=",
+		InactiveMarker: " ",
+		Separator:      "|",
+		Icons:          [4]string{"b", "!", "w", "B"},
+	},
+	{
+		Name:           "brackets",
+		HeaderInverse:  true,
+		HeaderPrefix:   "[",
+		HeaderSuffix:   "]",
+		SelectInverse:  true,
+		SelectMarker:   ">",
+		MenuPrefix:     "[+]",
+		ActionPrefix:   "[*]",
+		SettingPrefix:  "[>]",
+		ActiveMarker:   "(*)",
+		InactiveMarker: "( )",
+		Separator:      ":",
+		Icons:          [4]string{"[B]", "[!]", "[W]", "[*]"},
+	},
+	{
+		Name:           "symbols",
+		HeaderInverse:  true,
+		SelectMarker:   "→",
+		MenuPrefix:     "+",
+		ActionPrefix:   "·",
+		SettingPrefix:  "±",
+		ActiveMarker:   "✓",
+		InactiveMarker: " ",
+		Separator:      " ",
+		Icons:          [4]string{string(GlyphBatteryFull), string(GlyphBatteryLow), "W", "*"},
+	},
+}
+
+// Theme returns the currently-active status UI theme. The idle status screen draws the battery icons, and the radio
+// icons for drivers that implement RadioDriver; drivers may use this to draw icons in their StatusLine too.
+func (g *Gotogen) Theme() *Theme {
+	return g.theme
+}
+
+func (g *Gotogen) setTheme(selected uint8) {
+	if int(selected) < len(Themes) {
+		g.theme = Themes[selected]
+		if r, ok := g.menuRenderer.(*textMenuRenderer); ok {
+			r.theme = g.theme
+		}
+	}
+}
+
+func themeNames() []string {
+	names := make([]string, len(Themes))
+	for i, t := range Themes {
+		names[i] = t.Name
+	}
+	return names
+}
@@ -0,0 +1,141 @@
+package gotogen
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/ajanata/gotogen/internal/framebuf"
+)
+
+// transitionTime is how long a transition between animations takes.
+const transitionTime = 500 * time.Millisecond
+
+// transitionKind is how one animation turns into the next, from the Transition setting.
+type transitionKind uint8
+
+const (
+	transitionCut transitionKind = iota
+	transitionFade
+	// transitionWipeLeft brings the new animation in from the right edge, and transitionWipeRight from the left.
+	transitionWipeLeft
+	transitionWipeRight
+	// transitionDissolve switches the pixels over one at a time in a scattered order.
+	transitionDissolve
+)
+
+// transition blends the last frame of the old animation into the new one as it plays. Like an effect, it takes over
+// sending the whole frame to the face until it is done.
+type transition struct {
+	kind transitionKind
+	// from is the last frame of the old animation
+	from  *framebuf.Buffer
+	start time.Time
+}
+
+// transitioning reports whether a transition is being drawn.
+func (g *Gotogen) transitioning() bool {
+	return !g.transition.start.IsZero()
+}
+
+// startTransition keeps the frame that is on the face now to blend from, as the face switches to something else. A
+// switch in the middle of a transition keeps blending from the frame before the first switch, so quick changes don't
+// jump.
+func (g *Gotogen) startTransition() {
+	t := &g.transition
+	if t.kind == transitionCut || g.frame == nil || g.asleep() || g.photoMode || !g.effectsAllowed() {
+		return
+	}
+	if !g.transitioning() {
+		if w, h := g.frame.Size(); t.from == nil || !sameSize(t.from, w, h) {
+			t.from = framebuf.New(w, h)
+		}
+		t.from.CopyFrom(g.frame)
+	}
+	t.start = time.Now()
+}
+
+func sameSize(b *framebuf.Buffer, w, h int16) bool {
+	bw, bh := b.Size()
+	return bw == w && bh == h
+}
+
+// applyTransition redraws the face as the blend of the old and new animations, if a transition is running. When it is
+// done, the new animation's frame is redrawn on its own.
+func (g *Gotogen) applyTransition() {
+	t := &g.transition
+	if !g.transitioning() {
+		return
+	}
+	elapsed := time.Since(t.start)
+	w, h := g.frame.Size()
+	if elapsed >= transitionTime || !sameSize(t.from, w, h) {
+		t.start = time.Time{}
+		if g.effect == nil {
+			g.redrawFrame()
+		}
+		return
+	}
+	// how far along the transition is, out of 256
+	p := int32(elapsed * 256 / transitionTime)
+	for y := int16(0); y < h; y++ {
+		for x := int16(0); x < w; x++ {
+			to := g.frame.At(x, y)
+			if g.effect != nil {
+				to = g.effect.Pixel(g.frame, x, y)
+			}
+			g.outputPixel(x, y, t.blend(t.from.At(x, y), to, x, y, w, p))
+		}
+	}
+}
+
+// blend works out a pixel of the transition, p/256 of the way from the old frame to the new one.
+func (t *transition) blend(from, to color.RGBA, x, y, w int16, p int32) color.RGBA {
+	switch t.kind {
+	case transitionFade:
+		return color.RGBA{
+			R: fadeChannel(from.R, to.R, p),
+			G: fadeChannel(from.G, to.G, p),
+			B: fadeChannel(from.B, to.B, p),
+			A: 0xFF,
+		}
+	case transitionWipeLeft:
+		if int32(x) >= int32(w)-int32(w)*p/256 {
+			return to
+		}
+	case transitionWipeRight:
+		if int32(x) < int32(w)*p/256 {
+			return to
+		}
+	case transitionDissolve:
+		if int32(dissolveOrder(x, y)) < p {
+			return to
+		}
+	default:
+		return to
+	}
+	return from
+}
+
+func fadeChannel(from, to uint8, p int32) uint8 {
+	return uint8(int32(from) + (int32(to)-int32(from))*p/256)
+}
+
+// dissolveOrder is when, out of 256, a pixel switches over in a dissolve. It is scattered so that the pixels switch
+// in no visible pattern, but the same every time.
+func dissolveOrder(x, y int16) uint8 {
+	h := uint32(x)*2654435761 ^ uint32(y)*40503
+	h ^= h >> 15
+	h *= 2246822519
+	h ^= h >> 13
+	return uint8(h)
+}
+
+func (g *Gotogen) transitionSetting() *SettingItem {
+	return &SettingItem{
+		Name:    "Transition",
+		Help:    "How the face changes over from one animation to the next: a straight cut, a crossfade, a wipe from one side, or a dissolve. Power saving turns them off.",
+		Options: []string{"cut", "fade", "wipe left", "wipe right", "dissolve"},
+		Active:  uint8(g.transition.kind),
+		Apply:   func(selected uint8) { g.transition.kind = transitionKind(selected) },
+	}
+}
@@ -1,6 +1,8 @@
 package gotogen
 
 import (
+	"image"
+
 	"tinygo.org/x/drivers"
 )
 
@@ -13,6 +15,14 @@ type Display interface {
 	CanUpdateNow() bool
 }
 
+// PartialDisplay is an optional interface that a face Display may implement to only send part of the framebuffer to
+// the hardware. This is useful for long chains of panels, where sending the entire framebuffer every frame uses a lot
+// of bandwidth while most of the face isn't changing.
+type PartialDisplay interface {
+	// DisplayRegion sends the part of the framebuffer inside r to the hardware.
+	DisplayRegion(r image.Rectangle) error
+}
+
 type MenuButton uint8
 
 const (
@@ -24,6 +34,11 @@ const (
 	// MenuButtonDefault is for resetting a specific setting to its default value. Drivers may wish to require this
 	// button to be held down for a second before triggering it, or perhaps make it be a chord of up and down.
 	MenuButtonDefault
+	// MenuButtonNextFace switches to the next full-screen animation while the status screen is idle. This is typically
+	// not a physical button on its own, but something a physical button can be remapped to.
+	MenuButtonNextFace
+
+	menuButtonCount
 )
 
 func (b MenuButton) String() string {
@@ -40,6 +55,8 @@ func (b MenuButton) String() string {
 		return "down"
 	case MenuButtonDefault:
 		return "default"
+	case MenuButtonNextFace:
+		return "next face"
 	default:
 		return "INVALID"
 	}
@@ -53,6 +70,15 @@ const (
 	statusStateIdle
 	statusStateMenu
 	statusStateBlank
+	statusStateRemap
+	statusStateEditor
+	statusStateCaption
+	statusStateProfile
+	statusStateHelp
+	statusStateCurve
+	statusStatePrompter
+	statusStatePreview
+	statusStateQR
 )
 
 func (s statusState) String() string {
@@ -65,6 +91,24 @@ func (s statusState) String() string {
 		return "menu"
 	case statusStateBlank:
 		return "blank"
+	case statusStateRemap:
+		return "remap"
+	case statusStateEditor:
+		return "editor"
+	case statusStateCaption:
+		return "caption"
+	case statusStateProfile:
+		return "profile"
+	case statusStateHelp:
+		return "help"
+	case statusStatePrompter:
+		return "prompter"
+	case statusStatePreview:
+		return "preview"
+	case statusStateCurve:
+		return "curve"
+	case statusStateQR:
+		return "qr"
 	default:
 		return "INVALID"
 	}
@@ -98,4 +142,24 @@ const (
 	colorChannelRed
 	colorChannelGreen
 	colorChannelBlue
+	// colorChannelLuma isn't a channel, but the brightness of the whole color as the eye sees it.
+	colorChannelLuma
+	// colorChannelDither is the luma, dithered rather than cut off, so that shading shows up.
+	colorChannelDither
 )
+
+// ButtonSet is a set of MenuButtons, used to report multiple buttons being held at once.
+type ButtonSet uint8
+
+// Has returns whether the button is in the set.
+func (s ButtonSet) Has(b MenuButton) bool {
+	return b != MenuButtonNone && s&(1<<(b-1)) != 0
+}
+
+// With returns a copy of the set that also contains the button.
+func (s ButtonSet) With(b MenuButton) ButtonSet {
+	if b == MenuButtonNone {
+		return s
+	}
+	return s | 1<<(b-1)
+}
@@ -0,0 +1,57 @@
+package gotogen
+
+import (
+	"image/color"
+
+	"github.com/ajanata/gotogen/internal/animation"
+)
+
+const faceStyleSetting = "facestyle"
+
+// face styles, in the order of the Face style setting
+const (
+	faceStyleBitmap uint8 = iota
+	faceStyleVector
+)
+
+// vectorFaceColor is what the vector face is drawn in, before tint.
+var vectorFaceColor = color.RGBA{R: 0x00, G: 0xC0, B: 0xFF, A: 0xFF}
+
+// defaultFace is the face shown when nothing else is: the bitmap face, or the vector face if it is turned on.
+func (g *Gotogen) defaultFace() animation.Animation {
+	if g.faceStyle == faceStyleVector && g.vectorFace != nil {
+		return g.vectorFace
+	}
+	return f
+}
+
+func (g *Gotogen) loadFaceStyle() {
+	b, ok := g.loadSetting(faceStyleSetting)
+	if ok && len(b) == 1 && b[0] <= faceStyleVector {
+		g.faceStyle = b[0]
+	}
+}
+
+func (g *Gotogen) setFaceStyle(selected uint8) {
+	g.faceStyle = selected
+	g.saveSetting(faceStyleSetting, []byte{selected})
+	if g.faceState == faceStateDefault {
+		g.returnToFace()
+	}
+}
+
+func (g *Gotogen) vectorFaceMenu() *Menu {
+	// the expressions are in the Expressions menu, since they change the bitmap face as well
+	return &Menu{
+		Name: "Vector face",
+		Items: []Item{
+			&SettingItem{
+				Name:    "Face style",
+				Help:    "The bitmap face swaps between images; the vector face is drawn from shapes and morphs smoothly between expressions.",
+				Options: []string{"bitmap", "vector"},
+				Active:  g.faceStyle,
+				Apply:   g.setFaceStyle,
+			},
+		},
+	}
+}
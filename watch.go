@@ -0,0 +1,16 @@
+//go:build !tinygo
+
+package gotogen
+
+import (
+	"github.com/ajanata/gotogen/internal/media"
+)
+
+// WatchMedia loads media from the directory on disk in preference to the built-in media, and reloads the face whenever
+// anything in it changes. The directory must have the same layout as the built-in media (media/eye, media/mouth, etc.),
+// and may contain BMP, PNG or GIF files.
+//
+// This is only available on OS-based builds (like a simulator), to speed up iterating on art.
+func WatchMedia(dir string) error {
+	return media.Watch(dir)
+}